@@ -9,7 +9,11 @@ import (
 
 	"github.com/kaspanet/kaspad/infrastructure/config"
 	"github.com/kaspanet/kaspad/infrastructure/db/database"
-	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
+
+	// Register the database backends that aren't otherwise used
+	_ "github.com/kaspanet/kaspad/infrastructure/db/database/boltdb"
+	_ "github.com/kaspanet/kaspad/infrastructure/db/database/memdb"
+
 	"github.com/kaspanet/kaspad/infrastructure/logger"
 	"github.com/kaspanet/kaspad/infrastructure/os/execenv"
 	"github.com/kaspanet/kaspad/infrastructure/os/limits"
@@ -18,11 +22,12 @@ import (
 	"github.com/kaspanet/kaspad/util/panics"
 	"github.com/kaspanet/kaspad/util/profiling"
 	"github.com/kaspanet/kaspad/version"
+	"github.com/pkg/errors"
 )
 
 const (
-	leveldbCacheSizeMiB = 256
-	defaultDataDirname  = "datadir2"
+	databaseCacheSizeMiB = 256
+	defaultDataDirname   = "datadir2"
 )
 
 var desiredLimits = &limits.DesiredLimits{
@@ -101,6 +106,26 @@ func (app *kaspadApp) main(startedChan chan<- struct{}) error {
 		}
 	}
 
+	// With --migrate-db-dry-run, kaspad only checks the migrations its database needs and exits
+	if app.cfg.MigrateDatabaseDryRun {
+		err := dryRunDatabaseMigrations(app.cfg)
+		if err != nil {
+			log.Errorf("The database migration dry run failed: %+v", err)
+			return err
+		}
+		return nil
+	}
+
+	// With --verify-db alone, kaspad checks its database without writing anything into it and exits
+	if app.cfg.VerifyDatabase && !app.cfg.RepairDatabase && app.cfg.ImportSnapshot == "" {
+		err := verifyDatabaseReadOnly(app.cfg)
+		if err != nil {
+			log.Errorf("Verifying the database failed: %+v", err)
+			return err
+		}
+		return nil
+	}
+
 	// Open the database
 	databaseContext, err := openDB(app.cfg)
 	if err != nil {
@@ -121,6 +146,44 @@ func (app *kaspadApp) main(startedChan chan<- struct{}) error {
 		return nil
 	}
 
+	if app.cfg.ImportSnapshot != "" {
+		err := importSnapshot(app.cfg, databaseContext)
+		if err != nil {
+			log.Errorf("Importing the snapshot failed: %+v", err)
+			return err
+		}
+	}
+
+	// With --backup-to, kaspad only backs up its database and exits
+	if app.cfg.BackupTo != "" {
+		_, err := database.Backup(app.cfg.DbType, databaseContext, app.cfg.BackupTo)
+		if err != nil {
+			log.Errorf("Backing up the database failed: %+v", err)
+			return err
+		}
+		return nil
+	}
+
+	// With --verify-db, kaspad only checks (and with --repair-db repairs) its database and exits
+	if app.cfg.VerifyDatabase {
+		err := verifyDatabase(app.cfg, databaseContext)
+		if err != nil {
+			log.Errorf("Verifying the database failed: %+v", err)
+			return err
+		}
+		return nil
+	}
+
+	// With --export-snapshot, kaspad only writes a snapshot of its pruning point state and exits
+	if app.cfg.ExportSnapshot != "" {
+		err := exportSnapshot(app.cfg, databaseContext)
+		if err != nil {
+			log.Errorf("Exporting a snapshot failed: %+v", err)
+			return err
+		}
+		return nil
+	}
+
 	// Create componentManager and start it.
 	componentManager, err := NewComponentManager(app.cfg, databaseContext, interrupt)
 	if err != nil {
@@ -171,18 +234,95 @@ func removeDatabase(cfg *config.Config) error {
 }
 
 func openDB(cfg *config.Config) (database.Database, error) {
-	dbPath := databasePath(cfg)
+	db, databaseVersion, err := openDBWithVersion(cfg)
+	if err != nil {
+		return nil, err
+	}
 
-	err := checkDatabaseVersion(dbPath)
+	err = migrateDatabase(db, databasePath(cfg), databaseVersion)
 	if err != nil {
+		db.Close()
 		return nil, err
 	}
 
-	log.Infof("Loading database from '%s'", dbPath)
-	db, err := ldb.NewLevelDB(dbPath, leveldbCacheSizeMiB)
+	return db, nil
+}
+
+// openDBWithVersion opens the database without migrating it, and returns it along with its version
+func openDBWithVersion(cfg *config.Config) (database.Database, int, error) {
+	driver, err := database.LookupDriver(cfg.DbType)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if !driver.IsPersistent {
+		log.Warnf("Using the %s database backend. All data will be lost once kaspad shuts down", cfg.DbType)
+		db, err := driver.Open("", databaseCacheSizeMiB)
+		return db, currentDatabaseVersion, err
+	}
+
+	dbPath := databasePath(cfg)
+
+	databaseVersion, err := readDatabaseVersion(dbPath)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	log.Infof("Loading %s database from '%s'", cfg.DbType, dbPath)
+	db, err := driver.Open(dbPath, databaseCacheSizeMiB)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return db, databaseVersion, nil
+}
+
+// openDBReadOnly opens the existing database for reading only. Migrating a database
+// writes into it, so the database must already be at the current version
+func openDBReadOnly(cfg *config.Config) (database.Database, error) {
+	db, databaseVersion, err := openDBReadOnlyWithVersion(cfg)
 	if err != nil {
 		return nil, err
 	}
 
+	if databaseVersion != currentDatabaseVersion {
+		db.Close()
+		return nil, errors.Errorf("the database is at version %d and needs to be migrated to version %d "+
+			"before it can be opened for reading only. Start kaspad once to migrate it",
+			databaseVersion, currentDatabaseVersion)
+	}
+
 	return db, nil
 }
+
+// openDBReadOnlyWithVersion opens the existing database for reading only, and returns it
+// along with its version. Neither the database nor its version file are created if they're missing
+func openDBReadOnlyWithVersion(cfg *config.Config) (database.Database, int, error) {
+	driver, err := database.LookupDriver(cfg.DbType)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if driver.OpenReadOnly == nil {
+		return nil, 0, errors.Errorf("the %s database backend doesn't keep its data once kaspad shuts down, "+
+			"so there's no existing database to open", cfg.DbType)
+	}
+
+	dbPath := databasePath(cfg)
+
+	databaseVersion, exists, err := readExistingDatabaseVersion(dbPath)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !exists {
+		return nil, 0, errors.Errorf("there's no database in '%s'", dbPath)
+	}
+
+	log.Infof("Loading %s database from '%s' for reading only", cfg.DbType, dbPath)
+	db, err := driver.OpenReadOnly(dbPath, databaseCacheSizeMiB)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return db, databaseVersion, nil
+}
@@ -66,6 +66,7 @@ const (
 	CmdPruningPoints
 	CmdRequestPruningPointProof
 	CmdPruningPointProof
+	CmdPruningPointSubnetworkRegistrations
 
 	// rpc
 	CmdGetCurrentNetworkRequestMessage
@@ -145,6 +146,19 @@ const (
 	CmdNotifyVirtualDaaScoreChangedRequestMessage
 	CmdNotifyVirtualDaaScoreChangedResponseMessage
 	CmdVirtualDaaScoreChangedNotificationMessage
+	CmdGenerateBlocksRequestMessage
+	CmdGenerateBlocksResponseMessage
+	CmdSetMockTimeRequestMessage
+	CmdSetMockTimeResponseMessage
+	CmdBackupDatabaseRequestMessage
+	CmdBackupDatabaseResponseMessage
+	CmdGetDAGWindowRequestMessage
+	CmdGetDAGWindowResponseMessage
+	CmdNotifyVirtualChainReorgRequestMessage
+	CmdNotifyVirtualChainReorgResponseMessage
+	CmdVirtualChainReorgNotificationMessage
+	CmdGetDatabaseBackupStatusRequestMessage
+	CmdGetDatabaseBackupStatusResponseMessage
 )
 
 // ProtocolMessageCommandToString maps all MessageCommands to their string representation
@@ -185,6 +199,7 @@ var ProtocolMessageCommandToString = map[MessageCommand]string{
 	CmdPruningPoints:                       "PruningPoints",
 	CmdRequestPruningPointProof:            "RequestPruningPointProof",
 	CmdPruningPointProof:                   "PruningPointProof",
+	CmdPruningPointSubnetworkRegistrations: "PruningPointSubnetworkRegistrations",
 }
 
 // RPCMessageCommandToString maps all MessageCommands to their string representation
@@ -264,6 +279,19 @@ var RPCMessageCommandToString = map[MessageCommand]string{
 	CmdNotifyVirtualDaaScoreChangedRequestMessage:                 "NotifyVirtualDaaScoreChangedRequest",
 	CmdNotifyVirtualDaaScoreChangedResponseMessage:                "NotifyVirtualDaaScoreChangedResponse",
 	CmdVirtualDaaScoreChangedNotificationMessage:                  "VirtualDaaScoreChangedNotification",
+	CmdGenerateBlocksRequestMessage:                               "GenerateBlocksRequest",
+	CmdGenerateBlocksResponseMessage:                              "GenerateBlocksResponse",
+	CmdSetMockTimeRequestMessage:                                  "SetMockTimeRequest",
+	CmdSetMockTimeResponseMessage:                                 "SetMockTimeResponse",
+	CmdBackupDatabaseRequestMessage:                               "BackupDatabaseRequest",
+	CmdBackupDatabaseResponseMessage:                              "BackupDatabaseResponse",
+	CmdGetDAGWindowRequestMessage:                                 "GetDAGWindowRequest",
+	CmdGetDAGWindowResponseMessage:                                "GetDAGWindowResponse",
+	CmdNotifyVirtualChainReorgRequestMessage:                      "NotifyVirtualChainReorgRequest",
+	CmdNotifyVirtualChainReorgResponseMessage:                     "NotifyVirtualChainReorgResponse",
+	CmdVirtualChainReorgNotificationMessage:                       "VirtualChainReorgNotification",
+	CmdGetDatabaseBackupStatusRequestMessage:                      "GetDatabaseBackupStatusRequest",
+	CmdGetDatabaseBackupStatusResponseMessage:                     "GetDatabaseBackupStatusResponse",
 }
 
 // Message is an interface that describes a kaspa message. A type that
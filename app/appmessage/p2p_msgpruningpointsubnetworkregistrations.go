@@ -0,0 +1,25 @@
+package appmessage
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+// MsgPruningPointSubnetworkRegistrations represents a kaspa PruningPointSubnetworkRegistrations message
+type MsgPruningPointSubnetworkRegistrations struct {
+	baseMessage
+	Registrations []*externalapi.SubnetworkRegistration
+}
+
+// Command returns the protocol command string for the message
+func (msg *MsgPruningPointSubnetworkRegistrations) Command() MessageCommand {
+	return CmdPruningPointSubnetworkRegistrations
+}
+
+// NewMsgPruningPointSubnetworkRegistrations returns a new MsgPruningPointSubnetworkRegistrations
+func NewMsgPruningPointSubnetworkRegistrations(
+	registrations []*externalapi.SubnetworkRegistration) *MsgPruningPointSubnetworkRegistrations {
+
+	return &MsgPruningPointSubnetworkRegistrations{
+		Registrations: registrations,
+	}
+}
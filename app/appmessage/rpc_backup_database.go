@@ -0,0 +1,40 @@
+package appmessage
+
+// BackupDatabaseRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type BackupDatabaseRequestMessage struct {
+	baseMessage
+	Path string
+}
+
+// Command returns the protocol command string for the message
+func (msg *BackupDatabaseRequestMessage) Command() MessageCommand {
+	return CmdBackupDatabaseRequestMessage
+}
+
+// NewBackupDatabaseRequestMessage returns a instance of the message
+func NewBackupDatabaseRequestMessage(path string) *BackupDatabaseRequestMessage {
+	return &BackupDatabaseRequestMessage{
+		Path: path,
+	}
+}
+
+// BackupDatabaseResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type BackupDatabaseResponseMessage struct {
+	baseMessage
+	Path  string
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *BackupDatabaseResponseMessage) Command() MessageCommand {
+	return CmdBackupDatabaseResponseMessage
+}
+
+// NewBackupDatabaseResponseMessage returns a instance of the message
+func NewBackupDatabaseResponseMessage(path string) *BackupDatabaseResponseMessage {
+	return &BackupDatabaseResponseMessage{
+		Path: path,
+	}
+}
@@ -0,0 +1,43 @@
+package appmessage
+
+// GenerateBlocksRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GenerateBlocksRequestMessage struct {
+	baseMessage
+	NumberOfBlocks uint32
+	PayAddress     string
+}
+
+// Command returns the protocol command string for the message
+func (msg *GenerateBlocksRequestMessage) Command() MessageCommand {
+	return CmdGenerateBlocksRequestMessage
+}
+
+// NewGenerateBlocksRequestMessage returns a instance of the message
+func NewGenerateBlocksRequestMessage(numberOfBlocks uint32, payAddress string) *GenerateBlocksRequestMessage {
+	return &GenerateBlocksRequestMessage{
+		NumberOfBlocks: numberOfBlocks,
+		PayAddress:     payAddress,
+	}
+}
+
+// GenerateBlocksResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GenerateBlocksResponseMessage struct {
+	baseMessage
+	BlockHashes []string
+
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *GenerateBlocksResponseMessage) Command() MessageCommand {
+	return CmdGenerateBlocksResponseMessage
+}
+
+// NewGenerateBlocksResponseMessage returns a instance of the message
+func NewGenerateBlocksResponseMessage(blockHashes []string) *GenerateBlocksResponseMessage {
+	return &GenerateBlocksResponseMessage{
+		BlockHashes: blockHashes,
+	}
+}
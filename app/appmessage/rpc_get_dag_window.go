@@ -0,0 +1,74 @@
+package appmessage
+
+// GetDAGWindowRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GetDAGWindowRequestMessage struct {
+	baseMessage
+	LowBlueScore   uint64
+	HighBlueScore  uint64
+	AnticoneOfHash string
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetDAGWindowRequestMessage) Command() MessageCommand {
+	return CmdGetDAGWindowRequestMessage
+}
+
+// NewGetDAGWindowByBlueScoreRequestMessage returns a instance of the message
+// that requests the blocks with blue scores in the given range
+func NewGetDAGWindowByBlueScoreRequestMessage(lowBlueScore, highBlueScore uint64) *GetDAGWindowRequestMessage {
+	return &GetDAGWindowRequestMessage{
+		LowBlueScore:  lowBlueScore,
+		HighBlueScore: highBlueScore,
+	}
+}
+
+// NewGetDAGWindowByAnticoneRequestMessage returns a instance of the message
+// that requests the given block and its anticone
+func NewGetDAGWindowByAnticoneRequestMessage(anticoneOfHash string) *GetDAGWindowRequestMessage {
+	return &GetDAGWindowRequestMessage{
+		AnticoneOfHash: anticoneOfHash,
+	}
+}
+
+// GetDAGWindowResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GetDAGWindowResponseMessage struct {
+	baseMessage
+	Blocks []*RPCDAGWindowBlock
+
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetDAGWindowResponseMessage) Command() MessageCommand {
+	return CmdGetDAGWindowResponseMessage
+}
+
+// NewGetDAGWindowResponseMessage returns a instance of the message
+func NewGetDAGWindowResponseMessage(blocks []*RPCDAGWindowBlock) *GetDAGWindowResponseMessage {
+	return &GetDAGWindowResponseMessage{
+		Blocks: blocks,
+	}
+}
+
+// Colors of blocks in RPCDAGWindowBlock
+const (
+	DAGWindowBlockColorBlue = "blue"
+	DAGWindowBlockColorRed  = "red"
+)
+
+// RPCDAGWindowBlock is a block in a window of the DAG, as
+// returned by GetDAGWindow
+type RPCDAGWindowBlock struct {
+	Hash                string
+	ParentHashes        []string
+	SelectedParentHash  string
+	MergeSetBluesHashes []string
+	MergeSetRedsHashes  []string
+	IsChainBlock        bool
+	Color               string
+	Status              string
+	BlueScore           uint64
+	DAAScore            uint64
+}
@@ -0,0 +1,45 @@
+package appmessage
+
+// GetDatabaseBackupStatusRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GetDatabaseBackupStatusRequestMessage struct {
+	baseMessage
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetDatabaseBackupStatusRequestMessage) Command() MessageCommand {
+	return CmdGetDatabaseBackupStatusRequestMessage
+}
+
+// NewGetDatabaseBackupStatusRequestMessage returns a instance of the message
+func NewGetDatabaseBackupStatusRequestMessage() *GetDatabaseBackupStatusRequestMessage {
+	return &GetDatabaseBackupStatusRequestMessage{}
+}
+
+// GetDatabaseBackupStatusResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GetDatabaseBackupStatusResponseMessage struct {
+	baseMessage
+	Path       string
+	IsRunning  bool
+	EntryCount uint64
+	Failure    string
+	Error      *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetDatabaseBackupStatusResponseMessage) Command() MessageCommand {
+	return CmdGetDatabaseBackupStatusResponseMessage
+}
+
+// NewGetDatabaseBackupStatusResponseMessage returns a instance of the message
+func NewGetDatabaseBackupStatusResponseMessage(path string, isRunning bool, entryCount uint64,
+	failure string) *GetDatabaseBackupStatusResponseMessage {
+
+	return &GetDatabaseBackupStatusResponseMessage{
+		Path:       path,
+		IsRunning:  isRunning,
+		EntryCount: entryCount,
+		Failure:    failure,
+	}
+}
@@ -20,9 +20,11 @@ func NewGetInfoRequestMessage() *GetInfoRequestMessage {
 // its respective RPC message
 type GetInfoResponseMessage struct {
 	baseMessage
-	P2PID         string
-	MempoolSize   uint64
-	ServerVersion string
+	P2PID                 string
+	MempoolSize           uint64
+	ServerVersion         string
+	MempoolMass           uint64
+	MempoolMinimumFeeRate uint64
 
 	Error *RPCError
 }
@@ -33,10 +35,14 @@ func (msg *GetInfoResponseMessage) Command() MessageCommand {
 }
 
 // NewGetInfoResponseMessage returns a instance of the message
-func NewGetInfoResponseMessage(p2pID string, mempoolSize uint64, serverVersion string) *GetInfoResponseMessage {
+func NewGetInfoResponseMessage(p2pID string, mempoolSize uint64, serverVersion string,
+	mempoolMass uint64, mempoolMinimumFeeRate uint64) *GetInfoResponseMessage {
+
 	return &GetInfoResponseMessage{
-		P2PID:         p2pID,
-		MempoolSize:   mempoolSize,
-		ServerVersion: serverVersion,
+		P2PID:                 p2pID,
+		MempoolSize:           mempoolSize,
+		ServerVersion:         serverVersion,
+		MempoolMass:           mempoolMass,
+		MempoolMinimumFeeRate: mempoolMinimumFeeRate,
 	}
 }
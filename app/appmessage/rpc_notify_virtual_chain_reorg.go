@@ -0,0 +1,63 @@
+package appmessage
+
+// NotifyVirtualChainReorgRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type NotifyVirtualChainReorgRequestMessage struct {
+	baseMessage
+}
+
+// Command returns the protocol command string for the message
+func (msg *NotifyVirtualChainReorgRequestMessage) Command() MessageCommand {
+	return CmdNotifyVirtualChainReorgRequestMessage
+}
+
+// NewNotifyVirtualChainReorgRequestMessage returns a instance of the message
+func NewNotifyVirtualChainReorgRequestMessage() *NotifyVirtualChainReorgRequestMessage {
+	return &NotifyVirtualChainReorgRequestMessage{}
+}
+
+// NotifyVirtualChainReorgResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type NotifyVirtualChainReorgResponseMessage struct {
+	baseMessage
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *NotifyVirtualChainReorgResponseMessage) Command() MessageCommand {
+	return CmdNotifyVirtualChainReorgResponseMessage
+}
+
+// NewNotifyVirtualChainReorgResponseMessage returns a instance of the message
+func NewNotifyVirtualChainReorgResponseMessage() *NotifyVirtualChainReorgResponseMessage {
+	return &NotifyVirtualChainReorgResponseMessage{}
+}
+
+// VirtualChainReorgNotificationMessage is an appmessage corresponding to
+// its respective RPC message
+type VirtualChainReorgNotificationMessage struct {
+	baseMessage
+	Depth                       uint64
+	RemovedChainBlockHashes     []string
+	AddedChainBlockHashes       []string
+	RevertedTransactionIDs      []string
+	NewlyAcceptedTransactionIDs []string
+}
+
+// Command returns the protocol command string for the message
+func (msg *VirtualChainReorgNotificationMessage) Command() MessageCommand {
+	return CmdVirtualChainReorgNotificationMessage
+}
+
+// NewVirtualChainReorgNotificationMessage returns a instance of the message
+func NewVirtualChainReorgNotificationMessage(removedChainBlockHashes, addedChainBlockHashes,
+	revertedTransactionIDs, newlyAcceptedTransactionIDs []string) *VirtualChainReorgNotificationMessage {
+
+	return &VirtualChainReorgNotificationMessage{
+		Depth:                       uint64(len(removedChainBlockHashes)),
+		RemovedChainBlockHashes:     removedChainBlockHashes,
+		AddedChainBlockHashes:       addedChainBlockHashes,
+		RevertedTransactionIDs:      revertedTransactionIDs,
+		NewlyAcceptedTransactionIDs: newlyAcceptedTransactionIDs,
+	}
+}
@@ -0,0 +1,37 @@
+package appmessage
+
+// SetMockTimeRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type SetMockTimeRequestMessage struct {
+	baseMessage
+	MockTime int64
+}
+
+// Command returns the protocol command string for the message
+func (msg *SetMockTimeRequestMessage) Command() MessageCommand {
+	return CmdSetMockTimeRequestMessage
+}
+
+// NewSetMockTimeRequestMessage returns a instance of the message
+func NewSetMockTimeRequestMessage(mockTime int64) *SetMockTimeRequestMessage {
+	return &SetMockTimeRequestMessage{
+		MockTime: mockTime,
+	}
+}
+
+// SetMockTimeResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type SetMockTimeResponseMessage struct {
+	baseMessage
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *SetMockTimeResponseMessage) Command() MessageCommand {
+	return CmdSetMockTimeResponseMessage
+}
+
+// NewSetMockTimeResponseMessage returns a instance of the message
+func NewSetMockTimeResponseMessage() *SetMockTimeResponseMessage {
+	return &SetMockTimeResponseMessage{}
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/kaspanet/kaspad/infrastructure/network/connmanager"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/id"
+	"github.com/kaspanet/kaspad/util/mstime"
 	"github.com/kaspanet/kaspad/util/panics"
 )
 
@@ -68,6 +69,9 @@ func (a *ComponentManager) Stop() {
 
 	a.protocolManager.Close()
 
+	// The database is closed once kaspad stops, so a running backup must finish first
+	a.rpcManager.WaitForDatabaseBackup()
+
 	return
 }
 
@@ -76,16 +80,7 @@ func (a *ComponentManager) Stop() {
 func NewComponentManager(cfg *config.Config, db infrastructuredatabase.Database, interrupt chan<- struct{}) (
 	*ComponentManager, error) {
 
-	consensusConfig := consensus.Config{
-		Params:                          *cfg.ActiveNetParams,
-		IsArchival:                      cfg.IsArchivalNode,
-		EnableSanityCheckPruningUTXOSet: cfg.EnableSanityCheckPruningUTXOSet,
-	}
-	mempoolConfig := mempool.DefaultConfig(&consensusConfig.Params)
-	mempoolConfig.MaximumOrphanTransactionCount = cfg.MaxOrphanTxs
-	mempoolConfig.MinimumRelayTransactionFee = cfg.MinRelayTxFee
-
-	domain, err := domain.New(&consensusConfig, mempoolConfig, db)
+	domain, err := newDomain(cfg, db)
 	if err != nil {
 		return nil, err
 	}
@@ -118,7 +113,7 @@ func NewComponentManager(cfg *config.Config, db infrastructuredatabase.Database,
 	if err != nil {
 		return nil, err
 	}
-	rpcManager := setupRPC(cfg, domain, netAdapter, protocolManager, connectionManager, addressManager, utxoIndex, interrupt)
+	rpcManager := setupRPC(cfg, domain, netAdapter, protocolManager, connectionManager, addressManager, utxoIndex, db, interrupt)
 
 	return &ComponentManager{
 		cfg:               cfg,
@@ -131,6 +126,41 @@ func NewComponentManager(cfg *config.Config, db infrastructuredatabase.Database,
 
 }
 
+// newDomain creates the domain of the node, configured according to cfg
+func newDomain(cfg *config.Config, db infrastructuredatabase.Database) (domain.Domain, error) {
+	consensusConfig := newConsensusConfig(cfg)
+	mempoolConfig := mempool.DefaultConfig(&consensusConfig.Params)
+	mempoolConfig.Clock = consensusConfig.Clock
+	mempoolConfig.MaximumOrphanTransactionCount = cfg.MaxOrphanTxs
+	mempoolConfig.MaximumTotalTransactionMass = cfg.MaxMempoolMass
+	mempoolConfig.MinimumRelayTransactionFee = cfg.MinRelayTxFee
+	mempoolConfig.AllowReplaceByFee = cfg.AllowRBF
+	mempoolConfig.MinimumReplacementFeeRateIncrement = cfg.RBFMinFeeIncrement
+	mempoolConfig.MaximumReplacedTransactionCount = cfg.MaxRBFEvictions
+
+	return domain.New(consensusConfig, mempoolConfig, db)
+}
+
+// newConsensusConfig returns the consensus config of the node, according to cfg
+func newConsensusConfig(cfg *config.Config) *consensus.Config {
+	return &consensus.Config{
+		Params:                          *cfg.ActiveNetParams,
+		IsArchival:                      cfg.IsArchivalNode,
+		EnableSanityCheckPruningUTXOSet: cfg.EnableSanityCheckPruningUTXOSet,
+		HeadersOnly:                     cfg.HeadersOnly,
+		Clock:                           newClock(cfg),
+	}
+}
+
+// newClock returns the clock the node takes the current time from. On simnet and devnet
+// this is a clock that can be mocked over RPC, so that tests don't need to wait for real time to pass
+func newClock(cfg *config.Config) mstime.Clock {
+	if cfg.Simnet || cfg.Devnet {
+		return mstime.NewMockClock()
+	}
+	return mstime.NewSystemClock()
+}
+
 func setupRPC(
 	cfg *config.Config,
 	domain domain.Domain,
@@ -139,6 +169,7 @@ func setupRPC(
 	connectionManager *connmanager.ConnectionManager,
 	addressManager *addressmanager.AddressManager,
 	utxoIndex *utxoindex.UTXOIndex,
+	db infrastructuredatabase.Database,
 	shutDownChan chan<- struct{},
 ) *rpc.Manager {
 
@@ -150,6 +181,7 @@ func setupRPC(
 		connectionManager,
 		addressManager,
 		utxoIndex,
+		db,
 		shutDownChan,
 	)
 	protocolManager.SetOnBlockAddedToDAGHandler(rpcManager.NotifyBlockAddedToDAG)
@@ -5,53 +5,134 @@ import (
 	"path"
 	"strconv"
 
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/infrastructure/db/migration"
 	"github.com/pkg/errors"
 )
 
 const currentDatabaseVersion = 1
 
-func checkDatabaseVersion(dbPath string) (err error) {
+// databaseMigrations upgrade databases that were written by older versions of kaspad.
+// Any change to the on-disk format should increment currentDatabaseVersion and add the
+// migration from the previous version here, so that existing nodes don't need to resync.
+var databaseMigrations = []*migration.Migration{}
+
+// readDatabaseVersion returns the version of the database at dbPath
+func readDatabaseVersion(dbPath string) (int, error) {
+	databaseVersion, exists, err := readExistingDatabaseVersion(dbPath)
+	if err != nil {
+		return 0, err
+	}
+	if !exists { // If version file doesn't exist, we assume that the database is new
+		return currentDatabaseVersion, writeDatabaseVersion(dbPath, currentDatabaseVersion)
+	}
+	return databaseVersion, nil
+}
+
+// readExistingDatabaseVersion returns the version of the database at dbPath, and whether
+// its version file exists. Unlike readDatabaseVersion, it never writes anything
+func readExistingDatabaseVersion(dbPath string) (version int, exists bool, err error) {
 	versionFileName := versionFilePath(dbPath)
 
 	versionBytes, err := os.ReadFile(versionFileName)
 	if err != nil {
-		if os.IsNotExist(err) { // If version file doesn't exist, we assume that the database is new
-			return createDatabaseVersionFile(dbPath, versionFileName)
+		if os.IsNotExist(err) {
+			return 0, false, nil
 		}
-		return err
+		return 0, false, err
 	}
 
 	databaseVersion, err := strconv.Atoi(string(versionBytes))
 	if err != nil {
-		return err
+		return 0, false, err
 	}
 
-	if databaseVersion != currentDatabaseVersion {
-		// TODO: Once there's more then one database version, it might make sense to add upgrade logic at this point
-		return errors.Errorf("Invalid database version %d. Expected version: %d", databaseVersion, currentDatabaseVersion)
+	if databaseVersion > currentDatabaseVersion {
+		return 0, false, errors.Errorf("The database version %d is newer than the version this kaspad supports (%d). "+
+			"Upgrade kaspad or restart it with --reset-db", databaseVersion, currentDatabaseVersion)
 	}
 
-	return nil
+	return databaseVersion, true, nil
 }
 
-func createDatabaseVersionFile(dbPath string, versionFileName string) error {
+// writeDatabaseVersion writes the version file into a temporary file first, so that a crash
+// never leaves an empty or partially written version file behind
+func writeDatabaseVersion(dbPath string, version int) error {
 	err := os.MkdirAll(dbPath, 0700)
 	if err != nil {
 		return err
 	}
 
-	versionFile, err := os.Create(versionFileName)
+	versionFileName := versionFilePath(dbPath)
+	temporaryVersionFileName := versionFileName + ".tmp"
+	versionString := strconv.Itoa(version)
+	err = os.WriteFile(temporaryVersionFileName, []byte(versionString), 0600)
 	if err != nil {
-		return nil
+		return err
 	}
-	defer versionFile.Close()
-
-	versionString := strconv.Itoa(currentDatabaseVersion)
-	_, err = versionFile.Write([]byte(versionString))
-	return err
+	return os.Rename(temporaryVersionFileName, versionFileName)
 }
 
 func versionFilePath(dbPath string) string {
 	dbVersionFileName := path.Join(dbPath, "version")
 	return dbVersionFileName
 }
+
+// migrateDatabase brings the database at dbPath from databaseVersion to currentDatabaseVersion,
+// resuming a migration that was interrupted if there is one
+func migrateDatabase(db database.Database, dbPath string, databaseVersion int) error {
+	migrator, err := migration.NewMigrator(databaseMigrations...)
+	if err != nil {
+		return err
+	}
+
+	err = migrator.Migrate(db, databaseVersion, currentDatabaseVersion)
+	if err != nil {
+		return err
+	}
+
+	if databaseVersion != currentDatabaseVersion {
+		err := writeDatabaseVersion(dbPath, currentDatabaseVersion)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The progress of the migrations is only cleared once the new version is written,
+	// so that the database is never considered to be at a version it wasn't migrated from
+	return migration.ClearProgress(db)
+}
+
+// dryRunDatabaseMigrations logs the migrations that would run on the database, and tries
+// the first of them without committing anything. The database is opened for reading only,
+// so the dry run never writes anything, not even a version file for a missing database
+func dryRunDatabaseMigrations(cfg *config.Config) error {
+	db, databaseVersion, err := openDBReadOnlyWithVersion(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := migration.NewMigrator(databaseMigrations...)
+	if err != nil {
+		return err
+	}
+	pendingMigrations, stepCount, err := migrator.DryRun(
+		database.NewReadOnlyDatabase(db), databaseVersion, currentDatabaseVersion)
+	if err != nil {
+		return err
+	}
+
+	if len(pendingMigrations) == 0 {
+		log.Infof("The database is at version %d, and there's nothing to migrate", currentDatabaseVersion)
+		return nil
+	}
+	for _, pendingMigration := range pendingMigrations {
+		log.Infof("Would migrate the database from version %d: %s",
+			pendingMigration.FromVersion, pendingMigration.Description)
+	}
+	log.Infof("The migration from version %d completed in %d steps without committing anything",
+		pendingMigrations[0].FromVersion, stepCount)
+	return nil
+}
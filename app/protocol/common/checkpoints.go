@@ -0,0 +1,26 @@
+package common
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+)
+
+// ArePruningPointsViolatingCheckpoints returns whether any of the pruning points has the blue score of a
+// checkpoint but a different hash. All pruning points are in the selected parent chain of the last one,
+// so such a pruning point means that this chain doesn't go through the checkpoint.
+// Checkpoints below the pruning point that aren't pruning points themselves can't be validated this way,
+// since the peer sends no other headers from that part of the DAG.
+func ArePruningPointsViolatingCheckpoints(pruningPoints []externalapi.BlockHeader,
+	checkpoints []externalapi.Checkpoint) bool {
+
+	for _, checkpoint := range checkpoints {
+		for _, pruningPoint := range pruningPoints {
+			if pruningPoint.BlueScore() == checkpoint.BlueScore &&
+				!consensushashing.HeaderHash(pruningPoint).Equal(checkpoint.Hash) {
+
+				return true
+			}
+		}
+	}
+	return false
+}
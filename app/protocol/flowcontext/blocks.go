@@ -40,12 +40,15 @@ func (f *FlowContext) OnNewBlock(block *externalapi.DomainBlock,
 
 	allAcceptedTransactions := make([]*externalapi.DomainTransaction, 0)
 	for i, newBlock := range newBlocks {
-		log.Debugf("OnNewBlock: passing block %s transactions to mining manager", hash)
-		acceptedTransactions, err := f.Domain().MiningManager().HandleNewBlockTransactions(newBlock.Transactions)
-		if err != nil {
-			return err
+		// Headers-only nodes add only headers, which have no transactions to pass to the mining manager
+		if !f.Config().HeadersOnly {
+			log.Debugf("OnNewBlock: passing block %s transactions to mining manager", hash)
+			acceptedTransactions, err := f.Domain().MiningManager().HandleNewBlockTransactions(newBlock.Transactions)
+			if err != nil {
+				return err
+			}
+			allAcceptedTransactions = append(allAcceptedTransactions, acceptedTransactions...)
 		}
-		allAcceptedTransactions = append(allAcceptedTransactions, acceptedTransactions...)
 
 		if f.onBlockAddedToDAGHandler != nil {
 			log.Debugf("OnNewBlock: calling f.onBlockAddedToDAGHandler for block %s", hash)
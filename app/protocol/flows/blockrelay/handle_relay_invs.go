@@ -72,7 +72,9 @@ func (flow *handleRelayInvsFlow) start() error {
 		if err != nil {
 			return err
 		}
-		if blockInfo.Exists && blockInfo.BlockStatus != externalapi.StatusHeaderOnly {
+		// Headers-only nodes never have more than the header of a block
+		isKnown := blockInfo.Exists && (blockInfo.BlockStatus != externalapi.StatusHeaderOnly || flow.Config().HeadersOnly)
+		if isKnown {
 			if blockInfo.BlockStatus == externalapi.StatusInvalid {
 				return protocolerrors.Errorf(true, "sent inv of an invalid block %s",
 					inv.Hash)
@@ -96,6 +98,14 @@ func (flow *handleRelayInvsFlow) start() error {
 			continue
 		}
 
+		if flow.Config().HeadersOnly {
+			err := flow.syncRelayedBlockHeader(inv.Hash)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
 		log.Debugf("Requesting block %s", inv.Hash)
 		block, exists, err := flow.requestBlock(inv.Hash)
 		if err != nil {
@@ -275,7 +285,35 @@ func (flow *handleRelayInvsFlow) processOrphan(block *externalapi.DomainBlock) e
 	// Start IBD unless we already are in IBD
 	log.Debugf("Block %s is out of orphan resolution range. "+
 		"Attempting to start IBD against it.", blockHash)
-	return flow.runIBDIfNotRunning(block)
+	return flow.runIBDIfNotRunning(blockHash, block.Header)
+}
+
+// syncRelayedBlockHeader syncs the header of a relayed block, along with the headers
+// in its past that are missing, the way IBD does. Headers-only nodes can't serve
+// the block, so it isn't relayed any further.
+func (flow *handleRelayInvsFlow) syncRelayedBlockHeader(blockHash *externalapi.DomainHash) error {
+	log.Debugf("Syncing the headers up to block %s", blockHash)
+	err := flow.runIBDIfNotRunning(blockHash, nil)
+	if err != nil {
+		return err
+	}
+
+	blockInfo, err := flow.Domain().Consensus().GetBlockInfo(blockHash)
+	if err != nil {
+		return err
+	}
+	if !blockInfo.Exists {
+		log.Debugf("The header of block %s wasn't synced", blockHash)
+		return nil
+	}
+	header, err := flow.Domain().Consensus().GetBlockHeader(blockHash)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Accepted the header of block %s via relay", blockHash)
+	// Headers don't change the virtual, so there's nothing in the insertion result
+	return flow.OnNewBlock(&externalapi.DomainBlock{Header: header}, &externalapi.BlockInsertionResult{})
 }
 
 // isBlockInOrphanResolutionRange finds out whether the given blockHash should be
@@ -298,7 +336,9 @@ func (flow *handleRelayInvsFlow) isBlockInOrphanResolutionRange(blockHash *exter
 		if err != nil {
 			return false, err
 		}
-		if blockInfo.Exists && blockInfo.BlockStatus != externalapi.StatusHeaderOnly {
+		// Headers-only nodes never have more than the header of a block
+		isKnown := blockInfo.Exists && (blockInfo.BlockStatus != externalapi.StatusHeaderOnly || flow.Config().HeadersOnly)
+		if isKnown {
 			return true, nil
 		}
 	}
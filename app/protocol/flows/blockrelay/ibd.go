@@ -16,7 +16,11 @@ import (
 	"github.com/pkg/errors"
 )
 
-func (flow *handleRelayInvsFlow) runIBDIfNotRunning(block *externalapi.DomainBlock) error {
+// runIBDIfNotRunning syncs the DAG up to highHash with the peer, unless IBD is already running.
+// highBlockHeader is the header of highHash, or nil if the node doesn't have it.
+func (flow *handleRelayInvsFlow) runIBDIfNotRunning(highHash *externalapi.DomainHash,
+	highBlockHeader externalapi.BlockHeader) error {
+
 	wasIBDNotRunning := flow.TrySetIBDRunning(flow.peer)
 	if !wasIBDNotRunning {
 		log.Debugf("IBD is already running")
@@ -29,7 +33,6 @@ func (flow *handleRelayInvsFlow) runIBDIfNotRunning(block *externalapi.DomainBlo
 		flow.logIBDFinished(isFinishedSuccessfully)
 	}()
 
-	highHash := consensushashing.BlockHash(block)
 	log.Debugf("IBD started with peer %s and highHash %s", flow.peer, highHash)
 	log.Debugf("Syncing blocks up to %s", highHash)
 	log.Debugf("Trying to find highest shared chain block with peer %s with high hash %s", flow.peer, highHash)
@@ -39,7 +42,7 @@ func (flow *handleRelayInvsFlow) runIBDIfNotRunning(block *externalapi.DomainBlo
 	}
 	log.Debugf("Found highest shared chain block %s with peer %s", highestSharedBlockHash, flow.peer)
 
-	shouldDownloadHeadersProof, shouldSync, err := flow.shouldSyncAndShouldDownloadHeadersProof(block, highestSharedBlockFound)
+	shouldDownloadHeadersProof, shouldSync, err := flow.shouldSyncAndShouldDownloadHeadersProof(highHash, highBlockHeader, highestSharedBlockFound)
 	if err != nil {
 		return err
 	}
@@ -61,9 +64,12 @@ func (flow *handleRelayInvsFlow) runIBDIfNotRunning(block *externalapi.DomainBlo
 		}
 	}
 
-	err = flow.syncMissingBlockBodies(highHash)
-	if err != nil {
-		return err
+	// Headers-only nodes never download block bodies
+	if !flow.Config().HeadersOnly {
+		err = flow.syncMissingBlockBodies(highHash)
+		if err != nil {
+			return err
+		}
 	}
 
 	log.Debugf("Finished syncing blocks up to %s", highHash)
@@ -478,15 +484,21 @@ func (flow *handleRelayInvsFlow) syncMissingBlockBodies(highHash *externalapi.Do
 
 // dequeueIncomingMessageAndSkipInvs is a convenience method to be used during
 // IBD. Inv messages are expected to arrive at any given moment, but should be
-// ignored while we're in IBD
+// ignored while we're in IBD. Headers-only nodes queue them instead
 func (flow *handleRelayInvsFlow) dequeueIncomingMessageAndSkipInvs(timeout time.Duration) (appmessage.Message, error) {
 	for {
 		message, err := flow.incomingRoute.DequeueWithTimeout(timeout)
 		if err != nil {
 			return nil, err
 		}
-		if _, ok := message.(*appmessage.MsgInvRelayBlock); !ok {
+		inv, ok := message.(*appmessage.MsgInvRelayBlock)
+		if !ok {
 			return message, nil
 		}
+		// Headers-only nodes sync every relayed block this way, so skipping
+		// the invs that arrive meanwhile would leave them behind the peer
+		if flow.Config().HeadersOnly {
+			flow.invsQueue = append(flow.invsQueue, inv)
+		}
 	}
 }
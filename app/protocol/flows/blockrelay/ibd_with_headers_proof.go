@@ -39,11 +39,26 @@ func (flow *handleRelayInvsFlow) ibdWithHeadersProof(highHash *externalapi.Domai
 	return nil
 }
 
-func (flow *handleRelayInvsFlow) shouldSyncAndShouldDownloadHeadersProof(highBlock *externalapi.DomainBlock,
-	highestSharedBlockFound bool) (shouldDownload, shouldSync bool, err error) {
+func (flow *handleRelayInvsFlow) shouldSyncAndShouldDownloadHeadersProof(highHash *externalapi.DomainHash,
+	highBlockHeader externalapi.BlockHeader, highestSharedBlockFound bool) (shouldDownload, shouldSync bool, err error) {
 
 	if !highestSharedBlockFound {
-		hasMoreBlueWorkThanSelectedTipAndPruningDepthMoreBlueScore, err := flow.checkIfHighHashHasMoreBlueWorkThanSelectedTipAndPruningDepthMoreBlueScore(highBlock)
+		if highBlockHeader == nil {
+			// Headers-only nodes sync relayed blocks without downloading them. The peer can't
+			// send headers without a shared block though, so when this node is more than a
+			// pruning depth behind it, the high block is downloaded just for its header
+			highBlock, exists, err := flow.requestBlock(highHash)
+			if err != nil {
+				return false, false, err
+			}
+			if exists {
+				log.Debugf("Aborting IBD up to %s because the block is already requested", highHash)
+				return false, false, nil
+			}
+			highBlockHeader = highBlock.Header
+		}
+
+		hasMoreBlueWorkThanSelectedTipAndPruningDepthMoreBlueScore, err := flow.checkIfHighHashHasMoreBlueWorkThanSelectedTipAndPruningDepthMoreBlueScore(highBlockHeader)
 		if err != nil {
 			return false, false, err
 		}
@@ -58,7 +73,7 @@ func (flow *handleRelayInvsFlow) shouldSyncAndShouldDownloadHeadersProof(highBlo
 	return false, true, nil
 }
 
-func (flow *handleRelayInvsFlow) checkIfHighHashHasMoreBlueWorkThanSelectedTipAndPruningDepthMoreBlueScore(highBlock *externalapi.DomainBlock) (bool, error) {
+func (flow *handleRelayInvsFlow) checkIfHighHashHasMoreBlueWorkThanSelectedTipAndPruningDepthMoreBlueScore(highBlockHeader externalapi.BlockHeader) (bool, error) {
 	headersSelectedTip, err := flow.Domain().Consensus().GetHeadersSelectedTip()
 	if err != nil {
 		return false, err
@@ -69,11 +84,11 @@ func (flow *handleRelayInvsFlow) checkIfHighHashHasMoreBlueWorkThanSelectedTipAn
 		return false, err
 	}
 
-	if highBlock.Header.BlueScore() < headersSelectedTipInfo.BlueScore+flow.Config().NetParams().PruningDepth() {
+	if highBlockHeader.BlueScore() < headersSelectedTipInfo.BlueScore+flow.Config().NetParams().PruningDepth() {
 		return false, nil
 	}
 
-	return highBlock.Header.BlueWork().Cmp(headersSelectedTipInfo.BlueWork) > 0, nil
+	return highBlockHeader.BlueWork().Cmp(headersSelectedTipInfo.BlueWork) > 0, nil
 }
 
 func (flow *handleRelayInvsFlow) syncAndValidatePruningPointProof() (*externalapi.DomainHash, error) {
@@ -146,6 +161,13 @@ func (flow *handleRelayInvsFlow) downloadHeadersAndPruningUTXOSet(highHash *exte
 		return err
 	}
 
+	// Headers-only nodes keep no UTXO set. Their pruning point was imported along with the past
+	// pruning points, and consensus moves it by the headers that were synced above it
+	if flow.Config().HeadersOnly {
+		log.Debugf("Skipping the pruning point UTXO set since the node is headers-only")
+		return flow.validatePruningPoint(proofPruningPoint)
+	}
+
 	log.Debugf("Syncing the current pruning point UTXO set")
 	syncedPruningPointUTXOSetSuccessfully, err := flow.syncPruningPointUTXOSet(flow.Domain().StagingConsensus(), proofPruningPoint)
 	if err != nil {
@@ -283,6 +305,12 @@ func (flow *handleRelayInvsFlow) validateAndInsertPruningPoints(proofPruningPoin
 		return protocolerrors.Errorf(false, "pruning points are violating finality")
 	}
 
+	if common.ArePruningPointsViolatingCheckpoints(headers, flow.Config().NetParams().Checkpoints) {
+		// The peer is on a chain that contradicts our checkpoints, so we keep our pruning point
+		// and wait for a peer with a pruning point that is consistent with them
+		return protocolerrors.Errorf(false, "pruning points are violating checkpoints")
+	}
+
 	lastPruningPoint := consensushashing.HeaderHash(headers[len(headers)-1])
 	if !lastPruningPoint.Equal(proofPruningPoint) {
 		return protocolerrors.Errorf(true, "the proof pruning point is not equal to the last pruning "+
@@ -300,16 +328,11 @@ func (flow *handleRelayInvsFlow) validateAndInsertPruningPoints(proofPruningPoin
 func (flow *handleRelayInvsFlow) syncPruningPointUTXOSet(consensus externalapi.Consensus,
 	pruningPoint *externalapi.DomainHash) (bool, error) {
 
-	log.Infof("Checking if the suggested pruning point %s is compatible to the node DAG", pruningPoint)
-	isValid, err := flow.Domain().StagingConsensus().IsValidPruningPoint(pruningPoint)
+	err := flow.validatePruningPoint(pruningPoint)
 	if err != nil {
 		return false, err
 	}
 
-	if !isValid {
-		return false, protocolerrors.Errorf(true, "invalid pruning point %s", pruningPoint)
-	}
-
 	log.Info("Fetching the pruning point UTXO set")
 	isSuccessful, err := flow.fetchMissingUTXOSet(consensus, pruningPoint)
 	if err != nil {
@@ -325,6 +348,20 @@ func (flow *handleRelayInvsFlow) syncPruningPointUTXOSet(consensus externalapi.C
 	return true, nil
 }
 
+func (flow *handleRelayInvsFlow) validatePruningPoint(pruningPoint *externalapi.DomainHash) error {
+	log.Infof("Checking if the suggested pruning point %s is compatible to the node DAG", pruningPoint)
+	isValid, err := flow.Domain().StagingConsensus().IsValidPruningPoint(pruningPoint)
+	if err != nil {
+		return err
+	}
+
+	if !isValid {
+		return protocolerrors.Errorf(true, "invalid pruning point %s", pruningPoint)
+	}
+
+	return nil
+}
+
 func (flow *handleRelayInvsFlow) fetchMissingUTXOSet(consensus externalapi.Consensus, pruningPointHash *externalapi.DomainHash) (succeed bool, err error) {
 	defer func() {
 		err := flow.Domain().StagingConsensus().ClearImportedPruningPointData()
@@ -346,6 +383,14 @@ func (flow *handleRelayInvsFlow) fetchMissingUTXOSet(consensus externalapi.Conse
 		return false, nil
 	}
 
+	// Subnetworks are registered by the transactions the selected parent chain accepts. Nothing the
+	// syncer sends commits to the ones that were registered up to the pruning point, so only the ones
+	// registered by the chain blocks above it, which are validated locally, make it into the registry.
+	if flow.Config().ActiveNetParams.EnableNonNativeSubnetworks {
+		log.Warnf("Subnetworks that were registered up to the pruning point %s cannot be verified, "+
+			"so they are not known to this node", pruningPointHash)
+	}
+
 	err = flow.Domain().StagingConsensus().ValidateAndInsertImportedPruningPoint(pruningPointHash)
 	if err != nil {
 		// TODO: Find a better way to deal with finality conflicts.
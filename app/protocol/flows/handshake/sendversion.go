@@ -22,6 +22,11 @@ var (
 	// the server.
 	defaultServices = appmessage.DefaultServices
 
+	// headersOnlyServices describes the services that are supported by
+	// the server when it runs with --headers-only. Such a node can't
+	// serve block bodies, so it isn't a full node.
+	headersOnlyServices = defaultServices &^ appmessage.SFNodeNetwork
+
 	// defaultRequiredServices describes the default services that are
 	// required to be supported by outbound peers.
 	defaultRequiredServices = appmessage.SFNodeNetwork
@@ -62,12 +67,15 @@ func (flow *sendVersionFlow) start() error {
 
 	// Advertise the services flag
 	msg.Services = defaultServices
+	if flow.Config().HeadersOnly {
+		msg.Services = headersOnlyServices
+	}
 
 	// Advertise our max supported protocol version.
 	msg.ProtocolVersion = appmessage.ProtocolVersion
 
 	// Advertise if inv messages for transactions are desired.
-	msg.DisableRelayTx = flow.Config().BlocksOnly
+	msg.DisableRelayTx = flow.Config().BlocksOnly || flow.Config().HeadersOnly
 
 	err := flow.outgoingRoute.Enqueue(msg)
 	if err != nil {
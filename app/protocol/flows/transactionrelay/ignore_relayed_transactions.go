@@ -0,0 +1,16 @@
+package transactionrelay
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+)
+
+// IgnoreRelayedTransactions listens to appmessage.MsgInvTransaction messages and drops them. It replaces
+// HandleRelayedTransactions in headers-only nodes, which don't have the UTXO set to validate transactions against.
+func IgnoreRelayedTransactions(incomingRoute *router.Route) error {
+	for {
+		_, err := incomingRoute.Dequeue()
+		if err != nil {
+			return err
+		}
+	}
+}
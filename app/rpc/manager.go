@@ -8,6 +8,7 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/utxoindex"
 	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
 	"github.com/kaspanet/kaspad/infrastructure/logger"
 	"github.com/kaspanet/kaspad/infrastructure/network/addressmanager"
 	"github.com/kaspanet/kaspad/infrastructure/network/connmanager"
@@ -28,6 +29,7 @@ func NewManager(
 	connectionManager *connmanager.ConnectionManager,
 	addressManager *addressmanager.AddressManager,
 	utxoIndex *utxoindex.UTXOIndex,
+	db database.Database,
 	shutDownChan chan<- struct{}) *Manager {
 
 	manager := Manager{
@@ -39,6 +41,7 @@ func NewManager(
 			connectionManager,
 			addressManager,
 			utxoIndex,
+			db,
 			shutDownChan,
 		),
 	}
@@ -47,6 +50,12 @@ func NewManager(
 	return &manager
 }
 
+// WaitForDatabaseBackup waits for the database backup that was requested over RPC
+// to finish, if one is running
+func (m *Manager) WaitForDatabaseBackup() {
+	m.context.DatabaseBackup.Wait()
+}
+
 // NotifyBlockAddedToDAG notifies the manager that a block has been added to the DAG
 func (m *Manager) NotifyBlockAddedToDAG(block *externalapi.DomainBlock, blockInsertionResult *externalapi.BlockInsertionResult) error {
 	onEnd := logger.LogAndMeasureExecutionTime(log, "RPCManager.NotifyBlockAddedToDAG")
@@ -59,23 +68,31 @@ func (m *Manager) NotifyBlockAddedToDAG(block *externalapi.DomainBlock, blockIns
 		}
 	}
 
-	err := m.notifyVirtualSelectedParentBlueScoreChanged()
-	if err != nil {
-		return err
-	}
+	// Headers-only nodes add only headers, which don't change the virtual
+	if !m.context.Config.HeadersOnly {
+		err := m.notifyVirtualSelectedParentBlueScoreChanged()
+		if err != nil {
+			return err
+		}
 
-	err = m.notifyVirtualDaaScoreChanged()
-	if err != nil {
-		return err
-	}
+		err = m.notifyVirtualDaaScoreChanged()
+		if err != nil {
+			return err
+		}
 
-	err = m.notifyVirtualSelectedParentChainChanged(blockInsertionResult)
-	if err != nil {
-		return err
+		err = m.notifyVirtualSelectedParentChainChanged(blockInsertionResult)
+		if err != nil {
+			return err
+		}
+
+		err = m.notifyVirtualChainReorg(blockInsertionResult)
+		if err != nil {
+			return err
+		}
 	}
 
 	rpcBlock := appmessage.DomainBlockToRPCBlock(block)
-	err = m.context.PopulateBlockWithVerboseData(rpcBlock, block.Header, block, false)
+	err := m.context.PopulateBlockWithVerboseData(rpcBlock, block.Header, block, false)
 	if err != nil {
 		return err
 	}
@@ -182,3 +199,21 @@ func (m *Manager) notifyVirtualSelectedParentChainChanged(blockInsertionResult *
 	}
 	return m.context.NotificationManager.NotifyVirtualSelectedParentChainChanged(notification)
 }
+
+func (m *Manager) notifyVirtualChainReorg(blockInsertionResult *externalapi.BlockInsertionResult) error {
+	// Chain blocks that are only added extend the chain rather than reorganize it
+	if len(blockInsertionResult.VirtualSelectedParentChainChanges.Removed) == 0 ||
+		!m.context.NotificationManager.HasVirtualChainReorgListeners() {
+		return nil
+	}
+
+	onEnd := logger.LogAndMeasureExecutionTime(log, "RPCManager.NotifyVirtualChainReorg")
+	defer onEnd()
+
+	notification, err := m.context.ConvertVirtualSelectedParentChainChangesToVirtualChainReorgNotificationMessage(
+		blockInsertionResult.VirtualSelectedParentChainChanges)
+	if err != nil {
+		return err
+	}
+	return m.context.NotificationManager.NotifyVirtualChainReorg(notification)
+}
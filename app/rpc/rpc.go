@@ -46,6 +46,12 @@ var handlers = map[appmessage.MessageCommand]handler{
 	appmessage.CmdStopNotifyingPruningPointUTXOSetOverrideRequestMessage:    rpchandlers.HandleStopNotifyingPruningPointUTXOSetOverrideRequest,
 	appmessage.CmdEstimateNetworkHashesPerSecondRequestMessage:              rpchandlers.HandleEstimateNetworkHashesPerSecond,
 	appmessage.CmdNotifyVirtualDaaScoreChangedRequestMessage:                rpchandlers.HandleNotifyVirtualDaaScoreChanged,
+	appmessage.CmdGenerateBlocksRequestMessage:                              rpchandlers.HandleGenerateBlocks,
+	appmessage.CmdSetMockTimeRequestMessage:                                 rpchandlers.HandleSetMockTime,
+	appmessage.CmdBackupDatabaseRequestMessage:                              rpchandlers.HandleBackupDatabase,
+	appmessage.CmdGetDatabaseBackupStatusRequestMessage:                     rpchandlers.HandleGetDatabaseBackupStatus,
+	appmessage.CmdGetDAGWindowRequestMessage:                                rpchandlers.HandleGetDAGWindow,
+	appmessage.CmdNotifyVirtualChainReorgRequestMessage:                     rpchandlers.HandleNotifyVirtualChainReorg,
 }
 
 func (m *Manager) routerInitializer(router *router.Router, netConnection *netadapter.NetConnection) {
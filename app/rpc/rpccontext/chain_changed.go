@@ -3,6 +3,8 @@ package rpccontext
 import (
 	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/hashes"
 )
 
 // ConvertVirtualSelectedParentChainChangesToChainChangedNotificationMessage converts
@@ -22,3 +24,66 @@ func (ctx *Context) ConvertVirtualSelectedParentChainChangesToChainChangedNotifi
 
 	return appmessage.NewVirtualSelectedParentChainChangedNotificationMessage(removedChainBlockHashes, addedChainBlocks), nil
 }
+
+// ConvertVirtualSelectedParentChainChangesToVirtualChainReorgNotificationMessage converts
+// VirtualSelectedParentChainChanges to VirtualChainReorgNotificationMessage. The transactions
+// whose acceptance changed are found by comparing the acceptance data of the removed chain
+// blocks with that of the added ones. A transaction that moved from a removed chain block to
+// an added one is still accepted, so it's listed as neither reverted nor newly accepted.
+func (ctx *Context) ConvertVirtualSelectedParentChainChangesToVirtualChainReorgNotificationMessage(
+	selectedParentChainChanges *externalapi.SelectedChainPath) (*appmessage.VirtualChainReorgNotificationMessage, error) {
+
+	removedTransactionIDs, err := ctx.acceptedTransactionIDs(selectedParentChainChanges.Removed)
+	if err != nil {
+		return nil, err
+	}
+	addedTransactionIDs, err := ctx.acceptedTransactionIDs(selectedParentChainChanges.Added)
+	if err != nil {
+		return nil, err
+	}
+
+	return appmessage.NewVirtualChainReorgNotificationMessage(
+		hashes.ToStrings(selectedParentChainChanges.Removed),
+		hashes.ToStrings(selectedParentChainChanges.Added),
+		transactionIDsDifference(removedTransactionIDs, addedTransactionIDs),
+		transactionIDsDifference(addedTransactionIDs, removedTransactionIDs),
+	), nil
+}
+
+// acceptedTransactionIDs returns the IDs of the transactions accepted by the given
+// chain blocks, in the order of the chain blocks and their acceptance data
+func (ctx *Context) acceptedTransactionIDs(chainBlockHashes []*externalapi.DomainHash) ([]string, error) {
+	var transactionIDs []string
+	for _, chainBlockHash := range chainBlockHashes {
+		acceptanceData, err := ctx.Domain.Consensus().GetBlockAcceptanceData(chainBlockHash)
+		if err != nil {
+			return nil, err
+		}
+		for _, blockAcceptanceData := range acceptanceData {
+			for _, transactionAcceptanceData := range blockAcceptanceData.TransactionAcceptanceData {
+				if !transactionAcceptanceData.IsAccepted {
+					continue
+				}
+				transactionID := consensushashing.TransactionID(transactionAcceptanceData.Transaction)
+				transactionIDs = append(transactionIDs, transactionID.String())
+			}
+		}
+	}
+	return transactionIDs, nil
+}
+
+// transactionIDsDifference returns the transaction IDs that are in `from` but not in `subtract`
+func transactionIDsDifference(from, subtract []string) []string {
+	subtractSet := make(map[string]struct{}, len(subtract))
+	for _, transactionID := range subtract {
+		subtractSet[transactionID] = struct{}{}
+	}
+
+	difference := make([]string, 0, len(from))
+	for _, transactionID := range from {
+		if _, ok := subtractSet[transactionID]; !ok {
+			difference = append(difference, transactionID)
+		}
+	}
+	return difference
+}
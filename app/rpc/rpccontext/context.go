@@ -5,6 +5,7 @@ import (
 	"github.com/kaspanet/kaspad/domain"
 	"github.com/kaspanet/kaspad/domain/utxoindex"
 	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
 	"github.com/kaspanet/kaspad/infrastructure/network/addressmanager"
 	"github.com/kaspanet/kaspad/infrastructure/network/connmanager"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter"
@@ -19,9 +20,11 @@ type Context struct {
 	ConnectionManager *connmanager.ConnectionManager
 	AddressManager    *addressmanager.AddressManager
 	UTXOIndex         *utxoindex.UTXOIndex
+	Database          database.Database
 	ShutDownChan      chan<- struct{}
 
 	NotificationManager *NotificationManager
+	DatabaseBackup      *DatabaseBackup
 }
 
 // NewContext creates a new RPC context
@@ -32,6 +35,7 @@ func NewContext(cfg *config.Config,
 	connectionManager *connmanager.ConnectionManager,
 	addressManager *addressmanager.AddressManager,
 	utxoIndex *utxoindex.UTXOIndex,
+	db database.Database,
 	shutDownChan chan<- struct{}) *Context {
 
 	context := &Context{
@@ -42,9 +46,11 @@ func NewContext(cfg *config.Config,
 		ConnectionManager: connectionManager,
 		AddressManager:    addressManager,
 		UTXOIndex:         utxoIndex,
+		Database:          db,
 		ShutDownChan:      shutDownChan,
 	}
 	context.NotificationManager = NewNotificationManager()
+	context.DatabaseBackup = NewDatabaseBackup(db, cfg.DbType, cfg.BackupDir)
 
 	return context
 }
@@ -0,0 +1,171 @@
+package rpccontext
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/pkg/errors"
+)
+
+// DatabaseBackup writes the database backups that are requested over RPC
+// in the background, one at a time, and keeps the status of the latest one
+type DatabaseBackup struct {
+	database  database.Database
+	dbType    string
+	backupDir string
+
+	lock      sync.Mutex
+	status    DatabaseBackupStatus
+	waitGroup sync.WaitGroup
+}
+
+// DatabaseBackupStatus is the status of a database backup
+type DatabaseBackupStatus struct {
+	// Path is the directory the backup is written into. It's empty if no backup was started
+	Path       string
+	IsRunning  bool
+	EntryCount uint64
+	// Err is the reason the backup failed, if it did
+	Err error
+}
+
+// NewDatabaseBackup returns a new DatabaseBackup that backs up the given database,
+// of the given type, into directories under backupDir
+func NewDatabaseBackup(db database.Database, dbType string, backupDir string) *DatabaseBackup {
+	return &DatabaseBackup{
+		database:  db,
+		dbType:    dbType,
+		backupDir: backupDir,
+	}
+}
+
+// Start starts writing a backup into the given path in the background, and returns the
+// absolute path of the backup. Relative paths are resolved against the backup directory,
+// and paths outside of it are rejected. Start fails if another backup is still running.
+func (b *DatabaseBackup) Start(path string) (string, error) {
+	backupPath, err := b.resolvePath(path)
+	if err != nil {
+		return "", err
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.status.IsRunning {
+		return "", errors.Errorf("a backup into %s is already running", b.status.Path)
+	}
+	b.status = DatabaseBackupStatus{
+		Path:      backupPath,
+		IsRunning: true,
+	}
+
+	b.waitGroup.Add(1)
+	spawn("DatabaseBackup.Start-backup", func() {
+		defer b.waitGroup.Done()
+
+		log.Infof("Backing up the database to %s", backupPath)
+		entryCount, err := database.Backup(b.dbType, b.database, backupPath)
+		if err != nil {
+			log.Errorf("Failed backing up the database to %s: %s", backupPath, err)
+		}
+
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		b.status.IsRunning = false
+		b.status.EntryCount = entryCount
+		b.status.Err = err
+	})
+	return backupPath, nil
+}
+
+// Status returns the status of the latest backup
+func (b *DatabaseBackup) Status() DatabaseBackupStatus {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.status
+}
+
+// Wait waits for the running backup, if there is one, to finish.
+// The database must not be closed while a backup is running.
+func (b *DatabaseBackup) Wait() {
+	if b.Status().IsRunning {
+		log.Infof("Waiting for the database backup to finish")
+	}
+	b.waitGroup.Wait()
+}
+
+// resolvePath returns the absolute path of the given backup path, and makes sure
+// it's a directory under the backup directory
+func (b *DatabaseBackup) resolvePath(path string) (string, error) {
+	if b.backupDir == "" {
+		return "", errors.New("no backup directory is configured")
+	}
+	backupDir, err := filepath.Abs(b.backupDir)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(backupDir, path)
+	}
+	path = filepath.Clean(path)
+	if !isStrictlyUnder(backupDir, path) {
+		return "", errors.Errorf("the backup path %s is not under the backup directory %s", path, backupDir)
+	}
+
+	// Symbolic links in the path could still lead outside of the backup directory
+	resolvedBackupDir, err := resolveExistingPrefix(backupDir)
+	if err != nil {
+		return "", err
+	}
+	resolvedPath, err := resolveExistingPrefix(path)
+	if err != nil {
+		return "", err
+	}
+	if !isStrictlyUnder(resolvedBackupDir, resolvedPath) {
+		return "", errors.Errorf("the backup path %s leads outside of the backup directory %s", path, backupDir)
+	}
+	return path, nil
+}
+
+// isStrictlyUnder returns whether the given clean, absolute path is under directory,
+// and isn't the directory itself
+func isStrictlyUnder(directory string, path string) bool {
+	relativePath, err := filepath.Rel(directory, path)
+	if err != nil {
+		return false
+	}
+	return relativePath != "." && relativePath != ".." &&
+		!strings.HasPrefix(relativePath, ".."+string(filepath.Separator))
+}
+
+// resolveExistingPrefix resolves the symbolic links in the longest prefix of the
+// given absolute path that exists, and appends the rest of the path to it
+func resolveExistingPrefix(path string) (string, error) {
+	existingPrefix := path
+	var missingSuffix string
+	for {
+		_, err := os.Lstat(existingPrefix)
+		if err == nil {
+			break
+		}
+		if !os.IsNotExist(err) {
+			return "", errors.WithStack(err)
+		}
+		parent := filepath.Dir(existingPrefix)
+		if parent == existingPrefix {
+			break
+		}
+		missingSuffix = filepath.Join(filepath.Base(existingPrefix), missingSuffix)
+		existingPrefix = parent
+	}
+
+	resolvedPrefix, err := filepath.EvalSymlinks(existingPrefix)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return filepath.Join(resolvedPrefix, missingSuffix), nil
+}
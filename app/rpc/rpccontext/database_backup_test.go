@@ -0,0 +1,72 @@
+package rpccontext
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDatabaseBackupResolvePath(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "TestDatabaseBackupResolvePath")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	backupDir := filepath.Join(tmpDir, "backups")
+	err = os.Mkdir(backupDir, 0700)
+	if err != nil {
+		t.Fatalf("Mkdir: %s", err)
+	}
+	outsideDir := filepath.Join(tmpDir, "outside")
+	err = os.Mkdir(outsideDir, 0700)
+	if err != nil {
+		t.Fatalf("Mkdir: %s", err)
+	}
+	err = os.Symlink(outsideDir, filepath.Join(backupDir, "link"))
+	if err != nil {
+		t.Fatalf("Symlink: %s", err)
+	}
+
+	tests := []struct {
+		path         string
+		expectedPath string
+	}{
+		{path: "backup", expectedPath: filepath.Join(backupDir, "backup")},
+		{path: "nested/backup", expectedPath: filepath.Join(backupDir, "nested", "backup")},
+		{path: filepath.Join(backupDir, "backup"), expectedPath: filepath.Join(backupDir, "backup")},
+		{path: "nested/../backup", expectedPath: filepath.Join(backupDir, "backup")},
+		{path: ""},
+		{path: "."},
+		{path: backupDir},
+		{path: "../outside/backup"},
+		{path: filepath.Join(outsideDir, "backup")},
+		{path: "/"},
+		{path: "link/backup"},
+		{path: "link"},
+	}
+
+	databaseBackup := NewDatabaseBackup(nil, "", backupDir)
+	for _, test := range tests {
+		path, err := databaseBackup.resolvePath(test.path)
+		if test.expectedPath == "" {
+			if err == nil {
+				t.Errorf("%s: expected an error, but it resolved to %s", test.path, path)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.path, err)
+			continue
+		}
+		if path != test.expectedPath {
+			t.Errorf("%s: expected %s, but got %s", test.path, test.expectedPath, path)
+		}
+	}
+
+	_, err = NewDatabaseBackup(nil, "", "").resolvePath("backup")
+	if err == nil {
+		t.Errorf("Expected resolving a path without a backup directory to fail")
+	}
+}
@@ -32,6 +32,7 @@ type NotificationListener struct {
 	propagateVirtualSelectedParentBlueScoreChangedNotifications bool
 	propagateVirtualDaaScoreChangedNotifications                bool
 	propagatePruningPointUTXOSetOverrideNotifications           bool
+	propagateVirtualChainReorgNotifications                     bool
 
 	propagateUTXOsChangedNotificationAddresses map[utxoindex.ScriptPublicKeyString]*UTXOsChangedNotificationAddress
 }
@@ -201,6 +202,38 @@ func (nm *NotificationManager) NotifyVirtualDaaScoreChanged(
 	return nil
 }
 
+// HasVirtualChainReorgListeners returns whether any listener is registered for
+// virtual chain reorg notifications. Building these notifications requires reading
+// the acceptance data of every changed chain block, so it's skipped when no one listens
+func (nm *NotificationManager) HasVirtualChainReorgListeners() bool {
+	nm.RLock()
+	defer nm.RUnlock()
+
+	for _, listener := range nm.listeners {
+		if listener.propagateVirtualChainReorgNotifications {
+			return true
+		}
+	}
+	return false
+}
+
+// NotifyVirtualChainReorg notifies the notification manager that chain blocks were
+// removed from the virtual selected parent chain
+func (nm *NotificationManager) NotifyVirtualChainReorg(notification *appmessage.VirtualChainReorgNotificationMessage) error {
+	nm.RLock()
+	defer nm.RUnlock()
+
+	for router, listener := range nm.listeners {
+		if listener.propagateVirtualChainReorgNotifications {
+			err := router.OutgoingRoute().Enqueue(notification)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // NotifyPruningPointUTXOSetOverride notifies the notification manager that the UTXO index
 // reset due to pruning point change via IBD.
 func (nm *NotificationManager) NotifyPruningPointUTXOSetOverride() error {
@@ -227,6 +260,7 @@ func newNotificationListener() *NotificationListener {
 		propagateUTXOsChangedNotifications:                          false,
 		propagateVirtualSelectedParentBlueScoreChangedNotifications: false,
 		propagatePruningPointUTXOSetOverrideNotifications:           false,
+		propagateVirtualChainReorgNotifications:                     false,
 	}
 }
 
@@ -334,6 +368,12 @@ func (nl *NotificationListener) PropagateVirtualDaaScoreChangedNotifications() {
 	nl.propagateVirtualDaaScoreChangedNotifications = true
 }
 
+// PropagateVirtualChainReorgNotifications instructs the listener to send
+// virtual chain reorg notifications to the remote listener
+func (nl *NotificationListener) PropagateVirtualChainReorgNotifications() {
+	nl.propagateVirtualChainReorgNotifications = true
+}
+
 // PropagatePruningPointUTXOSetOverrideNotifications instructs the listener to send pruning point UTXO set override notifications
 // to the remote listener.
 func (nl *NotificationListener) PropagatePruningPointUTXOSetOverrideNotifications() {
@@ -0,0 +1,14 @@
+package rpccontext
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+// SelectedTip returns the virtual selected parent. The virtual of headers-only nodes never
+// advances, so for them it returns the headers selected tip instead.
+func (ctx *Context) SelectedTip() (*externalapi.DomainHash, error) {
+	if ctx.Config.HeadersOnly {
+		return ctx.Domain.Consensus().GetHeadersSelectedTip()
+	}
+	return ctx.Domain.Consensus().GetVirtualSelectedParent()
+}
@@ -0,0 +1,29 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+)
+
+// HandleBackupDatabase handles the respectively named RPC command
+func HandleBackupDatabase(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	backupDatabaseRequest := request.(*appmessage.BackupDatabaseRequestMessage)
+
+	// Backups write into the file system of the node, so they have to be explicitly allowed
+	if !context.Config.EnableBackupRPC {
+		errorMessage := &appmessage.BackupDatabaseResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Backing up the database over RPC is disabled. " +
+			"Restart kaspad with --enablebackuprpc and --backupdir in order to enable it")
+		return errorMessage, nil
+	}
+
+	backupPath, err := context.DatabaseBackup.Start(backupDatabaseRequest.Path)
+	if err != nil {
+		errorMessage := &appmessage.BackupDatabaseResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Failed starting the database backup: %s", err)
+		return errorMessage, nil
+	}
+
+	return appmessage.NewBackupDatabaseResponseMessage(backupPath), nil
+}
@@ -0,0 +1,107 @@
+package rpchandlers
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/protocol/protocolerrors"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/mining"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/kaspanet/kaspad/util"
+	"github.com/pkg/errors"
+)
+
+// maxGeneratedBlocks is the maximum number of blocks a single GenerateBlocks request may ask for
+const maxGeneratedBlocks = 1000
+
+// HandleGenerateBlocks handles the respectively named RPC command
+func HandleGenerateBlocks(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	if context.Config.HeadersOnly {
+		errorMessage := &appmessage.GenerateBlocksResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Method unavailable when kaspad is run with --headers-only")
+		return errorMessage, nil
+	}
+
+	generateBlocksRequest := request.(*appmessage.GenerateBlocksRequestMessage)
+
+	params := context.Config.ActiveNetParams
+	if params.Name != dagconfig.SimnetParams.Name && params.Name != dagconfig.DevnetParams.Name && !params.SkipProofOfWork {
+		errorMessage := &appmessage.GenerateBlocksResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("GenerateBlocks is only available on simnet, devnet, " +
+			"or on networks that skip proof of work")
+		return errorMessage, nil
+	}
+
+	if generateBlocksRequest.NumberOfBlocks == 0 || generateBlocksRequest.NumberOfBlocks > maxGeneratedBlocks {
+		errorMessage := &appmessage.GenerateBlocksResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("The number of blocks must be between 1 and %d", maxGeneratedBlocks)
+		return errorMessage, nil
+	}
+
+	payAddress, err := util.DecodeAddress(generateBlocksRequest.PayAddress, params.Prefix)
+	if err != nil {
+		errorMessage := &appmessage.GenerateBlocksResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Could not decode address: %s", err)
+		return errorMessage, nil
+	}
+	scriptPublicKey, err := txscript.PayToAddrScript(payAddress)
+	if err != nil {
+		return nil, err
+	}
+	coinbaseData := &externalapi.DomainCoinbaseData{ScriptPublicKey: scriptPublicKey}
+
+	rd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	blockHashes := make([]string, 0, generateBlocksRequest.NumberOfBlocks)
+	for i := uint32(0); i < generateBlocksRequest.NumberOfBlocks; i++ {
+		block, err := context.Domain.MiningManager().GetBlockTemplate(coinbaseData)
+		if err != nil {
+			return nil, err
+		}
+
+		// Block templates carry the UTXO entries of their transactions, which
+		// mustn't be set on blocks that are added to the DAG
+		for _, transaction := range block.Transactions {
+			for _, input := range transaction.Inputs {
+				input.UTXOEntry = nil
+			}
+		}
+
+		// The template might have been cached before the node's clock was moved forward
+		now := context.Domain.Clock().Now().UnixMilliseconds()
+		if block.Header.TimeInMilliseconds() < now {
+			header := block.Header.ToMutable()
+			header.SetTimeInMilliseconds(now)
+			block.Header = header.ToImmutable()
+		}
+
+		if !params.SkipProofOfWork {
+			mining.SolveBlock(block, rd)
+		}
+
+		err = context.ProtocolManager.AddBlock(block)
+		if err != nil {
+			isProtocolOrRuleError := errors.As(err, &ruleerrors.RuleError{}) || errors.As(err, &protocolerrors.ProtocolError{})
+			if !isProtocolOrRuleError {
+				return nil, err
+			}
+
+			errorMessage := &appmessage.GenerateBlocksResponseMessage{}
+			errorMessage.Error = appmessage.RPCErrorf("Generated block rejected after generating %d blocks. Reason: %s",
+				len(blockHashes), err)
+			return errorMessage, nil
+		}
+
+		blockHash := consensushashing.BlockHash(block)
+		log.Infof("Accepted block %s via generateBlocks", blockHash)
+		blockHashes = append(blockHashes, blockHash.String())
+	}
+
+	return appmessage.NewGenerateBlocksResponseMessage(blockHashes), nil
+}
@@ -12,6 +12,12 @@ import (
 func HandleGetBlock(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
 	getBlockRequest := request.(*appmessage.GetBlockRequestMessage)
 
+	if getBlockRequest.IncludeTransactions && context.Config.HeadersOnly {
+		errorMessage := &appmessage.GetBlockResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Transactions are unavailable when kaspad is run with --headers-only")
+		return errorMessage, nil
+	}
+
 	// Load the raw block bytes from the database.
 	hash, err := externalapi.NewDomainHashFromString(getBlockRequest.Hash)
 	if err != nil {
@@ -22,20 +22,36 @@ func HandleGetBlockDAGInfo(context *rpccontext.Context, _ *router.Router, _ appm
 	response.BlockCount = syncInfo.BlockCount
 	response.HeaderCount = syncInfo.HeaderCount
 
-	tipHashes, err := consensus.Tips()
-	if err != nil {
-		return nil, err
-	}
-	response.TipHashes = hashes.ToStrings(tipHashes)
-
-	virtualInfo, err := consensus.GetVirtualInfo()
-	if err != nil {
-		return nil, err
+	if context.Config.HeadersOnly {
+		// The virtual of headers-only nodes never advances, so the headers selected tip takes its place
+		headersSelectedTip, err := consensus.GetHeadersSelectedTip()
+		if err != nil {
+			return nil, err
+		}
+		headersSelectedTipHeader, err := consensus.GetBlockHeader(headersSelectedTip)
+		if err != nil {
+			return nil, err
+		}
+		response.TipHashes = []string{headersSelectedTip.String()}
+		response.VirtualParentHashes = []string{headersSelectedTip.String()}
+		response.Difficulty = context.GetDifficultyRatio(headersSelectedTipHeader.Bits(), context.Config.ActiveNetParams)
+		response.VirtualDAAScore = headersSelectedTipHeader.DAAScore()
+	} else {
+		tipHashes, err := consensus.Tips()
+		if err != nil {
+			return nil, err
+		}
+		response.TipHashes = hashes.ToStrings(tipHashes)
+
+		virtualInfo, err := consensus.GetVirtualInfo()
+		if err != nil {
+			return nil, err
+		}
+		response.VirtualParentHashes = hashes.ToStrings(virtualInfo.ParentHashes)
+		response.Difficulty = context.GetDifficultyRatio(virtualInfo.Bits, context.Config.ActiveNetParams)
+		response.PastMedianTime = virtualInfo.PastMedianTime
+		response.VirtualDAAScore = virtualInfo.DAAScore
 	}
-	response.VirtualParentHashes = hashes.ToStrings(virtualInfo.ParentHashes)
-	response.Difficulty = context.GetDifficultyRatio(virtualInfo.Bits, context.Config.ActiveNetParams)
-	response.PastMedianTime = virtualInfo.PastMedianTime
-	response.VirtualDAAScore = virtualInfo.DAAScore
 
 	pruningPoint, err := context.Domain.Consensus().PruningPoint()
 	if err != nil {
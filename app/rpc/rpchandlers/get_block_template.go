@@ -11,6 +11,12 @@ import (
 
 // HandleGetBlockTemplate handles the respectively named RPC command
 func HandleGetBlockTemplate(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	if context.Config.HeadersOnly {
+		errorMessage := &appmessage.GetBlockTemplateResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Method unavailable when kaspad is run with --headers-only")
+		return errorMessage, nil
+	}
+
 	getBlockTemplateRequest := request.(*appmessage.GetBlockTemplateRequestMessage)
 
 	payAddress, err := util.DecodeAddress(getBlockTemplateRequest.PayAddress, context.Config.ActiveNetParams.Prefix)
@@ -20,6 +20,12 @@ func HandleGetBlocks(context *rpccontext.Context, _ *router.Router, request appm
 		}, nil
 	}
 
+	if getBlocksRequest.IncludeTransactions && context.Config.HeadersOnly {
+		return &appmessage.GetBlocksResponseMessage{
+			Error: appmessage.RPCErrorf("Transactions are unavailable when kaspad is run with --headers-only"),
+		}, nil
+	}
+
 	// Decode lowHash
 	// If lowHash is empty - use genesis instead.
 	lowHash := context.Config.ActiveNetParams.GenesisHash
@@ -45,7 +51,7 @@ func HandleGetBlocks(context *rpccontext.Context, _ *router.Router, request appm
 	}
 
 	// Get hashes between lowHash and virtualSelectedParent
-	virtualSelectedParent, err := context.Domain.Consensus().GetVirtualSelectedParent()
+	virtualSelectedParent, err := context.SelectedTip()
 	if err != nil {
 		return nil, err
 	}
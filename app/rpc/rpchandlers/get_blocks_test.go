@@ -17,6 +17,7 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/utils/testutils"
 	"github.com/kaspanet/kaspad/domain/miningmanager"
 	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/util/mstime"
 )
 
 type fakeDomain struct {
@@ -41,6 +42,7 @@ func (d fakeDomain) CommitStagingConsensus() error {
 
 func (d fakeDomain) Consensus() externalapi.Consensus           { return d }
 func (d fakeDomain) MiningManager() miningmanager.MiningManager { return nil }
+func (d fakeDomain) Clock() mstime.Clock                        { return mstime.NewSystemClock() }
 
 func TestHandleGetBlocks(t *testing.T) {
 	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
@@ -0,0 +1,379 @@
+package rpchandlers
+
+import (
+	"sort"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/domain/consensus/model"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/hashes"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/pkg/errors"
+)
+
+// maxDAGWindowBlocks is the maximum number of blocks GetDAGWindow returns
+const maxDAGWindowBlocks = 10_000
+
+// maxDAGWindowVisitedBlocks is the maximum number of blocks GetDAGWindow visits
+// in order to find the blocks of a window and their colors
+const maxDAGWindowVisitedBlocks = 3 * maxDAGWindowBlocks
+
+var errDAGWindowTooLarge = errors.Errorf("the window contains more than %d blocks, or finding it "+
+	"requires visiting more than %d blocks", maxDAGWindowBlocks, maxDAGWindowVisitedBlocks)
+
+// HandleGetDAGWindow handles the respectively named RPC command
+func HandleGetDAGWindow(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	getDAGWindowRequest := request.(*appmessage.GetDAGWindowRequestMessage)
+	traversal := &dagWindowTraversal{context: context}
+
+	var windowHashes []*externalapi.DomainHash
+	if getDAGWindowRequest.AnticoneOfHash != "" {
+		if getDAGWindowRequest.LowBlueScore != 0 || getDAGWindowRequest.HighBlueScore != 0 {
+			errorMessage := &appmessage.GetDAGWindowResponseMessage{}
+			errorMessage.Error = appmessage.RPCErrorf("anticoneOfHash cannot be set along with a blue score range")
+			return errorMessage, nil
+		}
+
+		hash, err := externalapi.NewDomainHashFromString(getDAGWindowRequest.AnticoneOfHash)
+		if err != nil {
+			errorMessage := &appmessage.GetDAGWindowResponseMessage{}
+			errorMessage.Error = appmessage.RPCErrorf("Could not decode anticoneOfHash %s: %s",
+				getDAGWindowRequest.AnticoneOfHash, err)
+			return errorMessage, nil
+		}
+		blockInfo, err := context.Domain.Consensus().GetBlockInfo(hash)
+		if err != nil {
+			return nil, err
+		}
+		if !blockInfo.Exists || blockInfo.BlockStatus == externalapi.StatusInvalid {
+			errorMessage := &appmessage.GetDAGWindowResponseMessage{}
+			errorMessage.Error = appmessage.RPCErrorf("Could not find block %s", hash)
+			return errorMessage, nil
+		}
+
+		anticone, err := context.Domain.Consensus().Anticone(hash)
+		if err != nil {
+			return nil, err
+		}
+		if len(anticone)+1 > maxDAGWindowBlocks {
+			errorMessage := &appmessage.GetDAGWindowResponseMessage{}
+			errorMessage.Error = appmessage.RPCErrorf("Could not get the window: %s", errDAGWindowTooLarge)
+			return errorMessage, nil
+		}
+		windowHashes = append([]*externalapi.DomainHash{hash}, anticone...)
+	} else {
+		if getDAGWindowRequest.LowBlueScore > getDAGWindowRequest.HighBlueScore {
+			errorMessage := &appmessage.GetDAGWindowResponseMessage{}
+			errorMessage.Error = appmessage.RPCErrorf("lowBlueScore %d is higher than highBlueScore %d",
+				getDAGWindowRequest.LowBlueScore, getDAGWindowRequest.HighBlueScore)
+			return errorMessage, nil
+		}
+
+		var err error
+		windowHashes, err = dagWindowByBlueScore(traversal,
+			getDAGWindowRequest.LowBlueScore, getDAGWindowRequest.HighBlueScore)
+		if err != nil {
+			if errors.Is(err, errDAGWindowTooLarge) {
+				errorMessage := &appmessage.GetDAGWindowResponseMessage{}
+				errorMessage.Error = appmessage.RPCErrorf("Could not get the window: %s", err)
+				return errorMessage, nil
+			}
+			return nil, err
+		}
+	}
+
+	blocks, err := dagWindowBlocks(traversal, windowHashes)
+	if err != nil {
+		if errors.Is(err, errDAGWindowTooLarge) {
+			errorMessage := &appmessage.GetDAGWindowResponseMessage{}
+			errorMessage.Error = appmessage.RPCErrorf("Could not get the window: %s", err)
+			return errorMessage, nil
+		}
+		return nil, err
+	}
+	return appmessage.NewGetDAGWindowResponseMessage(blocks), nil
+}
+
+// dagWindowTraversal gets the info of the blocks GetDAGWindow visits, and fails
+// with errDAGWindowTooLarge once more than maxDAGWindowVisitedBlocks are visited
+type dagWindowTraversal struct {
+	context           *rpccontext.Context
+	visitedBlockCount int
+}
+
+func (t *dagWindowTraversal) blockInfo(blockHash *externalapi.DomainHash) (*externalapi.BlockInfo, error) {
+	if t.visitedBlockCount == maxDAGWindowVisitedBlocks {
+		return nil, errDAGWindowTooLarge
+	}
+	t.visitedBlockCount++
+	return t.context.Domain.Consensus().GetBlockInfo(blockHash)
+}
+
+// chainBlockAboveBlueScore returns the block with the lowest blue score above blueScore in
+// the selected parent chain of the selected tip, or the selected tip if there's no such block.
+// If the blue score of the pruning point is above blueScore, the pruning point is returned.
+//
+// The headers selected chain is indexed, so rather than walking down the selected parent
+// chain, the block is found by narrowing down block locators of the headers selected chain.
+func chainBlockAboveBlueScore(t *dagWindowTraversal, blueScore uint64) (*externalapi.DomainHash, error) {
+	selectedTip, err := t.context.SelectedTip()
+	if err != nil {
+		return nil, err
+	}
+	selectedTipInfo, err := t.blockInfo(selectedTip)
+	if err != nil {
+		return nil, err
+	}
+	if selectedTipInfo.BlueScore <= blueScore {
+		return selectedTip, nil
+	}
+
+	// The selected parent chain of the selected tip joins the headers selected
+	// chain right at the selected tip, unless the node is still syncing
+	headersSelectedTip, err := t.context.Domain.Consensus().GetHeadersSelectedTip()
+	if err != nil {
+		return nil, err
+	}
+	highHash := selectedTip
+	for {
+		isInHeadersSelectedChain, err := t.context.Domain.Consensus().IsInSelectedParentChainOf(
+			highHash, headersSelectedTip)
+		if err != nil {
+			return nil, err
+		}
+		if isInHeadersSelectedChain {
+			break
+		}
+		highInfo, err := t.blockInfo(highHash)
+		if err != nil {
+			return nil, err
+		}
+		selectedParentInfo, err := t.blockInfo(highInfo.SelectedParent)
+		if err != nil {
+			return nil, err
+		}
+		if selectedParentInfo.BlueScore <= blueScore {
+			return highHash, nil
+		}
+		highHash = highInfo.SelectedParent
+	}
+
+	lowHash, err := t.context.Domain.Consensus().PruningPoint()
+	if err != nil {
+		return nil, err
+	}
+	lowInfo, err := t.blockInfo(lowHash)
+	if err != nil {
+		return nil, err
+	}
+	if lowInfo.BlueScore > blueScore {
+		return lowHash, nil
+	}
+
+	// The block is always above lowHash and at or below highHash. The locator
+	// between them starts with highHash and the block right below it, and ends
+	// with lowHash, so every iteration at least halves the distance between them.
+	for {
+		locator, err := t.context.Domain.Consensus().CreateHeadersSelectedChainBlockLocator(lowHash, highHash)
+		if err != nil {
+			return nil, err
+		}
+		for i := 1; i < len(locator); i++ {
+			blockInfo, err := t.blockInfo(locator[i])
+			if err != nil {
+				return nil, err
+			}
+			if blockInfo.BlueScore <= blueScore {
+				if i == 1 {
+					return locator[0], nil
+				}
+				highHash, lowHash = locator[i-1], locator[i]
+				break
+			}
+		}
+	}
+}
+
+// dagWindowByBlueScore returns the hashes of the blocks with blue scores between lowBlueScore
+// and highBlueScore, inclusive. It traverses the DAG down through the parents of every block,
+// starting from the chain block right above highBlueScore, until it reaches blocks below
+// lowBlueScore. Blocks that weren't merged by that chain block, such as blocks that were mined
+// late, are only returned once highBlueScore reaches the blue score of the block that merges them.
+func dagWindowByBlueScore(t *dagWindowTraversal, lowBlueScore, highBlueScore uint64) (
+	[]*externalapi.DomainHash, error) {
+
+	chainBlock, err := chainBlockAboveBlueScore(t, highBlueScore)
+	if err != nil {
+		return nil, err
+	}
+	queue := []*externalapi.DomainHash{chainBlock}
+	// When the window reaches the selected tip, it contains the tips that aren't merged yet
+	selectedTip, err := t.context.SelectedTip()
+	if err != nil {
+		return nil, err
+	}
+	if chainBlock.Equal(selectedTip) {
+		tips, err := t.context.Domain.Consensus().Tips()
+		if err != nil {
+			return nil, err
+		}
+		queue = append(queue, tips...)
+	}
+
+	var windowHashes []*externalapi.DomainHash
+	visited := make(map[externalapi.DomainHash]struct{})
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if _, ok := visited[*current]; ok || current.Equal(model.VirtualGenesisBlockHash) {
+			continue
+		}
+		visited[*current] = struct{}{}
+
+		blockInfo, err := t.blockInfo(current)
+		if err != nil {
+			return nil, err
+		}
+		if !blockInfo.Exists || blockInfo.BlockStatus == externalapi.StatusInvalid ||
+			blockInfo.BlueScore < lowBlueScore {
+			continue
+		}
+		if blockInfo.BlueScore <= highBlueScore {
+			if len(windowHashes) == maxDAGWindowBlocks {
+				return nil, errDAGWindowTooLarge
+			}
+			windowHashes = append(windowHashes, current)
+		}
+
+		parents, _, _, err := t.context.Domain.Consensus().GetBlockRelations(current)
+		if err != nil {
+			return nil, err
+		}
+		queue = append(queue, parents...)
+	}
+	return windowHashes, nil
+}
+
+// dagWindowBlocks returns the given blocks, annotated and sorted by blue score and then by hash
+func dagWindowBlocks(t *dagWindowTraversal, windowHashes []*externalapi.DomainHash) (
+	[]*appmessage.RPCDAGWindowBlock, error) {
+
+	context := t.context
+	blockInfos := make(map[externalapi.DomainHash]*externalapi.BlockInfo, len(windowHashes))
+	var minBlueScore, maxBlueScore uint64
+	for i, hash := range windowHashes {
+		blockInfo, err := context.Domain.Consensus().GetBlockInfo(hash)
+		if err != nil {
+			return nil, err
+		}
+		blockInfos[*hash] = blockInfo
+		if i == 0 || blockInfo.BlueScore < minBlueScore {
+			minBlueScore = blockInfo.BlueScore
+		}
+		if i == 0 || blockInfo.BlueScore > maxBlueScore {
+			maxBlueScore = blockInfo.BlueScore
+		}
+	}
+
+	chainBlocks, colors, err := dagWindowChainBlocksAndColors(t, minBlueScore, maxBlueScore)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(windowHashes, func(i, j int) bool {
+		blueScoreI := blockInfos[*windowHashes[i]].BlueScore
+		blueScoreJ := blockInfos[*windowHashes[j]].BlueScore
+		if blueScoreI != blueScoreJ {
+			return blueScoreI < blueScoreJ
+		}
+		return windowHashes[i].Less(windowHashes[j])
+	})
+
+	blocks := make([]*appmessage.RPCDAGWindowBlock, len(windowHashes))
+	for i, hash := range windowHashes {
+		blockInfo := blockInfos[*hash]
+		parents, _, _, err := context.Domain.Consensus().GetBlockRelations(hash)
+		if err != nil {
+			return nil, err
+		}
+		header, err := context.Domain.Consensus().GetBlockHeader(hash)
+		if err != nil {
+			return nil, err
+		}
+
+		selectedParentHash := ""
+		if blockInfo.SelectedParent != nil && !blockInfo.SelectedParent.Equal(model.VirtualGenesisBlockHash) {
+			selectedParentHash = blockInfo.SelectedParent.String()
+		}
+		_, isChainBlock := chainBlocks[*hash]
+		blocks[i] = &appmessage.RPCDAGWindowBlock{
+			Hash:                hash.String(),
+			ParentHashes:        hashes.ToStrings(withoutVirtualGenesis(parents)),
+			SelectedParentHash:  selectedParentHash,
+			MergeSetBluesHashes: hashes.ToStrings(withoutVirtualGenesis(blockInfo.MergeSetBlues)),
+			MergeSetRedsHashes:  hashes.ToStrings(withoutVirtualGenesis(blockInfo.MergeSetReds)),
+			IsChainBlock:        isChainBlock,
+			Color:               colors[*hash],
+			Status:              blockInfo.BlockStatus.String(),
+			BlueScore:           blockInfo.BlueScore,
+			DAAScore:            header.DAAScore(),
+		}
+	}
+	return blocks, nil
+}
+
+// dagWindowChainBlocksAndColors walks down the selected parent chain of the virtual from
+// the chain block right above maxBlueScore, and returns the chain blocks and the colors of
+// the blocks they merged. Since every block is merged by a chain block with a higher blue
+// score, the walk stops at the first chain block below minBlueScore. Blocks that are only
+// merged above the chain block the walk starts from are left without a color.
+func dagWindowChainBlocksAndColors(t *dagWindowTraversal, minBlueScore, maxBlueScore uint64) (
+	chainBlocks map[externalapi.DomainHash]struct{}, colors map[externalapi.DomainHash]string, err error) {
+
+	chainBlocks = make(map[externalapi.DomainHash]struct{})
+	colors = make(map[externalapi.DomainHash]string)
+
+	current, err := chainBlockAboveBlueScore(t, maxBlueScore)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Chain blocks are blue in the eyes of the chain block that selected them,
+	// and the selected tip in the eyes of the virtual
+	colors[*current] = appmessage.DAGWindowBlockColorBlue
+	for {
+		blockInfo, err := t.blockInfo(current)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !blockInfo.Exists || blockInfo.BlueScore < minBlueScore {
+			break
+		}
+
+		chainBlocks[*current] = struct{}{}
+		for _, blue := range blockInfo.MergeSetBlues {
+			colors[*blue] = appmessage.DAGWindowBlockColorBlue
+		}
+		for _, red := range blockInfo.MergeSetReds {
+			colors[*red] = appmessage.DAGWindowBlockColorRed
+		}
+
+		if blockInfo.SelectedParent == nil || blockInfo.SelectedParent.Equal(model.VirtualGenesisBlockHash) {
+			break
+		}
+		current = blockInfo.SelectedParent
+	}
+	return chainBlocks, colors, nil
+}
+
+// withoutVirtualGenesis returns the given hashes without the virtual genesis, which
+// is an internal marker rather than a real block
+func withoutVirtualGenesis(blockHashes []*externalapi.DomainHash) []*externalapi.DomainHash {
+	result := make([]*externalapi.DomainHash, 0, len(blockHashes))
+	for _, blockHash := range blockHashes {
+		if !blockHash.Equal(model.VirtualGenesisBlockHash) {
+			result = append(result, blockHash)
+		}
+	}
+	return result
+}
@@ -0,0 +1,137 @@
+package rpchandlers_test
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/app/rpc/rpchandlers"
+	"github.com/kaspanet/kaspad/domain/consensus"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/testutils"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+)
+
+// blockInfoCountingDomain is a fakeDomain that counts the calls to GetBlockInfo
+type blockInfoCountingDomain struct {
+	fakeDomain
+	blockInfoCount *int
+}
+
+func (d blockInfoCountingDomain) Consensus() externalapi.Consensus { return d }
+
+func (d blockInfoCountingDomain) GetBlockInfo(blockHash *externalapi.DomainHash) (*externalapi.BlockInfo, error) {
+	*d.blockInfoCount++
+	return d.fakeDomain.GetBlockInfo(blockHash)
+}
+
+func TestHandleGetDAGWindow(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestHandleGetDAGWindow")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		blockInfoCount := 0
+		fakeContext := rpccontext.Context{
+			Config: &config.Config{Flags: &config.Flags{NetworkFlags: config.NetworkFlags{ActiveNetParams: &consensusConfig.Params}}},
+			Domain: blockInfoCountingDomain{fakeDomain: fakeDomain{tc}, blockInfoCount: &blockInfoCount},
+		}
+
+		// Create a chain in which every tenth block is split into two
+		// siblings, which are merged by the block after them
+		const chainLength = 500
+		allBlocks := []*externalapi.DomainHash{consensusConfig.GenesisHash}
+		tip := consensusConfig.GenesisHash
+		for i := 0; i < chainLength; i++ {
+			parents := []*externalapi.DomainHash{tip}
+			if i%10 == 0 {
+				parents = nil
+				for j := 0; j < 2; j++ {
+					siblingHash, _, err := tc.AddBlock([]*externalapi.DomainHash{tip}, nil, nil)
+					if err != nil {
+						t.Fatalf("AddBlock: %+v", err)
+					}
+					parents = append(parents, siblingHash)
+					allBlocks = append(allBlocks, siblingHash)
+				}
+			}
+			tip, _, err = tc.AddBlock(parents, nil, nil)
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+			allBlocks = append(allBlocks, tip)
+		}
+
+		blueScores := make(map[string]uint64, len(allBlocks))
+		for _, blockHash := range allBlocks {
+			blockInfo, err := tc.GetBlockInfo(blockHash)
+			if err != nil {
+				t.Fatalf("GetBlockInfo: %+v", err)
+			}
+			blueScores[blockHash.String()] = blockInfo.BlueScore
+		}
+		tipBlueScore := blueScores[tip.String()]
+
+		tests := []struct {
+			name                      string
+			lowBlueScore              uint64
+			highBlueScore             uint64
+			maxExpectedBlockInfoCount int
+		}{
+			{name: "deep below the tip", lowBlueScore: 20, highBlueScore: 40, maxExpectedBlockInfoCount: 200},
+			{name: "from the genesis", lowBlueScore: 0, highBlueScore: 15, maxExpectedBlockInfoCount: 200},
+			{name: "at the tip", lowBlueScore: tipBlueScore - 15, highBlueScore: tipBlueScore,
+				maxExpectedBlockInfoCount: 200},
+			{name: "above the tip", lowBlueScore: tipBlueScore - 15, highBlueScore: tipBlueScore + 100,
+				maxExpectedBlockInfoCount: 200},
+		}
+		for _, test := range tests {
+			blockInfoCount = 0
+			request := appmessage.NewGetDAGWindowByBlueScoreRequestMessage(test.lowBlueScore, test.highBlueScore)
+			response, err := rpchandlers.HandleGetDAGWindow(&fakeContext, nil, request)
+			if err != nil {
+				t.Fatalf("%s: HandleGetDAGWindow: %+v", test.name, err)
+			}
+			getDAGWindowResponse := response.(*appmessage.GetDAGWindowResponseMessage)
+			if getDAGWindowResponse.Error != nil {
+				t.Fatalf("%s: HandleGetDAGWindow: %s", test.name, getDAGWindowResponse.Error.Message)
+			}
+			if blockInfoCount > test.maxExpectedBlockInfoCount {
+				t.Fatalf("%s: expected to visit at most %d blocks, but visited %d",
+					test.name, test.maxExpectedBlockInfoCount, blockInfoCount)
+			}
+
+			expectedBlocks := make(map[string]struct{})
+			for blockHash, blueScore := range blueScores {
+				if blueScore >= test.lowBlueScore && blueScore <= test.highBlueScore {
+					expectedBlocks[blockHash] = struct{}{}
+				}
+			}
+			if len(getDAGWindowResponse.Blocks) != len(expectedBlocks) {
+				t.Fatalf("%s: expected %d blocks, got %d", test.name, len(expectedBlocks), len(getDAGWindowResponse.Blocks))
+			}
+			for _, block := range getDAGWindowResponse.Blocks {
+				if _, ok := expectedBlocks[block.Hash]; !ok {
+					t.Fatalf("%s: unexpected block %s with blue score %d", test.name, block.Hash, block.BlueScore)
+				}
+				if block.Color != appmessage.DAGWindowBlockColorBlue {
+					t.Fatalf("%s: expected block %s to be blue, but its color is %q", test.name, block.Hash, block.Color)
+				}
+				blockHash, err := externalapi.NewDomainHashFromString(block.Hash)
+				if err != nil {
+					t.Fatalf("NewDomainHashFromString: %s", err)
+				}
+				isChainBlock, err := tc.IsInSelectedParentChainOf(blockHash, tip)
+				if err != nil {
+					t.Fatalf("IsInSelectedParentChainOf: %+v", err)
+				}
+				if block.IsChainBlock != isChainBlock {
+					t.Fatalf("%s: expected IsChainBlock of block %s to be %t", test.name, block.Hash, isChainBlock)
+				}
+			}
+		}
+	})
+}
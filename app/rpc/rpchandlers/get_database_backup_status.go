@@ -0,0 +1,19 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+)
+
+// HandleGetDatabaseBackupStatus handles the respectively named RPC command
+func HandleGetDatabaseBackupStatus(context *rpccontext.Context, _ *router.Router, _ appmessage.Message) (appmessage.Message, error) {
+	status := context.DatabaseBackup.Status()
+
+	failure := ""
+	if status.Err != nil {
+		failure = status.Err.Error()
+	}
+	return appmessage.NewGetDatabaseBackupStatusResponseMessage(status.Path, status.IsRunning, status.EntryCount,
+		failure), nil
+}
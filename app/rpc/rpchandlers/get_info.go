@@ -13,6 +13,8 @@ func HandleGetInfo(context *rpccontext.Context, _ *router.Router, _ appmessage.M
 		context.NetAdapter.ID().String(),
 		uint64(context.Domain.MiningManager().TransactionCount()),
 		version.Version(),
+		context.Domain.MiningManager().TotalTransactionMass(),
+		context.Domain.MiningManager().MinimumFeeRate(),
 	)
 
 	return response, nil
@@ -8,7 +8,7 @@ import (
 
 // HandleGetSelectedTipHash handles the respectively named RPC command
 func HandleGetSelectedTipHash(context *rpccontext.Context, _ *router.Router, _ appmessage.Message) (appmessage.Message, error) {
-	selectedTip, err := context.Domain.Consensus().GetVirtualSelectedParent()
+	selectedTip, err := context.SelectedTip()
 	if err != nil {
 		return nil, err
 	}
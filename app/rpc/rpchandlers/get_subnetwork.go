@@ -3,12 +3,30 @@ package rpchandlers
 import (
 	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/subnetworks"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
 )
 
 // HandleGetSubnetwork handles the respectively named RPC command
 func HandleGetSubnetwork(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
-	response := &appmessage.GetSubnetworkResponseMessage{}
-	response.Error = appmessage.RPCErrorf("not implemented")
-	return response, nil
+	getSubnetworkRequest := request.(*appmessage.GetSubnetworkRequestMessage)
+
+	subnetworkID, err := subnetworks.FromString(getSubnetworkRequest.SubnetworkID)
+	if err != nil {
+		errorMessage := &appmessage.GetSubnetworkResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Subnetwork ID could not be parsed: %s", err)
+		return errorMessage, nil
+	}
+
+	gasLimit, exists, err := context.Domain.Consensus().GetSubnetworkGasLimit(subnetworkID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		errorMessage := &appmessage.GetSubnetworkResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Subnetwork %s not found", subnetworkID)
+		return errorMessage, nil
+	}
+
+	return appmessage.NewGetSubnetworkResponseMessage(gasLimit), nil
 }
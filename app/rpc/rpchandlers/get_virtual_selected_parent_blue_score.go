@@ -8,12 +8,11 @@ import (
 
 // HandleGetVirtualSelectedParentBlueScore handles the respectively named RPC command
 func HandleGetVirtualSelectedParentBlueScore(context *rpccontext.Context, _ *router.Router, _ appmessage.Message) (appmessage.Message, error) {
-	c := context.Domain.Consensus()
-	selectedParent, err := c.GetVirtualSelectedParent()
+	selectedParent, err := context.SelectedTip()
 	if err != nil {
 		return nil, err
 	}
-	blockInfo, err := c.GetBlockInfo(selectedParent)
+	blockInfo, err := context.Domain.Consensus().GetBlockInfo(selectedParent)
 	if err != nil {
 		return nil, err
 	}
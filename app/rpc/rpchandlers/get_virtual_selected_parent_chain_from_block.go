@@ -9,6 +9,12 @@ import (
 
 // HandleGetVirtualSelectedParentChainFromBlock handles the respectively named RPC command
 func HandleGetVirtualSelectedParentChainFromBlock(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	if context.Config.HeadersOnly {
+		errorMessage := &appmessage.GetVirtualSelectedParentChainFromBlockResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Method unavailable when kaspad is run with --headers-only")
+		return errorMessage, nil
+	}
+
 	getVirtualSelectedParentChainFromBlockRequest := request.(*appmessage.GetVirtualSelectedParentChainFromBlockRequestMessage)
 
 	startHash, err := externalapi.NewDomainHashFromString(getVirtualSelectedParentChainFromBlockRequest.StartHash)
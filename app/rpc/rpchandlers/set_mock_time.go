@@ -0,0 +1,38 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/kaspanet/kaspad/util/mstime"
+)
+
+// HandleSetMockTime handles the respectively named RPC command
+func HandleSetMockTime(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	setMockTimeRequest := request.(*appmessage.SetMockTimeRequestMessage)
+
+	// The node only uses a mock clock on the networks where SetMockTime is allowed
+	clock, ok := context.Domain.Clock().(*mstime.MockClock)
+	if !ok {
+		errorMessage := &appmessage.SetMockTimeResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("SetMockTime is only available on simnet and devnet")
+		return errorMessage, nil
+	}
+
+	if setMockTimeRequest.MockTime < 0 {
+		errorMessage := &appmessage.SetMockTimeResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("The mock time must not be negative")
+		return errorMessage, nil
+	}
+
+	if setMockTimeRequest.MockTime == 0 {
+		clock.ClearMockTime()
+		log.Infof("Mock time cleared via setMockTime")
+	} else {
+		mockTime := mstime.UnixMilliseconds(setMockTimeRequest.MockTime)
+		clock.SetMockTime(mockTime)
+		log.Infof("Mock time set to %s via setMockTime", mockTime)
+	}
+
+	return appmessage.NewSetMockTimeResponseMessage(), nil
+}
@@ -12,6 +12,12 @@ import (
 
 // HandleSubmitBlock handles the respectively named RPC command
 func HandleSubmitBlock(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	if context.Config.HeadersOnly {
+		errorMessage := &appmessage.SubmitBlockResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Method unavailable when kaspad is run with --headers-only")
+		return errorMessage, nil
+	}
+
 	submitBlockRequest := request.(*appmessage.SubmitBlockRequestMessage)
 
 	isSynced, err := context.ProtocolManager.ShouldMine()
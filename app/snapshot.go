@@ -0,0 +1,75 @@
+package app
+
+import (
+	"os"
+
+	"github.com/kaspanet/kaspad/app/snapshot"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/pkg/errors"
+)
+
+// exportSnapshot writes a snapshot of the pruning point state into cfg.ExportSnapshot.
+// The snapshot is first written into a temporary file, so that a partial snapshot is
+// never left behind under the requested name.
+func exportSnapshot(cfg *config.Config, db database.Database) error {
+	path := cfg.ExportSnapshot
+	_, err := os.Stat(path)
+	if err == nil {
+		return errors.Errorf("%s already exists", path)
+	}
+	if !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+
+	domain, err := newDomain(cfg, db)
+	if err != nil {
+		return err
+	}
+
+	temporaryPath := path + ".tmp"
+	file, err := os.OpenFile(temporaryPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer os.Remove(temporaryPath)
+
+	pruningPoint, err := snapshot.Export(domain.Consensus(), cfg.ActiveNetParams.GenesisHash, file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	err = file.Sync()
+	if err != nil {
+		file.Close()
+		return errors.WithStack(err)
+	}
+	err = file.Close()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = os.Rename(temporaryPath, path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	log.Infof("Wrote a snapshot of pruning point %s to %s", pruningPoint, path)
+	return nil
+}
+
+// importSnapshot bootstraps the node from the snapshot in cfg.ImportSnapshot
+func importSnapshot(cfg *config.Config, db database.Database) error {
+	file, err := os.Open(cfg.ImportSnapshot)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer file.Close()
+
+	domain, err := newDomain(cfg, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = snapshot.Import(domain, cfg.ActiveNetParams, file)
+	return err
+}
@@ -0,0 +1,183 @@
+package snapshot
+
+import (
+	"io"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/pkg/errors"
+)
+
+// Export writes a snapshot of the pruning point state of the given consensus into w.
+// It returns the pruning point the snapshot was made of.
+//
+// NOTE: While this is called no new blocks can be added to the consensus.
+func Export(consensus externalapi.Consensus, genesisHash *externalapi.DomainHash, w io.Writer) (
+	*externalapi.DomainHash, error) {
+
+	pruningPoint, err := consensus.PruningPoint()
+	if err != nil {
+		return nil, err
+	}
+	if pruningPoint.Equal(genesisHash) {
+		return nil, errors.Errorf("the pruning point is still the genesis, so there's nothing to export")
+	}
+	log.Infof("Exporting a snapshot of pruning point %s", pruningPoint)
+
+	snapshotWriter, err := newWriter(w, genesisHash)
+	if err != nil {
+		return nil, err
+	}
+
+	err = exportPruningPointProofAndPruningPoints(consensus, snapshotWriter)
+	if err != nil {
+		return nil, err
+	}
+	err = exportPruningPointAndItsAnticone(consensus, snapshotWriter)
+	if err != nil {
+		return nil, err
+	}
+	err = exportPruningPointFutureHeaders(consensus, snapshotWriter, pruningPoint)
+	if err != nil {
+		return nil, err
+	}
+	err = exportPruningPointUTXOSet(consensus, snapshotWriter, pruningPoint)
+	if err != nil {
+		return nil, err
+	}
+	err = exportPruningPointSubnetworkRegistrations(consensus, snapshotWriter, pruningPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	err = snapshotWriter.close()
+	if err != nil {
+		return nil, err
+	}
+	return pruningPoint, nil
+}
+
+func exportPruningPointProofAndPruningPoints(consensus externalapi.Consensus, snapshotWriter *writer) error {
+	pruningPointProof, err := consensus.BuildPruningPointProof()
+	if err != nil {
+		return err
+	}
+	err = snapshotWriter.writeMessage(appmessage.DomainPruningPointProofToMsgPruningPointProof(pruningPointProof))
+	if err != nil {
+		return err
+	}
+
+	pruningPointHeaders, err := consensus.PruningPointHeaders()
+	if err != nil {
+		return err
+	}
+	msgPruningPointHeaders := make([]*appmessage.MsgBlockHeader, len(pruningPointHeaders))
+	for i, header := range pruningPointHeaders {
+		msgPruningPointHeaders[i] = appmessage.DomainBlockHeaderToBlockHeader(header)
+	}
+	return snapshotWriter.writeMessage(appmessage.NewMsgPruningPoints(msgPruningPointHeaders))
+}
+
+func exportPruningPointAndItsAnticone(consensus externalapi.Consensus, snapshotWriter *writer) error {
+	pruningPointAndItsAnticone, err := consensus.PruningPointAndItsAnticone()
+	if err != nil {
+		return err
+	}
+	for _, blockHash := range pruningPointAndItsAnticone {
+		blockWithTrustedData, err := consensus.BlockWithTrustedData(blockHash)
+		if err != nil {
+			return err
+		}
+		err = snapshotWriter.writeMessage(
+			appmessage.DomainBlockWithTrustedDataToBlockWithTrustedData(blockWithTrustedData))
+		if err != nil {
+			return err
+		}
+	}
+	log.Debugf("Exported the pruning point and its anticone (%d blocks)", len(pruningPointAndItsAnticone))
+
+	return snapshotWriter.writeMessage(appmessage.NewMsgDoneBlocksWithTrustedData())
+}
+
+func exportPruningPointFutureHeaders(consensus externalapi.Consensus, snapshotWriter *writer,
+	pruningPoint *externalapi.DomainHash) error {
+
+	headersSelectedTip, err := consensus.GetHeadersSelectedTip()
+	if err != nil {
+		return err
+	}
+
+	headerCount := 0
+	lowHash := pruningPoint
+	for !lowHash.Equal(headersSelectedTip) {
+		// maxBlocks MUST be >= MergeSetSizeLimit + 1
+		const maxBlocks = 1 << 10
+		blockHashes, _, err := consensus.GetHashesBetween(lowHash, headersSelectedTip, maxBlocks)
+		if err != nil {
+			return err
+		}
+
+		blockHeaders := make([]*appmessage.MsgBlockHeader, len(blockHashes))
+		for i, blockHash := range blockHashes {
+			blockHeader, err := consensus.GetBlockHeader(blockHash)
+			if err != nil {
+				return err
+			}
+			blockHeaders[i] = appmessage.DomainBlockHeaderToBlockHeader(blockHeader)
+		}
+		err = snapshotWriter.writeMessage(appmessage.NewBlockHeadersMessage(blockHeaders))
+		if err != nil {
+			return err
+		}
+		headerCount += len(blockHeaders)
+
+		// The next lowHash is the last element in blockHashes
+		lowHash = blockHashes[len(blockHashes)-1]
+	}
+	log.Debugf("Exported %d headers in the future of the pruning point", headerCount)
+
+	return snapshotWriter.writeMessage(appmessage.NewMsgDoneHeaders())
+}
+
+func exportPruningPointUTXOSet(consensus externalapi.Consensus, snapshotWriter *writer,
+	pruningPoint *externalapi.DomainHash) error {
+
+	const step = 1000
+	utxoCount := 0
+	var fromOutpoint *externalapi.DomainOutpoint
+	for {
+		pruningPointUTXOs, err := consensus.GetPruningPointUTXOs(pruningPoint, fromOutpoint, step)
+		if err != nil {
+			return err
+		}
+		if len(pruningPointUTXOs) > 0 {
+			outpointAndUTXOEntryPairs :=
+				appmessage.DomainOutpointAndUTXOEntryPairsToOutpointAndUTXOEntryPairs(pruningPointUTXOs)
+			err = snapshotWriter.writeMessage(appmessage.NewMsgPruningPointUTXOSetChunk(outpointAndUTXOEntryPairs))
+			if err != nil {
+				return err
+			}
+			utxoCount += len(pruningPointUTXOs)
+		}
+
+		if len(pruningPointUTXOs) < step {
+			break
+		}
+		fromOutpoint = pruningPointUTXOs[len(pruningPointUTXOs)-1].Outpoint
+	}
+	log.Infof("Exported the pruning point UTXO set (%d UTXOs)", utxoCount)
+
+	return snapshotWriter.writeMessage(appmessage.NewMsgDonePruningPointUTXOSetChunks())
+}
+
+func exportPruningPointSubnetworkRegistrations(consensus externalapi.Consensus, snapshotWriter *writer,
+	pruningPoint *externalapi.DomainHash) error {
+
+	registrations, err := consensus.GetPruningPointSubnetworkRegistrations(pruningPoint)
+	if err != nil {
+		return err
+	}
+	log.Debugf("Exported %d subnetwork registrations of the pruning point", len(registrations))
+
+	return snapshotWriter.writeMessage(appmessage.NewMsgPruningPointSubnetworkRegistrations(registrations))
+}
@@ -0,0 +1,183 @@
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+// A snapshot file is a gzip stream that starts with a header:
+//   magic (8 bytes) | format version (uint32) | genesis hash (32 bytes)
+// and continues with a sequence of length-prefixed (uint32) p2p messages, in the
+// same order a syncing node receives them from its peer during IBD:
+//   MsgPruningPointProof
+//   MsgPruningPoints
+//   MsgBlockWithTrustedData for the pruning point and each block in its anticone
+//   MsgDoneBlocksWithTrustedData
+//   BlockHeadersMessage for the headers in the future of the pruning point
+//   MsgDoneHeaders
+//   MsgPruningPointUTXOSetChunk for the pruning point UTXO set
+//   MsgDonePruningPointUTXOSetChunks
+//   MsgPruningPointSubnetworkRegistrations for the subnetworks registered up to the pruning point,
+//   which peers don't send since nothing they send commits to them
+
+var magic = [8]byte{'k', 'a', 's', 'p', 'a', 's', 'n', 'p'}
+
+// formatVersion 2 added the subnetwork registrations
+const formatVersion uint32 = 2
+
+// maxMessageSize is the largest message that is allowed in a snapshot file. It
+// matches the largest message that's allowed in the p2p protocol.
+const maxMessageSize = 1024 * 1024 * 1024
+
+// ErrInvalidSnapshot indicates that a snapshot file is malformed
+var ErrInvalidSnapshot = errors.New("invalid snapshot")
+
+type writer struct {
+	gzipWriter *gzip.Writer
+	buffer     *bufio.Writer
+}
+
+func newWriter(w io.Writer, genesisHash *externalapi.DomainHash) (*writer, error) {
+	buffer := bufio.NewWriter(w)
+	gzipWriter := gzip.NewWriter(buffer)
+
+	header := make([]byte, 0, len(magic)+4+externalapi.DomainHashSize)
+	header = append(header, magic[:]...)
+	header = append(header, make([]byte, 4)...)
+	binary.LittleEndian.PutUint32(header[len(magic):], formatVersion)
+	header = append(header, genesisHash.ByteSlice()...)
+	_, err := gzipWriter.Write(header)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &writer{gzipWriter: gzipWriter, buffer: buffer}, nil
+}
+
+func (w *writer) writeMessage(message appmessage.Message) error {
+	protoMessage, err := protowire.FromAppMessage(message)
+	if err != nil {
+		return err
+	}
+	serializedMessage, err := proto.Marshal(protoMessage)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(serializedMessage)))
+	_, err = w.gzipWriter.Write(length[:])
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = w.gzipWriter.Write(serializedMessage)
+	return errors.WithStack(err)
+}
+
+// close flushes everything that was written. It does not close the underlying writer.
+func (w *writer) close() error {
+	err := w.gzipWriter.Close()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(w.buffer.Flush())
+}
+
+type reader struct {
+	gzipReader *gzip.Reader
+}
+
+func newReader(r io.Reader, expectedGenesisHash *externalapi.DomainHash) (*reader, error) {
+	gzipReader, err := gzip.NewReader(bufio.NewReader(r))
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidSnapshot, "the snapshot is not gzipped: %s", err)
+	}
+
+	header := make([]byte, len(magic)+4+externalapi.DomainHashSize)
+	_, err = io.ReadFull(gzipReader, header)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidSnapshot, "couldn't read the snapshot header: %s", err)
+	}
+	if !bytes.Equal(header[:len(magic)], magic[:]) {
+		return nil, errors.Wrapf(ErrInvalidSnapshot, "the file is not a snapshot")
+	}
+	version := binary.LittleEndian.Uint32(header[len(magic):])
+	if version != formatVersion {
+		return nil, errors.Wrapf(ErrInvalidSnapshot, "unsupported snapshot version %d", version)
+	}
+	genesisHash, err := externalapi.NewDomainHashFromByteSlice(header[len(magic)+4:])
+	if err != nil {
+		return nil, err
+	}
+	if !genesisHash.Equal(expectedGenesisHash) {
+		return nil, errors.Wrapf(ErrInvalidSnapshot, "the snapshot is of a network with genesis %s, "+
+			"but the genesis of this network is %s", genesisHash, expectedGenesisHash)
+	}
+
+	return &reader{gzipReader: gzipReader}, nil
+}
+
+func (r *reader) readMessage() (appmessage.Message, error) {
+	var length [4]byte
+	_, err := io.ReadFull(r.gzipReader, length[:])
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidSnapshot, "couldn't read the next message: %s", err)
+	}
+	messageLength := binary.LittleEndian.Uint32(length[:])
+	if messageLength > maxMessageSize {
+		return nil, errors.Wrapf(ErrInvalidSnapshot, "message of size %d is bigger than the maximum of %d",
+			messageLength, maxMessageSize)
+	}
+
+	serializedMessage := make([]byte, messageLength)
+	_, err = io.ReadFull(r.gzipReader, serializedMessage)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidSnapshot, "couldn't read the next message: %s", err)
+	}
+	protoMessage := &protowire.KaspadMessage{}
+	err = proto.Unmarshal(serializedMessage, protoMessage)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidSnapshot, "couldn't deserialize the next message: %s", err)
+	}
+	message, err := protoMessage.ToAppMessage()
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidSnapshot, "couldn't convert the next message: %s", err)
+	}
+	return message, nil
+}
+
+// readEOF makes sure that there's nothing left in the snapshot after the last message
+func (r *reader) readEOF() error {
+	var next [1]byte
+	_, err := r.gzipReader.Read(next[:])
+	if err == nil {
+		return errors.Wrapf(ErrInvalidSnapshot, "the snapshot has data after its last message")
+	}
+	if !errors.Is(err, io.EOF) {
+		return errors.Wrapf(ErrInvalidSnapshot, "couldn't read the end of the snapshot: %s", err)
+	}
+	return nil
+}
+
+// readExpectedMessage reads the next message and returns an error if its command isn't
+// expectedCommand
+func (r *reader) readExpectedMessage(expectedCommand appmessage.MessageCommand) (appmessage.Message, error) {
+	message, err := r.readMessage()
+	if err != nil {
+		return nil, err
+	}
+	if message.Command() != expectedCommand {
+		return nil, errors.Wrapf(ErrInvalidSnapshot, "unexpected message. expected: %s, got: %s",
+			expectedCommand, message.Command())
+	}
+	return message, nil
+}
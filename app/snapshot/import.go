@@ -0,0 +1,313 @@
+package snapshot
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/protocol/common"
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/pkg/errors"
+)
+
+// Import reads a snapshot from r and replaces the consensus of the given domain with the
+// state it describes. The snapshot is validated the same way a pruning point sent by a peer
+// during IBD is: the pruning point proof and the pruning points are checked against the
+// current consensus, and the UTXO set is checked against the UTXO commitment of the pruning
+// point. The current consensus is left untouched if anything fails.
+//
+// It returns the pruning point of the imported snapshot.
+func Import(domain domain.Domain, params *dagconfig.Params, r io.Reader) (*externalapi.DomainHash, error) {
+	snapshotReader, err := newReader(r, params.GenesisHash)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := snapshotReader.readExpectedMessage(appmessage.CmdPruningPointProof)
+	if err != nil {
+		return nil, err
+	}
+	pruningPointProof := appmessage.MsgPruningPointProofToDomainPruningPointProof(message.(*appmessage.MsgPruningPointProof))
+	if len(pruningPointProof.Headers) == 0 || len(pruningPointProof.Headers[0]) == 0 {
+		return nil, errors.Wrapf(ErrInvalidSnapshot, "the pruning point proof is empty")
+	}
+	pruningPoint := consensushashing.HeaderHash(pruningPointProof.Headers[0][len(pruningPointProof.Headers[0])-1])
+	log.Infof("Importing a snapshot of pruning point %s", pruningPoint)
+
+	if pruningPoint.Equal(params.GenesisHash) {
+		return nil, errors.Wrapf(ErrInvalidSnapshot, "the pruning point of the snapshot is the genesis")
+	}
+	currentPruningPoint, err := domain.Consensus().PruningPoint()
+	if err != nil {
+		return nil, err
+	}
+	if currentPruningPoint.Equal(pruningPoint) {
+		return nil, errors.Errorf("the pruning point of the snapshot is already the pruning point of this node")
+	}
+
+	err = domain.Consensus().ValidatePruningPointProof(pruningPointProof)
+	if err != nil {
+		return nil, errors.Wrapf(err, "pruning point proof validation failed")
+	}
+
+	err = domain.InitStagingConsensus()
+	if err != nil {
+		return nil, err
+	}
+	err = importIntoStagingConsensus(domain, params, snapshotReader, pruningPointProof, pruningPoint)
+	if err != nil {
+		deleteStagingConsensusErr := domain.DeleteStagingConsensus()
+		if deleteStagingConsensusErr != nil {
+			return nil, deleteStagingConsensusErr
+		}
+		return nil, err
+	}
+
+	err = domain.CommitStagingConsensus()
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Imported the snapshot of pruning point %s", pruningPoint)
+	return pruningPoint, nil
+}
+
+func importIntoStagingConsensus(domain domain.Domain, params *dagconfig.Params, snapshotReader *reader,
+	pruningPointProof *externalapi.PruningPointProof, pruningPoint *externalapi.DomainHash) error {
+
+	stagingConsensus := domain.StagingConsensus()
+	err := stagingConsensus.ApplyPruningPointProof(pruningPointProof)
+	if err != nil {
+		return err
+	}
+
+	err = importPruningPoints(domain, params, snapshotReader, pruningPoint)
+	if err != nil {
+		return err
+	}
+	err = importPruningPointAndItsAnticone(stagingConsensus, snapshotReader, pruningPoint)
+	if err != nil {
+		return err
+	}
+	err = importPruningPointFutureHeaders(stagingConsensus, snapshotReader)
+	if err != nil {
+		return err
+	}
+
+	isValid, err := stagingConsensus.IsValidPruningPoint(pruningPoint)
+	if err != nil {
+		return err
+	}
+	if !isValid {
+		return errors.Wrapf(ErrInvalidSnapshot, "%s is not a valid pruning point of the headers in the snapshot",
+			pruningPoint)
+	}
+
+	return importPruningPointUTXOSet(stagingConsensus, params, snapshotReader, pruningPoint)
+}
+
+func importPruningPoints(domain domain.Domain, params *dagconfig.Params, snapshotReader *reader,
+	pruningPoint *externalapi.DomainHash) error {
+
+	message, err := snapshotReader.readExpectedMessage(appmessage.CmdPruningPoints)
+	if err != nil {
+		return err
+	}
+	msgPruningPoints := message.(*appmessage.MsgPruningPoints)
+	if len(msgPruningPoints.Headers) == 0 {
+		return errors.Wrapf(ErrInvalidSnapshot, "the snapshot has no pruning points")
+	}
+
+	headers := make([]externalapi.BlockHeader, len(msgPruningPoints.Headers))
+	for i, header := range msgPruningPoints.Headers {
+		headers[i] = appmessage.BlockHeaderToDomainBlockHeader(header)
+	}
+
+	arePruningPointsViolatingFinality, err := domain.Consensus().ArePruningPointsViolatingFinality(headers)
+	if err != nil {
+		return err
+	}
+	if arePruningPointsViolatingFinality {
+		return errors.Errorf("the pruning points of the snapshot are violating finality")
+	}
+	if common.ArePruningPointsViolatingCheckpoints(headers, params.Checkpoints) {
+		return errors.Errorf("the pruning points of the snapshot are violating checkpoints")
+	}
+
+	lastPruningPoint := consensushashing.HeaderHash(headers[len(headers)-1])
+	if !lastPruningPoint.Equal(pruningPoint) {
+		return errors.Wrapf(ErrInvalidSnapshot, "the proof pruning point is not equal to the last "+
+			"pruning point in the list")
+	}
+
+	return domain.StagingConsensus().ImportPruningPoints(headers)
+}
+
+func importPruningPointAndItsAnticone(stagingConsensus externalapi.Consensus, snapshotReader *reader,
+	pruningPoint *externalapi.DomainHash) error {
+
+	blockCount := 0
+	for {
+		message, err := snapshotReader.readMessage()
+		if err != nil {
+			return err
+		}
+
+		switch message := message.(type) {
+		case *appmessage.MsgBlockWithTrustedData:
+			blockWithTrustedData := appmessage.BlockWithTrustedDataToDomainBlockWithTrustedData(message)
+			if blockCount == 0 && !consensushashing.BlockHash(blockWithTrustedData.Block).Equal(pruningPoint) {
+				return errors.Wrapf(ErrInvalidSnapshot, "first block with trusted data is not the pruning point")
+			}
+			_, err := stagingConsensus.ValidateAndInsertBlockWithTrustedData(blockWithTrustedData, false)
+			if err != nil {
+				return err
+			}
+			blockCount++
+
+		case *appmessage.MsgDoneBlocksWithTrustedData:
+			if blockCount == 0 {
+				return errors.Wrapf(ErrInvalidSnapshot, "the snapshot doesn't have the pruning point block")
+			}
+			log.Debugf("Imported the pruning point and its anticone (%d blocks)", blockCount)
+			return nil
+
+		default:
+			return errors.Wrapf(ErrInvalidSnapshot, "unexpected message. expected: %s or %s, got: %s",
+				appmessage.CmdBlockWithTrustedData, appmessage.CmdDoneBlocksWithTrustedData, message.Command())
+		}
+	}
+}
+
+func importPruningPointFutureHeaders(stagingConsensus externalapi.Consensus, snapshotReader *reader) error {
+	headerCount := 0
+	for {
+		message, err := snapshotReader.readMessage()
+		if err != nil {
+			return err
+		}
+
+		switch message := message.(type) {
+		case *appmessage.BlockHeadersMessage:
+			for _, msgBlockHeader := range message.BlockHeaders {
+				err := importHeader(stagingConsensus, msgBlockHeader)
+				if err != nil {
+					return err
+				}
+			}
+			headerCount += len(message.BlockHeaders)
+
+		case *appmessage.MsgDoneHeaders:
+			log.Infof("Imported %d headers in the future of the pruning point", headerCount)
+			return nil
+
+		default:
+			return errors.Wrapf(ErrInvalidSnapshot, "unexpected message. expected: %s or %s, got: %s",
+				appmessage.CmdBlockHeaders, appmessage.CmdDoneHeaders, message.Command())
+		}
+	}
+}
+
+func importHeader(stagingConsensus externalapi.Consensus, msgBlockHeader *appmessage.MsgBlockHeader) error {
+	block := &externalapi.DomainBlock{
+		Header:       appmessage.BlockHeaderToDomainBlockHeader(msgBlockHeader),
+		Transactions: nil,
+	}
+
+	blockHash := consensushashing.BlockHash(block)
+	blockInfo, err := stagingConsensus.GetBlockInfo(blockHash)
+	if err != nil {
+		return err
+	}
+	if blockInfo.Exists {
+		return nil
+	}
+
+	_, err = stagingConsensus.ValidateAndInsertBlock(block, false)
+	if err != nil {
+		if errors.Is(err, ruleerrors.ErrDuplicateBlock) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to import header %s", blockHash)
+	}
+	return nil
+}
+
+func importPruningPointUTXOSet(stagingConsensus externalapi.Consensus, params *dagconfig.Params,
+	snapshotReader *reader, pruningPoint *externalapi.DomainHash) error {
+
+	defer func() {
+		err := stagingConsensus.ClearImportedPruningPointData()
+		if err != nil {
+			panic(fmt.Sprintf("failed to clear imported pruning point data: %s", err))
+		}
+	}()
+
+	utxoCount := 0
+	for {
+		message, err := snapshotReader.readMessage()
+		if err != nil {
+			return err
+		}
+
+		switch message := message.(type) {
+		case *appmessage.MsgPruningPointUTXOSetChunk:
+			domainOutpointAndUTXOEntryPairs :=
+				appmessage.OutpointAndUTXOEntryPairsToDomainOutpointAndUTXOEntryPairs(message.OutpointAndUTXOEntryPairs)
+			err := stagingConsensus.AppendImportedPruningPointUTXOs(domainOutpointAndUTXOEntryPairs)
+			if err != nil {
+				return err
+			}
+			utxoCount += len(domainOutpointAndUTXOEntryPairs)
+
+		case *appmessage.MsgDonePruningPointUTXOSetChunks:
+			log.Infof("Imported the pruning point UTXO set (%d UTXOs)", utxoCount)
+
+			err := importPruningPointSubnetworkRegistrations(stagingConsensus, params, snapshotReader)
+			if err != nil {
+				return err
+			}
+
+			// Reading to the end of the stream verifies the gzip checksum
+			err = snapshotReader.readEOF()
+			if err != nil {
+				return err
+			}
+			return stagingConsensus.ValidateAndInsertImportedPruningPoint(pruningPoint)
+
+		default:
+			return errors.Wrapf(ErrInvalidSnapshot, "unexpected message. expected: %s or %s, got: %s",
+				appmessage.CmdPruningPointUTXOSetChunk, appmessage.CmdDonePruningPointUTXOSetChunks, message.Command())
+		}
+	}
+}
+
+// importPruningPointSubnetworkRegistrations imports the subnetworks that were registered up to the
+// pruning point. Unlike the rest of the snapshot, they aren't covered by the pruning point proof or
+// by the UTXO commitment, so they are taken from the snapshot as they are.
+func importPruningPointSubnetworkRegistrations(stagingConsensus externalapi.Consensus, params *dagconfig.Params,
+	snapshotReader *reader) error {
+
+	message, err := snapshotReader.readExpectedMessage(appmessage.CmdPruningPointSubnetworkRegistrations)
+	if err != nil {
+		return err
+	}
+	registrations := message.(*appmessage.MsgPruningPointSubnetworkRegistrations).Registrations
+	if len(registrations) == 0 {
+		return nil
+	}
+	if !params.EnableNonNativeSubnetworks {
+		return errors.Wrapf(ErrInvalidSnapshot, "the snapshot has %d subnetwork registrations, but "+
+			"non-native subnetworks are disabled on this network", len(registrations))
+	}
+
+	err = stagingConsensus.ImportPruningPointSubnetworkRegistrations(registrations)
+	if err != nil {
+		return err
+	}
+	log.Infof("Imported %d subnetwork registrations of the pruning point", len(registrations))
+	return nil
+}
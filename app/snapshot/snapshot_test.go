@@ -0,0 +1,223 @@
+package snapshot
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/consensus"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/subnetworks"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/testutils"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionhelper"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/memdb"
+	"github.com/pkg/errors"
+)
+
+func TestExportAndImport(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		// This is done to reduce the pruning depth to a few blocks
+		finalityDepth := 5
+		consensusConfig.FinalityDuration = time.Duration(finalityDepth) * consensusConfig.TargetTimePerBlock
+		consensusConfig.K = 0
+		consensusConfig.PruningProofM = 1
+
+		newDomain := func() domain.Domain {
+			domainInstance, err := domain.New(consensusConfig, mempool.DefaultConfig(&consensusConfig.Params), memdb.NewMemDB())
+			if err != nil {
+				t.Fatalf("New: %+v", err)
+			}
+			return domainInstance
+		}
+
+		exporter := newDomain()
+		var buffer bytes.Buffer
+		_, err := Export(exporter.Consensus(), consensusConfig.GenesisHash, &buffer)
+		if err == nil {
+			t.Fatalf("Expected exporting a snapshot of the genesis to fail")
+		}
+
+		coinbaseData := &externalapi.DomainCoinbaseData{
+			ScriptPublicKey: &externalapi.ScriptPublicKey{},
+			ExtraData:       []byte{},
+		}
+		for i := 0; i < 50; i++ {
+			block, err := exporter.Consensus().BuildBlock(coinbaseData, nil)
+			if err != nil {
+				t.Fatalf("BuildBlock: %+v", err)
+			}
+			_, err = exporter.Consensus().ValidateAndInsertBlock(block, true)
+			if err != nil {
+				t.Fatalf("ValidateAndInsertBlock: %+v", err)
+			}
+		}
+
+		buffer.Reset()
+		exportedPruningPoint, err := Export(exporter.Consensus(), consensusConfig.GenesisHash, &buffer)
+		if err != nil {
+			t.Fatalf("Export: %+v", err)
+		}
+		snapshot := buffer.Bytes()
+
+		// A snapshot of another network must be rejected
+		otherParams := dagconfig.MainnetParams
+		if consensusConfig.Name == otherParams.Name {
+			otherParams = dagconfig.TestnetParams
+		}
+		importer := newDomain()
+		_, err = Import(importer, &otherParams, bytes.NewReader(snapshot))
+		if !errors.Is(err, ErrInvalidSnapshot) {
+			t.Fatalf("Expected a snapshot of another network to be rejected, got: %+v", err)
+		}
+
+		// A truncated snapshot must be rejected and leave the consensus as it was
+		_, err = Import(importer, &consensusConfig.Params, bytes.NewReader(snapshot[:len(snapshot)-10]))
+		if !errors.Is(err, ErrInvalidSnapshot) {
+			t.Fatalf("Expected a truncated snapshot to be rejected, got: %+v", err)
+		}
+		pruningPoint, err := importer.Consensus().PruningPoint()
+		if err != nil {
+			t.Fatalf("PruningPoint: %+v", err)
+		}
+		if !pruningPoint.Equal(consensusConfig.GenesisHash) {
+			t.Fatalf("Expected a failed import to leave the pruning point at the genesis, but it's %s", pruningPoint)
+		}
+
+		importedPruningPoint, err := Import(importer, &consensusConfig.Params, bytes.NewReader(snapshot))
+		if err != nil {
+			t.Fatalf("Import: %+v", err)
+		}
+		if !importedPruningPoint.Equal(exportedPruningPoint) {
+			t.Fatalf("Expected the imported pruning point to be %s, but got %s",
+				exportedPruningPoint, importedPruningPoint)
+		}
+
+		pruningPoint, err = importer.Consensus().PruningPoint()
+		if err != nil {
+			t.Fatalf("PruningPoint: %+v", err)
+		}
+		if !pruningPoint.Equal(exportedPruningPoint) {
+			t.Fatalf("Expected the pruning point to be %s after the import, but it's %s",
+				exportedPruningPoint, pruningPoint)
+		}
+
+		exporterHeadersSelectedTip, err := exporter.Consensus().GetHeadersSelectedTip()
+		if err != nil {
+			t.Fatalf("GetHeadersSelectedTip: %+v", err)
+		}
+		importerHeadersSelectedTip, err := importer.Consensus().GetHeadersSelectedTip()
+		if err != nil {
+			t.Fatalf("GetHeadersSelectedTip: %+v", err)
+		}
+		if !importerHeadersSelectedTip.Equal(exporterHeadersSelectedTip) {
+			t.Fatalf("Expected the headers selected tip to be %s after the import, but it's %s",
+				exporterHeadersSelectedTip, importerHeadersSelectedTip)
+		}
+
+		exportedUTXOs, err := exporter.Consensus().GetPruningPointUTXOs(exportedPruningPoint, nil, 1000)
+		if err != nil {
+			t.Fatalf("GetPruningPointUTXOs: %+v", err)
+		}
+		importedUTXOs, err := importer.Consensus().GetPruningPointUTXOs(exportedPruningPoint, nil, 1000)
+		if err != nil {
+			t.Fatalf("GetPruningPointUTXOs: %+v", err)
+		}
+		if len(importedUTXOs) != len(exportedUTXOs) {
+			t.Fatalf("Expected %d pruning point UTXOs after the import, but got %d",
+				len(exportedUTXOs), len(importedUTXOs))
+		}
+
+		// The same snapshot can't be imported twice
+		_, err = Import(importer, &consensusConfig.Params, bytes.NewReader(snapshot))
+		if err == nil {
+			t.Fatalf("Expected importing the current pruning point to fail")
+		}
+	})
+}
+
+func TestExportAndImportSubnetworkRegistrations(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		consensusConfig.BlockCoinbaseMaturity = 0
+		consensusConfig.EnableNonNativeSubnetworks = true
+		// This is done to reduce the pruning depth to a few blocks
+		finalityDepth := 5
+		consensusConfig.FinalityDuration = time.Duration(finalityDepth) * consensusConfig.TargetTimePerBlock
+		consensusConfig.K = 0
+		consensusConfig.PruningProofM = 1
+
+		newDomain := func() domain.Domain {
+			domainInstance, err := domain.New(consensusConfig, mempool.DefaultConfig(&consensusConfig.Params), memdb.NewMemDB())
+			if err != nil {
+				t.Fatalf("New: %+v", err)
+			}
+			return domainInstance
+		}
+
+		exporter := newDomain()
+		scriptPublicKey, _ := testutils.OpTrueScript()
+		coinbaseData := &externalapi.DomainCoinbaseData{
+			ScriptPublicKey: scriptPublicKey,
+			ExtraData:       []byte{},
+		}
+		addBlock := func(transactions []*externalapi.DomainTransaction) *externalapi.DomainBlock {
+			block, err := exporter.Consensus().BuildBlock(coinbaseData, transactions)
+			if err != nil {
+				t.Fatalf("BuildBlock: %+v", err)
+			}
+			_, err = exporter.Consensus().ValidateAndInsertBlock(block, true)
+			if err != nil {
+				t.Fatalf("ValidateAndInsertBlock: %+v", err)
+			}
+			return block
+		}
+
+		addBlock(nil)
+		fundingBlock := addBlock(nil)
+		registryTransaction, err := testutils.CreateTransaction(
+			fundingBlock.Transactions[transactionhelper.CoinbaseTransactionIndex], 1000)
+		if err != nil {
+			t.Fatalf("CreateTransaction: %+v", err)
+		}
+		registryTransaction.SubnetworkID = subnetworks.SubnetworkIDRegistry
+		registryTransaction.Payload = subnetworks.RegistryPayload(1)
+		subnetworkID := subnetworks.FromRegistryTransactionID(consensushashing.TransactionID(registryTransaction))
+		addBlock([]*externalapi.DomainTransaction{registryTransaction})
+		for i := 0; i < 50; i++ {
+			addBlock(nil)
+		}
+
+		var buffer bytes.Buffer
+		_, err = Export(exporter.Consensus(), consensusConfig.GenesisHash, &buffer)
+		if err != nil {
+			t.Fatalf("Export: %+v", err)
+		}
+		snapshot := buffer.Bytes()
+
+		// The registrations must be rejected by a node of a network without non-native subnetworks
+		paramsWithoutSubnetworks := consensusConfig.Params
+		paramsWithoutSubnetworks.EnableNonNativeSubnetworks = false
+		importer := newDomain()
+		_, err = Import(importer, &paramsWithoutSubnetworks, bytes.NewReader(snapshot))
+		if !errors.Is(err, ErrInvalidSnapshot) {
+			t.Fatalf("Expected subnetwork registrations to be rejected when non-native subnetworks "+
+				"are disabled, got: %+v", err)
+		}
+
+		_, err = Import(importer, &consensusConfig.Params, bytes.NewReader(snapshot))
+		if err != nil {
+			t.Fatalf("Import: %+v", err)
+		}
+		gasLimit, exists, err := importer.Consensus().GetSubnetworkGasLimit(&subnetworkID)
+		if err != nil {
+			t.Fatalf("GetSubnetworkGasLimit: %+v", err)
+		}
+		if !exists || gasLimit != 1 {
+			t.Fatalf("Expected subnetwork %s to be registered with gas limit 1 after the import, "+
+				"but exists: %t, gas limit: %d", subnetworkID, exists, gasLimit)
+		}
+	})
+}
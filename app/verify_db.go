@@ -0,0 +1,118 @@
+package app
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/utxoindex"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/pkg/errors"
+)
+
+type integrityReportJSON struct {
+	CheckedBlocks    uint64                `json:"checkedBlocks"`
+	UnrepairedIssues int                   `json:"unrepairedIssues"`
+	Issues           []*integrityIssueJSON `json:"issues"`
+}
+
+type integrityIssueJSON struct {
+	Check       string `json:"check"`
+	BlockHash   string `json:"blockHash,omitempty"`
+	Description string `json:"description"`
+	Repaired    bool   `json:"repaired"`
+}
+
+// verifyDatabaseReadOnly opens the database for reading only and checks its integrity
+func verifyDatabaseReadOnly(cfg *config.Config) error {
+	db, err := openDBReadOnly(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return verifyDatabase(cfg, db)
+}
+
+// verifyDatabase checks the integrity of the given database, repairs what it can if
+// --repair-db was given, and returns an error if any issue was left unrepaired.
+// Consensus is only ever read, so only the UTXO index is written into, and only when repairing
+func verifyDatabase(cfg *config.Config, db database.Database) error {
+	consensus, err := domain.NewReadOnlyConsensus(newConsensusConfig(cfg), db)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Checking the integrity of the database")
+	report, err := consensus.CheckIntegrity()
+	if err != nil {
+		return err
+	}
+
+	utxoIndexIssues, err := utxoindex.CheckIntegrity(consensus, db, cfg.RepairDatabase)
+	if err != nil {
+		return err
+	}
+	report.Issues = append(report.Issues, utxoIndexIssues...)
+
+	unrepairedIssueCount := 0
+	for _, issue := range report.Issues {
+		if !issue.Repaired {
+			unrepairedIssueCount++
+		}
+		logIntegrityIssue(issue)
+	}
+	log.Infof("Checked %d blocks and found %d integrity issues, %d of them unrepaired",
+		report.CheckedBlocks, len(report.Issues), unrepairedIssueCount)
+
+	if cfg.VerifyDatabaseReport != "" {
+		err := writeIntegrityReport(cfg.VerifyDatabaseReport, report, unrepairedIssueCount)
+		if err != nil {
+			return err
+		}
+		log.Infof("Wrote the integrity report to %s", cfg.VerifyDatabaseReport)
+	}
+
+	if unrepairedIssueCount > 0 {
+		return errors.Errorf("the database has %d unrepaired integrity issues", unrepairedIssueCount)
+	}
+	return nil
+}
+
+func logIntegrityIssue(issue *externalapi.IntegrityIssue) {
+	status := "unrepaired"
+	if issue.Repaired {
+		status = "repaired"
+	}
+	if issue.BlockHash != nil {
+		log.Warnf("[%s] (%s) block %s: %s", issue.Check, status, issue.BlockHash, issue.Description)
+		return
+	}
+	log.Warnf("[%s] (%s) %s", issue.Check, status, issue.Description)
+}
+
+func writeIntegrityReport(path string, report *externalapi.IntegrityReport, unrepairedIssueCount int) error {
+	reportJSON := &integrityReportJSON{
+		CheckedBlocks:    report.CheckedBlocks,
+		UnrepairedIssues: unrepairedIssueCount,
+		Issues:           make([]*integrityIssueJSON, len(report.Issues)),
+	}
+	for i, issue := range report.Issues {
+		reportJSON.Issues[i] = &integrityIssueJSON{
+			Check:       issue.Check,
+			Description: issue.Description,
+			Repaired:    issue.Repaired,
+		}
+		if issue.BlockHash != nil {
+			reportJSON.Issues[i].BlockHash = issue.BlockHash.String()
+		}
+	}
+
+	serializedReport, err := json.MarshalIndent(reportJSON, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(ioutil.WriteFile(path, serializedReport, 0600))
+}
@@ -25,9 +25,14 @@ var commandTypes = []reflect.Type{
 	reflect.TypeOf(protowire.KaspadMessage_GetVirtualSelectedParentChainFromBlockRequest{}),
 	reflect.TypeOf(protowire.KaspadMessage_ResolveFinalityConflictRequest{}),
 	reflect.TypeOf(protowire.KaspadMessage_EstimateNetworkHashesPerSecondRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_GetDAGWindowRequest{}),
 
 	reflect.TypeOf(protowire.KaspadMessage_GetBlockTemplateRequest{}),
 	reflect.TypeOf(protowire.KaspadMessage_SubmitBlockRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_GenerateBlocksRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_SetMockTimeRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_BackupDatabaseRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_GetDatabaseBackupStatusRequest{}),
 
 	reflect.TypeOf(protowire.KaspadMessage_GetMempoolEntryRequest{}),
 	reflect.TypeOf(protowire.KaspadMessage_GetMempoolEntriesRequest{}),
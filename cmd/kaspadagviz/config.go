@@ -0,0 +1,63 @@
+package main
+
+import (
+	"github.com/jessevdk/go-flags"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	formatDOT  = "dot"
+	formatJSON = "json"
+)
+
+var (
+	defaultRPCServer        = "localhost"
+	defaultTimeout   uint64 = 30
+	defaultFormat           = formatDOT
+)
+
+type configFlags struct {
+	RPCServer     string `short:"s" long:"rpcserver" description:"RPC server to connect to"`
+	Timeout       uint64 `short:"t" long:"timeout" description:"Timeout for the request (in seconds)"`
+	LowBlueScore  uint64 `long:"low-blue-score" description:"The lowest blue score of the blocks to export"`
+	HighBlueScore uint64 `long:"high-blue-score" description:"The highest blue score of the blocks to export"`
+	AnticoneOf    string `long:"anticone-of" description:"Export the given block and its anticone instead of a range of blue scores"`
+	Format        string `short:"f" long:"format" description:"The output format (dot or json)"`
+	Output        string `short:"o" long:"output" description:"The file to write the output to (default: stdout)"`
+	config.NetworkFlags
+}
+
+func parseConfig() (*configFlags, error) {
+	cfg := &configFlags{
+		RPCServer: defaultRPCServer,
+		Timeout:   defaultTimeout,
+		Format:    defaultFormat,
+	}
+	parser := flags.NewParser(cfg, flags.HelpFlag)
+	parser.Usage = "kaspadagviz [OPTIONS]\n\nExports a window of the DAG of a running kaspad, given either as a range of " +
+		"blue scores or as the anticone of a block."
+	_, err := parser.Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	err = cfg.ResolveNetwork(parser)
+	if err != nil {
+		return nil, err
+	}
+
+	isBlueScoreRangeSet := cfg.LowBlueScore != 0 || cfg.HighBlueScore != 0
+	if isBlueScoreRangeSet == (cfg.AnticoneOf != "") {
+		return nil, errors.New("Exactly one of --anticone-of or a blue score range must be specified")
+	}
+	if cfg.LowBlueScore > cfg.HighBlueScore {
+		return nil, errors.New("--low-blue-score must not be higher than --high-blue-score")
+	}
+	if cfg.Format != formatDOT && cfg.Format != formatJSON {
+		return nil, errors.Errorf("Unknown format '%s'. The supported formats are %s and %s",
+			cfg.Format, formatDOT, formatJSON)
+	}
+
+	return cfg, nil
+}
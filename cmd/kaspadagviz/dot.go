@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+// shortHashLength is the number of hash characters shown in node labels
+const shortHashLength = 8
+
+// writeDOT writes the given blocks as a graphviz DOT graph, in which every block
+// points at its parents. Blue and red blocks are filled with their color, chain
+// blocks are drawn bold, and edges to selected parents are drawn bold as well.
+// Parents outside the window are drawn as gray nodes.
+func writeDOT(writer io.Writer, blocks []*appmessage.RPCDAGWindowBlock) error {
+	var dotBuilder strings.Builder
+	dotBuilder.WriteString("digraph {\n\trankdir = RL;\n\tnode [shape = box, style = filled];\n\n")
+
+	inWindow := make(map[string]struct{}, len(blocks))
+	for _, block := range blocks {
+		inWindow[block.Hash] = struct{}{}
+	}
+
+	outsideWindow := make(map[string]struct{})
+	var edges []string
+	for _, block := range blocks {
+		dotBuilder.WriteString(fmt.Sprintf("\t\"%s\" [label = \"%s\", fillcolor = \"%s\"%s];\n",
+			block.Hash, blockLabel(block), fillColor(block.Color), chainBlockAttributes(block)))
+
+		for _, parentHash := range block.ParentHashes {
+			edgeAttributes := ""
+			if parentHash == block.SelectedParentHash {
+				edgeAttributes = " [style = bold, color = \"darkgreen\"]"
+			}
+			edges = append(edges, fmt.Sprintf("\t\"%s\" -> \"%s\"%s;", block.Hash, parentHash, edgeAttributes))
+
+			if _, ok := inWindow[parentHash]; !ok {
+				outsideWindow[parentHash] = struct{}{}
+			}
+		}
+	}
+	for hash := range outsideWindow {
+		dotBuilder.WriteString(fmt.Sprintf("\t\"%s\" [label = \"%s\", fillcolor = \"lightgray\", style = \"filled,dashed\"];\n",
+			hash, shortHash(hash)))
+	}
+
+	dotBuilder.WriteString("\n")
+	dotBuilder.WriteString(strings.Join(edges, "\n"))
+	dotBuilder.WriteString("\n}\n")
+
+	_, err := io.WriteString(writer, dotBuilder.String())
+	return errors.WithStack(err)
+}
+
+func blockLabel(block *appmessage.RPCDAGWindowBlock) string {
+	return fmt.Sprintf("%s\\nblue score: %d\\nDAA score: %d\\n%s\\nmergeset: %d blue, %d red",
+		shortHash(block.Hash), block.BlueScore, block.DAAScore, block.Status,
+		len(block.MergeSetBluesHashes), len(block.MergeSetRedsHashes))
+}
+
+func shortHash(hash string) string {
+	if len(hash) <= shortHashLength {
+		return hash
+	}
+	return hash[:shortHashLength]
+}
+
+func fillColor(color string) string {
+	switch color {
+	case appmessage.DAGWindowBlockColorBlue:
+		return "lightblue"
+	case appmessage.DAGWindowBlockColorRed:
+		return "salmon"
+	default:
+		// Blocks that were not merged yet have no color
+		return "white"
+	}
+}
+
+func chainBlockAttributes(block *appmessage.RPCDAGWindowBlock) string {
+	if !block.IsChainBlock {
+		return ""
+	}
+	return ", penwidth = 3"
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/infrastructure/network/rpcclient"
+	"github.com/pkg/errors"
+)
+
+func main() {
+	cfg, err := parseConfig()
+	if err != nil {
+		printErrorAndExit(fmt.Sprintf("error parsing command-line arguments: %s", err))
+	}
+
+	blocks, err := getDAGWindow(cfg)
+	if err != nil {
+		printErrorAndExit(fmt.Sprintf("error getting the DAG window: %s", err))
+	}
+
+	err = writeOutput(cfg, blocks)
+	if err != nil {
+		printErrorAndExit(fmt.Sprintf("error writing the output: %s", err))
+	}
+}
+
+func getDAGWindow(cfg *configFlags) ([]*appmessage.RPCDAGWindowBlock, error) {
+	rpcAddress, err := cfg.NetParams().NormalizeRPCServerAddress(cfg.RPCServer)
+	if err != nil {
+		return nil, err
+	}
+	client, err := rpcclient.NewRPCClient(rpcAddress)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	client.SetTimeout(time.Duration(cfg.Timeout) * time.Second)
+
+	var response *appmessage.GetDAGWindowResponseMessage
+	if cfg.AnticoneOf != "" {
+		response, err = client.GetDAGWindowByAnticone(cfg.AnticoneOf)
+	} else {
+		response, err = client.GetDAGWindowByBlueScore(cfg.LowBlueScore, cfg.HighBlueScore)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return response.Blocks, nil
+}
+
+func writeOutput(cfg *configFlags, blocks []*appmessage.RPCDAGWindowBlock) (err error) {
+	var writer io.Writer = os.Stdout
+	if cfg.Output != "" {
+		file, err := os.Create(cfg.Output)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer func() {
+			closeErr := file.Close()
+			if err == nil {
+				err = errors.WithStack(closeErr)
+			}
+		}()
+		writer = file
+	}
+
+	switch cfg.Format {
+	case formatJSON:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return errors.WithStack(encoder.Encode(blocks))
+	default:
+		return writeDOT(writer, blocks)
+	}
+}
+
+func printErrorAndExit(message string) {
+	fmt.Fprintf(os.Stderr, "%s\n", message)
+	os.Exit(1)
+}
@@ -14,11 +14,12 @@ type minerClient struct {
 	*rpcclient.RPCClient
 
 	cfg                        *configFlags
+	rpcServer                  string
 	blockAddedNotificationChan chan struct{}
 }
 
 func (mc *minerClient) connect() error {
-	rpcAddress, err := mc.cfg.NetParams().NormalizeRPCServerAddress(mc.cfg.RPCServer)
+	rpcAddress, err := mc.cfg.NetParams().NormalizeRPCServerAddress(mc.rpcServer)
 	if err != nil {
 		return err
 	}
@@ -37,6 +38,7 @@ func (mc *minerClient) connect() error {
 		}
 	})
 	if err != nil {
+		mc.close()
 		return errors.Wrapf(err, "error requesting block-added notifications")
 	}
 
@@ -45,10 +47,26 @@ func (mc *minerClient) connect() error {
 	return nil
 }
 
-func newMinerClient(cfg *configFlags) (*minerClient, error) {
+// close closes the client without letting it reconnect, since reconnecting
+// is handled by the node pool
+func (mc *minerClient) close() {
+	err := mc.Close()
+	if err != nil {
+		log.Debugf("Error closing the client of %s: %s", mc.Address(), err)
+	}
+	err = mc.Disconnect()
+	if err != nil {
+		log.Debugf("Error disconnecting from %s: %s", mc.Address(), err)
+	}
+}
+
+// newMinerClient connects to the given RPC server. Block-added notifications from
+// it are signaled over blockAddedNotificationChan.
+func newMinerClient(cfg *configFlags, rpcServer string, blockAddedNotificationChan chan struct{}) (*minerClient, error) {
 	minerClient := &minerClient{
 		cfg:                        cfg,
-		blockAddedNotificationChan: make(chan struct{}),
+		rpcServer:                  rpcServer,
+		blockAddedNotificationChan: blockAddedNotificationChan,
 	}
 
 	err := minerClient.connect()
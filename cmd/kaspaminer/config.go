@@ -20,6 +20,7 @@ const (
 	defaultLogFilename          = "kaspaminer.log"
 	defaultErrLogFilename       = "kaspaminer_err.log"
 	defaultTargetBlockRateRatio = 2.0
+	defaultThreads              = 1
 )
 
 var (
@@ -32,18 +33,19 @@ var (
 
 type configFlags struct {
 	ShowVersion           bool     `short:"V" long:"version" description:"Display version information and exit"`
-	RPCServer             string   `short:"s" long:"rpcserver" description:"RPC server to connect to"`
+	RPCServers            []string `short:"s" long:"rpcserver" description:"RPC server to connect to. Can be given multiple times to fail over between nodes"`
 	MiningAddr            string   `long:"miningaddr" description:"Address to mine to"`
 	NumberOfBlocks        uint64   `short:"n" long:"numblocks" description:"Number of blocks to mine. If omitted, will mine until the process is interrupted."`
 	MineWhenNotSynced     bool     `long:"mine-when-not-synced" description:"Mine even if the node is not synced with the rest of the network."`
 	Profile               string   `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
 	TargetBlocksPerSecond *float64 `long:"target-blocks-per-second" description:"Sets a maximum block rate. 0 means no limit (The default one is 2 * target network block rate)"`
+	Threads               int      `long:"threads" description:"Number of CPU threads to mine with"`
 	config.NetworkFlags
 }
 
 func parseConfig() (*configFlags, error) {
 	cfg := &configFlags{
-		RPCServer: defaultRPCServer,
+		Threads: defaultThreads,
 	}
 	parser := flags.NewParser(cfg, flags.PrintErrors|flags.HelpFlag)
 	_, err := parser.Parse()
@@ -65,6 +67,10 @@ func parseConfig() (*configFlags, error) {
 		return nil, err
 	}
 
+	if len(cfg.RPCServers) == 0 {
+		cfg.RPCServers = []string{defaultRPCServer}
+	}
+
 	if cfg.TargetBlocksPerSecond == nil {
 		targetBlocksPerSecond := defaultTargetBlockRateRatio / cfg.NetParams().TargetTimePerBlock.Seconds()
 		cfg.TargetBlocksPerSecond = &targetBlocksPerSecond
@@ -77,6 +83,10 @@ func parseConfig() (*configFlags, error) {
 		}
 	}
 
+	if cfg.Threads < 1 {
+		return nil, errors.New("--threads must be at least 1")
+	}
+
 	if cfg.MiningAddr == "" {
 		return nil, errors.New("--miningaddr is required")
 	}
@@ -36,20 +36,22 @@ func main() {
 		profiling.Start(cfg.Profile, log)
 	}
 
-	client, err := newMinerClient(cfg)
+	miningAddr, err := util.DecodeAddress(cfg.MiningAddr, cfg.ActiveNetParams.Prefix)
 	if err != nil {
-		panic(errors.Wrap(err, "error connecting to the RPC server"))
+		panic(errors.Wrap(err, "error decoding mining address"))
 	}
-	defer client.Disconnect()
 
-	miningAddr, err := util.DecodeAddress(cfg.MiningAddr, cfg.ActiveNetParams.Prefix)
+	pool, err := newNodePool(cfg, miningAddr)
 	if err != nil {
-		panic(errors.Wrap(err, "error decoding mining address"))
+		panic(errors.Wrap(err, "error connecting to the RPC servers"))
 	}
+	defer pool.close()
+	pool.start()
 
 	doneChan := make(chan struct{})
 	spawn("mineLoop", func() {
-		err = mineLoop(client, cfg.NumberOfBlocks, *cfg.TargetBlocksPerSecond, cfg.MineWhenNotSynced, miningAddr)
+		err = mineLoop(pool, cfg.NumberOfBlocks, *cfg.TargetBlocksPerSecond, cfg.MineWhenNotSynced, miningAddr,
+			cfg.Threads)
 		if err != nil {
 			panic(errors.Wrap(err, "error in mine loop"))
 		}
@@ -2,26 +2,25 @@ package main
 
 import (
 	nativeerrors "errors"
+	"fmt"
 	"math/rand"
-	"sync/atomic"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/kaspanet/kaspad/cmd/kaspaminer/templatemanager"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
-	"github.com/kaspanet/kaspad/domain/consensus/utils/pow"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
 	"github.com/kaspanet/kaspad/util"
 	"github.com/pkg/errors"
 )
 
-var hashesTried uint64
-
 const logHashRateInterval = 10 * time.Second
 
-func mineLoop(client *minerClient, numberOfBlocks uint64, targetBlocksPerSecond float64, mineWhenNotSynced bool,
-	miningAddr util.Address) error {
+func mineLoop(pool *nodePool, numberOfBlocks uint64, targetBlocksPerSecond float64, mineWhenNotSynced bool,
+	miningAddr util.Address, numberOfThreads int) error {
 	rand.Seed(time.Now().UnixNano()) // Seed the global concurrent-safe random source.
 
 	errChan := make(chan error)
@@ -34,9 +33,18 @@ func mineLoop(client *minerClient, numberOfBlocks uint64, targetBlocksPerSecond
 	foundBlockChan := make(chan *externalapi.DomainBlock, router.DefaultMaxMessages/2)
 
 	spawn("templatesLoop", func() {
-		templatesLoop(client, miningAddr, errChan)
+		templatesLoop(pool, miningAddr, errChan)
 	})
 
+	workerFoundBlockChan := make(chan *externalapi.DomainBlock)
+	workers := newMiningWorkers(numberOfThreads)
+	for _, worker := range workers {
+		worker := worker
+		spawn("miningWorker", func() {
+			worker.mine(mineWhenNotSynced, workerFoundBlockChan)
+		})
+	}
+
 	spawn("blocksLoop", func() {
 		const windowSize = 10
 		hasBlockRateTarget := targetBlocksPerSecond != 0
@@ -56,7 +64,7 @@ func mineLoop(client *minerClient, numberOfBlocks uint64, targetBlocksPerSecond
 		}
 		windowStart := time.Now()
 		for blockIndex := 1; ; blockIndex++ {
-			foundBlockChan <- mineNextBlock(mineWhenNotSynced)
+			foundBlockChan <- <-workerFoundBlockChan
 			if hasBlockRateTarget {
 				<-blockTicker.C
 				if (blockIndex % windowSize) == 0 {
@@ -72,7 +80,7 @@ func mineLoop(client *minerClient, numberOfBlocks uint64, targetBlocksPerSecond
 	spawn("handleFoundBlock", func() {
 		for i := uint64(0); numberOfBlocks == 0 || i < numberOfBlocks; i++ {
 			block := <-foundBlockChan
-			err := handleFoundBlock(client, block)
+			err := handleFoundBlock(pool, block)
 			if err != nil {
 				errChan <- err
 				return
@@ -81,7 +89,7 @@ func mineLoop(client *minerClient, numberOfBlocks uint64, targetBlocksPerSecond
 		doneChan <- struct{}{}
 	})
 
-	logHashRate()
+	logHashRate(workers)
 
 	select {
 	case err := <-errChan:
@@ -91,71 +99,98 @@ func mineLoop(client *minerClient, numberOfBlocks uint64, targetBlocksPerSecond
 	}
 }
 
-func logHashRate() {
+func logHashRate(workers []*miningWorker) {
 	spawn("logHashRate", func() {
 		lastCheck := time.Now()
 		for range time.Tick(logHashRateInterval) {
-			currentHashesTried := atomic.LoadUint64(&hashesTried)
 			currentTime := time.Now()
-			kiloHashesTried := float64(currentHashesTried) / 1000.0
-			hashRate := kiloHashesTried / currentTime.Sub(lastCheck).Seconds()
-			log.Infof("Current hash rate is %.2f Khash/s", hashRate)
+			elapsedSeconds := currentTime.Sub(lastCheck).Seconds()
 			lastCheck = currentTime
-			// subtract from hashesTried the hashes we already sampled
-			atomic.AddUint64(&hashesTried, -currentHashesTried)
+
+			totalHashRate := 0.0
+			workerHashRates := make([]string, len(workers))
+			for i, worker := range workers {
+				kiloHashesTried := float64(worker.sampleHashesTried()) / 1000.0
+				hashRate := kiloHashesTried / elapsedSeconds
+				totalHashRate += hashRate
+				workerHashRates[i] = fmt.Sprintf("worker %d: %.2f", worker.index, hashRate)
+			}
+			if len(workers) == 1 {
+				log.Infof("Current hash rate is %.2f Khash/s", totalHashRate)
+				continue
+			}
+			log.Infof("Current hash rate is %.2f Khash/s (%s Khash/s)",
+				totalHashRate, strings.Join(workerHashRates, ", "))
 		}
 	})
 }
 
-func handleFoundBlock(client *minerClient, block *externalapi.DomainBlock) error {
+// handleFoundBlock submits the block to all the connected nodes, so that it propagates
+// even if some of them stall. It returns an error only if the block was rejected as invalid
+// and no node accepted it.
+func handleFoundBlock(pool *nodePool, block *externalapi.DomainBlock) error {
 	blockHash := consensushashing.BlockHash(block)
-	log.Infof("Submitting block %s to %s", blockHash, client.Address())
+	nodes, clients := pool.connected()
+	if len(clients) == 0 {
+		log.Warnf("None of the nodes is connected. Dropping block %s", blockHash)
+		return nil
+	}
 
-	rejectReason, err := client.SubmitBlock(block)
-	if err != nil {
-		if nativeerrors.Is(err, router.ErrTimeout) {
-			log.Warnf("Got timeout while submitting block %s to %s: %s", blockHash, client.Address(), err)
-			return client.Reconnect()
-		}
-		if nativeerrors.Is(err, router.ErrRouteClosed) {
-			log.Debugf("Got route is closed while requesting block template from %s. "+
-				"The client is most likely reconnecting", client.Address())
+	rejectReasons := make([]appmessage.RejectReason, len(clients))
+	errs := make([]error, len(clients))
+	waitGroup := sync.WaitGroup{}
+	for i := range clients {
+		i := i
+		waitGroup.Add(1)
+		spawn("submitBlock", func() {
+			defer waitGroup.Done()
+			rejectReasons[i], errs[i] = submitBlock(pool, nodes[i], clients[i], block, blockHash)
+		})
+	}
+	waitGroup.Wait()
+
+	var invalidBlockErr error
+	isInIBD := false
+	for i, err := range errs {
+		if err == nil {
 			return nil
 		}
-		if rejectReason == appmessage.RejectReasonIsInIBD {
-			const waitTime = 1 * time.Second
-			log.Warnf("Block %s was rejected because the node is in IBD. Waiting for %s", blockHash, waitTime)
-			time.Sleep(waitTime)
-			return nil
+		switch rejectReasons[i] {
+		case appmessage.RejectReasonBlockInvalid:
+			invalidBlockErr = err
+		case appmessage.RejectReasonIsInIBD:
+			isInIBD = true
 		}
-		return errors.Wrapf(err, "Error submitting block %s to %s", blockHash, client.Address())
+	}
+	if invalidBlockErr != nil {
+		return invalidBlockErr
+	}
+	if isInIBD {
+		const waitTime = 1 * time.Second
+		log.Warnf("Block %s was rejected because the nodes are in IBD. Waiting for %s", blockHash, waitTime)
+		time.Sleep(waitTime)
 	}
 	return nil
 }
 
-func mineNextBlock(mineWhenNotSynced bool) *externalapi.DomainBlock {
-	nonce := rand.Uint64() // Use the global concurrent-safe random source.
-	for {
-		nonce++
-		// For each nonce we try to build a block from the most up to date
-		// block template.
-		// In the rare case where the nonce space is exhausted for a specific
-		// block, it'll keep looping the nonce until a new block template
-		// is discovered.
-		block, state := getBlockForMining(mineWhenNotSynced)
-		state.Nonce = nonce
-		atomic.AddUint64(&hashesTried, 1)
-		if state.CheckProofOfWork() {
-			mutHeader := block.Header.ToMutable()
-			mutHeader.SetNonce(nonce)
-			block.Header = mutHeader.ToImmutable()
-			log.Infof("Found block %s with parents %s", consensushashing.BlockHash(block), block.Header.DirectParents())
-			return block
+func submitBlock(pool *nodePool, node *minerNode, client *minerClient, block *externalapi.DomainBlock,
+	blockHash *externalapi.DomainHash) (appmessage.RejectReason, error) {
+
+	log.Infof("Submitting block %s to %s", blockHash, client.Address())
+
+	rejectReason, err := client.SubmitBlock(block)
+	if err != nil {
+		if nativeerrors.Is(err, router.ErrTimeout) || nativeerrors.Is(err, router.ErrRouteClosed) {
+			pool.markFailed(node, client, errors.Wrapf(err, "error submitting block %s", blockHash))
+		} else {
+			log.Warnf("Block %s was rejected by %s: %s", blockHash, client.Address(), err)
 		}
+		return rejectReason, errors.Wrapf(err, "Error submitting block %s to %s", blockHash, client.Address())
 	}
+	return rejectReason, nil
 }
 
-func getBlockForMining(mineWhenNotSynced bool) (*externalapi.DomainBlock, *pow.State) {
+func getTemplateForMining(mineWhenNotSynced bool) *templatemanager.Template {
 	tryCount := 0
 
 	const sleepTime = 500 * time.Millisecond
@@ -165,7 +200,7 @@ func getBlockForMining(mineWhenNotSynced bool) (*externalapi.DomainBlock, *pow.S
 		tryCount++
 
 		shouldLog := (tryCount-1)%10 == 0
-		template, state, isSynced := templatemanager.Get()
+		template := templatemanager.Get()
 		if template == nil {
 			if shouldLog {
 				log.Info("Waiting for the initial template")
@@ -173,7 +208,7 @@ func getBlockForMining(mineWhenNotSynced bool) (*externalapi.DomainBlock, *pow.S
 			time.Sleep(sleepTime)
 			continue
 		}
-		if !isSynced && !mineWhenNotSynced {
+		if !template.IsSynced && !mineWhenNotSynced {
 			if shouldLog {
 				log.Warnf("Kaspad is not synced. Skipping current block template")
 			}
@@ -181,33 +216,29 @@ func getBlockForMining(mineWhenNotSynced bool) (*externalapi.DomainBlock, *pow.S
 			continue
 		}
 
-		return template, state
+		return template
 	}
 }
 
-func templatesLoop(client *minerClient, miningAddr util.Address, errChan chan error) {
+// templatesLoop keeps the current block template up to date with the selected node.
+// Failing nodes are reported to the node pool, which fails over to the next best node.
+func templatesLoop(pool *nodePool, miningAddr util.Address, errChan chan error) {
 	getBlockTemplate := func() {
-		template, err := client.GetBlockTemplate(miningAddr.String())
-		if nativeerrors.Is(err, router.ErrTimeout) {
-			log.Warnf("Got timeout while requesting block template from %s: %s", client.Address(), err)
-			reconnectErr := client.Reconnect()
-			if reconnectErr != nil {
-				errChan <- reconnectErr
+		for {
+			node, client := pool.selected()
+			if node == nil {
+				return
+			}
+			template, err := client.GetBlockTemplate(miningAddr.String())
+			if err != nil {
+				pool.markFailed(node, client, errors.Wrap(err, "error requesting block template"))
+				continue
+			}
+			pool.updateNode(node, template)
+			err = templatemanager.Set(template)
+			if err != nil {
+				errChan <- errors.Wrapf(err, "Error setting block template from %s", client.Address())
 			}
-			return
-		}
-		if nativeerrors.Is(err, router.ErrRouteClosed) {
-			log.Debugf("Got route is closed while requesting block template from %s. "+
-				"The client is most likely reconnecting", client.Address())
-			return
-		}
-		if err != nil {
-			errChan <- errors.Wrapf(err, "Error getting block template from %s", client.Address())
-			return
-		}
-		err = templatemanager.Set(template)
-		if err != nil {
-			errChan <- errors.Wrapf(err, "Error setting block template from %s", client.Address())
 			return
 		}
 	}
@@ -217,7 +248,7 @@ func templatesLoop(client *minerClient, miningAddr util.Address, errChan chan er
 	ticker := time.NewTicker(tickerTime)
 	for {
 		select {
-		case <-client.blockAddedNotificationChan:
+		case <-pool.blockAddedNotificationChan:
 			getBlockTemplate()
 			ticker.Reset(tickerTime)
 		case <-ticker.C:
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"sync/atomic"
+
+	"github.com/kaspanet/kaspad/cmd/kaspaminer/templatemanager"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/pow"
+)
+
+// miningWorker searches for a valid nonce within its own partition of the nonce
+// space, so that workers never try the same nonce for the same template.
+type miningWorker struct {
+	// hashesTried is accessed atomically, as it's sampled by logHashRate.
+	// It's kept first in the struct so it's 64-bit aligned on 32-bit platforms.
+	hashesTried uint64
+
+	index          int
+	partitionStart uint64
+	partitionSize  uint64
+
+	template    *templatemanager.Template
+	state       pow.State
+	nonceOffset uint64
+}
+
+func newMiningWorkers(numberOfWorkers int) []*miningWorker {
+	partitionSize := math.MaxUint64 / uint64(numberOfWorkers)
+	workers := make([]*miningWorker, numberOfWorkers)
+	for i := range workers {
+		workers[i] = &miningWorker{
+			index:          i,
+			partitionStart: uint64(i) * partitionSize,
+			partitionSize:  partitionSize,
+		}
+	}
+	return workers
+}
+
+// mine tries nonces on the most up to date block template until it's stopped,
+// and sends every block it finds to foundBlockChan
+func (w *miningWorker) mine(mineWhenNotSynced bool, foundBlockChan chan<- *externalapi.DomainBlock) {
+	for {
+		// Loading the current template is a single atomic operation, so we check
+		// it for every nonce and only copy its state when it was replaced.
+		template := getTemplateForMining(mineWhenNotSynced)
+		if template != w.template {
+			w.setTemplate(template)
+		}
+
+		w.state.Nonce = w.partitionStart + w.nonceOffset
+		// In the rare case where the partition is exhausted for a specific
+		// template, we keep looping over it until a new template is discovered.
+		w.nonceOffset = (w.nonceOffset + 1) % w.partitionSize
+		atomic.AddUint64(&w.hashesTried, 1)
+
+		if w.state.CheckProofOfWork() {
+			block := w.foundBlock()
+			log.Infof("Worker %d found block %s with parents %s",
+				w.index, consensushashing.BlockHash(block), block.Header.DirectParents())
+			foundBlockChan <- block
+		}
+	}
+}
+
+func (w *miningWorker) setTemplate(template *templatemanager.Template) {
+	w.template = template
+	w.state = *template.State
+	w.nonceOffset = rand.Uint64() % w.partitionSize // Use the global concurrent-safe random source.
+}
+
+func (w *miningWorker) foundBlock() *externalapi.DomainBlock {
+	// Shallow copy the block so that replacing its header won't affect the shared template
+	block := *w.template.Block
+	mutHeader := block.Header.ToMutable()
+	mutHeader.SetNonce(w.state.Nonce)
+	block.Header = mutHeader.ToImmutable()
+	return &block
+}
+
+// sampleHashesTried returns the number of hashes tried since the previous sample
+func (w *miningWorker) sampleHashesTried() uint64 {
+	return atomic.SwapUint64(&w.hashesTried, 0)
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	healthCheckInterval = 5 * time.Second
+	minReconnectDelay   = time.Second
+	maxReconnectDelay   = 2 * time.Minute
+)
+
+// minerNode is a kaspad node the miner may get block templates from
+type minerNode struct {
+	rpcServer string
+
+	// All the fields below are guarded by the node pool lock
+	client               *minerClient
+	isSynced             bool
+	daaScore             uint64
+	failures             int
+	nextReconnectionTime time.Time
+}
+
+func (node *minerNode) isConnected() bool {
+	return node.client != nil
+}
+
+// nodePool keeps track of the health of all the nodes given by --rpcserver, and selects the
+// most advanced synced one to mine on. Nodes that fail are disconnected and reconnected
+// with exponential backoff.
+type nodePool struct {
+	cfg               *configFlags
+	miningAddr        util.Address
+	mineWhenNotSynced bool
+
+	// blockAddedNotificationChan is signaled whenever any of the nodes adds a block
+	blockAddedNotificationChan chan struct{}
+
+	lock         sync.Mutex
+	nodes        []*minerNode
+	selectedNode *minerNode
+}
+
+// newNodePool connects to all the given nodes. It returns an error only if none of them is reachable
+func newNodePool(cfg *configFlags, miningAddr util.Address) (*nodePool, error) {
+	pool := &nodePool{
+		cfg:                        cfg,
+		miningAddr:                 miningAddr,
+		mineWhenNotSynced:          cfg.MineWhenNotSynced,
+		blockAddedNotificationChan: make(chan struct{}),
+		nodes:                      make([]*minerNode, len(cfg.RPCServers)),
+	}
+	for i, rpcServer := range cfg.RPCServers {
+		pool.nodes[i] = &minerNode{rpcServer: rpcServer}
+	}
+
+	pool.checkHealth()
+	for _, node := range pool.nodes {
+		if node.isConnected() {
+			return pool, nil
+		}
+	}
+	return nil, errors.Errorf("could not connect to any of the RPC servers %s", cfg.RPCServers)
+}
+
+func (pool *nodePool) start() {
+	spawn("nodePool.healthCheckLoop", func() {
+		for range time.Tick(healthCheckInterval) {
+			pool.checkHealth()
+		}
+	})
+}
+
+// checkHealth connects to the nodes that are due for reconnection, updates the state of the
+// connected ones, and selects the node to mine on
+func (pool *nodePool) checkHealth() {
+	waitGroup := sync.WaitGroup{}
+	for _, node := range pool.nodes {
+		node := node
+		waitGroup.Add(1)
+		spawn("nodePool.checkNodeHealth", func() {
+			defer waitGroup.Done()
+			pool.checkNodeHealth(node)
+		})
+	}
+	waitGroup.Wait()
+
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+	pool.selectNode()
+}
+
+func (pool *nodePool) checkNodeHealth(node *minerNode) {
+	pool.lock.Lock()
+	client := node.client
+	shouldReconnect := client == nil && !time.Now().Before(node.nextReconnectionTime)
+	pool.lock.Unlock()
+
+	if client == nil {
+		if !shouldReconnect {
+			return
+		}
+		var err error
+		client, err = newMinerClient(pool.cfg, node.rpcServer, pool.blockAddedNotificationChan)
+		if err != nil {
+			pool.markFailed(node, nil, err)
+			return
+		}
+		pool.lock.Lock()
+		node.client = client
+		pool.lock.Unlock()
+	}
+
+	// The template a node would give us tells both whether it's synced and how advanced it is
+	template, err := client.GetBlockTemplate(pool.miningAddr.String())
+	if err != nil {
+		pool.markFailed(node, client, err)
+		return
+	}
+	pool.updateNode(node, template)
+}
+
+// updateNode records the sync state and DAA score of a node according to a template it returned
+func (pool *nodePool) updateNode(node *minerNode, template *appmessage.GetBlockTemplateResponseMessage) {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	node.isSynced = template.IsSynced
+	node.daaScore = template.Block.Header.DAAScore
+	node.failures = 0
+}
+
+// markFailed disconnects the given client of the given node, and schedules the node for
+// reconnection. A nil client means that connecting to the node had failed.
+func (pool *nodePool) markFailed(node *minerNode, client *minerClient, err error) {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	// Another goroutine might have already handled the failure of this client
+	if client != nil && node.client != client {
+		return
+	}
+
+	reconnectDelay := minReconnectDelay << node.failures
+	if reconnectDelay > maxReconnectDelay || reconnectDelay <= 0 {
+		reconnectDelay = maxReconnectDelay
+	} else {
+		node.failures++
+	}
+	node.nextReconnectionTime = time.Now().Add(reconnectDelay)
+	log.Warnf("Node %s failed: %s. Reconnecting in %s", node.rpcServer, err, reconnectDelay)
+
+	if client != nil {
+		node.client = nil
+		spawn("minerClient.close", client.close)
+	}
+	if pool.selectedNode == node {
+		pool.selectNode()
+	}
+}
+
+// selectNode selects the connected node with the highest DAA score among the synced ones,
+// or among all the connected ones if mining on unsynced nodes is allowed.
+// This function must be called with the pool lock held
+func (pool *nodePool) selectNode() {
+	var bestNode *minerNode
+	for _, node := range pool.nodes {
+		if !node.isConnected() || (!node.isSynced && !pool.mineWhenNotSynced) {
+			continue
+		}
+		if bestNode == nil || node.daaScore > bestNode.daaScore ||
+			(node.daaScore == bestNode.daaScore && node.isSynced && !bestNode.isSynced) {
+			bestNode = node
+		}
+	}
+	// Stay on the currently selected node unless another one is strictly better,
+	// so that nodes at the same DAA score don't make us switch back and forth
+	if pool.selectedNode != nil && bestNode != nil && pool.selectedNode.isConnected() &&
+		pool.selectedNode.daaScore == bestNode.daaScore && pool.selectedNode.isSynced == bestNode.isSynced {
+		return
+	}
+
+	if bestNode == pool.selectedNode {
+		return
+	}
+	if bestNode == nil {
+		log.Warnf("None of the nodes is available for mining")
+	} else {
+		log.Infof("Mining on node %s (synced: %t, DAA score: %d)", bestNode.rpcServer, bestNode.isSynced, bestNode.daaScore)
+	}
+	pool.selectedNode = bestNode
+}
+
+// selected returns the node to get block templates from, and its client.
+// It returns nil if no node is available
+func (pool *nodePool) selected() (*minerNode, *minerClient) {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	if pool.selectedNode == nil {
+		return nil, nil
+	}
+	return pool.selectedNode, pool.selectedNode.client
+}
+
+// connected returns all the connected nodes along with their clients
+func (pool *nodePool) connected() ([]*minerNode, []*minerClient) {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	nodes := make([]*minerNode, 0, len(pool.nodes))
+	clients := make([]*minerClient, 0, len(pool.nodes))
+	for _, node := range pool.nodes {
+		if node.isConnected() {
+			nodes = append(nodes, node)
+			clients = append(clients, node.client)
+		}
+	}
+	return nodes, clients
+}
+
+func (pool *nodePool) close() {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	for _, node := range pool.nodes {
+		if node.isConnected() {
+			node.client.close()
+			node.client = nil
+		}
+	}
+	pool.selectedNode = nil
+}
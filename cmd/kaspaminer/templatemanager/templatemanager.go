@@ -1,28 +1,28 @@
 package templatemanager
 
 import (
+	"sync/atomic"
+
 	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/pow"
-	"sync"
 )
 
-var currentTemplate *externalapi.DomainBlock
-var currentState *pow.State
-var isSynced bool
-var lock = &sync.Mutex{}
+// Template is a block template together with its pre-computed PoW state.
+// A Template is never modified after it's set, so it can be shared between
+// mining workers. Workers should copy State before changing its nonce.
+type Template struct {
+	Block    *externalapi.DomainBlock
+	State    *pow.State
+	IsSynced bool
+}
+
+var current atomic.Value
 
-// Get returns the template to work on
-func Get() (*externalapi.DomainBlock, *pow.State, bool) {
-	lock.Lock()
-	defer lock.Unlock()
-	// Shallow copy the block so when the user replaces the header it won't affect the template here.
-	if currentTemplate == nil {
-		return nil, nil, false
-	}
-	block := *currentTemplate
-	state := *currentState
-	return &block, &state, isSynced
+// Get returns the template to work on, or nil if no template was set yet
+func Get() *Template {
+	template, _ := current.Load().(*Template)
+	return template
 }
 
 // Set sets the current template to work on
@@ -31,10 +31,10 @@ func Set(template *appmessage.GetBlockTemplateResponseMessage) error {
 	if err != nil {
 		return err
 	}
-	lock.Lock()
-	defer lock.Unlock()
-	currentTemplate = block
-	currentState = pow.NewState(block.Header.ToMutable())
-	isSynced = template.IsSynced
+	current.Store(&Template{
+		Block:    block,
+		State:    pow.NewState(block.Header.ToMutable()),
+		IsSynced: template.IsSynced,
+	})
 	return nil
 }
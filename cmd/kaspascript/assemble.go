@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+)
+
+func assemble(conf *assembleConfig) error {
+	assembly := conf.Script
+	if conf.File != "" {
+		assemblyBytes, err := readFile(conf.File)
+		if err != nil {
+			return err
+		}
+		assembly = string(assemblyBytes)
+	}
+
+	script, err := txscript.Assemble(assembly)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%x\n", script)
+	return nil
+}
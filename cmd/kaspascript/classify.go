@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/kaspanet/kaspad/util"
+)
+
+func classify(conf *classifyConfig) error {
+	prefix, err := util.ParsePrefix(conf.Prefix)
+	if err != nil {
+		return err
+	}
+	script, err := conf.parseScript()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Class:     %s\n", txscript.GetScriptClass(script))
+
+	// Only the address prefix of the network is required to extract the address
+	scriptPublicKey := &externalapi.ScriptPublicKey{Script: script, Version: conf.ScriptVersion}
+	_, address, err := txscript.ExtractScriptPubKeyAddress(scriptPublicKey, &dagconfig.Params{Prefix: prefix})
+	if err != nil {
+		return err
+	}
+	if address != nil {
+		fmt.Printf("Address:   %s\n", address)
+	}
+
+	assembly, err := txscript.Disassemble(conf.ScriptVersion, script)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Assembly:  %s\n", assembly)
+	return nil
+}
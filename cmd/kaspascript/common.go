@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/pkg/errors"
+)
+
+func printErrorAndExit(err error) {
+	fmt.Fprintf(os.Stderr, "%s\n", err)
+	os.Exit(1)
+}
+
+// readFile returns the contents of the given file, or of stdin if path is -
+func readFile(path string) ([]byte, error) {
+	var content []byte
+	var err error
+	if path == "-" {
+		content, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		content, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading %s", path)
+	}
+	return content, nil
+}
+
+// parseScript returns the script given in the flags, either in assembly or in hex
+func (f *scriptFlags) parseScript() ([]byte, error) {
+	if f.Hex != "" {
+		script, err := hex.DecodeString(f.Hex)
+		if err != nil {
+			return nil, errors.Wrap(err, "error decoding --hex")
+		}
+		return script, nil
+	}
+	script, err := txscript.Assemble(f.Script)
+	if err != nil {
+		return nil, errors.Wrap(err, "error assembling --script")
+	}
+	return script, nil
+}
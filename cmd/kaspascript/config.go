@@ -0,0 +1,152 @@
+package main
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/jessevdk/go-flags"
+)
+
+const (
+	assembleSubCmd    = "assemble"
+	disassembleSubCmd = "disassemble"
+	p2shAddressSubCmd = "p2sh-address"
+	classifySubCmd    = "classify"
+	evaluateSubCmd    = "evaluate"
+	debugSubCmd       = "debug"
+)
+
+const defaultPrefix = "kaspa"
+
+type configFlags struct{}
+
+// scriptFlags are the flags of sub-commands that take a single script, which may
+// be given either in assembly or in hex
+type scriptFlags struct {
+	Script string `long:"script" short:"s" description:"The script in assembly (e.g. \"OP_2 <pubkey1> <pubkey2> OP_2 OP_CHECKMULTISIG\"). See the README for the full syntax"`
+	Hex    string `long:"hex" short:"x" description:"The script, encoded in hex"`
+}
+
+type assembleConfig struct {
+	Script string `long:"script" short:"s" description:"The script to assemble (e.g. \"OP_2 <pubkey1> <pubkey2> OP_2 OP_CHECKMULTISIG\"). See the README for the full syntax"`
+	File   string `long:"file" short:"f" description:"A file containing the script to assemble. Use - to read from stdin"`
+}
+
+type disassembleConfig struct {
+	Hex           string `long:"hex" short:"x" description:"The script to disassemble, encoded in hex" required:"true"`
+	ScriptVersion uint16 `long:"script-version" short:"v" description:"The version of the script (default: 0)"`
+}
+
+type p2shAddressConfig struct {
+	scriptFlags
+	Prefix string `long:"prefix" short:"p" description:"The address prefix of the network (kaspa, kaspatest, kaspasim or kaspadev)" default:"kaspa"`
+}
+
+type classifyConfig struct {
+	scriptFlags
+	ScriptVersion uint16 `long:"script-version" short:"v" description:"The version of the script (default: 0)"`
+	Prefix        string `long:"prefix" short:"p" description:"The address prefix of the network (kaspa, kaspatest, kaspasim or kaspadev)" default:"kaspa"`
+}
+
+type evaluateConfig struct {
+	SignatureScript string `long:"signature-script" description:"The signature script in assembly (default: an empty script)"`
+	ScriptPublicKey string `long:"script-public-key" description:"The script public key in assembly" required:"true"`
+	ScriptVersion   uint16 `long:"script-version" short:"v" description:"The version of the script public key (default: 0)"`
+	Interactive     bool   `long:"interactive" description:"Step through the scripts one opcode at a time instead of printing a full trace"`
+}
+
+type debugConfig struct {
+	TransactionFile string `long:"transaction-file" short:"t" description:"A file containing the transaction, as JSON in the format of the SubmitTransaction RPC. Use - to read from stdin" required:"true"`
+	UTXOEntriesFile string `long:"utxo-entries-file" short:"u" description:"A file containing a JSON array of the UTXO entries spent by the transaction, in the order of its inputs" required:"true"`
+	InputIndex      int    `long:"input" short:"i" description:"The index of the input to debug (default: all inputs)"`
+	Interactive     bool   `long:"interactive" description:"Step through the scripts one opcode at a time instead of printing a full trace"`
+}
+
+func parseCommandLine() (subCommand string, config interface{}) {
+	cfg := &configFlags{}
+	parser := flags.NewParser(cfg, flags.PrintErrors|flags.HelpFlag)
+
+	assembleConf := &assembleConfig{}
+	parser.AddCommand(assembleSubCmd, "Assembles a script",
+		"Assembles a script and prints it in hex", assembleConf)
+
+	disassembleConf := &disassembleConfig{}
+	parser.AddCommand(disassembleSubCmd, "Disassembles a script",
+		"Disassembles a script given in hex. The result can be assembled back into the same script", disassembleConf)
+
+	p2shAddressConf := &p2shAddressConfig{Prefix: defaultPrefix}
+	parser.AddCommand(p2shAddressSubCmd, "Computes the pay-to-script-hash address of a redeem script",
+		"Computes the pay-to-script-hash address of a redeem script, and the script public key that pays to it",
+		p2shAddressConf)
+
+	classifyConf := &classifyConfig{Prefix: defaultPrefix}
+	parser.AddCommand(classifySubCmd, "Classifies a script public key",
+		"Prints the standard class of a script public key, and the address it pays to if it has one", classifyConf)
+
+	evaluateConf := &evaluateConfig{}
+	parser.AddCommand(evaluateSubCmd, "Evaluates a script",
+		"Evaluates a signature script against a script public key, outside of any real transaction, and prints "+
+			"every executed opcode along with the data, alt and condition stacks. Signature checks always fail, "+
+			"since there is no real transaction to sign", evaluateConf)
+
+	debugConf := &debugConfig{InputIndex: -1}
+	parser.AddCommand(debugSubCmd, "Traces the script execution of a transaction",
+		"Runs the scripts of a transaction against the UTXO entries it spends, and prints every executed "+
+			"opcode along with the data, alt and condition stacks. Use --interactive to step through "+
+			"the scripts one opcode at a time", debugConf)
+
+	_, err := parser.Parse()
+
+	if err != nil {
+		var flagsErr *flags.Error
+		if ok := errors.As(err, &flagsErr); ok && flagsErr.Type == flags.ErrHelp {
+			os.Exit(0)
+		} else {
+			os.Exit(1)
+		}
+		return "", nil
+	}
+
+	switch parser.Command.Active.Name {
+	case assembleSubCmd:
+		if (assembleConf.Script == "") == (assembleConf.File == "") {
+			printErrorAndExit(errors.New("exactly one of --script and --file is required"))
+		}
+		config = assembleConf
+	case disassembleSubCmd:
+		config = disassembleConf
+	case p2shAddressSubCmd:
+		err := p2shAddressConf.scriptFlags.validate()
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = p2shAddressConf
+	case classifySubCmd:
+		err := classifyConf.scriptFlags.validate()
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = classifyConf
+	case evaluateSubCmd:
+		config = evaluateConf
+	case debugSubCmd:
+		if debugConf.InputIndex < -1 {
+			printErrorAndExit(errors.New("--input must not be negative"))
+		}
+		if debugConf.Interactive && (debugConf.TransactionFile == "-" || debugConf.UTXOEntriesFile == "-") {
+			printErrorAndExit(errors.New("--interactive reads commands from stdin, so the input files " +
+				"cannot be read from stdin as well"))
+		}
+		config = debugConf
+	}
+
+	return parser.Command.Active.Name, config
+}
+
+func (f *scriptFlags) validate() error {
+	if (f.Script == "") == (f.Hex == "") {
+		return errors.New("exactly one of --script and --hex is required")
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/pkg/errors"
+)
+
+func debug(conf *debugConfig) error {
+	transaction, err := readTransactionWithUTXOEntries(conf.TransactionFile, conf.UTXOEntriesFile)
+	if err != nil {
+		return err
+	}
+
+	inputIndexes := make([]int, 0, len(transaction.Inputs))
+	if conf.InputIndex == -1 {
+		for i := range transaction.Inputs {
+			inputIndexes = append(inputIndexes, i)
+		}
+	} else {
+		if conf.InputIndex >= len(transaction.Inputs) {
+			return errors.Errorf("--input is %d, but the transaction has only %d inputs",
+				conf.InputIndex, len(transaction.Inputs))
+		}
+		inputIndexes = append(inputIndexes, conf.InputIndex)
+	}
+
+	sighashReusedValues := &consensushashing.SighashReusedValues{}
+	failedInputs := 0
+	for _, inputIndex := range inputIndexes {
+		fmt.Printf("Input %d:\n", inputIndex)
+		scriptPubKey := transaction.Inputs[inputIndex].UTXOEntry.ScriptPublicKey()
+		vm, err := txscript.NewEngine(scriptPubKey, transaction, inputIndex, txscript.ScriptVersion1Active,
+			nil, nil, sighashReusedValues)
+		if err != nil {
+			return errors.Wrapf(err, "error creating the script engine for input %d", inputIndex)
+		}
+
+		err = runEngine(vm, conf.Interactive)
+		if errors.Is(err, errQuit) {
+			return nil
+		}
+		if err != nil {
+			var scriptErr txscript.Error
+			if !errors.As(err, &scriptErr) {
+				return err
+			}
+			failedInputs++
+			fmt.Printf("Input %d failed: %s\n\n", inputIndex, err)
+			continue
+		}
+		fmt.Printf("Input %d succeeded\n\n", inputIndex)
+	}
+
+	if failedInputs > 0 {
+		return errors.Errorf("%d out of %d inputs failed", failedInputs, len(inputIndexes))
+	}
+	return nil
+}
+
+// readTransactionWithUTXOEntries reads a transaction and the UTXO entries it spends
+// from the given files, and populates the UTXO entries of its inputs
+func readTransactionWithUTXOEntries(transactionFile, utxoEntriesFile string) (*externalapi.DomainTransaction, error) {
+	transactionJSON, err := readFile(transactionFile)
+	if err != nil {
+		return nil, err
+	}
+	rpcTransaction := &appmessage.RPCTransaction{}
+	err = json.Unmarshal(transactionJSON, rpcTransaction)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing the transaction")
+	}
+	transaction, err := appmessage.RPCTransactionToDomainTransaction(rpcTransaction)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing the transaction")
+	}
+
+	utxoEntriesJSON, err := readFile(utxoEntriesFile)
+	if err != nil {
+		return nil, err
+	}
+	var rpcUTXOEntries []*appmessage.RPCUTXOEntry
+	err = json.Unmarshal(utxoEntriesJSON, &rpcUTXOEntries)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing the UTXO entries")
+	}
+	if len(rpcUTXOEntries) != len(transaction.Inputs) {
+		return nil, errors.Errorf("the transaction has %d inputs, but %d UTXO entries were given",
+			len(transaction.Inputs), len(rpcUTXOEntries))
+	}
+	for i, rpcUTXOEntry := range rpcUTXOEntries {
+		if rpcUTXOEntry == nil || rpcUTXOEntry.ScriptPublicKey == nil {
+			return nil, errors.Errorf("UTXO entry %d is missing its script public key", i)
+		}
+		utxoEntry, err := appmessage.RPCUTXOEntryToUTXOEntry(rpcUTXOEntry)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error parsing UTXO entry %d", i)
+		}
+		transaction.Inputs[i].UTXOEntry = utxoEntry
+	}
+
+	return transaction, nil
+}
+
+// runEngine executes the scripts of the given engine, either by printing a full
+// trace or by letting the user step through them
+func runEngine(vm *txscript.Engine, interactive bool) error {
+	if interactive {
+		return debugInteractively(vm)
+	}
+	return printTrace(vm)
+}
+
+func printTrace(vm *txscript.Engine) error {
+	trace, err := vm.Trace()
+	for _, step := range trace {
+		fmt.Printf("%s\n", step.Disassembly)
+		printStacks(step.DataStack, step.AltStack, step.CondStack)
+	}
+	return err
+}
+
+const interactiveHelp = `Commands:
+  step, s, <enter>  Execute the next opcode
+  continue, c       Execute the remaining opcodes
+  stack, p          Print the stacks
+  help, h           Print this help
+  quit, q           Stop debugging`
+
+// errQuit is returned from debugInteractively when the user asks to stop debugging
+var errQuit = errors.New("stopped debugging")
+
+func debugInteractively(vm *txscript.Engine) error {
+	fmt.Println(interactiveHelp)
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		nextOpcode, err := vm.DisasmPC()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Next: %s\n> ", nextOpcode)
+		if !scanner.Scan() {
+			if scanner.Err() != nil {
+				return scanner.Err()
+			}
+			return errQuit
+		}
+
+		switch strings.TrimSpace(scanner.Text()) {
+		case "step", "s", "":
+			done, err := vm.Step()
+			if err != nil {
+				return err
+			}
+			printStacks(vm.GetStack(), vm.GetAltStack(), vm.GetCondStack())
+			if done {
+				return vm.CheckErrorCondition(true)
+			}
+		case "continue", "c":
+			return printTrace(vm)
+		case "stack", "p":
+			printStacks(vm.GetStack(), vm.GetAltStack(), vm.GetCondStack())
+		case "help", "h":
+			fmt.Println(interactiveHelp)
+		case "quit", "q":
+			return errQuit
+		default:
+			fmt.Printf("Unknown command %q\n", scanner.Text())
+		}
+	}
+}
+
+func printStacks(dataStack, altStack [][]byte, condStack []int) {
+	fmt.Printf("    Data stack: %s\n", formatStack(dataStack))
+	if len(altStack) > 0 {
+		fmt.Printf("    Alt stack:  %s\n", formatStack(altStack))
+	}
+	if len(condStack) > 0 {
+		fmt.Printf("    Cond stack: %s\n", formatCondStack(condStack))
+	}
+}
+
+// formatStack formats the given stack with its top item on the right
+func formatStack(stack [][]byte) string {
+	items := make([]string, len(stack))
+	for i, item := range stack {
+		if len(item) == 0 {
+			items[i] = "<empty>"
+			continue
+		}
+		items[i] = fmt.Sprintf("%x", item)
+	}
+	return "[" + strings.Join(items, " ") + "]"
+}
+
+func formatCondStack(condStack []int) string {
+	items := make([]string, len(condStack))
+	for i, cond := range condStack {
+		switch cond {
+		case txscript.OpCondTrue:
+			items[i] = "true"
+		case txscript.OpCondFalse:
+			items[i] = "false"
+		default:
+			items[i] = "skip"
+		}
+	}
+	return "[" + strings.Join(items, " ") + "]"
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/pkg/errors"
+)
+
+func disassemble(conf *disassembleConfig) error {
+	script, err := hex.DecodeString(conf.Hex)
+	if err != nil {
+		return errors.Wrap(err, "error decoding --hex")
+	}
+
+	assembly, err := txscript.Disassemble(conf.ScriptVersion, script)
+	if err != nil {
+		return err
+	}
+	fmt.Println(assembly)
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/subnetworks"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
+	"github.com/pkg/errors"
+)
+
+func evaluate(conf *evaluateConfig) error {
+	signatureScript, err := txscript.Assemble(conf.SignatureScript)
+	if err != nil {
+		return errors.Wrap(err, "error assembling --signature-script")
+	}
+	scriptPublicKeyScript, err := txscript.Assemble(conf.ScriptPublicKey)
+	if err != nil {
+		return errors.Wrap(err, "error assembling --script-public-key")
+	}
+	scriptPublicKey := &externalapi.ScriptPublicKey{Script: scriptPublicKeyScript, Version: conf.ScriptVersion}
+
+	// The scripts are evaluated as the only input of an otherwise empty transaction
+	transaction := &externalapi.DomainTransaction{
+		Inputs: []*externalapi.DomainTransactionInput{{
+			SignatureScript: signatureScript,
+			UTXOEntry:       utxo.NewUTXOEntry(0, scriptPublicKey, false, 0),
+		}},
+		Outputs:      []*externalapi.DomainTransactionOutput{},
+		SubnetworkID: subnetworks.SubnetworkIDNative,
+	}
+	vm, err := txscript.NewEngine(scriptPublicKey, transaction, 0, txscript.ScriptVersion1Active,
+		nil, nil, &consensushashing.SighashReusedValues{})
+	if err != nil {
+		return err
+	}
+
+	err = runEngine(vm, conf.Interactive)
+	if errors.Is(err, errQuit) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "the script failed")
+	}
+	fmt.Println("The script succeeded")
+	return nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import "github.com/pkg/errors"
+
+func main() {
+	subCmd, config := parseCommandLine()
+
+	var err error
+	switch subCmd {
+	case assembleSubCmd:
+		err = assemble(config.(*assembleConfig))
+	case disassembleSubCmd:
+		err = disassemble(config.(*disassembleConfig))
+	case p2shAddressSubCmd:
+		err = p2shAddress(config.(*p2shAddressConfig))
+	case classifySubCmd:
+		err = classify(config.(*classifyConfig))
+	case evaluateSubCmd:
+		err = evaluate(config.(*evaluateConfig))
+	case debugSubCmd:
+		err = debug(config.(*debugConfig))
+	default:
+		err = errors.Errorf("Unknown sub-command '%s'\n", subCmd)
+	}
+
+	if err != nil {
+		printErrorAndExit(err)
+	}
+}
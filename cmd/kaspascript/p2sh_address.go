@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/util"
+)
+
+func p2shAddress(conf *p2shAddressConfig) error {
+	prefix, err := util.ParsePrefix(conf.Prefix)
+	if err != nil {
+		return err
+	}
+	redeemScript, err := conf.parseScript()
+	if err != nil {
+		return err
+	}
+
+	address, err := util.NewAddressScriptHash(redeemScript, prefix)
+	if err != nil {
+		return err
+	}
+	scriptPublicKey, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Address:            %s\n", address)
+	fmt.Printf("Script public key:  %x (version %d)\n", scriptPublicKey.Script, scriptPublicKey.Version)
+	fmt.Printf("Redeem script:      %x\n", redeemScript)
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+	"github.com/kaspanet/kaspad/infrastructure/network/rpcclient"
+	"github.com/pkg/errors"
+)
+
+const clientTimeout = 10 * time.Second
+
+type stratumClient struct {
+	*rpcclient.RPCClient
+
+	cfg                        *configFlags
+	blockAddedNotificationChan chan struct{}
+}
+
+func (sc *stratumClient) connect() error {
+	rpcAddress, err := sc.cfg.NetParams().NormalizeRPCServerAddress(sc.cfg.RPCServer)
+	if err != nil {
+		return err
+	}
+	rpcClient, err := rpcclient.NewRPCClient(rpcAddress)
+	if err != nil {
+		return err
+	}
+	sc.RPCClient = rpcClient
+	sc.SetTimeout(clientTimeout)
+	sc.SetLogger(logger.BackendLog, logger.LevelTrace)
+
+	err = sc.RegisterForBlockAddedNotifications(func(_ *appmessage.BlockAddedNotificationMessage) {
+		select {
+		case sc.blockAddedNotificationChan <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		return errors.Wrapf(err, "error requesting block-added notifications")
+	}
+
+	log.Infof("Connected to %s", rpcAddress)
+
+	return nil
+}
+
+func newStratumClient(cfg *configFlags) (*stratumClient, error) {
+	stratumClient := &stratumClient{
+		cfg:                        cfg,
+		blockAddedNotificationChan: make(chan struct{}),
+	}
+
+	err := stratumClient.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	return stratumClient, nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+
+	"github.com/kaspanet/kaspad/util"
+	"github.com/pkg/errors"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/kaspanet/kaspad/version"
+)
+
+const (
+	defaultLogFilename            = "kaspastratum.log"
+	defaultErrLogFilename         = "kaspastratum_err.log"
+	defaultListen                 = "0.0.0.0:5555"
+	defaultShareDifficulty        = 1.0
+	defaultMinimumShareDifficulty = 1.0
+	defaultSharesPerMinute        = 20.0
+)
+
+var (
+	// Default configuration options
+	defaultAppDir     = util.AppDir("kaspastratum", false)
+	defaultLogFile    = filepath.Join(defaultAppDir, defaultLogFilename)
+	defaultErrLogFile = filepath.Join(defaultAppDir, defaultErrLogFilename)
+	defaultRPCServer  = "localhost"
+)
+
+type configFlags struct {
+	ShowVersion            bool    `short:"V" long:"version" description:"Display version information and exit"`
+	RPCServer              string  `short:"s" long:"rpcserver" description:"RPC server to connect to"`
+	Listen                 string  `short:"l" long:"listen" description:"Address to accept Stratum connections on (default: 0.0.0.0:5555)"`
+	MiningAddr             string  `long:"miningaddr" description:"Address to mine to"`
+	ShareDifficulty        float64 `long:"share-difficulty" description:"Initial share difficulty of every worker (default: 1)"`
+	MinimumShareDifficulty float64 `long:"min-share-difficulty" description:"Lowest share difficulty a worker can be retargeted to (default: 1)"`
+	SharesPerMinute        float64 `long:"shares-per-minute" description:"Share rate to retarget the share difficulty of every worker to. 0 disables retargeting (default: 20)"`
+	MineWhenNotSynced      bool    `long:"mine-when-not-synced" description:"Hand out jobs even if the node is not synced with the rest of the network."`
+	Profile                string  `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
+	DebugLevel             string  `short:"d" long:"debuglevel" description:"Logging level {trace, debug, info, warn, error, critical}"`
+	config.NetworkFlags
+}
+
+func parseConfig() (*configFlags, error) {
+	cfg := &configFlags{
+		RPCServer:              defaultRPCServer,
+		Listen:                 defaultListen,
+		ShareDifficulty:        defaultShareDifficulty,
+		MinimumShareDifficulty: defaultMinimumShareDifficulty,
+		SharesPerMinute:        defaultSharesPerMinute,
+		DebugLevel:             "info",
+	}
+	parser := flags.NewParser(cfg, flags.PrintErrors|flags.HelpFlag)
+	_, err := parser.Parse()
+
+	// Show the version and exit if the version flag was specified.
+	if cfg.ShowVersion {
+		appName := filepath.Base(os.Args[0])
+		appName = strings.TrimSuffix(appName, filepath.Ext(appName))
+		fmt.Println(appName, "version", version.Version())
+		os.Exit(0)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = cfg.ResolveNetwork(parser)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Profile != "" {
+		profilePort, err := strconv.Atoi(cfg.Profile)
+		if err != nil || profilePort < 1024 || profilePort > 65535 {
+			return nil, errors.New("The profile port must be between 1024 and 65535")
+		}
+	}
+
+	if cfg.MiningAddr == "" {
+		return nil, errors.New("--miningaddr is required")
+	}
+
+	if cfg.MinimumShareDifficulty <= 0 {
+		return nil, errors.New("--min-share-difficulty must be positive")
+	}
+	if cfg.ShareDifficulty < cfg.MinimumShareDifficulty {
+		return nil, errors.New("--share-difficulty must not be lower than --min-share-difficulty")
+	}
+	if cfg.SharesPerMinute < 0 {
+		return nil, errors.New("--shares-per-minute must not be negative")
+	}
+
+	initLog(defaultLogFile, defaultErrLogFile)
+
+	err = logger.ParseAndSetLogLevels(cfg.DebugLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
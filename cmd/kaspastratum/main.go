@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/kaspanet/kaspad/cmd/kaspastratum/stratum"
+	"github.com/kaspanet/kaspad/util"
+
+	"github.com/kaspanet/kaspad/version"
+
+	"github.com/pkg/errors"
+
+	_ "net/http/pprof"
+
+	"github.com/kaspanet/kaspad/infrastructure/os/signal"
+	"github.com/kaspanet/kaspad/util/panics"
+	"github.com/kaspanet/kaspad/util/profiling"
+)
+
+func main() {
+	defer panics.HandlePanic(log, "MAIN", nil)
+	interrupt := signal.InterruptListener()
+
+	cfg, err := parseConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing command-line arguments: %s\n", err)
+		os.Exit(1)
+	}
+
+	// Show version at startup.
+	log.Infof("Version %s", version.Version())
+
+	// Enable http profiling server if requested.
+	if cfg.Profile != "" {
+		profiling.Start(cfg.Profile, log)
+	}
+
+	client, err := newStratumClient(cfg)
+	if err != nil {
+		panic(errors.Wrap(err, "error connecting to the RPC server"))
+	}
+	defer client.Disconnect()
+
+	miningAddr, err := util.DecodeAddress(cfg.MiningAddr, cfg.ActiveNetParams.Prefix)
+	if err != nil {
+		panic(errors.Wrap(err, "error decoding mining address"))
+	}
+
+	server := stratum.NewServer(&stratum.Config{
+		ShareDifficulty:        cfg.ShareDifficulty,
+		MinimumShareDifficulty: cfg.MinimumShareDifficulty,
+		TargetSharesPerMinute:  cfg.SharesPerMinute,
+	}, client)
+	defer server.Stop()
+
+	listener, err := net.Listen("tcp", cfg.Listen)
+	if err != nil {
+		panic(errors.Wrapf(err, "error listening on %s", cfg.Listen))
+	}
+	log.Infof("Accepting Stratum connections on %s", cfg.Listen)
+
+	errChan := make(chan error)
+	spawn("server.Serve", func() {
+		errChan <- server.Serve(listener)
+	})
+	spawn("templatesLoop", func() {
+		templatesLoop(client, server, miningAddr, cfg.MineWhenNotSynced, errChan)
+	})
+
+	select {
+	case err := <-errChan:
+		if err != nil {
+			panic(err)
+		}
+	case <-interrupt:
+	}
+}
@@ -0,0 +1,57 @@
+package stratum
+
+import (
+	"math/big"
+	"time"
+)
+
+// difficultyOneTarget is the share target of difficulty 1, which on average takes 2^32 hashes to find
+var difficultyOneTarget = new(big.Int).Lsh(big.NewInt(1), 224)
+
+// maxTarget is the largest possible PoW value, and so the target that accepts every share
+var maxTarget = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+const (
+	// maxDifficultyAdjustmentFactor bounds how much the share difficulty may change in a single retarget
+	maxDifficultyAdjustmentFactor = 4.0
+
+	// difficultyAdjustmentTolerance is the ratio between the actual and the target share rate
+	// under which the share difficulty is left unchanged
+	difficultyAdjustmentTolerance = 1.25
+)
+
+// shareTarget returns the target a share with the given difficulty must meet
+func shareTarget(difficulty float64) *big.Int {
+	target, _ := new(big.Float).Quo(new(big.Float).SetInt(difficultyOneTarget), big.NewFloat(difficulty)).Int(nil)
+	if target.Cmp(maxTarget) > 0 {
+		return new(big.Int).Set(maxTarget)
+	}
+	return target
+}
+
+// nextShareDifficulty returns the share difficulty that would bring a worker that submitted
+// sharesCount shares over elapsedTime to targetSharesPerMinute
+func nextShareDifficulty(currentDifficulty float64, sharesCount uint64, elapsedTime time.Duration,
+	targetSharesPerMinute float64, minimumDifficulty float64) float64 {
+
+	ratio := 1 / maxDifficultyAdjustmentFactor
+	if sharesCount > 0 {
+		sharesPerMinute := float64(sharesCount) / elapsedTime.Minutes()
+		ratio = sharesPerMinute / targetSharesPerMinute
+	}
+	if ratio > maxDifficultyAdjustmentFactor {
+		ratio = maxDifficultyAdjustmentFactor
+	}
+	if ratio < 1/maxDifficultyAdjustmentFactor {
+		ratio = 1 / maxDifficultyAdjustmentFactor
+	}
+	if ratio <= difficultyAdjustmentTolerance && ratio >= 1/difficultyAdjustmentTolerance {
+		return currentDifficulty
+	}
+
+	nextDifficulty := currentDifficulty * ratio
+	if nextDifficulty < minimumDifficulty {
+		return minimumDifficulty
+	}
+	return nextDifficulty
+}
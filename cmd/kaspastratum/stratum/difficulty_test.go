@@ -0,0 +1,52 @@
+package stratum
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestShareTarget(t *testing.T) {
+	tests := []struct {
+		difficulty     float64
+		expectedTarget *big.Int
+	}{
+		{difficulty: 1, expectedTarget: new(big.Int).Lsh(big.NewInt(1), 224)},
+		{difficulty: 2, expectedTarget: new(big.Int).Lsh(big.NewInt(1), 223)},
+		{difficulty: 1.0 / 16, expectedTarget: new(big.Int).Lsh(big.NewInt(1), 228)},
+		{difficulty: 1e-20, expectedTarget: maxTarget},
+	}
+	for _, test := range tests {
+		target := shareTarget(test.difficulty)
+		if target.Cmp(test.expectedTarget) != 0 {
+			t.Errorf("shareTarget(%g): expected %x, but got %x", test.difficulty, test.expectedTarget, target)
+		}
+	}
+}
+
+func TestNextShareDifficulty(t *testing.T) {
+	tests := []struct {
+		name               string
+		currentDifficulty  float64
+		sharesCount        uint64
+		elapsedTime        time.Duration
+		expectedDifficulty float64
+	}{
+		{name: "on target", currentDifficulty: 8, sharesCount: 20, elapsedTime: time.Minute, expectedDifficulty: 8},
+		{name: "within tolerance", currentDifficulty: 8, sharesCount: 24, elapsedTime: time.Minute, expectedDifficulty: 8},
+		{name: "too many shares", currentDifficulty: 8, sharesCount: 40, elapsedTime: time.Minute, expectedDifficulty: 16},
+		{name: "too few shares", currentDifficulty: 8, sharesCount: 10, elapsedTime: time.Minute, expectedDifficulty: 4},
+		{name: "adjustment is bounded up", currentDifficulty: 8, sharesCount: 1000, elapsedTime: time.Minute, expectedDifficulty: 32},
+		{name: "adjustment is bounded down", currentDifficulty: 8, sharesCount: 0, elapsedTime: time.Minute, expectedDifficulty: 2},
+		{name: "minimum difficulty", currentDifficulty: 1.5, sharesCount: 0, elapsedTime: time.Minute, expectedDifficulty: 1},
+	}
+	const targetSharesPerMinute = 20
+	const minimumDifficulty = 1
+	for _, test := range tests {
+		difficulty := nextShareDifficulty(test.currentDifficulty, test.sharesCount, test.elapsedTime,
+			targetSharesPerMinute, minimumDifficulty)
+		if difficulty != test.expectedDifficulty {
+			t.Errorf("%s: expected difficulty %g, but got %g", test.name, test.expectedDifficulty, difficulty)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package stratum
+
+import (
+	"math/big"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/pow"
+)
+
+// job is a unit of work handed out to miners, built from a single block template
+type job struct {
+	id    string
+	block *externalapi.DomainBlock
+	state *pow.State
+
+	// submittedNonces is guarded by the server lock
+	submittedNonces map[uint64]struct{}
+}
+
+func newJob(id string, block *externalapi.DomainBlock) *job {
+	return &job{
+		id:              id,
+		block:           block,
+		state:           pow.NewState(block.Header.ToMutable()),
+		submittedNonces: make(map[uint64]struct{}),
+	}
+}
+
+// notifyParams returns the parameters of the mining.notify notification for this job:
+// [job ID, pre-PoW hash, timestamp, bits, clean jobs]
+func (j *job) notifyParams(cleanJobs bool) []interface{} {
+	return []interface{}{j.id, j.state.PrePowHash().String(), j.state.Timestamp, j.block.Header.Bits(), cleanJobs}
+}
+
+// proofOfWorkValue returns the PoW value of the job's block with the given nonce
+func (j *job) proofOfWorkValue(nonce uint64) *big.Int {
+	state := *j.state
+	state.Nonce = nonce
+	return state.CalculateProofOfWorkValue()
+}
+
+// meetsNetworkTarget returns whether the given PoW value makes the job's block valid
+func (j *job) meetsNetworkTarget(proofOfWorkValue *big.Int) bool {
+	return proofOfWorkValue.Cmp(&j.state.Target) <= 0
+}
+
+// blockWithNonce returns the job's block with its nonce set to the given one
+func (j *job) blockWithNonce(nonce uint64) *externalapi.DomainBlock {
+	// Shallow copy the block so that replacing its header won't affect the job
+	block := *j.block
+	mutableHeader := block.Header.ToMutable()
+	mutableHeader.SetNonce(nonce)
+	block.Header = mutableHeader.ToImmutable()
+	return &block
+}
+
+// hasSameContents returns whether both jobs are for the same block, up to its timestamp
+func (j *job) hasSameContents(other *job) bool {
+	return j.state.PrePowHash().Equal(other.state.PrePowHash())
+}
@@ -0,0 +1,113 @@
+package stratum
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Stratum methods handled by the server
+const (
+	methodSubscribe = "mining.subscribe"
+	methodAuthorize = "mining.authorize"
+	methodSubmit    = "mining.submit"
+)
+
+// Stratum notifications sent by the server
+const (
+	methodNotify        = "mining.notify"
+	methodSetDifficulty = "mining.set_difficulty"
+)
+
+// Stratum error codes, as used by common pool software
+const (
+	ErrorCodeOther              = 20
+	ErrorCodeJobNotFound        = 21
+	ErrorCodeDuplicateShare     = 22
+	ErrorCodeLowDifficultyShare = 23
+	ErrorCodeUnauthorizedWorker = 24
+	ErrorCodeNotSubscribed      = 25
+)
+
+// Error is an error returned to a Stratum client.
+// It's serialized as [code, message, traceback], as expected by Stratum clients
+type Error struct {
+	Code    int
+	Message string
+}
+
+func newError(code int, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("stratum error %d: %s", e.Code, e.Message)
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (e *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{e.Code, e.Message, nil})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (e *Error) UnmarshalJSON(data []byte) error {
+	var fields []interface{}
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return err
+	}
+	if len(fields) < 2 {
+		return fmt.Errorf("malformed stratum error %s", data)
+	}
+	code, ok := fields[0].(float64)
+	if !ok {
+		return fmt.Errorf("malformed stratum error code in %s", data)
+	}
+	message, ok := fields[1].(string)
+	if !ok {
+		return fmt.Errorf("malformed stratum error message in %s", data)
+	}
+	e.Code = int(code)
+	e.Message = message
+	return nil
+}
+
+// Request is a request sent by a Stratum client.
+// Requests, responses and notifications are sent as newline-delimited JSON
+type Request struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// Response is the server's response to a Request
+type Response struct {
+	ID     json.RawMessage `json:"id"`
+	Result interface{}     `json:"result"`
+	Error  *Error          `json:"error"`
+}
+
+// Notification is a message sent by the server without a preceding request
+type Notification struct {
+	ID     interface{}   `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+func newNotification(method string, params ...interface{}) *Notification {
+	return &Notification{ID: nil, Method: method, Params: params}
+}
+
+func parseStringParams(request *Request, minimumCount int) ([]string, error) {
+	if len(request.Params) < minimumCount {
+		return nil, newError(ErrorCodeOther, "%s expects at least %d parameters, but got %d",
+			request.Method, minimumCount, len(request.Params))
+	}
+	params := make([]string, len(request.Params))
+	for i, rawParam := range request.Params {
+		err := json.Unmarshal(rawParam, &params[i])
+		if err != nil {
+			return nil, newError(ErrorCodeOther, "parameter %d of %s must be a string", i, request.Method)
+		}
+	}
+	return params, nil
+}
@@ -0,0 +1,268 @@
+package stratum
+
+import (
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/pkg/errors"
+)
+
+const (
+	// extranonceSize is the number of leading nonce bytes reserved to tell sessions apart.
+	// Every session searches a different range of the nonce space, so sessions never
+	// submit the same shares.
+	extranonceSize = 2
+
+	// nonceSize is the size of a block nonce in bytes
+	nonceSize = 8
+
+	// maxJobs is the number of recent jobs kept for accepting shares
+	maxJobs = 16
+
+	// shareDifficultyRetargetInterval is how often the share difficulty of sessions is adjusted
+	shareDifficultyRetargetInterval = time.Minute
+)
+
+// BlockSubmitter submits blocks found by miners to the network
+type BlockSubmitter interface {
+	SubmitBlock(block *externalapi.DomainBlock) (appmessage.RejectReason, error)
+}
+
+// Config is the configuration of a stratum Server
+type Config struct {
+	// ShareDifficulty is the initial share difficulty of every session
+	ShareDifficulty float64
+
+	// MinimumShareDifficulty is the lowest share difficulty retargeting may set
+	MinimumShareDifficulty float64
+
+	// TargetSharesPerMinute is the share rate the share difficulty of every session
+	// is retargeted to. 0 disables retargeting.
+	TargetSharesPerMinute float64
+}
+
+// Server hands out jobs built from block templates to Stratum clients, validates
+// their shares, and submits the blocks they find
+type Server struct {
+	cfg       *Config
+	submitter BlockSubmitter
+
+	lock            sync.Mutex
+	jobs            map[string]*job
+	jobIDs          []string
+	currentJob      *job
+	nextJobID       uint64
+	sessions        map[*session]struct{}
+	usedExtranonces map[uint16]struct{}
+	nextExtranonce  uint16
+	listener        net.Listener
+	quit            chan struct{}
+	stopOnce        sync.Once
+}
+
+// NewServer creates a new stratum Server
+func NewServer(cfg *Config, submitter BlockSubmitter) *Server {
+	return &Server{
+		cfg:             cfg,
+		submitter:       submitter,
+		jobs:            make(map[string]*job),
+		sessions:        make(map[*session]struct{}),
+		usedExtranonces: make(map[uint16]struct{}),
+		quit:            make(chan struct{}),
+	}
+}
+
+// Serve accepts Stratum connections on the given listener until the server is stopped
+func (s *Server) Serve(listener net.Listener) error {
+	s.lock.Lock()
+	s.listener = listener
+	s.lock.Unlock()
+
+	if s.cfg.TargetSharesPerMinute > 0 {
+		spawn("Server.retargetLoop", s.retargetLoop)
+	}
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			select {
+			case <-s.quit:
+				return nil
+			default:
+				return errors.Wrap(err, "error accepting a stratum connection")
+			}
+		}
+
+		session, err := s.newSession(conn)
+		if err != nil {
+			log.Warnf("Rejecting stratum connection from %s: %s", conn.RemoteAddr(), err)
+			conn.Close()
+			continue
+		}
+		log.Infof("Accepted stratum connection from %s", conn.RemoteAddr())
+		spawn("session.handle", session.handle)
+	}
+}
+
+// Stop stops accepting connections and disconnects all sessions. It may be called more than once.
+func (s *Server) Stop() {
+	s.stopOnce.Do(func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		close(s.quit)
+		if s.listener != nil {
+			s.listener.Close()
+		}
+		for session := range s.sessions {
+			session.conn.Close()
+		}
+	})
+}
+
+// UpdateTemplate creates a new job from the given block template and sends it to all
+// authorized sessions. Templates with the same contents as the current job are ignored.
+func (s *Server) UpdateTemplate(block *externalapi.DomainBlock) {
+	notification, sessions := s.addJob(block)
+	for _, session := range sessions {
+		session.send(notification)
+	}
+}
+
+// addJob makes a job from the given block template the current job, and returns its notification
+// along with the sessions to send it to. It returns no sessions if the current job has the same contents.
+func (s *Server) addJob(block *externalapi.DomainBlock) (*Notification, []*session) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	newJob := newJob(strconv.FormatUint(s.nextJobID, 16), block)
+	if s.currentJob != nil && s.currentJob.hasSameContents(newJob) {
+		return nil, nil
+	}
+	s.nextJobID++
+
+	// Shares for jobs built on other parents can't become blocks anymore, so we drop
+	// these jobs and ask the miners to abandon them
+	cleanJobs := s.currentJob == nil ||
+		!externalapi.HashesEqual(s.currentJob.block.Header.DirectParents(), block.Header.DirectParents())
+	if cleanJobs {
+		s.jobs = make(map[string]*job)
+		s.jobIDs = nil
+	}
+	if len(s.jobIDs) == maxJobs {
+		delete(s.jobs, s.jobIDs[0])
+		s.jobIDs = s.jobIDs[1:]
+	}
+	s.jobs[newJob.id] = newJob
+	s.jobIDs = append(s.jobIDs, newJob.id)
+	s.currentJob = newJob
+
+	log.Debugf("Created job %s for block with parents %s", newJob.id, block.Header.DirectParents())
+	sessions := make([]*session, 0, len(s.sessions))
+	for session := range s.sessions {
+		if session.isAuthorized() {
+			sessions = append(sessions, session)
+		}
+	}
+	return newNotification(methodNotify, newJob.notifyParams(cleanJobs)...), sessions
+}
+
+// newSession creates a session for the given connection and allocates its extranonce
+func (s *Server) newSession(conn net.Conn) (*session, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	const extranonceCount = 1 << (8 * extranonceSize)
+	if len(s.usedExtranonces) == extranonceCount {
+		return nil, errors.Errorf("all %d extranonces are in use", extranonceCount)
+	}
+	for {
+		extranonce := s.nextExtranonce
+		s.nextExtranonce++
+		if _, ok := s.usedExtranonces[extranonce]; ok {
+			continue
+		}
+		s.usedExtranonces[extranonce] = struct{}{}
+		session := newSession(s, conn, extranonce)
+		s.sessions[session] = struct{}{}
+		return session, nil
+	}
+}
+
+func (s *Server) removeSession(session *session) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.sessions, session)
+	delete(s.usedExtranonces, session.extranonce)
+}
+
+// currentJobNotification returns the mining.notify notification for the current job, or nil
+// if there's none yet
+func (s *Server) currentJobNotification() *Notification {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.currentJob == nil {
+		return nil
+	}
+	return newNotification(methodNotify, s.currentJob.notifyParams(true)...)
+}
+
+// registerShare returns the job with the given ID, and marks the given nonce as submitted for it
+func (s *Server) registerShare(jobID string, nonce uint64) (*job, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	job, ok := s.jobs[jobID]
+	if !ok {
+		return nil, newError(ErrorCodeJobNotFound, "job %s not found", jobID)
+	}
+	if _, ok := job.submittedNonces[nonce]; ok {
+		return nil, newError(ErrorCodeDuplicateShare, "duplicate share")
+	}
+	job.submittedNonces[nonce] = struct{}{}
+	return job, nil
+}
+
+func (s *Server) submitBlock(job *job, nonce uint64, workerName string) {
+	block := job.blockWithNonce(nonce)
+	blockHash := consensushashing.BlockHash(block)
+	log.Infof("Worker %s found block %s", workerName, blockHash)
+
+	rejectReason, err := s.submitter.SubmitBlock(block)
+	if err != nil {
+		log.Warnf("Block %s was rejected (reason %s): %s", blockHash, rejectReason, err)
+		return
+	}
+	log.Infof("Submitted block %s", blockHash)
+}
+
+func (s *Server) retargetLoop() {
+	ticker := time.NewTicker(shareDifficultyRetargetInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			return
+		case <-ticker.C:
+		}
+
+		s.lock.Lock()
+		sessions := make([]*session, 0, len(s.sessions))
+		for session := range s.sessions {
+			sessions = append(sessions, session)
+		}
+		s.lock.Unlock()
+
+		for _, session := range sessions {
+			session.retargetShareDifficulty()
+		}
+	}
+}
@@ -0,0 +1,347 @@
+package stratum
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/blockheader"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/pow"
+	"github.com/kaspanet/kaspad/util/mstime"
+)
+
+// easyBits is a target that about half of all nonces meet
+const easyBits = 0x207fffff
+
+type stubSubmitter struct {
+	blocks chan *externalapi.DomainBlock
+}
+
+func (s *stubSubmitter) SubmitBlock(block *externalapi.DomainBlock) (appmessage.RejectReason, error) {
+	s.blocks <- block
+	return appmessage.RejectReasonNone, nil
+}
+
+// stubMiner is a minimal Stratum client
+type stubMiner struct {
+	t             *testing.T
+	conn          net.Conn
+	reader        *bufio.Reader
+	nextRequestID int
+	notifications []*stubMessage
+}
+
+type stubMessage struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+	Result json.RawMessage   `json:"result"`
+	Error  *Error            `json:"error"`
+}
+
+func newStubMiner(t *testing.T, address string) *stubMiner {
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatalf("Dial: %s", err)
+	}
+	return &stubMiner{t: t, conn: conn, reader: bufio.NewReader(conn)}
+}
+
+func (m *stubMiner) readMessage() *stubMessage {
+	err := m.conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	if err != nil {
+		m.t.Fatalf("SetReadDeadline: %s", err)
+	}
+	line, err := m.reader.ReadBytes('\n')
+	if err != nil {
+		m.t.Fatalf("ReadBytes: %s", err)
+	}
+	message := &stubMessage{}
+	err = json.Unmarshal(line, message)
+	if err != nil {
+		m.t.Fatalf("Unmarshal: %s", err)
+	}
+	return message
+}
+
+// call sends a request and returns its response, queueing any notification received in the meanwhile
+func (m *stubMiner) call(method string, params ...string) *stubMessage {
+	m.nextRequestID++
+	requestID := m.nextRequestID
+	request, err := json.Marshal(map[string]interface{}{"id": requestID, "method": method, "params": params})
+	if err != nil {
+		m.t.Fatalf("Marshal: %s", err)
+	}
+	_, err = m.conn.Write(append(request, '\n'))
+	if err != nil {
+		m.t.Fatalf("Write: %s", err)
+	}
+
+	for {
+		message := m.readMessage()
+		if message.Method != "" {
+			m.notifications = append(m.notifications, message)
+			continue
+		}
+		if string(message.ID) != strconv.Itoa(requestID) {
+			m.t.Fatalf("Expected a response to request %d, but got a response to %s", requestID, message.ID)
+		}
+		return message
+	}
+}
+
+func (m *stubMiner) callSuccessfully(method string, params ...string) json.RawMessage {
+	response := m.call(method, params...)
+	if response.Error != nil {
+		m.t.Fatalf("%s failed: %s", method, response.Error)
+	}
+	return response.Result
+}
+
+func (m *stubMiner) callAndExpectError(expectedCode int, method string, params ...string) {
+	response := m.call(method, params...)
+	if response.Error == nil {
+		m.t.Fatalf("Expected %s to fail with code %d, but it succeeded", method, expectedCode)
+	}
+	if response.Error.Code != expectedCode {
+		m.t.Fatalf("Expected %s to fail with code %d, but got %s", method, expectedCode, response.Error)
+	}
+}
+
+// nextNotification returns the next notification with the given method
+func (m *stubMiner) nextNotification(method string) *stubMessage {
+	for {
+		var notification *stubMessage
+		if len(m.notifications) > 0 {
+			notification, m.notifications = m.notifications[0], m.notifications[1:]
+		} else {
+			notification = m.readMessage()
+		}
+		if notification.Method == method {
+			return notification
+		}
+	}
+}
+
+func (m *stubMiner) subscribeAndAuthorize(workerName string) (extranonce uint64) {
+	var subscribeResult []json.RawMessage
+	err := json.Unmarshal(m.callSuccessfully(methodSubscribe, "stub-miner"), &subscribeResult)
+	if err != nil || len(subscribeResult) != 3 {
+		m.t.Fatalf("Unexpected mining.subscribe result %s", subscribeResult)
+	}
+	var extranonceHex string
+	err = json.Unmarshal(subscribeResult[1], &extranonceHex)
+	if err != nil {
+		m.t.Fatalf("Unmarshal: %s", err)
+	}
+	extranonce, err = strconv.ParseUint(extranonceHex, 16, 64)
+	if err != nil {
+		m.t.Fatalf("ParseUint: %s", err)
+	}
+
+	m.callSuccessfully(methodAuthorize, workerName, "password")
+	return extranonce
+}
+
+// nextJob returns the ID and pre-PoW hash of the next mining.notify, and whether it asks to clean older jobs
+func (m *stubMiner) nextJob() (jobID string, prePowHash string, cleanJobs bool) {
+	notification := m.nextNotification(methodNotify)
+	if len(notification.Params) != 5 {
+		m.t.Fatalf("Unexpected mining.notify params %s", notification.Params)
+	}
+	err := json.Unmarshal(notification.Params[0], &jobID)
+	if err != nil {
+		m.t.Fatalf("Unmarshal: %s", err)
+	}
+	err = json.Unmarshal(notification.Params[1], &prePowHash)
+	if err != nil {
+		m.t.Fatalf("Unmarshal: %s", err)
+	}
+	err = json.Unmarshal(notification.Params[4], &cleanJobs)
+	if err != nil {
+		m.t.Fatalf("Unmarshal: %s", err)
+	}
+	return jobID, prePowHash, cleanJobs
+}
+
+func nonceHex(extranonce uint64, nonce uint64) string {
+	return fmt.Sprintf("%016x", extranonce<<(8*(nonceSize-extranonceSize))|nonce)
+}
+
+func newTemplate(parentHashByte byte, bits uint32) *externalapi.DomainBlock {
+	parentHash := externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{parentHashByte})
+	header := blockheader.NewImmutableBlockHeader(0, []externalapi.BlockLevelParents{{parentHash}},
+		&externalapi.DomainHash{}, &externalapi.DomainHash{}, &externalapi.DomainHash{},
+		mstime.Now().UnixMilliseconds(), bits, 0, 1, 1, big.NewInt(1), &externalapi.DomainHash{})
+	return &externalapi.DomainBlock{Header: header}
+}
+
+func startTestServer(t *testing.T, shareDifficulty float64) (*Server, *stubSubmitter, string) {
+	submitter := &stubSubmitter{blocks: make(chan *externalapi.DomainBlock, 100)}
+	server, address := startTestServerWithSubmitter(t, shareDifficulty, submitter)
+	return server, submitter, address
+}
+
+func startTestServerWithSubmitter(t *testing.T, shareDifficulty float64, submitter BlockSubmitter) (*Server, string) {
+	server := NewServer(&Config{ShareDifficulty: shareDifficulty, MinimumShareDifficulty: shareDifficulty}, submitter)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %s", err)
+	}
+	go func() {
+		err := server.Serve(listener)
+		if err != nil {
+			t.Errorf("Serve: %s", err)
+		}
+	}()
+	return server, listener.Addr().String()
+}
+
+func TestServer(t *testing.T) {
+	// Every share meets the lowest possible share difficulty
+	server, submitter, address := startTestServer(t, 1e-20)
+	defer server.Stop()
+
+	template := newTemplate(1, easyBits)
+	server.UpdateTemplate(template)
+
+	miner := newStubMiner(t, address)
+	miner.callAndExpectError(ErrorCodeNotSubscribed, methodAuthorize, "worker1", "password")
+	miner.callAndExpectError(ErrorCodeUnauthorizedWorker, methodSubmit, "worker1", "0", nonceHex(0, 0))
+	extranonce := miner.subscribeAndAuthorize("worker1")
+
+	miner.nextNotification(methodSetDifficulty)
+	jobID, prePowHash, _ := miner.nextJob()
+	expectedPrePowHash := pow.NewState(template.Header.ToMutable()).PrePowHash().String()
+	if prePowHash != expectedPrePowHash {
+		t.Fatalf("Expected pre-PoW hash %s, but got %s", expectedPrePowHash, prePowHash)
+	}
+
+	// Every session gets its own extranonce, and may only submit nonces that start with it
+	otherMiner := newStubMiner(t, address)
+	otherExtranonce := otherMiner.subscribeAndAuthorize("worker2")
+	if otherExtranonce == extranonce {
+		t.Fatalf("Both sessions got extranonce %x", extranonce)
+	}
+	miner.callAndExpectError(ErrorCodeOther, methodSubmit, "worker1", jobID, nonceHex(otherExtranonce, 0))
+	miner.callAndExpectError(ErrorCodeOther, methodSubmit, "worker1", jobID, "not a nonce")
+	miner.callAndExpectError(ErrorCodeJobNotFound, methodSubmit, "worker1", "unknown-job", nonceHex(extranonce, 0))
+
+	// Submit shares until one of them also meets the network target
+	var foundBlock *externalapi.DomainBlock
+	nonce := uint64(0)
+	for ; foundBlock == nil; nonce++ {
+		miner.callSuccessfully(methodSubmit, "worker1", jobID, nonceHex(extranonce, nonce))
+		select {
+		case foundBlock = <-submitter.blocks:
+		default:
+		}
+	}
+	if !pow.CheckProofOfWorkByBits(foundBlock.Header.ToMutable()) {
+		t.Fatalf("The submitted block doesn't have a valid PoW")
+	}
+	if foundBlock.Header.Nonce()>>(8*(nonceSize-extranonceSize)) != extranonce {
+		t.Fatalf("The nonce of the submitted block doesn't start with the extranonce of its worker")
+	}
+	miner.callAndExpectError(ErrorCodeDuplicateShare, methodSubmit, "worker1", jobID, nonceHex(extranonce, nonce-1))
+
+	// A template with the same contents doesn't create a new job
+	server.UpdateTemplate(newTemplate(1, easyBits))
+	// A template built on other parents replaces all previous jobs
+	server.UpdateTemplate(newTemplate(2, easyBits))
+	newJobID, _, cleanJobs := miner.nextJob()
+	if !cleanJobs {
+		t.Fatalf("Expected a job built on new parents to clean older jobs")
+	}
+	if newJobID == jobID {
+		t.Fatalf("Expected a new job ID, but got %s again", jobID)
+	}
+	miner.callAndExpectError(ErrorCodeJobNotFound, methodSubmit, "worker1", jobID, nonceHex(extranonce, nonce))
+	miner.callSuccessfully(methodSubmit, "worker1", newJobID, nonceHex(extranonce, nonce))
+}
+
+func TestServerLowDifficultyShare(t *testing.T) {
+	// A share of difficulty 2^60 takes about 2^92 hashes to find
+	server, submitter, address := startTestServer(t, 1<<60)
+	defer server.Stop()
+	server.UpdateTemplate(newTemplate(1, easyBits))
+
+	miner := newStubMiner(t, address)
+	extranonce := miner.subscribeAndAuthorize("worker")
+	jobID, _, _ := miner.nextJob()
+	for nonce := uint64(0); nonce < 10; nonce++ {
+		miner.callAndExpectError(ErrorCodeLowDifficultyShare, methodSubmit, "worker", jobID, nonceHex(extranonce, nonce))
+	}
+	if len(submitter.blocks) != 0 {
+		t.Fatalf("Expected no block to be submitted for low difficulty shares")
+	}
+}
+
+// blockingSubmitter is a BlockSubmitter that doesn't return until it's released
+type blockingSubmitter struct {
+	release chan struct{}
+	blocks  chan *externalapi.DomainBlock
+}
+
+func (s *blockingSubmitter) SubmitBlock(block *externalapi.DomainBlock) (appmessage.RejectReason, error) {
+	<-s.release
+	s.blocks <- block
+	return appmessage.RejectReasonNone, nil
+}
+
+func TestServerSubmitsBlocksAsynchronously(t *testing.T) {
+	submitter := &blockingSubmitter{
+		release: make(chan struct{}),
+		blocks:  make(chan *externalapi.DomainBlock, 100),
+	}
+	server, address := startTestServerWithSubmitter(t, 1e-20, submitter)
+	defer server.Stop()
+	template := newTemplate(1, easyBits)
+	server.UpdateTemplate(template)
+
+	miner := newStubMiner(t, address)
+	extranonce := miner.subscribeAndAuthorize("worker")
+	jobID, _, _ := miner.nextJob()
+
+	// The shares are answered while the submission of their blocks is still blocked
+	const blockCount = 3
+	foundBlocks := 0
+	for nonce := uint64(0); foundBlocks < blockCount; nonce++ {
+		fullNonce, err := strconv.ParseUint(nonceHex(extranonce, nonce), 16, 64)
+		if err != nil {
+			t.Fatalf("ParseUint: %s", err)
+		}
+		header := template.Header.ToMutable()
+		header.SetNonce(fullNonce)
+		if !pow.CheckProofOfWorkByBits(header) {
+			continue
+		}
+		miner.callSuccessfully(methodSubmit, "worker", jobID, nonceHex(extranonce, nonce))
+		foundBlocks++
+	}
+	if len(submitter.blocks) != 0 {
+		t.Fatalf("Expected no block to be submitted before the submitter is released")
+	}
+
+	close(submitter.release)
+	for i := 0; i < blockCount; i++ {
+		select {
+		case <-submitter.blocks:
+		case <-time.After(10 * time.Second):
+			t.Fatalf("Timed out waiting for block %d to be submitted", i)
+		}
+	}
+}
+
+func TestServerStopTwice(t *testing.T) {
+	server, _, _ := startTestServer(t, 1)
+	server.Stop()
+	server.Stop()
+}
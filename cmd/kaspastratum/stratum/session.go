@@ -0,0 +1,255 @@
+package stratum
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// maxMessageSize is the maximum size of a single message sent by a Stratum client
+	maxMessageSize = 4096
+
+	writeTimeout = 10 * time.Second
+)
+
+// session is a single Stratum client connection
+type session struct {
+	server     *Server
+	conn       net.Conn
+	extranonce uint16
+
+	writeLock sync.Mutex
+
+	lock                    sync.Mutex
+	isSubscribed            bool
+	workerName              string
+	shareDifficulty         float64
+	shareTarget             *big.Int
+	sharesSinceRetarget     uint64
+	lastShareDifficultyTime time.Time
+}
+
+func newSession(server *Server, conn net.Conn, extranonce uint16) *session {
+	return &session{
+		server:                  server,
+		conn:                    conn,
+		extranonce:              extranonce,
+		shareDifficulty:         server.cfg.ShareDifficulty,
+		shareTarget:             shareTarget(server.cfg.ShareDifficulty),
+		lastShareDifficultyTime: time.Now(),
+	}
+}
+
+func (s *session) handle() {
+	defer func() {
+		s.conn.Close()
+		s.server.removeSession(s)
+		log.Infof("Stratum connection from %s was closed", s.conn.RemoteAddr())
+	}()
+
+	scanner := bufio.NewScanner(s.conn)
+	scanner.Buffer(make([]byte, maxMessageSize), maxMessageSize)
+	for scanner.Scan() {
+		request := &Request{}
+		err := json.Unmarshal(scanner.Bytes(), request)
+		if err != nil {
+			log.Warnf("Received a malformed message from %s: %s", s.conn.RemoteAddr(), err)
+			return
+		}
+
+		result, err := s.handleRequest(request)
+		response := &Response{ID: request.ID, Result: result}
+		if err != nil {
+			stratumError, ok := err.(*Error)
+			if !ok {
+				stratumError = newError(ErrorCodeOther, "%s", err)
+			}
+			log.Debugf("Request %s from %s failed: %s", request.Method, s.conn.RemoteAddr(), stratumError)
+			response.Result = nil
+			response.Error = stratumError
+		}
+		if !s.send(response) {
+			return
+		}
+
+		if request.Method == methodAuthorize && err == nil {
+			s.sendInitialWork()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Debugf("Error reading from %s: %s", s.conn.RemoteAddr(), err)
+	}
+}
+
+func (s *session) handleRequest(request *Request) (interface{}, error) {
+	switch request.Method {
+	case methodSubscribe:
+		return s.handleSubscribe()
+	case methodAuthorize:
+		return s.handleAuthorize(request)
+	case methodSubmit:
+		return s.handleSubmit(request)
+	default:
+		return nil, newError(ErrorCodeOther, "unsupported method %s", request.Method)
+	}
+}
+
+// handleSubscribe replies with [subscriptions, extranonce, extranonce2 size]. Miners must
+// start every nonce they submit with the returned extranonce.
+func (s *session) handleSubscribe() (interface{}, error) {
+	s.lock.Lock()
+	s.isSubscribed = true
+	s.lock.Unlock()
+
+	subscriptionID := fmt.Sprintf("%x", s.extranonce)
+	subscriptions := [][]string{{methodSetDifficulty, subscriptionID}, {methodNotify, subscriptionID}}
+	return []interface{}{subscriptions, s.extranonceHex(), nonceSize - extranonceSize}, nil
+}
+
+func (s *session) handleAuthorize(request *Request) (interface{}, error) {
+	params, err := parseStringParams(request, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.isSubscribed {
+		return nil, newError(ErrorCodeNotSubscribed, "not subscribed")
+	}
+	s.workerName = params[0]
+	log.Infof("Authorized worker %s from %s", s.workerName, s.conn.RemoteAddr())
+	return true, nil
+}
+
+// handleSubmit validates a share submitted as [worker name, job ID, nonce], and submits
+// the job's block if the share also meets the network target
+func (s *session) handleSubmit(request *Request) (interface{}, error) {
+	params, err := parseStringParams(request, 3)
+	if err != nil {
+		return nil, err
+	}
+	jobID, nonceString := params[1], params[2]
+
+	s.lock.Lock()
+	workerName, target := s.workerName, s.shareTarget
+	s.lock.Unlock()
+	if workerName == "" {
+		return nil, newError(ErrorCodeUnauthorizedWorker, "unauthorized worker")
+	}
+
+	if len(nonceString) != 2*nonceSize {
+		return nil, newError(ErrorCodeOther, "nonce must be %d hex characters", 2*nonceSize)
+	}
+	nonce, err := strconv.ParseUint(nonceString, 16, 64)
+	if err != nil {
+		return nil, newError(ErrorCodeOther, "malformed nonce %s", nonceString)
+	}
+	if uint16(nonce>>(8*(nonceSize-extranonceSize))) != s.extranonce {
+		return nil, newError(ErrorCodeOther, "nonce %s doesn't start with extranonce %s", nonceString, s.extranonceHex())
+	}
+
+	job, err := s.server.registerShare(jobID, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	proofOfWorkValue := job.proofOfWorkValue(nonce)
+	if proofOfWorkValue.Cmp(target) > 0 {
+		return nil, newError(ErrorCodeLowDifficultyShare, "low difficulty share")
+	}
+
+	s.lock.Lock()
+	s.sharesSinceRetarget++
+	s.lock.Unlock()
+
+	// Submitting a block can take a while, and the miner shouldn't wait for it to get the
+	// response to its share
+	if job.meetsNetworkTarget(proofOfWorkValue) {
+		spawn("Server.submitBlock", func() {
+			s.server.submitBlock(job, nonce, workerName)
+		})
+	}
+	return true, nil
+}
+
+// sendInitialWork sends the share difficulty and the current job to a newly authorized worker
+func (s *session) sendInitialWork() {
+	s.lock.Lock()
+	shareDifficulty := s.shareDifficulty
+	s.lock.Unlock()
+
+	if !s.send(newNotification(methodSetDifficulty, shareDifficulty)) {
+		return
+	}
+	notification := s.server.currentJobNotification()
+	if notification != nil {
+		s.send(notification)
+	}
+}
+
+// retargetShareDifficulty adjusts the share difficulty of the session according to its recent
+// share rate, and notifies the miner if it changed
+func (s *session) retargetShareDifficulty() {
+	s.lock.Lock()
+	if s.workerName == "" {
+		s.lock.Unlock()
+		return
+	}
+	now := time.Now()
+	cfg := s.server.cfg
+	nextDifficulty := nextShareDifficulty(s.shareDifficulty, s.sharesSinceRetarget,
+		now.Sub(s.lastShareDifficultyTime), cfg.TargetSharesPerMinute, cfg.MinimumShareDifficulty)
+	s.sharesSinceRetarget = 0
+	s.lastShareDifficultyTime = now
+	changed := nextDifficulty != s.shareDifficulty
+	s.shareDifficulty = nextDifficulty
+	s.shareTarget = shareTarget(nextDifficulty)
+	s.lock.Unlock()
+
+	if changed {
+		log.Debugf("Share difficulty of %s changed to %f", s.conn.RemoteAddr(), nextDifficulty)
+		s.send(newNotification(methodSetDifficulty, nextDifficulty))
+	}
+}
+
+func (s *session) isAuthorized() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.workerName != ""
+}
+
+func (s *session) extranonceHex() string {
+	return fmt.Sprintf("%0*x", 2*extranonceSize, s.extranonce)
+}
+
+// send writes the given message to the client, and returns false if the connection failed
+func (s *session) send(message interface{}) bool {
+	serializedMessage, err := json.Marshal(message)
+	if err != nil {
+		log.Errorf("Error serializing a stratum message: %s", err)
+		return false
+	}
+
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	err = s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if err == nil {
+		_, err = s.conn.Write(append(serializedMessage, '\n'))
+	}
+	if err != nil {
+		log.Debugf("Error writing to %s: %s", s.conn.RemoteAddr(), err)
+		s.conn.Close()
+		return false
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	nativeerrors "errors"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/cmd/kaspastratum/stratum"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/kaspanet/kaspad/util"
+	"github.com/pkg/errors"
+)
+
+// templatesLoop keeps the stratum server's job up to date with the block templates of kaspad
+func templatesLoop(client *stratumClient, server *stratum.Server, miningAddr util.Address,
+	mineWhenNotSynced bool, errChan chan error) {
+
+	getBlockTemplate := func() {
+		template, err := client.GetBlockTemplate(miningAddr.String())
+		if nativeerrors.Is(err, router.ErrTimeout) {
+			log.Warnf("Got timeout while requesting block template from %s: %s", client.Address(), err)
+			reconnectErr := client.Reconnect()
+			if reconnectErr != nil {
+				errChan <- reconnectErr
+			}
+			return
+		}
+		if nativeerrors.Is(err, router.ErrRouteClosed) {
+			log.Debugf("Got route is closed while requesting block template from %s. "+
+				"The client is most likely reconnecting", client.Address())
+			return
+		}
+		if err != nil {
+			errChan <- errors.Wrapf(err, "Error getting block template from %s", client.Address())
+			return
+		}
+		if !template.IsSynced && !mineWhenNotSynced {
+			log.Warnf("Kaspad is not synced. Skipping current block template")
+			return
+		}
+		block, err := appmessage.RPCBlockToDomainBlock(template.Block)
+		if err != nil {
+			errChan <- errors.Wrapf(err, "Error parsing block template from %s", client.Address())
+			return
+		}
+		server.UpdateTemplate(block)
+	}
+
+	getBlockTemplate()
+	const tickerTime = time.Second
+	ticker := time.NewTicker(tickerTime)
+	for {
+		select {
+		case <-client.blockAddedNotificationChan:
+			getBlockTemplate()
+			ticker.Reset(tickerTime)
+		case <-ticker.C:
+			getBlockTemplate()
+		}
+	}
+}
@@ -226,4 +226,7 @@ func combineNetworkFlags(dst, src *config.NetworkFlags) {
 	if dst.OverrideDAGParamsFile == "" {
 		dst.OverrideDAGParamsFile = src.OverrideDAGParamsFile
 	}
+	if dst.NetParamsFile == "" {
+		dst.NetParamsFile = src.NetParamsFile
+	}
 }
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/blockheader"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/pow"
+	"github.com/kaspanet/kaspad/util/difficulty"
+	"github.com/kaspanet/kaspad/util/mstime"
+)
+
+// hashesPerBatch is the number of hashes a benchmark thread tries between checks of whether to stop
+const hashesPerBatch = 1000
+
+func benchmark(conf *benchmarkConfig) error {
+	threadCounts := conf.Threads
+	if len(threadCounts) == 0 {
+		threadCounts = defaultThreadCounts()
+	}
+
+	// The PoW of every header costs the same, so we benchmark with a random one
+	// that has the network's easiest target
+	state := pow.NewState(randomHeader(difficulty.BigToCompact(conf.NetParams().PowMax)))
+
+	fmt.Printf("Benchmarking for %s per thread count on %d CPUs\n", conf.Duration, runtime.NumCPU())
+	fmt.Printf("%8s %16s %18s\n", "Threads", "Khash/s", "Khash/s/thread")
+	for _, threads := range threadCounts {
+		hashRate := measureHashRate(state, threads, conf.Duration)
+		fmt.Printf("%8d %16.2f %18.2f\n", threads, hashRate/1000, hashRate/1000/float64(threads))
+	}
+	return nil
+}
+
+// defaultThreadCounts returns the powers of 2 up to the number of CPUs, followed by the number of CPUs itself
+func defaultThreadCounts() []int {
+	numCPU := runtime.NumCPU()
+	threadCounts := []int{}
+	for threads := 1; threads < numCPU; threads *= 2 {
+		threadCounts = append(threadCounts, threads)
+	}
+	return append(threadCounts, numCPU)
+}
+
+// measureHashRate returns the number of hashes per second the given number of threads try
+// when mining on the given state
+func measureHashRate(state *pow.State, threads int, duration time.Duration) float64 {
+	var shouldStop uint32
+	hashesTried := make([]uint64, threads)
+	waitGroup := sync.WaitGroup{}
+
+	start := time.Now()
+	for i := 0; i < threads; i++ {
+		i := i
+		threadState := *state
+		// Give every thread its own part of the nonce space
+		threadState.Nonce = uint64(i) << 32
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for atomic.LoadUint32(&shouldStop) == 0 {
+				for j := 0; j < hashesPerBatch; j++ {
+					threadState.IncrementNonce()
+					threadState.CheckProofOfWork()
+				}
+				hashesTried[i] += hashesPerBatch
+			}
+		}()
+	}
+	time.Sleep(duration)
+	atomic.StoreUint32(&shouldStop, 1)
+	waitGroup.Wait()
+	elapsed := time.Since(start)
+
+	totalHashesTried := uint64(0)
+	for _, threadHashesTried := range hashesTried {
+		totalHashesTried += threadHashesTried
+	}
+	return float64(totalHashesTried) / elapsed.Seconds()
+}
+
+func randomHeader(bits uint32) externalapi.MutableBlockHeader {
+	hashBytes := [externalapi.DomainHashSize]byte{}
+	rand.Read(hashBytes[:])
+	randomHash := externalapi.NewDomainHashFromByteArray(&hashBytes)
+
+	return blockheader.NewImmutableBlockHeader(0, []externalapi.BlockLevelParents{{randomHash}},
+		randomHash, &externalapi.DomainHash{}, &externalapi.DomainHash{},
+		mstime.Now().UnixMilliseconds(), bits, 0, 0, 0, big.NewInt(0), &externalapi.DomainHash{}).ToMutable()
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/pkg/errors"
+
+	"github.com/jessevdk/go-flags"
+)
+
+const (
+	benchmarkSubCmd = "benchmark"
+	verifySubCmd    = "verify"
+)
+
+const defaultBenchmarkDuration = 5 * time.Second
+
+type configFlags struct {
+	config.NetworkFlags
+}
+
+type benchmarkConfig struct {
+	Threads  []int         `long:"threads" short:"t" description:"Number of threads to benchmark with. Can be given multiple times (default: powers of 2 up to the number of CPUs)"`
+	Duration time.Duration `long:"duration" short:"d" description:"How long to run the benchmark for each number of threads (default: 5s)"`
+	config.NetworkFlags
+}
+
+type verifyConfig struct {
+	Header string `long:"header" description:"The header to verify, as JSON (e.g. the output of kaspactl GetBlock) or as hex"`
+	File   string `long:"file" short:"f" description:"A file containing the header to verify, as JSON or as hex. Use - to read from stdin"`
+	config.NetworkFlags
+}
+
+func parseCommandLine() (subCommand string, config interface{}) {
+	cfg := &configFlags{}
+	parser := flags.NewParser(cfg, flags.PrintErrors|flags.HelpFlag)
+
+	benchmarkConf := &benchmarkConfig{Duration: defaultBenchmarkDuration}
+	parser.AddCommand(benchmarkSubCmd, "Measures the PoW hash rate of this machine",
+		"Measures the PoW hash rate of this machine with different numbers of threads", benchmarkConf)
+
+	verifyConf := &verifyConfig{}
+	parser.AddCommand(verifySubCmd, "Verifies the PoW of a block header",
+		"Verifies the PoW of a block header, and prints its PoW value, target, difficulty and block level",
+		verifyConf)
+
+	_, err := parser.Parse()
+
+	if err != nil {
+		var flagsErr *flags.Error
+		if ok := errors.As(err, &flagsErr); ok && flagsErr.Type == flags.ErrHelp {
+			os.Exit(0)
+		} else {
+			os.Exit(1)
+		}
+	}
+
+	switch parser.Command.Active.Name {
+	case benchmarkSubCmd:
+		combineNetworkFlags(&benchmarkConf.NetworkFlags, &cfg.NetworkFlags)
+		err := benchmarkConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		for _, threads := range benchmarkConf.Threads {
+			if threads < 1 {
+				printErrorAndExit(errors.New("--threads must be at least 1"))
+			}
+		}
+		if benchmarkConf.Duration <= 0 {
+			printErrorAndExit(errors.New("--duration must be positive"))
+		}
+		config = benchmarkConf
+	case verifySubCmd:
+		combineNetworkFlags(&verifyConf.NetworkFlags, &cfg.NetworkFlags)
+		err := verifyConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		if (verifyConf.Header == "") == (verifyConf.File == "") {
+			printErrorAndExit(errors.New("exactly one of --header and --file is required"))
+		}
+		config = verifyConf
+	}
+
+	return parser.Command.Active.Name, config
+}
+
+func combineNetworkFlags(dst, src *config.NetworkFlags) {
+	dst.Testnet = dst.Testnet || src.Testnet
+	dst.Simnet = dst.Simnet || src.Simnet
+	dst.Devnet = dst.Devnet || src.Devnet
+	if dst.OverrideDAGParamsFile == "" {
+		dst.OverrideDAGParamsFile = src.OverrideDAGParamsFile
+	}
+	if dst.NetParamsFile == "" {
+		dst.NetParamsFile = src.NetParamsFile
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/database/serialization"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// parseHeader parses a block header given as JSON or as hex.
+//
+// JSON may be any kaspad message that contains a single block (such as the output of
+// kaspactl GetBlock or a SubmitBlock request), a block, or a bare header.
+// Hex is the header serialized the way kaspad stores it in its database.
+func parseHeader(headerString string) (externalapi.BlockHeader, error) {
+	headerString = strings.TrimSpace(headerString)
+	if strings.HasPrefix(headerString, "{") {
+		return parseJSONHeader(headerString)
+	}
+	return parseHexHeader(headerString)
+}
+
+func parseJSONHeader(headerJSON string) (externalapi.BlockHeader, error) {
+	rpcBlock, err := parseJSONBlock(headerJSON)
+	if err != nil {
+		return nil, err
+	}
+	// We only need the header, so there's no point in validating the transactions
+	rpcBlock.Transactions = nil
+
+	block, err := rpcBlockToDomainBlock(rpcBlock)
+	if err != nil {
+		return nil, err
+	}
+	return block.Header, nil
+}
+
+// parseJSONBlock parses the given JSON as a kaspad message, a block, or a header, in that order
+func parseJSONBlock(blockJSON string) (*protowire.RpcBlock, error) {
+	kaspadMessage := &protowire.KaspadMessage{}
+	err := protojson.Unmarshal([]byte(blockJSON), kaspadMessage)
+	if err == nil {
+		return blockFromKaspadMessage(kaspadMessage)
+	}
+
+	rpcBlock := &protowire.RpcBlock{}
+	err = protojson.Unmarshal([]byte(blockJSON), rpcBlock)
+	if err == nil && rpcBlock.Header != nil {
+		return rpcBlock, nil
+	}
+
+	rpcHeader := &protowire.RpcBlockHeader{}
+	err = protojson.Unmarshal([]byte(blockJSON), rpcHeader)
+	if err != nil {
+		return nil, errors.Wrap(err, "the JSON is neither a kaspad message, a block, nor a header")
+	}
+	return &protowire.RpcBlock{Header: rpcHeader}, nil
+}
+
+func blockFromKaspadMessage(kaspadMessage *protowire.KaspadMessage) (*protowire.RpcBlock, error) {
+	switch payload := kaspadMessage.Payload.(type) {
+	case *protowire.KaspadMessage_GetBlockResponse:
+		if payload.GetBlockResponse.Block != nil {
+			return payload.GetBlockResponse.Block, nil
+		}
+	case *protowire.KaspadMessage_SubmitBlockRequest:
+		if payload.SubmitBlockRequest.Block != nil {
+			return payload.SubmitBlockRequest.Block, nil
+		}
+	case *protowire.KaspadMessage_GetBlockTemplateResponse:
+		if payload.GetBlockTemplateResponse.Block != nil {
+			return payload.GetBlockTemplateResponse.Block, nil
+		}
+	}
+	return nil, errors.Errorf("the kaspad message doesn't contain a block")
+}
+
+// rpcBlockToDomainBlock converts the given protowire block using the conversions of SubmitBlock requests
+func rpcBlockToDomainBlock(rpcBlock *protowire.RpcBlock) (*externalapi.DomainBlock, error) {
+	kaspadMessage := &protowire.KaspadMessage{
+		Payload: &protowire.KaspadMessage_SubmitBlockRequest{
+			SubmitBlockRequest: &protowire.SubmitBlockRequestMessage{Block: rpcBlock},
+		},
+	}
+	message, err := kaspadMessage.ToAppMessage()
+	if err != nil {
+		return nil, err
+	}
+	return appmessage.RPCBlockToDomainBlock(message.(*appmessage.SubmitBlockRequestMessage).Block)
+}
+
+func parseHexHeader(headerHex string) (externalapi.BlockHeader, error) {
+	headerBytes, err := hex.DecodeString(headerHex)
+	if err != nil {
+		return nil, errors.Wrap(err, "the header is neither JSON nor valid hex")
+	}
+	dbHeader := &serialization.DbBlockHeader{}
+	err = proto.Unmarshal(headerBytes, dbHeader)
+	if err != nil {
+		return nil, errors.Wrap(err, "error deserializing the header")
+	}
+	return serialization.DbBlockHeaderToDomainBlockHeader(dbHeader)
+}
@@ -0,0 +1,21 @@
+package main
+
+import "github.com/pkg/errors"
+
+func main() {
+	subCmd, config := parseCommandLine()
+
+	var err error
+	switch subCmd {
+	case benchmarkSubCmd:
+		err = benchmark(config.(*benchmarkConfig))
+	case verifySubCmd:
+		err = verify(config.(*verifyConfig))
+	default:
+		err = errors.Errorf("Unknown sub-command '%s'\n", subCmd)
+	}
+
+	if err != nil {
+		printErrorAndExit(err)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/pow"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/kaspanet/kaspad/util/difficulty"
+	"github.com/pkg/errors"
+)
+
+func verify(conf *verifyConfig) error {
+	headerString := conf.Header
+	if conf.File != "" {
+		var headerBytes []byte
+		var err error
+		if conf.File == "-" {
+			headerBytes, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			headerBytes, err = ioutil.ReadFile(conf.File)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "error reading %s", conf.File)
+		}
+		headerString = string(headerBytes)
+	}
+
+	header, err := parseHeader(headerString)
+	if err != nil {
+		return err
+	}
+
+	params := conf.NetParams()
+	proofOfWorkValue, target, isValid, err := verifyHeader(header)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Block hash:        %s\n", consensushashing.HeaderHash(header))
+	fmt.Printf("Pre-PoW hash:      %s\n", pow.NewState(header.ToMutable()).PrePowHash())
+	fmt.Printf("Timestamp:         %d\n", header.TimeInMilliseconds())
+	fmt.Printf("Nonce:             %d (0x%016x)\n", header.Nonce(), header.Nonce())
+	fmt.Printf("PoW value:         %064x\n", proofOfWorkValue)
+	fmt.Printf("Target:            %064x\n", target)
+	fmt.Printf("Bits:              0x%08x\n", header.Bits())
+	fmt.Printf("Difficulty:        %.2f\n", difficultyRatio(target, params))
+	fmt.Printf("Network hashrate:  %s (for this target)\n", difficulty.GetHashrateString(target, params.TargetTimePerBlock))
+	fmt.Printf("Block level:       %d\n", pow.BlockLevel(header))
+	if target.Cmp(params.PowMax) > 0 {
+		fmt.Printf("Warning: the target is above the maximum target of %s\n", params.Name)
+	}
+	fmt.Printf("Valid PoW:         %t\n", isValid)
+
+	if !isValid {
+		return errors.New("the PoW of the header is invalid")
+	}
+	return nil
+}
+
+// verifyHeader returns the PoW value and the target of the given header, and whether the
+// PoW value meets the target
+func verifyHeader(header externalapi.BlockHeader) (proofOfWorkValue *big.Int, target *big.Int, isValid bool, err error) {
+	target, err = targetFromBits(header.Bits())
+	if err != nil {
+		return nil, nil, false, err
+	}
+	proofOfWorkValue = pow.NewState(header.ToMutable()).CalculateProofOfWorkValue()
+	return proofOfWorkValue, target, proofOfWorkValue.Cmp(target) <= 0, nil
+}
+
+// targetFromBits converts the given compact bits to a target. No PoW value can meet a target
+// that isn't positive, and the difficulty of such a target is undefined, so these are rejected.
+func targetFromBits(bits uint32) (*big.Int, error) {
+	target := difficulty.CompactToBig(bits)
+	if target.Sign() <= 0 {
+		return nil, errors.Errorf("bits 0x%08x represent the non-positive target %s", bits, target)
+	}
+	return target, nil
+}
+
+// difficultyRatio returns the difficulty of the given target as a multiple of the minimum
+// difficulty of the network, the same way kaspad reports it over RPC
+func difficultyRatio(target *big.Int, params *dagconfig.Params) float64 {
+	ratio, _ := new(big.Rat).SetFrac(params.PowMax, target).Float64()
+	return ratio
+}
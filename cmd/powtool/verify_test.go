@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus/database/serialization"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/blockheader"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/pow"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTargetFromBits(t *testing.T) {
+	tests := []struct {
+		name           string
+		bits           uint32
+		expectedTarget string
+		expectedError  bool
+	}{
+		{name: "zero", bits: 0, expectedError: true},
+		{name: "zero mantissa", bits: 0x1d000000, expectedError: true},
+		{name: "mantissa shifted out", bits: 0x01003456, expectedError: true},
+		{name: "negative", bits: 0x04923456, expectedError: true},
+		{name: "small exponent", bits: 0x01123456, expectedTarget: "12"},
+		{name: "maximal simnet target", bits: 0x207fffff,
+			expectedTarget: "7fffff0000000000000000000000000000000000000000000000000000000000"},
+		{name: "typical target", bits: 0x1d00ffff,
+			expectedTarget: "ffff0000000000000000000000000000000000000000000000000000"},
+	}
+
+	for _, test := range tests {
+		target, err := targetFromBits(test.bits)
+		if test.expectedError {
+			if err == nil {
+				t.Errorf("%s: expected an error, but got target %x", test.name, target)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+		if target.Text(16) != test.expectedTarget {
+			t.Errorf("%s: expected target %s, but got %x", test.name, test.expectedTarget, target)
+		}
+	}
+}
+
+func TestVerifyHeader(t *testing.T) {
+	const easyBits = 0x207fffff
+	validNonce := uint64(0)
+	for ; !pow.CheckProofOfWorkByBits(testHeader(easyBits, validNonce).ToMutable()); validNonce++ {
+	}
+	invalidNonce := uint64(0)
+	for ; pow.CheckProofOfWorkByBits(testHeader(easyBits, invalidNonce).ToMutable()); invalidNonce++ {
+	}
+
+	tests := []struct {
+		name          string
+		header        externalapi.BlockHeader
+		expectedValid bool
+		expectedError bool
+	}{
+		{name: "valid PoW", header: testHeader(easyBits, validNonce), expectedValid: true},
+		{name: "invalid PoW", header: testHeader(easyBits, invalidNonce), expectedValid: false},
+		{name: "hard target", header: testHeader(0x1d00ffff, validNonce), expectedValid: false},
+		{name: "zero bits", header: testHeader(0, validNonce), expectedError: true},
+		{name: "negative target", header: testHeader(0x04923456, validNonce), expectedError: true},
+	}
+
+	for _, test := range tests {
+		// Verify the header the way it's given on the command line
+		header, err := parseHeader(serializeTestHeader(t, test.header))
+		if err != nil {
+			t.Fatalf("%s: parseHeader: %s", test.name, err)
+		}
+
+		proofOfWorkValue, target, isValid, err := verifyHeader(header)
+		if test.expectedError {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+		if isValid != test.expectedValid {
+			t.Errorf("%s: expected the PoW to be valid: %t, but got: %t", test.name, test.expectedValid, isValid)
+		}
+		if isValid != (proofOfWorkValue.Cmp(target) <= 0) {
+			t.Errorf("%s: the PoW value %x and the target %x don't match the result", test.name, proofOfWorkValue, target)
+		}
+	}
+}
+
+func testHeader(bits uint32, nonce uint64) externalapi.BlockHeader {
+	parentHash := externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{1})
+	return blockheader.NewImmutableBlockHeader(0, []externalapi.BlockLevelParents{{parentHash}},
+		&externalapi.DomainHash{}, &externalapi.DomainHash{}, &externalapi.DomainHash{},
+		1234, bits, nonce, 1, 1, big.NewInt(1), &externalapi.DomainHash{})
+}
+
+func serializeTestHeader(t *testing.T, header externalapi.BlockHeader) string {
+	headerBytes, err := proto.Marshal(serialization.DomainBlockHeaderToDbBlockHeader(header))
+	if err != nil {
+		t.Fatalf("Marshal: %s", err)
+	}
+	return hex.EncodeToString(headerBytes)
+}
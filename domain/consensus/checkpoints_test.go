@@ -0,0 +1,114 @@
+package consensus_test
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/model/testapi"
+	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/testutils"
+	"github.com/pkg/errors"
+)
+
+func TestCheckpoints(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		// Set the finality depth to 10 blocks, so that checkpoints are enforced after a few blocks
+		consensusConfig.FinalityDuration = 10 * consensusConfig.TargetTimePerBlock
+		finalityDepth := consensusConfig.FinalityDepth()
+
+		factory := consensus.NewFactory()
+
+		// buildChain builds a chain of the given length on top of genesis in a consensus of
+		// its own, and returns its blocks
+		buildChain := func(testName string, extraData []byte, length int) []*externalapi.DomainBlock {
+			tc, teardown, err := factory.NewTestConsensus(consensusConfig, testName)
+			if err != nil {
+				t.Fatalf("Error setting up consensus: %+v", err)
+			}
+			defer teardown(false)
+
+			coinbaseData := &externalapi.DomainCoinbaseData{
+				ScriptPublicKey: &externalapi.ScriptPublicKey{
+					Script:  nil,
+					Version: 0,
+				},
+				ExtraData: extraData,
+			}
+			blocks := make([]*externalapi.DomainBlock, 0, length)
+			tipHash := consensusConfig.GenesisHash
+			for i := 0; i < length; i++ {
+				tipHash, _, err = tc.AddBlock([]*externalapi.DomainHash{tipHash}, coinbaseData, nil)
+				if err != nil {
+					t.Fatalf("AddBlock: %+v", err)
+				}
+				block, err := tc.GetBlock(tipHash)
+				if err != nil {
+					t.Fatalf("GetBlock: %+v", err)
+				}
+				blocks = append(blocks, block)
+			}
+			return blocks
+		}
+
+		const chainLength = 30
+		checkpointedChain := buildChain("TestCheckpoints_checkpointed", []byte("checkpointed"), chainLength)
+		conflictingChain := buildChain("TestCheckpoints_conflicting", []byte("conflicting"), chainLength)
+
+		checkpointBlock := checkpointedChain[4]
+		checkpoint := externalapi.Checkpoint{
+			BlueScore: checkpointBlock.Header.BlueScore(),
+			Hash:      consensushashing.BlockHash(checkpointBlock),
+		}
+		consensusConfig.Checkpoints = []externalapi.Checkpoint{checkpoint}
+
+		// syncConflictingChain inserts the conflicting chain, and makes sure it is rejected
+		// once its finality point passes the checkpoint
+		syncConflictingChain := func(tc testapi.TestConsensus) {
+			for _, block := range conflictingChain {
+				_, err := tc.ValidateAndInsertBlock(block, true)
+				if err == nil {
+					continue
+				}
+				if !errors.Is(err, ruleerrors.ErrViolatingCheckpoint) {
+					t.Fatalf("Expected ErrViolatingCheckpoint, but got: %+v", err)
+				}
+				if block.Header.BlueScore() < checkpoint.BlueScore+finalityDepth {
+					t.Fatalf("Block with blue score %d was rejected even though its finality point "+
+						"is below the checkpoint", block.Header.BlueScore())
+				}
+				return
+			}
+			t.Fatalf("Expected the conflicting chain to be rejected")
+		}
+
+		syncCheckpointedChain := func(tc testapi.TestConsensus) {
+			for i, block := range checkpointedChain {
+				_, err := tc.ValidateAndInsertBlock(block, true)
+				if err != nil {
+					t.Fatalf("Failed to insert block #%d of the checkpointed chain: %+v", i, err)
+				}
+			}
+		}
+
+		// A node that already has the checkpoint rejects the conflicting chain
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestCheckpoints_checkpointedFirst")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+		syncCheckpointedChain(tc)
+		syncConflictingChain(tc)
+
+		// A node that syncs from a conflicting peer first rejects its chain even though it doesn't know
+		// the checkpoint, and still accepts the checkpointed chain afterwards
+		tc, teardown, err = factory.NewTestConsensus(consensusConfig, "TestCheckpoints_conflictingFirst")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+		syncConflictingChain(tc)
+		syncCheckpointedChain(tc)
+	})
+}
@@ -54,6 +54,7 @@ type consensus struct {
 	finalityStore             model.FinalityStore
 	headersSelectedChainStore model.HeadersSelectedChainStore
 	daaBlocksStore            model.DAABlocksStore
+	subnetworkStore           model.SubnetworkStore
 }
 
 func (s *consensus) ValidateAndInsertBlockWithTrustedData(block *externalapi.BlockWithTrustedData, validateUTXO bool) (*externalapi.BlockInsertionResult, error) {
@@ -288,6 +289,9 @@ func (s *consensus) GetBlockInfo(blockHash *externalapi.DomainHash) (*externalap
 
 	blockInfo.BlueScore = ghostdagData.BlueScore()
 	blockInfo.BlueWork = ghostdagData.BlueWork()
+	blockInfo.SelectedParent = ghostdagData.SelectedParent()
+	blockInfo.MergeSetBlues = ghostdagData.MergeSetBlues()
+	blockInfo.MergeSetReds = ghostdagData.MergeSetReds()
 
 	return blockInfo, nil
 }
@@ -541,6 +545,67 @@ func (s *consensus) GetVirtualDAAScore() (uint64, error) {
 	return s.daaBlocksStore.DAAScore(s.databaseContext, stagingArea, model.VirtualBlockHash)
 }
 
+func (s *consensus) GetSubnetworkGasLimit(subnetworkID *externalapi.DomainSubnetworkID) (
+	gasLimit uint64, exists bool, err error) {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	stagingArea := model.NewStagingArea()
+
+	exists, err = s.subnetworkStore.Exists(s.databaseContext, stagingArea, subnetworkID)
+	if err != nil || !exists {
+		return 0, false, err
+	}
+	gasLimit, err = s.subnetworkStore.GasLimit(s.databaseContext, stagingArea, subnetworkID)
+	if err != nil {
+		return 0, false, err
+	}
+	return gasLimit, true, nil
+}
+
+// GetPruningPointSubnetworkRegistrations returns the subnetworks that were registered by
+// the selected parent chain up to and including the pruning point
+func (s *consensus) GetPruningPointSubnetworkRegistrations(expectedPruningPointHash *externalapi.DomainHash) (
+	[]*externalapi.SubnetworkRegistration, error) {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	stagingArea := model.NewStagingArea()
+
+	pruningPointHash, err := s.pruningStore.PruningPoint(s.databaseContext, stagingArea)
+	if err != nil {
+		return nil, err
+	}
+
+	if !expectedPruningPointHash.Equal(pruningPointHash) {
+		return nil, errors.Wrapf(ruleerrors.ErrWrongPruningPointHash, "expected pruning point %s but got %s",
+			expectedPruningPointHash,
+			pruningPointHash)
+	}
+
+	pruningPointGHOSTDAGData, err := s.ghostdagDataStores[0].Get(s.databaseContext, stagingArea, pruningPointHash, false)
+	if err != nil {
+		return nil, err
+	}
+	return s.subnetworkStore.Registrations(s.databaseContext, stagingArea, pruningPointGHOSTDAGData.BlueScore())
+}
+
+// ImportPruningPointSubnetworkRegistrations registers the given subnetworks, which were
+// registered by the selected parent chain up to and including the imported pruning point.
+// Reorgs never go below the pruning point, so these registrations are never reverted.
+func (s *consensus) ImportPruningPointSubnetworkRegistrations(registrations []*externalapi.SubnetworkRegistration) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	stagingArea := model.NewStagingArea()
+	for _, registration := range registrations {
+		s.subnetworkStore.Stage(stagingArea, &registration.SubnetworkID, registration.GasLimit, 0)
+	}
+	return staging.CommitAllChanges(s.databaseContext, stagingArea)
+}
+
 func (s *consensus) CreateBlockLocatorFromPruningPoint(highHash *externalapi.DomainHash, limit uint32) (externalapi.BlockLocator, error) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
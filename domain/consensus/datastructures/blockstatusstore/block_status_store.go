@@ -7,6 +7,7 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/lrucache"
 	"github.com/kaspanet/kaspad/util/staging"
+	"github.com/pkg/errors"
 )
 
 var bucketName = []byte("block-statuses")
@@ -82,6 +83,62 @@ func (bss *blockStatusStore) Exists(dbContext model.DBReader, stagingArea *model
 	return exists, nil
 }
 
+type allBlockHashesIterator struct {
+	cursor   model.DBCursor
+	isClosed bool
+}
+
+func (a *allBlockHashesIterator) First() bool {
+	if a.isClosed {
+		panic("Tried using a closed AllBlockHashesIterator")
+	}
+	return a.cursor.First()
+}
+
+func (a *allBlockHashesIterator) Next() bool {
+	if a.isClosed {
+		panic("Tried using a closed AllBlockHashesIterator")
+	}
+	return a.cursor.Next()
+}
+
+func (a *allBlockHashesIterator) Get() (*externalapi.DomainHash, error) {
+	if a.isClosed {
+		return nil, errors.New("Tried using a closed AllBlockHashesIterator")
+	}
+	key, err := a.cursor.Key()
+	if err != nil {
+		return nil, err
+	}
+
+	blockHashBytes := key.Suffix()
+	return externalapi.NewDomainHashFromByteSlice(blockHashBytes)
+}
+
+func (a *allBlockHashesIterator) Close() error {
+	if a.isClosed {
+		return errors.New("Tried using a closed AllBlockHashesIterator")
+	}
+	a.isClosed = true
+	err := a.cursor.Close()
+	if err != nil {
+		return err
+	}
+	a.cursor = nil
+	return nil
+}
+
+// AllBlockHashesIterator returns an iterator over the hashes of all the blocks
+// that have a status in the database. Staged statuses are not included.
+func (bss *blockStatusStore) AllBlockHashesIterator(dbContext model.DBReader) (model.BlockIterator, error) {
+	cursor, err := dbContext.Cursor(bss.bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	return &allBlockHashesIterator{cursor: cursor}, nil
+}
+
 func (bss *blockStatusStore) serializeBlockStatus(status externalapi.BlockStatus) ([]byte, error) {
 	dbBlockStatus := serialization.DomainBlockStatusToDbBlockStatus(status)
 	return proto.Marshal(dbBlockStatus)
@@ -0,0 +1,56 @@
+package subnetworkstore
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+type subnetworkStagingShard struct {
+	store              *subnetworkStore
+	toAdd              map[externalapi.DomainSubnetworkID]*registration
+	toDelete           map[externalapi.DomainSubnetworkID]struct{}
+	isRegistryBuiltSet bool
+}
+
+func (ss *subnetworkStore) stagingShard(stagingArea *model.StagingArea) *subnetworkStagingShard {
+	return stagingArea.GetOrCreateShard(ss.shardID, func() model.StagingShard {
+		return &subnetworkStagingShard{
+			store:    ss,
+			toAdd:    make(map[externalapi.DomainSubnetworkID]*registration),
+			toDelete: make(map[externalapi.DomainSubnetworkID]struct{}),
+		}
+	}).(*subnetworkStagingShard)
+}
+
+func (sss *subnetworkStagingShard) Commit(dbTx model.DBTransaction) error {
+	for subnetworkID, registration := range sss.toAdd {
+		subnetworkID := subnetworkID
+		err := dbTx.Put(sss.store.subnetworkIDAsKey(&subnetworkID), registration.serialize())
+		if err != nil {
+			return err
+		}
+		sss.store.cache[subnetworkID] = registration
+	}
+
+	for subnetworkID := range sss.toDelete {
+		subnetworkID := subnetworkID
+		err := dbTx.Delete(sss.store.subnetworkIDAsKey(&subnetworkID))
+		if err != nil {
+			return err
+		}
+		delete(sss.store.cache, subnetworkID)
+	}
+
+	if sss.isRegistryBuiltSet {
+		err := dbTx.Put(sss.store.registryBuiltKey, []byte{})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (sss *subnetworkStagingShard) isStaged() bool {
+	return len(sss.toAdd) != 0 || len(sss.toDelete) != 0 || sss.isRegistryBuiltSet
+}
@@ -0,0 +1,229 @@
+package subnetworkstore
+
+import (
+	"encoding/binary"
+
+	"github.com/kaspanet/kaspad/domain/consensus/database"
+	"github.com/kaspanet/kaspad/domain/consensus/model"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/util/staging"
+	"github.com/pkg/errors"
+)
+
+var bucketName = []byte("subnetworks")
+var registryBuiltKeyName = []byte("subnetwork-registry-built")
+
+// registration is the stored data of a registered subnetwork. acceptingBlueScore is the
+// blue score of the selected chain block that accepted the subnetwork's registry transaction.
+type registration struct {
+	gasLimit           uint64
+	acceptingBlueScore uint64
+}
+
+// subnetworkStore represents a store of the registered subnetworks. There are few subnetworks,
+// so all the ones that were accessed are kept in memory.
+type subnetworkStore struct {
+	shardID          model.StagingShardID
+	cache            map[externalapi.DomainSubnetworkID]*registration
+	bucket           model.DBBucket
+	registryBuiltKey model.DBKey
+}
+
+// New instantiates a new SubnetworkStore
+func New(prefixBucket model.DBBucket) model.SubnetworkStore {
+	return &subnetworkStore{
+		shardID:          staging.GenerateShardingID(),
+		cache:            make(map[externalapi.DomainSubnetworkID]*registration),
+		bucket:           prefixBucket.Bucket(bucketName),
+		registryBuiltKey: prefixBucket.Key(registryBuiltKeyName),
+	}
+}
+
+// Stage stages the given subnetwork with the given gas limit and the blue score of the
+// selected chain block that accepted its registry transaction
+func (ss *subnetworkStore) Stage(stagingArea *model.StagingArea, subnetworkID *externalapi.DomainSubnetworkID,
+	gasLimit uint64, acceptingBlueScore uint64) {
+
+	stagingShard := ss.stagingShard(stagingArea)
+
+	delete(stagingShard.toDelete, *subnetworkID)
+	stagingShard.toAdd[*subnetworkID] = &registration{
+		gasLimit:           gasLimit,
+		acceptingBlueScore: acceptingBlueScore,
+	}
+}
+
+// Delete stages the given subnetwork for removal from the registry
+func (ss *subnetworkStore) Delete(stagingArea *model.StagingArea, subnetworkID *externalapi.DomainSubnetworkID) {
+	stagingShard := ss.stagingShard(stagingArea)
+
+	delete(stagingShard.toAdd, *subnetworkID)
+	stagingShard.toDelete[*subnetworkID] = struct{}{}
+}
+
+// StageRegistryBuilt stages that the registry had been built from the selected parent chain
+func (ss *subnetworkStore) StageRegistryBuilt(stagingArea *model.StagingArea) {
+	ss.stagingShard(stagingArea).isRegistryBuiltSet = true
+}
+
+func (ss *subnetworkStore) IsStaged(stagingArea *model.StagingArea) bool {
+	return ss.stagingShard(stagingArea).isStaged()
+}
+
+// GasLimit gets the gas limit of the given subnetwork
+func (ss *subnetworkStore) GasLimit(dbContext model.DBReader, stagingArea *model.StagingArea,
+	subnetworkID *externalapi.DomainSubnetworkID) (uint64, error) {
+
+	registration, err := ss.registration(dbContext, stagingArea, subnetworkID)
+	if err != nil {
+		return 0, err
+	}
+	return registration.gasLimit, nil
+}
+
+// Exists returns whether the given subnetwork is registered
+func (ss *subnetworkStore) Exists(dbContext model.DBReader, stagingArea *model.StagingArea,
+	subnetworkID *externalapi.DomainSubnetworkID) (bool, error) {
+
+	stagingShard := ss.stagingShard(stagingArea)
+
+	if _, ok := stagingShard.toAdd[*subnetworkID]; ok {
+		return true, nil
+	}
+
+	if _, ok := stagingShard.toDelete[*subnetworkID]; ok {
+		return false, nil
+	}
+
+	if _, ok := ss.cache[*subnetworkID]; ok {
+		return true, nil
+	}
+
+	return dbContext.Has(ss.subnetworkIDAsKey(subnetworkID))
+}
+
+// Registrations returns all the registered subnetworks whose registry transaction was
+// accepted by a selected chain block with a blue score of at most maxAcceptingBlueScore
+func (ss *subnetworkStore) Registrations(dbContext model.DBReader, stagingArea *model.StagingArea,
+	maxAcceptingBlueScore uint64) ([]*externalapi.SubnetworkRegistration, error) {
+
+	stagingShard := ss.stagingShard(stagingArea)
+
+	registrations := make(map[externalapi.DomainSubnetworkID]*registration)
+	cursor, err := dbContext.Cursor(ss.bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close()
+
+	for ok := cursor.First(); ok; ok = cursor.Next() {
+		key, err := cursor.Key()
+		if err != nil {
+			return nil, err
+		}
+		var subnetworkID externalapi.DomainSubnetworkID
+		if len(key.Suffix()) != len(subnetworkID) {
+			return nil, errors.Errorf("unexpected subnetwork ID length %d", len(key.Suffix()))
+		}
+		copy(subnetworkID[:], key.Suffix())
+
+		registrationBytes, err := cursor.Value()
+		if err != nil {
+			return nil, err
+		}
+		registration, err := deserializeRegistration(registrationBytes)
+		if err != nil {
+			return nil, err
+		}
+		registrations[subnetworkID] = registration
+	}
+
+	for subnetworkID := range stagingShard.toDelete {
+		delete(registrations, subnetworkID)
+	}
+	for subnetworkID, registration := range stagingShard.toAdd {
+		registrations[subnetworkID] = registration
+	}
+
+	result := make([]*externalapi.SubnetworkRegistration, 0, len(registrations))
+	for subnetworkID, registration := range registrations {
+		if registration.acceptingBlueScore > maxAcceptingBlueScore {
+			continue
+		}
+		result = append(result, &externalapi.SubnetworkRegistration{
+			SubnetworkID: subnetworkID,
+			GasLimit:     registration.gasLimit,
+		})
+	}
+	return result, nil
+}
+
+// IsRegistryBuilt returns whether the registry had been built from the selected parent chain.
+// Databases that were created before the registry followed the selected parent chain have
+// to rebuild it.
+func (ss *subnetworkStore) IsRegistryBuilt(dbContext model.DBReader) (bool, error) {
+	return dbContext.Has(ss.registryBuiltKey)
+}
+
+func (ss *subnetworkStore) registration(dbContext model.DBReader, stagingArea *model.StagingArea,
+	subnetworkID *externalapi.DomainSubnetworkID) (*registration, error) {
+
+	stagingShard := ss.stagingShard(stagingArea)
+
+	if registration, ok := stagingShard.toAdd[*subnetworkID]; ok {
+		return registration, nil
+	}
+
+	if _, ok := stagingShard.toDelete[*subnetworkID]; ok {
+		return nil, errors.Wrapf(database.ErrNotFound, "subnetwork %s is staged for deletion", subnetworkID)
+	}
+
+	if registration, ok := ss.cache[*subnetworkID]; ok {
+		return registration, nil
+	}
+
+	registrationBytes, err := dbContext.Get(ss.subnetworkIDAsKey(subnetworkID))
+	if err != nil {
+		return nil, err
+	}
+	registration, err := deserializeRegistration(registrationBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	ss.cache[*subnetworkID] = registration
+	return registration, nil
+}
+
+func (ss *subnetworkStore) subnetworkIDAsKey(subnetworkID *externalapi.DomainSubnetworkID) model.DBKey {
+	return ss.bucket.Key(subnetworkID[:])
+}
+
+const (
+	legacyRegistrationLength = 8
+	registrationLength       = 16
+)
+
+func (r *registration) serialize() []byte {
+	registrationBytes := make([]byte, registrationLength)
+	binary.LittleEndian.PutUint64(registrationBytes[:8], r.gasLimit)
+	binary.LittleEndian.PutUint64(registrationBytes[8:], r.acceptingBlueScore)
+	return registrationBytes
+}
+
+func deserializeRegistration(registrationBytes []byte) (*registration, error) {
+	switch len(registrationBytes) {
+	case registrationLength:
+		return &registration{
+			gasLimit:           binary.LittleEndian.Uint64(registrationBytes[:8]),
+			acceptingBlueScore: binary.LittleEndian.Uint64(registrationBytes[8:]),
+		}, nil
+	case legacyRegistrationLength:
+		// Registrations that were stored before the registry was built from the selected parent
+		// chain hold only the gas limit. They are removed when the registry is rebuilt.
+		return &registration{gasLimit: binary.LittleEndian.Uint64(registrationBytes)}, nil
+	default:
+		return nil, errors.Errorf("the given registration is %d bytes so it cannot be deserialized",
+			len(registrationBytes))
+	}
+}
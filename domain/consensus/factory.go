@@ -7,7 +7,6 @@ import (
 	parentssanager "github.com/kaspanet/kaspad/domain/consensus/processes/parentsmanager"
 	"github.com/kaspanet/kaspad/domain/consensus/processes/pruningproofmanager"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
-	"io/ioutil"
 	"os"
 	"sync"
 
@@ -28,6 +27,7 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/datastructures/multisetstore"
 	"github.com/kaspanet/kaspad/domain/consensus/datastructures/pruningstore"
 	"github.com/kaspanet/kaspad/domain/consensus/datastructures/reachabilitydatastore"
+	"github.com/kaspanet/kaspad/domain/consensus/datastructures/subnetworkstore"
 	"github.com/kaspanet/kaspad/domain/consensus/datastructures/utxodiffstore"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/model/testapi"
@@ -51,6 +51,8 @@ import (
 	"github.com/kaspanet/kaspad/domain/dagconfig"
 	infrastructuredatabase "github.com/kaspanet/kaspad/infrastructure/db/database"
 	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/memdb"
+	"github.com/kaspanet/kaspad/util/mstime"
 )
 
 const (
@@ -66,14 +68,22 @@ type Config struct {
 	IsArchival bool
 	// EnableSanityCheckPruningUTXOSet checks the full pruning point utxo set against the commitment at every pruning movement
 	EnableSanityCheckPruningUTXOSet bool
+	// HeadersOnly tells the consensus that it's only given block headers, so that its pruning
+	// point follows the headers selected tip instead of the virtual
+	HeadersOnly bool
 
 	SkipAddingGenesis bool
+
+	// Clock is the source of the current time for consensus. The system clock is used if it's nil
+	Clock mstime.Clock
 }
 
 // Factory instantiates new Consensuses
 type Factory interface {
 	NewConsensus(config *Config, db infrastructuredatabase.Database, dbPrefix *prefix.Prefix) (
 		externalapi.Consensus, error)
+	NewReadOnlyConsensus(config *Config, db infrastructuredatabase.Database, dbPrefix *prefix.Prefix) (
+		externalapi.Consensus, error)
 	NewTestConsensus(config *Config, testName string) (
 		tc testapi.TestConsensus, teardown func(keepDataDir bool), err error)
 
@@ -107,9 +117,57 @@ func NewFactory() Factory {
 func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Database, dbPrefix *prefix.Prefix) (
 	externalapi.Consensus, error) {
 
+	c, err := f.newConsensus(config, db, dbPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.Init(config.SkipAddingGenesis)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.consensusStateManager.RecoverUTXOIfRequired()
+	if err != nil {
+		return nil, err
+	}
+	err = c.consensusStateManager.RebuildSubnetworkRegistryIfRequired()
+	if err != nil {
+		return nil, err
+	}
+	err = c.pruningManager.ClearImportedPruningPointData()
+	if err != nil {
+		return nil, err
+	}
+	err = c.pruningManager.UpdatePruningPointIfRequired()
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// NewReadOnlyConsensus instantiates a Consensus over the existing data in the given database
+// for inspection only. Unlike NewConsensus, it doesn't initialize the consensus, recover it
+// or move its pruning point, and every attempt to write through it fails.
+func (f *factory) NewReadOnlyConsensus(config *Config, db infrastructuredatabase.Database, dbPrefix *prefix.Prefix) (
+	externalapi.Consensus, error) {
+
+	return f.newConsensus(config, infrastructuredatabase.NewReadOnlyDatabase(db), dbPrefix)
+}
+
+// newConsensus builds a consensus and all of its stores and managers, without touching the database
+func (f *factory) newConsensus(config *Config, db infrastructuredatabase.Database, dbPrefix *prefix.Prefix) (
+	*consensus, error) {
+
 	dbManager := consensusdatabase.New(db)
 	prefixBucket := consensusdatabase.MakeBucket(dbPrefix.Serialize())
 
+	clock := config.Clock
+	if clock == nil {
+		clock = mstime.NewSystemClock()
+	}
+
 	pruningWindowSizeForCaches := int(config.PruningDepth())
 
 	var preallocateCaches bool
@@ -145,6 +203,7 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 	finalityStore := finalitystore.New(prefixBucket, 200, preallocateCaches)
 	headersSelectedChainStore := headersselectedchainstore.New(prefixBucket, pruningWindowSizeForCaches, preallocateCaches)
 	daaBlocksStore := daablocksstore.New(prefixBucket, pruningWindowSizeForCaches, int(config.FinalityDepth()), preallocateCaches)
+	subnetworkStore := subnetworkstore.New(prefixBucket)
 
 	blockRelationStores, reachabilityDataStores, ghostdagDataStores := dagStores(config, prefixBucket, pruningWindowSizePlusFinalityDepthForCache, pruningWindowSizeForCaches, preallocateCaches)
 	reachabilityManagers, dagTopologyManagers, ghostdagManagers, dagTraversalManagers := f.dagProcesses(config, dbManager, blockHeaderStore, daaWindowStore, blockRelationStores, reachabilityDataStores, ghostdagDataStores)
@@ -183,6 +242,7 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 		config.MassPerScriptPubKeyByte,
 		config.MassPerSigOp,
 		config.MaxCoinbasePayloadLength,
+		config.ScriptPublicKeyVersion1ActivationDAAScore,
 		dbManager,
 		pastMedianTimeManager,
 		ghostdagDataStore,
@@ -240,6 +300,7 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 		config.MaxBlockParents,
 		config.MergeSetSizeLimit,
 		genesisHash,
+		config.EnableNonNativeSubnetworks,
 
 		ghostdagManager,
 		dagTopologyManager,
@@ -262,7 +323,8 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 		blockHeaderStore,
 		headersSelectedTipStore,
 		pruningStore,
-		daaBlocksStore)
+		daaBlocksStore,
+		subnetworkStore)
 	if err != nil {
 		return nil, err
 	}
@@ -307,6 +369,8 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 		config.MaxBlockParents,
 		config.TimestampDeviationTolerance,
 		config.TargetTimePerBlock,
+		clock,
+		config.Checkpoints,
 
 		dbManager,
 		difficultyManager,
@@ -352,6 +416,7 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 	blockBuilder := blockbuilder.New(
 		dbManager,
 		genesisHash,
+		clock,
 
 		difficultyManager,
 		pastMedianTimeManager,
@@ -373,6 +438,7 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 	blockProcessor := blockprocessor.New(
 		genesisHash,
 		config.TargetTimePerBlock,
+		config.HeadersOnly,
 		dbManager,
 		consensusStateManager,
 		pruningManager,
@@ -464,24 +530,7 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 		finalityStore:             finalityStore,
 		headersSelectedChainStore: headersSelectedChainStore,
 		daaBlocksStore:            daaBlocksStore,
-	}
-
-	err = c.Init(config.SkipAddingGenesis)
-	if err != nil {
-		return nil, err
-	}
-
-	err = consensusStateManager.RecoverUTXOIfRequired()
-	if err != nil {
-		return nil, err
-	}
-	err = pruningManager.ClearImportedPruningPointData()
-	if err != nil {
-		return nil, err
-	}
-	err = pruningManager.UpdatePruningPointIfRequired()
-	if err != nil {
-		return nil, err
+		subnetworkStore:           subnetworkStore,
 	}
 
 	return c, nil
@@ -489,13 +538,6 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 
 func (f *factory) NewTestConsensus(config *Config, testName string) (
 	tc testapi.TestConsensus, teardown func(keepDataDir bool), err error) {
-	datadir := f.dataDir
-	if datadir == "" {
-		datadir, err = ioutil.TempDir("", testName)
-		if err != nil {
-			return nil, nil, err
-		}
-	}
 	var cacheSizeMiB int
 	if f.cacheSizeMiB != nil {
 		cacheSizeMiB = *f.cacheSizeMiB
@@ -505,9 +547,17 @@ func (f *factory) NewTestConsensus(config *Config, testName string) (
 	if f.preallocateCaches == nil {
 		f.SetTestPreAllocateCache(defaultTestPreallocateCaches)
 	}
-	db, err := ldb.NewLevelDB(datadir, cacheSizeMiB)
-	if err != nil {
-		return nil, nil, err
+
+	// Test consensuses keep their data in memory, which is much faster, unless
+	// they were explicitly given a data directory to keep it in
+	var db infrastructuredatabase.Database
+	if f.dataDir == "" {
+		db = memdb.NewMemDB()
+	} else {
+		db, err = ldb.NewLevelDB(f.dataDir, cacheSizeMiB)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
 	testConsensusDBPrefix := &prefix.Prefix{}
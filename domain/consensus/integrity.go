@@ -0,0 +1,404 @@
+package consensus
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/kaspanet/kaspad/domain/consensus/database"
+	"github.com/kaspanet/kaspad/domain/consensus/model"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/hashset"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/multiset"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+)
+
+// integrityChecker walks over the consensus stores and collects the inconsistencies it finds
+type integrityChecker struct {
+	*consensus
+	stagingArea *model.StagingArea
+	report      *externalapi.IntegrityReport
+}
+
+// CheckIntegrity goes over the stored consensus data and reports the inconsistencies
+// it finds in it. It's meant to find the damage left by a node that crashed in the middle
+// of a write, and is slow: it reads every block's data and the entire UTXO set.
+func (s *consensus) CheckIntegrity() (*externalapi.IntegrityReport, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	onEnd := logger.LogAndMeasureExecutionTime(log, "CheckIntegrity")
+	defer onEnd()
+
+	checker := &integrityChecker{
+		consensus:   s,
+		stagingArea: model.NewStagingArea(),
+		report:      &externalapi.IntegrityReport{},
+	}
+
+	log.Infof("Checking the block statuses and reachability data")
+	err := checker.checkBlocks()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Checking the virtual UTXO set")
+	err = checker.checkVirtualUTXOSet()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Checking the pruning point UTXO set")
+	err = checker.checkPruningPointUTXOSet()
+	if err != nil {
+		return nil, err
+	}
+
+	return checker.report, nil
+}
+
+func (ic *integrityChecker) addIssue(check string, blockHash *externalapi.DomainHash, format string, args ...interface{}) {
+	ic.report.Issues = append(ic.report.Issues, &externalapi.IntegrityIssue{
+		Check:       check,
+		BlockHash:   blockHash,
+		Description: fmt.Sprintf(format, args...),
+	})
+}
+
+func (ic *integrityChecker) checkBlocks() error {
+	iterator, err := ic.blockStatusStore.AllBlockHashesIterator(ic.databaseContext)
+	if err != nil {
+		return err
+	}
+	defer iterator.Close()
+
+	for ok := iterator.First(); ok; ok = iterator.Next() {
+		blockHash, err := iterator.Get()
+		if err != nil {
+			return err
+		}
+
+		// The reachability tree is rooted at the virtual genesis, so its reachability
+		// data is checked even though it's not a real block
+		if !blockHash.Equal(model.VirtualBlockHash) {
+			err = ic.checkReachability(blockHash)
+			if err != nil {
+				return err
+			}
+		}
+
+		if blockHash.Equal(model.VirtualBlockHash) || blockHash.Equal(model.VirtualGenesisBlockHash) {
+			continue
+		}
+		err = ic.checkBlockStatus(blockHash)
+		if err != nil {
+			return err
+		}
+		ic.report.CheckedBlocks++
+	}
+	return nil
+}
+
+// checkBlockStatus makes sure that the data stored for the given block matches its status,
+// and that its relations and GHOSTDAG data agree with each other
+func (ic *integrityChecker) checkBlockStatus(blockHash *externalapi.DomainHash) error {
+	const check = externalapi.IntegrityCheckBlockStatuses
+
+	status, err := ic.blockStatusStore.Get(ic.databaseContext, ic.stagingArea, blockHash)
+	if err != nil {
+		return err
+	}
+	// Invalid blocks are only kept so that they're not validated again
+	if status == externalapi.StatusInvalid {
+		return nil
+	}
+
+	if status != externalapi.StatusHeaderOnly {
+		hasBlock, err := ic.blockStore.HasBlock(ic.databaseContext, ic.stagingArea, blockHash)
+		if err != nil {
+			return err
+		}
+		if !hasBlock {
+			ic.addIssue(check, blockHash, "the block has status %s but its body is missing", status)
+		}
+	}
+
+	hasHeader, err := ic.blockHeaderStore.HasBlockHeader(ic.databaseContext, ic.stagingArea, blockHash)
+	if err != nil {
+		return err
+	}
+	if !hasHeader {
+		ic.addIssue(check, blockHash, "the block has status %s but its header is missing", status)
+	}
+	hasRelations, err := ic.blockRelationStores[0].Has(ic.databaseContext, ic.stagingArea, blockHash)
+	if err != nil {
+		return err
+	}
+	if !hasRelations {
+		ic.addIssue(check, blockHash, "the block has status %s but its relations are missing", status)
+	}
+	ghostdagData, err := ic.ghostdagDataStores[0].Get(ic.databaseContext, ic.stagingArea, blockHash, false)
+	hasGHOSTDAGData := err == nil
+	if err != nil {
+		if !database.IsNotFoundError(err) {
+			return err
+		}
+		ic.addIssue(check, blockHash, "the block has status %s but its GHOSTDAG data is missing", status)
+	}
+
+	if hasHeader && hasGHOSTDAGData {
+		header, err := ic.blockHeaderStore.BlockHeader(ic.databaseContext, ic.stagingArea, blockHash)
+		if err != nil {
+			return err
+		}
+		if header.BlueScore() != ghostdagData.BlueScore() || header.BlueWork().Cmp(ghostdagData.BlueWork()) != 0 {
+			ic.addIssue(check, blockHash, "the block header has blue score %d and blue work %s, but its "+
+				"GHOSTDAG data has blue score %d and blue work %s", header.BlueScore(), header.BlueWork(),
+				ghostdagData.BlueScore(), ghostdagData.BlueWork())
+		}
+	}
+
+	if !hasRelations {
+		return nil
+	}
+	relations, err := ic.blockRelationStores[0].BlockRelation(ic.databaseContext, ic.stagingArea, blockHash)
+	if err != nil {
+		return err
+	}
+
+	for _, parent := range relations.Parents {
+		if parent.Equal(model.VirtualGenesisBlockHash) {
+			continue
+		}
+		parentExists, err := ic.blockStatusStore.Exists(ic.databaseContext, ic.stagingArea, parent)
+		if err != nil {
+			return err
+		}
+		if !parentExists {
+			ic.addIssue(check, blockHash, "the block's parent %s is missing", parent)
+			continue
+		}
+		parentStatus, err := ic.blockStatusStore.Get(ic.databaseContext, ic.stagingArea, parent)
+		if err != nil {
+			return err
+		}
+		if parentStatus == externalapi.StatusInvalid {
+			ic.addIssue(check, blockHash, "the block has status %s but its parent %s is invalid", status, parent)
+		}
+		parentRelations, err := ic.blockRelationStores[0].BlockRelation(ic.databaseContext, ic.stagingArea, parent)
+		if err != nil {
+			if database.IsNotFoundError(err) {
+				// The parent is reported when it's checked itself
+				continue
+			}
+			return err
+		}
+		if !hashset.NewFromSlice(parentRelations.Children...).Contains(blockHash) {
+			ic.addIssue(check, blockHash, "the block's parent %s doesn't have it as a child", parent)
+		}
+	}
+
+	for _, child := range relations.Children {
+		if child.Equal(model.VirtualBlockHash) {
+			continue
+		}
+		childRelations, err := ic.blockRelationStores[0].BlockRelation(ic.databaseContext, ic.stagingArea, child)
+		if err != nil {
+			if database.IsNotFoundError(err) {
+				ic.addIssue(check, blockHash, "the relations of the block's child %s are missing", child)
+				continue
+			}
+			return err
+		}
+		if !hashset.NewFromSlice(childRelations.Parents...).Contains(blockHash) {
+			ic.addIssue(check, blockHash, "the block's child %s doesn't have it as a parent", child)
+		}
+	}
+
+	if !hasGHOSTDAGData || ghostdagData.SelectedParent().Equal(model.VirtualGenesisBlockHash) {
+		return nil
+	}
+	selectedParent := ghostdagData.SelectedParent()
+	selectedParentExists, err := ic.blockStatusStore.Exists(ic.databaseContext, ic.stagingArea, selectedParent)
+	if err != nil {
+		return err
+	}
+	// The selected parents of blocks that were received with trusted data may be unknown
+	if !selectedParentExists {
+		return nil
+	}
+	if !hashset.NewFromSlice(relations.Parents...).Contains(selectedParent) {
+		ic.addIssue(check, blockHash, "the block's selected parent %s is not one of its parents", selectedParent)
+	}
+	if status == externalapi.StatusUTXOValid {
+		selectedParentStatus, err := ic.blockStatusStore.Get(ic.databaseContext, ic.stagingArea, selectedParent)
+		if err != nil {
+			return err
+		}
+		if selectedParentStatus == externalapi.StatusDisqualifiedFromChain {
+			ic.addIssue(check, blockHash, "the block has status %s but its selected parent %s has status %s",
+				status, selectedParent, selectedParentStatus)
+		}
+	}
+	return nil
+}
+
+// checkReachability makes sure that the reachability interval of the given block
+// is allocated out of its tree parent's interval, and that its children's intervals
+// don't overlap
+func (ic *integrityChecker) checkReachability(blockHash *externalapi.DomainHash) error {
+	const check = externalapi.IntegrityCheckReachability
+
+	hasReachabilityData, err := ic.reachabilityDataStores[0].HasReachabilityData(
+		ic.databaseContext, ic.stagingArea, blockHash)
+	if err != nil {
+		return err
+	}
+	if !hasReachabilityData {
+		if !blockHash.Equal(model.VirtualGenesisBlockHash) {
+			status, err := ic.blockStatusStore.Get(ic.databaseContext, ic.stagingArea, blockHash)
+			if err != nil {
+				return err
+			}
+			if status != externalapi.StatusInvalid {
+				ic.addIssue(check, blockHash, "the block has status %s but its reachability data is missing", status)
+			}
+		}
+		return nil
+	}
+	reachabilityData, err := ic.reachabilityDataStores[0].ReachabilityData(
+		ic.databaseContext, ic.stagingArea, blockHash)
+	if err != nil {
+		return err
+	}
+	interval := reachabilityData.Interval()
+	// An interval may be empty, in which case its end is one before its start
+	if interval.Start > interval.End+1 {
+		ic.addIssue(check, blockHash, "the block's reachability interval %s is malformed", interval)
+		return nil
+	}
+
+	childIntervals := make([]*model.ReachabilityInterval, 0, len(reachabilityData.Children()))
+	for _, child := range reachabilityData.Children() {
+		childReachabilityData, err := ic.reachabilityDataStores[0].ReachabilityData(
+			ic.databaseContext, ic.stagingArea, child)
+		if err != nil {
+			if database.IsNotFoundError(err) {
+				ic.addIssue(check, blockHash, "the reachability data of the block's reachability tree "+
+					"child %s is missing", child)
+				continue
+			}
+			return err
+		}
+		if childReachabilityData.Parent() == nil || !childReachabilityData.Parent().Equal(blockHash) {
+			ic.addIssue(check, blockHash, "the block's reachability tree child %s has a different "+
+				"tree parent %s", child, childReachabilityData.Parent())
+		}
+		childInterval := childReachabilityData.Interval()
+		if childInterval.Start < interval.Start || childInterval.End > interval.End {
+			ic.addIssue(check, blockHash, "the reachability interval %s of the block's child %s is not "+
+				"contained in the block's interval %s", childInterval, child, interval)
+		}
+		childIntervals = append(childIntervals, childInterval)
+	}
+
+	sort.Slice(childIntervals, func(i, j int) bool {
+		return childIntervals[i].Start < childIntervals[j].Start
+	})
+	for i := 1; i < len(childIntervals); i++ {
+		if childIntervals[i].Start <= childIntervals[i-1].End {
+			ic.addIssue(check, blockHash, "the reachability intervals %s and %s of the block's children overlap",
+				childIntervals[i-1], childIntervals[i])
+		}
+	}
+	return nil
+}
+
+// checkVirtualUTXOSet makes sure that the stored virtual UTXO set matches the stored
+// multiset of the virtual
+func (ic *integrityChecker) checkVirtualUTXOSet() error {
+	const check = externalapi.IntegrityCheckVirtualUTXOSet
+
+	virtualMultiset, err := ic.multisetStore.Get(ic.databaseContext, ic.stagingArea, model.VirtualBlockHash)
+	if err != nil {
+		if database.IsNotFoundError(err) {
+			ic.addIssue(check, nil, "the multiset of the virtual is missing")
+			return nil
+		}
+		return err
+	}
+
+	iterator, err := ic.consensusStateStore.VirtualUTXOSetIterator(ic.databaseContext, ic.stagingArea)
+	if err != nil {
+		return err
+	}
+	defer iterator.Close()
+	utxoSetHash, err := utxoSetMultisetHash(iterator)
+	if err != nil {
+		return err
+	}
+
+	if !utxoSetHash.Equal(virtualMultiset.Hash()) {
+		ic.addIssue(check, nil, "the hash of the virtual UTXO set is %s, but the multiset of the virtual "+
+			"has hash %s", utxoSetHash, virtualMultiset.Hash())
+	}
+	return nil
+}
+
+// checkPruningPointUTXOSet makes sure that the stored pruning point UTXO set matches
+// the UTXO commitment of the pruning point
+func (ic *integrityChecker) checkPruningPointUTXOSet() error {
+	const check = externalapi.IntegrityCheckPruningPointUTXOSet
+
+	pruningPoint, err := ic.pruningStore.PruningPoint(ic.databaseContext, ic.stagingArea)
+	if err != nil {
+		return err
+	}
+
+	// The node finishes updating the pruning point UTXO set when it starts, so
+	// there's nothing to compare to the commitment until it does
+	hadStartedUpdatingPruningPointUTXOSet, err := ic.pruningStore.HadStartedUpdatingPruningPointUTXOSet(ic.databaseContext)
+	if err != nil {
+		return err
+	}
+	if hadStartedUpdatingPruningPointUTXOSet {
+		ic.addIssue(check, pruningPoint, "the update of the pruning point UTXO set was interrupted")
+		return nil
+	}
+
+	iterator, err := ic.pruningStore.PruningPointUTXOIterator(ic.databaseContext)
+	if err != nil {
+		return err
+	}
+	defer iterator.Close()
+	utxoSetHash, err := utxoSetMultisetHash(iterator)
+	if err != nil {
+		return err
+	}
+
+	header, err := ic.blockHeaderStore.BlockHeader(ic.databaseContext, ic.stagingArea, pruningPoint)
+	if err != nil {
+		return err
+	}
+	if !utxoSetHash.Equal(header.UTXOCommitment()) {
+		ic.addIssue(check, pruningPoint, "the hash of the pruning point UTXO set is %s, but the pruning "+
+			"point's UTXO commitment is %s", utxoSetHash, header.UTXOCommitment())
+	}
+	return nil
+}
+
+func utxoSetMultisetHash(iterator externalapi.ReadOnlyUTXOSetIterator) (*externalapi.DomainHash, error) {
+	utxoSetMultiset := multiset.New()
+	for ok := iterator.First(); ok; ok = iterator.Next() {
+		outpoint, entry, err := iterator.Get()
+		if err != nil {
+			return nil, err
+		}
+		serializedUTXO, err := utxo.SerializeUTXO(entry, outpoint)
+		if err != nil {
+			return nil, err
+		}
+		utxoSetMultiset.Add(serializedUTXO)
+	}
+	return utxoSetMultiset.Hash(), nil
+}
@@ -0,0 +1,82 @@
+package consensus_test
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus"
+	"github.com/kaspanet/kaspad/domain/consensus/model"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/multiset"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/testutils"
+	"github.com/kaspanet/kaspad/util/staging"
+)
+
+func TestCheckIntegrity(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestCheckIntegrity")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		// Build a small DAG with a merge block, so that blocks have several parents and children
+		tipHash := consensusConfig.GenesisHash
+		for i := 0; i < 5; i++ {
+			tipHash, _, err = tc.AddBlock([]*externalapi.DomainHash{tipHash}, nil, nil)
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+		}
+		sideBlockHash, _, err := tc.AddBlock([]*externalapi.DomainHash{consensusConfig.GenesisHash}, nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+		tipHash, _, err = tc.AddBlock([]*externalapi.DomainHash{tipHash, sideBlockHash}, nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+
+		report, err := tc.CheckIntegrity()
+		if err != nil {
+			t.Fatalf("CheckIntegrity: %+v", err)
+		}
+		if len(report.Issues) != 0 {
+			t.Fatalf("Expected no integrity issues, but got %d. The first: %s: %s",
+				len(report.Issues), report.Issues[0].Check, report.Issues[0].Description)
+		}
+		// The genesis and the 7 added blocks
+		if report.CheckedBlocks != 8 {
+			t.Fatalf("Expected 8 blocks to be checked, but %d were", report.CheckedBlocks)
+		}
+
+		// Simulate a crash in the middle of a write, that left a valid block without its body
+		// and the virtual multiset without the UTXO set that matches it
+		stagingArea := model.NewStagingArea()
+		tc.BlockStore().Delete(stagingArea, sideBlockHash)
+		tc.MultisetStore().Stage(stagingArea, model.VirtualBlockHash, multiset.New())
+		err = staging.CommitAllChanges(tc.DatabaseContext(), stagingArea)
+		if err != nil {
+			t.Fatalf("CommitAllChanges: %+v", err)
+		}
+
+		report, err = tc.CheckIntegrity()
+		if err != nil {
+			t.Fatalf("CheckIntegrity: %+v", err)
+		}
+		issuesByCheck := make(map[string][]*externalapi.IntegrityIssue)
+		for _, issue := range report.Issues {
+			issuesByCheck[issue.Check] = append(issuesByCheck[issue.Check], issue)
+		}
+		if len(report.Issues) != 2 {
+			t.Fatalf("Expected 2 integrity issues, but got %d: %v", len(report.Issues), issuesByCheck)
+		}
+		blockStatusIssues := issuesByCheck[externalapi.IntegrityCheckBlockStatuses]
+		if len(blockStatusIssues) != 1 || !blockStatusIssues[0].BlockHash.Equal(sideBlockHash) {
+			t.Fatalf("Expected the missing body of block %s to be reported", sideBlockHash)
+		}
+		if len(issuesByCheck[externalapi.IntegrityCheckVirtualUTXOSet]) != 1 {
+			t.Fatalf("Expected the mismatch between the virtual UTXO set and multiset to be reported")
+		}
+	})
+}
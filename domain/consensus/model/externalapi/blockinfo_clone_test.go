@@ -14,31 +14,58 @@ func initTestBlockInfoStructsForClone() []*BlockInfo {
 			BlockStatus(0x01),
 			0,
 			big.NewInt(0),
+			nil,
+			[]*DomainHash{},
+			[]*DomainHash{},
 		}, {
 			true,
 			BlockStatus(0x02),
 			0,
 			big.NewInt(0),
+			nil,
+			[]*DomainHash{},
+			[]*DomainHash{},
 		}, {
 			true,
 			1,
 			1,
 			big.NewInt(0),
+			nil,
+			[]*DomainHash{},
+			[]*DomainHash{},
 		}, {
 			true,
 			255,
 			2,
 			big.NewInt(0),
+			nil,
+			[]*DomainHash{},
+			[]*DomainHash{},
 		}, {
 			true,
 			0,
 			3,
 			big.NewInt(0),
+			nil,
+			[]*DomainHash{},
+			[]*DomainHash{},
 		}, {
 			true,
 			BlockStatus(0x01),
 			0,
 			big.NewInt(1),
+			nil,
+			[]*DomainHash{},
+			[]*DomainHash{},
+		}, {
+			true,
+			BlockStatus(0x01),
+			1,
+			big.NewInt(1),
+			NewDomainHashFromByteArray(&[DomainHashSize]byte{0x01}),
+			[]*DomainHash{NewDomainHashFromByteArray(&[DomainHashSize]byte{0x01})},
+			[]*DomainHash{NewDomainHashFromByteArray(&[DomainHashSize]byte{0x02}),
+				NewDomainHashFromByteArray(&[DomainHashSize]byte{0x03})},
 		},
 	}
 	return tests
@@ -0,0 +1,7 @@
+package externalapi
+
+// Checkpoint is a block that is known to be in the selected parent chain of the network
+type Checkpoint struct {
+	BlueScore uint64
+	Hash      *DomainHash
+}
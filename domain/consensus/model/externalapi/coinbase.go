@@ -1,5 +1,7 @@
 package externalapi
 
+import "bytes"
+
 // DomainCoinbaseData contains data by which a coinbase transaction
 // is built
 type DomainCoinbaseData struct {
@@ -7,6 +9,27 @@ type DomainCoinbaseData struct {
 	ExtraData       []byte
 }
 
+// If this doesn't compile, it means the type definition has been changed, so it's
+// an indication to update Equal and Clone accordingly.
+var _ = &DomainCoinbaseData{&ScriptPublicKey{}, []byte{}}
+
+// Equal returns whether dcd equals to other
+func (dcd *DomainCoinbaseData) Equal(other *DomainCoinbaseData) bool {
+	if dcd == nil || other == nil {
+		return dcd == other
+	}
+
+	if dcd.ScriptPublicKey.Version != other.ScriptPublicKey.Version {
+		return false
+	}
+
+	if !bytes.Equal(dcd.ScriptPublicKey.Script, other.ScriptPublicKey.Script) {
+		return false
+	}
+
+	return bytes.Equal(dcd.ExtraData, other.ExtraData)
+}
+
 // Clone returns a clone of DomainCoinbaseData
 func (dcd *DomainCoinbaseData) Clone() *DomainCoinbaseData {
 
@@ -57,3 +57,32 @@ func TestDomainCoinbaseData_Clone(t *testing.T) {
 		}
 	}
 }
+
+func TestDomainCoinbaseData_Equal(t *testing.T) {
+	coinbaseData := initTestCoinbaseDataStructsForClone()
+	for i, coinbase := range coinbaseData {
+		if !coinbase.Equal(coinbase.Clone()) {
+			t.Fatalf("Test #%d: clone should be equal to the original", i)
+		}
+		for j, other := range coinbaseData {
+			if i != j && coinbase.Equal(other) {
+				t.Fatalf("Test #%d: expected coinbase data to differ from test #%d", i, j)
+			}
+		}
+	}
+
+	base := coinbaseData[0]
+	differentVersion := base.Clone()
+	differentVersion.ScriptPublicKey.Version++
+	if base.Equal(differentVersion) {
+		t.Fatalf("Coinbase data with different script public key versions are expected to differ")
+	}
+	differentExtraData := base.Clone()
+	differentExtraData.ExtraData = append(differentExtraData.ExtraData, 0)
+	if base.Equal(differentExtraData) {
+		t.Fatalf("Coinbase data with different extra data are expected to differ")
+	}
+	if base.Equal(nil) || !(*DomainCoinbaseData)(nil).Equal(nil) {
+		t.Fatalf("Unexpected result when comparing to nil")
+	}
+}
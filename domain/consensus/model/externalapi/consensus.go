@@ -38,6 +38,9 @@ type Consensus interface {
 	Tips() ([]*DomainHash, error)
 	GetVirtualInfo() (*VirtualInfo, error)
 	GetVirtualDAAScore() (uint64, error)
+	GetSubnetworkGasLimit(subnetworkID *DomainSubnetworkID) (gasLimit uint64, exists bool, err error)
+	GetPruningPointSubnetworkRegistrations(expectedPruningPointHash *DomainHash) ([]*SubnetworkRegistration, error)
+	ImportPruningPointSubnetworkRegistrations(registrations []*SubnetworkRegistration) error
 	IsValidPruningPoint(blockHash *DomainHash) (bool, error)
 	ArePruningPointsViolatingFinality(pruningPoints []BlockHeader) (bool, error)
 	GetVirtualSelectedParentChainFromBlock(blockHash *DomainHash) (*SelectedChainPath, error)
@@ -47,4 +50,5 @@ type Consensus interface {
 	EstimateNetworkHashesPerSecond(startHash *DomainHash, windowSize int) (uint64, error)
 	PopulateMass(transaction *DomainTransaction)
 	ResolveVirtual() error
+	CheckIntegrity() (*IntegrityReport, error)
 }
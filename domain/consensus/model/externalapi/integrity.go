@@ -0,0 +1,31 @@
+package externalapi
+
+// The names of the checks that are performed when checking the integrity of a node's data
+const (
+	IntegrityCheckBlockStatuses       = "block-statuses"
+	IntegrityCheckReachability        = "reachability"
+	IntegrityCheckVirtualUTXOSet      = "virtual-utxo-set"
+	IntegrityCheckPruningPointUTXOSet = "pruning-point-utxo-set"
+	IntegrityCheckUTXOIndex           = "utxo-index"
+)
+
+// IntegrityIssue is an inconsistency that was found in a node's stored data
+type IntegrityIssue struct {
+	// Check is the name of the check that found the issue
+	Check string
+
+	// BlockHash is the block the issue was found in, or nil if the issue isn't
+	// specific to a single block
+	BlockHash *DomainHash
+
+	Description string
+
+	// Repaired is true if the issue was repaired after it was found
+	Repaired bool
+}
+
+// IntegrityReport is the result of checking the integrity of the consensus data
+type IntegrityReport struct {
+	CheckedBlocks uint64
+	Issues        []*IntegrityIssue
+}
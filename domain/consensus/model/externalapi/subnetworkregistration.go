@@ -0,0 +1,8 @@
+package externalapi
+
+// SubnetworkRegistration is a subnetwork that was registered by an accepted
+// subnetwork registry transaction, along with its gas limit
+type SubnetworkRegistration struct {
+	SubnetworkID DomainSubnetworkID
+	GasLimit     uint64
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+// SubnetworkStore represents a store of the registered subnetworks and their gas limits
+type SubnetworkStore interface {
+	Store
+	Stage(stagingArea *StagingArea, subnetworkID *externalapi.DomainSubnetworkID, gasLimit uint64, acceptingBlueScore uint64)
+	Delete(stagingArea *StagingArea, subnetworkID *externalapi.DomainSubnetworkID)
+	StageRegistryBuilt(stagingArea *StagingArea)
+	IsStaged(stagingArea *StagingArea) bool
+	GasLimit(dbContext DBReader, stagingArea *StagingArea, subnetworkID *externalapi.DomainSubnetworkID) (uint64, error)
+	Exists(dbContext DBReader, stagingArea *StagingArea, subnetworkID *externalapi.DomainSubnetworkID) (bool, error)
+	Registrations(dbContext DBReader, stagingArea *StagingArea, maxAcceptingBlueScore uint64) (
+		[]*externalapi.SubnetworkRegistration, error)
+	IsRegistryBuilt(dbContext DBReader) (bool, error)
+}
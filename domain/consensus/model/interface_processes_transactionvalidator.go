@@ -12,5 +12,7 @@ type TransactionValidator interface {
 		povBlockHash *externalapi.DomainHash) error
 	ValidateTransactionInContextAndPopulateFee(stagingArea *StagingArea,
 		tx *externalapi.DomainTransaction, povBlockHash *externalapi.DomainHash) error
+	ValidateTransactionsInContextAndPopulateFees(stagingArea *StagingArea,
+		txs []*externalapi.DomainTransaction, povBlockHash *externalapi.DomainHash) error
 	PopulateMass(transaction *externalapi.DomainTransaction)
 }
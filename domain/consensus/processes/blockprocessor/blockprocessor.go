@@ -14,6 +14,7 @@ import (
 type blockProcessor struct {
 	genesisHash        *externalapi.DomainHash
 	targetTimePerBlock time.Duration
+	isHeadersOnlyNode  bool
 	databaseContext    model.DBManager
 	blockLogger        *blocklogger.BlockLogger
 
@@ -53,6 +54,7 @@ type blockProcessor struct {
 func New(
 	genesisHash *externalapi.DomainHash,
 	targetTimePerBlock time.Duration,
+	isHeadersOnlyNode bool,
 	databaseContext model.DBManager,
 
 	consensusStateManager model.ConsensusStateManager,
@@ -88,6 +90,7 @@ func New(
 	return &blockProcessor{
 		genesisHash:           genesisHash,
 		targetTimePerBlock:    targetTimePerBlock,
+		isHeadersOnlyNode:     isHeadersOnlyNode,
 		databaseContext:       databaseContext,
 		blockLogger:           blocklogger.NewBlockLogger(),
 		pruningManager:        pruningManager,
@@ -171,6 +171,14 @@ func (bp *blockProcessor) validateAndInsertBlock(stagingArea *model.StagingArea,
 		}
 	}
 
+	if isHeaderOnlyBlock && bp.isHeadersOnlyNode {
+		// Headers-only nodes have no virtual, so their pruning point follows the headers selected tip
+		err = bp.pruningManager.UpdatePruningPointByHeadersSelectedTip(stagingArea)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	err = staging.CommitAllChanges(bp.databaseContext, stagingArea)
 	if err != nil {
 		return nil, err
@@ -216,3 +216,84 @@ func initData(consensusConfig *consensus.Config) (*externalapi.DomainHash, exter
 				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02}),
 		}
 }
+
+func TestHeadersOnlyPruningPoint(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		// This is done to make a pruning depth of 6 blocks
+		consensusConfig.FinalityDuration = 2 * consensusConfig.TargetTimePerBlock
+		consensusConfig.K = 0
+
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestHeadersOnlyPruningPoint")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		headersOnlyConsensusConfig := *consensusConfig
+		headersOnlyConsensusConfig.HeadersOnly = true
+		tcHeadersOnly, teardownHeadersOnly, err := factory.NewTestConsensus(&headersOnlyConsensusConfig,
+			"TestHeadersOnlyPruningPoint-headers-only")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardownHeadersOnly(false)
+
+		tipHash := consensusConfig.GenesisHash
+		for i := uint64(0); i < 4*consensusConfig.PruningDepth(); i++ {
+			tipHash, _, err = tc.AddBlock([]*externalapi.DomainHash{tipHash}, nil, nil)
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+			block, err := tc.GetBlock(tipHash)
+			if err != nil {
+				t.Fatalf("GetBlock: %+v", err)
+			}
+
+			_, err = tcHeadersOnly.ValidateAndInsertBlock(&externalapi.DomainBlock{Header: block.Header}, true)
+			if err != nil {
+				t.Fatalf("ValidateAndInsertBlock: %+v", err)
+			}
+
+			expectedPruningPoint, err := tc.PruningPoint()
+			if err != nil {
+				t.Fatalf("PruningPoint: %+v", err)
+			}
+			pruningPoint, err := tcHeadersOnly.PruningPoint()
+			if err != nil {
+				t.Fatalf("PruningPoint: %+v", err)
+			}
+			if !pruningPoint.Equal(expectedPruningPoint) {
+				t.Fatalf("Expected the pruning point of the headers-only consensus to be %s, but got %s",
+					expectedPruningPoint, pruningPoint)
+			}
+		}
+
+		stagingArea := model.NewStagingArea()
+		headersOnlyPruningPointIndex, err := tcHeadersOnly.PruningStore().CurrentPruningPointIndex(
+			tcHeadersOnly.DatabaseContext(), stagingArea)
+		if err != nil {
+			t.Fatalf("CurrentPruningPointIndex: %+v", err)
+		}
+		if headersOnlyPruningPointIndex == 0 {
+			t.Fatalf("The pruning point of the headers-only consensus didn't move")
+		}
+
+		// The headers-only consensus keeps the same past pruning points as the full one
+		for i := uint64(0); i <= headersOnlyPruningPointIndex; i++ {
+			expectedPruningPoint, err := tc.PruningStore().PruningPointByIndex(tc.DatabaseContext(), stagingArea, i)
+			if err != nil {
+				t.Fatalf("PruningPointByIndex: %+v", err)
+			}
+			pruningPoint, err := tcHeadersOnly.PruningStore().PruningPointByIndex(
+				tcHeadersOnly.DatabaseContext(), stagingArea, i)
+			if err != nil {
+				t.Fatalf("PruningPointByIndex: %+v", err)
+			}
+			if !pruningPoint.Equal(expectedPruningPoint) {
+				t.Fatalf("Expected pruning point #%d of the headers-only consensus to be %s, but got %s",
+					i, expectedPruningPoint, pruningPoint)
+			}
+		}
+	})
+}
@@ -103,6 +103,11 @@ func (v *blockValidator) ValidateHeaderInContext(stagingArea *model.StagingArea,
 		if err != nil {
 			return err
 		}
+
+		err = v.validateHeaderCheckpoints(stagingArea, blockHash)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
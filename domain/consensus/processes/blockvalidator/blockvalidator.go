@@ -7,6 +7,7 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/model"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/util/difficulty"
+	"github.com/kaspanet/kaspad/util/mstime"
 )
 
 // blockValidator exposes a set of validation classes, after which
@@ -22,6 +23,8 @@ type blockValidator struct {
 	maxBlockParents             externalapi.KType
 	timestampDeviationTolerance int
 	targetTimePerBlock          time.Duration
+	clock                       mstime.Clock
+	checkpoints                 []externalapi.Checkpoint
 
 	databaseContext       model.DBReader
 	difficultyManager     model.DifficultyManager
@@ -58,6 +61,8 @@ func New(powMax *big.Int,
 	maxBlockParents externalapi.KType,
 	timestampDeviationTolerance int,
 	targetTimePerBlock time.Duration,
+	clock mstime.Clock,
+	checkpoints []externalapi.Checkpoint,
 
 	databaseContext model.DBReader,
 
@@ -97,6 +102,8 @@ func New(powMax *big.Int,
 
 		timestampDeviationTolerance: timestampDeviationTolerance,
 		targetTimePerBlock:          targetTimePerBlock,
+		clock:                       clock,
+		checkpoints:                 checkpoints,
 		databaseContext:             databaseContext,
 		difficultyManager:           difficultyManager,
 		pastMedianTimeManager:       pastMedianTimeManager,
@@ -0,0 +1,79 @@
+package blockvalidator
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
+	"github.com/pkg/errors"
+)
+
+// validateHeaderCheckpoints makes sure the block doesn't contradict any of the checkpoints.
+// A checkpoint is treated like a finality point: once the finality point of a block is above
+// a checkpoint, the checkpoint must be in the selected parent chain of that finality point.
+func (v *blockValidator) validateHeaderCheckpoints(stagingArea *model.StagingArea, blockHash *externalapi.DomainHash) error {
+	if len(v.checkpoints) == 0 {
+		return nil
+	}
+
+	finalityPoint, err := v.finalityManager.FinalityPoint(stagingArea, blockHash, false)
+	if err != nil {
+		return err
+	}
+	// Blocks whose finality point is below the pruning point they were synced from have a virtual
+	// genesis finality point. The checkpoints they pass are validated against the past pruning
+	// points during IBD instead
+	if finalityPoint.Equal(model.VirtualGenesisBlockHash) {
+		return nil
+	}
+	finalityPointGHOSTDAGData, err := v.ghostdagDataStores[0].Get(v.databaseContext, stagingArea, finalityPoint, false)
+	if err != nil {
+		return err
+	}
+
+	for _, checkpoint := range v.checkpoints {
+		if checkpoint.BlueScore > finalityPointGHOSTDAGData.BlueScore() {
+			continue
+		}
+
+		hasReachabilityData, err := v.reachabilityStore.HasReachabilityData(v.databaseContext, stagingArea, checkpoint.Hash)
+		if err != nil {
+			return err
+		}
+		if !hasReachabilityData {
+			// Nodes that synced from a pruning point proof don't know the blocks below their pruning point
+			isBelowPruningPoint, err := v.isBelowPruningPoint(stagingArea, checkpoint.BlueScore)
+			if err != nil {
+				return err
+			}
+			if isBelowPruningPoint {
+				continue
+			}
+			return errors.Wrapf(ruleerrors.ErrViolatingCheckpoint, "block %s doesn't have the checkpoint %s "+
+				"in its selected parent chain", blockHash, checkpoint.Hash)
+		}
+
+		isInSelectedParentChainOfFinalityPoint, err := v.dagTopologyManagers[0].IsInSelectedParentChainOf(
+			stagingArea, checkpoint.Hash, finalityPoint)
+		if err != nil {
+			return err
+		}
+		if !isInSelectedParentChainOfFinalityPoint {
+			return errors.Wrapf(ruleerrors.ErrViolatingCheckpoint, "block %s doesn't have the checkpoint %s "+
+				"in its selected parent chain", blockHash, checkpoint.Hash)
+		}
+	}
+
+	return nil
+}
+
+func (v *blockValidator) isBelowPruningPoint(stagingArea *model.StagingArea, blueScore uint64) (bool, error) {
+	pruningPoint, err := v.pruningStore.PruningPoint(v.databaseContext, stagingArea)
+	if err != nil {
+		return false, err
+	}
+	pruningPointGHOSTDAGData, err := v.ghostdagDataStores[0].Get(v.databaseContext, stagingArea, pruningPoint, false)
+	if err != nil {
+		return false, err
+	}
+	return blueScore < pruningPointGHOSTDAGData.BlueScore(), nil
+}
@@ -7,10 +7,11 @@ import (
 
 // consensusStateManager manages the node's consensus state
 type consensusStateManager struct {
-	maxBlockParents   externalapi.KType
-	mergeSetSizeLimit uint64
-	genesisHash       *externalapi.DomainHash
-	databaseContext   model.DBManager
+	maxBlockParents            externalapi.KType
+	mergeSetSizeLimit          uint64
+	genesisHash                *externalapi.DomainHash
+	enableNonNativeSubnetworks bool
+	databaseContext            model.DBManager
 
 	ghostdagManager       model.GHOSTDAGManager
 	dagTopologyManager    model.DAGTopologyManager
@@ -34,6 +35,7 @@ type consensusStateManager struct {
 	blockHeaderStore        model.BlockHeaderStore
 	pruningStore            model.PruningStore
 	daaBlocksStore          model.DAABlocksStore
+	subnetworkStore         model.SubnetworkStore
 
 	stores []model.Store
 }
@@ -44,6 +46,7 @@ func New(
 	maxBlockParents externalapi.KType,
 	mergeSetSizeLimit uint64,
 	genesisHash *externalapi.DomainHash,
+	enableNonNativeSubnetworks bool,
 
 	ghostdagManager model.GHOSTDAGManager,
 	dagTopologyManager model.DAGTopologyManager,
@@ -66,13 +69,15 @@ func New(
 	blockHeaderStore model.BlockHeaderStore,
 	headersSelectedTipStore model.HeaderSelectedTipStore,
 	pruningStore model.PruningStore,
-	daaBlocksStore model.DAABlocksStore) (model.ConsensusStateManager, error) {
+	daaBlocksStore model.DAABlocksStore,
+	subnetworkStore model.SubnetworkStore) (model.ConsensusStateManager, error) {
 
 	csm := &consensusStateManager{
-		maxBlockParents:   maxBlockParents,
-		mergeSetSizeLimit: mergeSetSizeLimit,
-		genesisHash:       genesisHash,
-		databaseContext:   databaseContext,
+		maxBlockParents:            maxBlockParents,
+		mergeSetSizeLimit:          mergeSetSizeLimit,
+		genesisHash:                genesisHash,
+		enableNonNativeSubnetworks: enableNonNativeSubnetworks,
+		databaseContext:            databaseContext,
 
 		ghostdagManager:       ghostdagManager,
 		dagTopologyManager:    dagTopologyManager,
@@ -96,6 +101,7 @@ func New(
 		headersSelectedTipStore: headersSelectedTipStore,
 		pruningStore:            pruningStore,
 		daaBlocksStore:          daaBlocksStore,
+		subnetworkStore:         subnetworkStore,
 
 		stores: []model.Store{
 			consensusStateStore,
@@ -110,6 +116,7 @@ func New(
 			blockHeaderStore,
 			headersSelectedTipStore,
 			pruningStore,
+			subnetworkStore,
 		},
 	}
 
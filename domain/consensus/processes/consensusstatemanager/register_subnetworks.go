@@ -0,0 +1,147 @@
+package consensusstatemanager
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/subnetworks"
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+	"github.com/kaspanet/kaspad/util/staging"
+)
+
+// updateSubnetworkRegistry keeps the subnetwork registry in line with the virtual selected parent
+// chain: the subnetworks registered by the chain blocks that were removed from it are unregistered,
+// and the subnetworks registered by the chain blocks that were added to it are registered.
+// A subnetwork ID is derived from the ID of the transaction that registered it, so a registry
+// transaction that is accepted by both a removed and an added chain block stays registered.
+// It must only be called on networks with non-native subnetworks enabled.
+func (csm *consensusStateManager) updateSubnetworkRegistry(stagingArea *model.StagingArea,
+	selectedParentChainChanges *externalapi.SelectedChainPath) error {
+
+	for _, removedChainBlock := range selectedParentChainChanges.Removed {
+		acceptanceData, err := csm.acceptanceDataStore.Get(csm.databaseContext, stagingArea, removedChainBlock)
+		if err != nil {
+			return err
+		}
+		err = forEachAcceptedRegistryTransaction(acceptanceData,
+			func(subnetworkID *externalapi.DomainSubnetworkID, _ uint64) {
+				log.Debugf("Unregistering subnetwork %s", subnetworkID)
+				csm.subnetworkStore.Delete(stagingArea, subnetworkID)
+			})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, addedChainBlock := range selectedParentChainChanges.Added {
+		acceptanceData, err := csm.acceptanceDataStore.Get(csm.databaseContext, stagingArea, addedChainBlock)
+		if err != nil {
+			return err
+		}
+		ghostdagData, err := csm.ghostdagDataStore.Get(csm.databaseContext, stagingArea, addedChainBlock, false)
+		if err != nil {
+			return err
+		}
+		err = forEachAcceptedRegistryTransaction(acceptanceData,
+			func(subnetworkID *externalapi.DomainSubnetworkID, gasLimit uint64) {
+				log.Debugf("Registering subnetwork %s with gas limit %d", subnetworkID, gasLimit)
+				csm.subnetworkStore.Stage(stagingArea, subnetworkID, gasLimit, ghostdagData.BlueScore())
+			})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func forEachAcceptedRegistryTransaction(acceptanceData externalapi.AcceptanceData,
+	callback func(subnetworkID *externalapi.DomainSubnetworkID, gasLimit uint64)) error {
+
+	for _, blockAcceptanceData := range acceptanceData {
+		for _, transactionAcceptanceData := range blockAcceptanceData.TransactionAcceptanceData {
+			transaction := transactionAcceptanceData.Transaction
+			if !transactionAcceptanceData.IsAccepted || transaction.SubnetworkID != subnetworks.SubnetworkIDRegistry {
+				continue
+			}
+
+			// The payload length of registry transactions is validated in isolation
+			gasLimit, err := subnetworks.GasLimitFromRegistryPayload(transaction.Payload)
+			if err != nil {
+				return err
+			}
+			subnetworkID := subnetworks.FromRegistryTransactionID(consensushashing.TransactionID(transaction))
+			callback(&subnetworkID, gasLimit)
+		}
+	}
+	return nil
+}
+
+// RebuildSubnetworkRegistryIfRequired rebuilds the subnetwork registry of databases that were
+// created before it followed the virtual selected parent chain. The acceptance data below the
+// pruning point is no longer available, so only the subnetworks that were registered by the
+// chain blocks above it can be recovered.
+// The registry is only kept on networks with non-native subnetworks enabled.
+func (csm *consensusStateManager) RebuildSubnetworkRegistryIfRequired() error {
+	if !csm.enableNonNativeSubnetworks {
+		return nil
+	}
+
+	isRegistryBuilt, err := csm.subnetworkStore.IsRegistryBuilt(csm.databaseContext)
+	if err != nil {
+		return err
+	}
+	if isRegistryBuilt {
+		return nil
+	}
+
+	onEnd := logger.LogAndMeasureExecutionTime(log, "RebuildSubnetworkRegistryIfRequired")
+	defer onEnd()
+
+	stagingArea := model.NewStagingArea()
+
+	hasPruningPoint, err := csm.pruningStore.HasPruningPoint(csm.databaseContext, stagingArea)
+	if err != nil {
+		return err
+	}
+	if !hasPruningPoint {
+		// This is a fresh database, so there's nothing to rebuild
+		csm.subnetworkStore.StageRegistryBuilt(stagingArea)
+		return staging.CommitAllChanges(csm.databaseContext, stagingArea)
+	}
+
+	log.Debugf("Rebuilding the subnetwork registry from the virtual selected parent chain")
+	registrations, err := csm.subnetworkStore.Registrations(csm.databaseContext, stagingArea, ^uint64(0))
+	if err != nil {
+		return err
+	}
+	for _, registration := range registrations {
+		csm.subnetworkStore.Delete(stagingArea, &registration.SubnetworkID)
+	}
+
+	pruningPoint, err := csm.pruningStore.PruningPoint(csm.databaseContext, stagingArea)
+	if err != nil {
+		return err
+	}
+	virtualGHOSTDAGData, err := csm.ghostdagDataStore.Get(csm.databaseContext, stagingArea, model.VirtualBlockHash, false)
+	if err != nil {
+		return err
+	}
+	selectedParentChain, err := csm.dagTraversalManager.CalculateChainPath(
+		stagingArea, pruningPoint, virtualGHOSTDAGData.SelectedParent())
+	if err != nil {
+		return err
+	}
+	err = csm.updateSubnetworkRegistry(stagingArea, selectedParentChain)
+	if err != nil {
+		return err
+	}
+
+	if !pruningPoint.Equal(csm.genesisHash) {
+		log.Warnf("The subnetwork registry was rebuilt from the chain blocks above the pruning point %s. "+
+			"Subnetworks that were registered below it cannot be recovered", pruningPoint)
+	}
+
+	csm.subnetworkStore.StageRegistryBuilt(stagingArea)
+	return staging.CommitAllChanges(csm.databaseContext, stagingArea)
+}
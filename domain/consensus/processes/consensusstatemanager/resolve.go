@@ -79,11 +79,32 @@ func (csm *consensusStateManager) ResolveVirtual(maxBlocksToResolve uint64) (boo
 	}
 
 	updateVirtualStagingArea := model.NewStagingArea()
+	var oldVirtualSelectedParent *externalapi.DomainHash
+	if csm.enableNonNativeSubnetworks {
+		oldVirtualGHOSTDAGData, err := csm.ghostdagDataStore.Get(
+			csm.databaseContext, updateVirtualStagingArea, model.VirtualBlockHash, false)
+		if err != nil {
+			return false, err
+		}
+		oldVirtualSelectedParent = oldVirtualGHOSTDAGData.SelectedParent()
+	}
 	_, err = csm.updateVirtualWithParents(updateVirtualStagingArea, []*externalapi.DomainHash{selectedTip})
 	if err != nil {
 		return false, err
 	}
 
+	if csm.enableNonNativeSubnetworks {
+		selectedParentChainChanges, err := csm.dagTraversalManager.
+			CalculateChainPath(updateVirtualStagingArea, oldVirtualSelectedParent, selectedTip)
+		if err != nil {
+			return false, err
+		}
+		err = csm.updateSubnetworkRegistry(updateVirtualStagingArea, selectedParentChainChanges)
+		if err != nil {
+			return false, err
+		}
+	}
+
 	err = staging.CommitAllChanges(csm.databaseContext, updateVirtualStagingArea)
 	if err != nil {
 		return false, err
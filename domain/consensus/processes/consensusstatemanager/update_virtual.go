@@ -51,6 +51,14 @@ func (csm *consensusStateManager) updateVirtual(stagingArea *model.StagingArea,
 		}
 		log.Debugf("Selected parent chain changes: %d blocks were removed and %d blocks were added",
 			len(selectedParentChainChanges.Removed), len(selectedParentChainChanges.Added))
+
+		if csm.enableNonNativeSubnetworks {
+			log.Debugf("Updating the subnetwork registry with the selected parent chain changes")
+			err = csm.updateSubnetworkRegistry(stagingArea, selectedParentChainChanges)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
 	}
 
 	return selectedParentChainChanges, virtualUTXODiff, nil
@@ -170,6 +170,67 @@ func (pm *pruningManager) UpdatePruningPointByVirtual(stagingArea *model.Staging
 	return nil
 }
 
+// UpdatePruningPointByHeadersSelectedTip moves the pruning point of a headers-only node, which has no
+// virtual to move it by. The headers selected tip takes the place of the selected parent of the virtual,
+// so the pruning point is the same as that of a full node. Headers-only nodes keep neither block bodies
+// nor a UTXO set, so unlike UpdatePruningPointByVirtual nothing is pruned.
+func (pm *pruningManager) UpdatePruningPointByHeadersSelectedTip(stagingArea *model.StagingArea) error {
+	onEnd := logger.LogAndMeasureExecutionTime(log, "pruningManager.UpdatePruningPointByHeadersSelectedTip")
+	defer onEnd()
+
+	// The initial pruning point is saved once the genesis is added, or imported along with
+	// the past pruning points when syncing with a headers proof
+	hasPruningPoint, err := pm.pruningStore.HasPruningPoint(pm.databaseContext, stagingArea)
+	if err != nil {
+		return err
+	}
+	if !hasPruningPoint {
+		return nil
+	}
+
+	hasHeadersSelectedTip, err := pm.headerSelectedTipStore.Has(pm.databaseContext, stagingArea)
+	if err != nil {
+		return err
+	}
+	if !hasHeadersSelectedTip {
+		return nil
+	}
+	headersSelectedTip, err := pm.headerSelectedTipStore.HeadersSelectedTip(pm.databaseContext, stagingArea)
+	if err != nil {
+		return err
+	}
+	if headersSelectedTip.Equal(pm.genesisHash) {
+		return nil
+	}
+
+	newPruningPoint, newCandidate, err := pm.nextPruningPointAndCandidateByBlockHash(stagingArea, headersSelectedTip, nil)
+	if err != nil {
+		return err
+	}
+
+	currentCandidate, err := pm.pruningPointCandidate(stagingArea)
+	if err != nil {
+		return err
+	}
+
+	if !newCandidate.Equal(currentCandidate) {
+		log.Debugf("Staged a new pruning candidate, old: %s, new: %s", currentCandidate, newCandidate)
+		pm.pruningStore.StagePruningPointCandidate(stagingArea, newCandidate)
+	}
+
+	currentPruningPoint, err := pm.pruningStore.PruningPoint(pm.databaseContext, stagingArea)
+	if err != nil {
+		return err
+	}
+
+	if !newPruningPoint.Equal(currentPruningPoint) {
+		log.Debugf("Moving pruning point from %s to %s", currentPruningPoint, newPruningPoint)
+		return pm.pruningStore.StagePruningPoint(pm.databaseContext, stagingArea, newPruningPoint)
+	}
+
+	return nil
+}
+
 func (pm *pruningManager) nextPruningPointAndCandidateByBlockHash(stagingArea *model.StagingArea,
 	blockHash, suggestedLowHash *externalapi.DomainHash) (*externalapi.DomainHash, *externalapi.DomainHash, error) {
 
@@ -0,0 +1,9 @@
+package transactionvalidator
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+	"github.com/kaspanet/kaspad/util/panics"
+)
+
+var log = logger.RegisterSubSystem("BDAG")
+var spawn = panics.GoroutineWrapperFunc(log)
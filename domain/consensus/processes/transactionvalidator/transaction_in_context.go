@@ -6,7 +6,6 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/model"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
-	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionhelper"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
@@ -72,6 +71,63 @@ func (v *transactionValidator) ValidateTransactionInContextIgnoringUTXO(stagingA
 func (v *transactionValidator) ValidateTransactionInContextAndPopulateFee(stagingArea *model.StagingArea,
 	tx *externalapi.DomainTransaction, povBlockHash *externalapi.DomainHash) error {
 
+	scriptFlags, err := v.scriptFlags(stagingArea, povBlockHash)
+	if err != nil {
+		return err
+	}
+
+	err = v.validateTransactionInContextIgnoringScriptsAndPopulateFee(stagingArea, tx, povBlockHash, scriptFlags)
+	if err != nil {
+		return err
+	}
+
+	return v.validateTransactionsScripts([]*externalapi.DomainTransaction{tx}, scriptFlags)
+}
+
+// ValidateTransactionsInContextAndPopulateFees validates the given transactions against their referenced
+// UTXOs, and populates their fee fields. The scripts of all the inputs of all the transactions are executed
+// in parallel.
+//
+// Note: if the function fails, there's no guarantee that the transaction fee fields will remain unaffected.
+func (v *transactionValidator) ValidateTransactionsInContextAndPopulateFees(stagingArea *model.StagingArea,
+	txs []*externalapi.DomainTransaction, povBlockHash *externalapi.DomainHash) error {
+
+	scriptFlags, err := v.scriptFlags(stagingArea, povBlockHash)
+	if err != nil {
+		return err
+	}
+
+	for _, tx := range txs {
+		err := v.validateTransactionInContextIgnoringScriptsAndPopulateFee(stagingArea, tx, povBlockHash, scriptFlags)
+		if err != nil {
+			return err
+		}
+	}
+
+	return v.validateTransactionsScripts(txs, scriptFlags)
+}
+
+// scriptFlags returns the flags with which the scripts of transactions are executed
+// in the context of povBlockHash. They activate the script versions whose
+// activation DAA score povBlockHash has reached.
+func (v *transactionValidator) scriptFlags(stagingArea *model.StagingArea,
+	povBlockHash *externalapi.DomainHash) (txscript.ScriptFlags, error) {
+
+	povDAAScore, err := v.daaBlocksStore.DAAScore(v.databaseContext, stagingArea, povBlockHash)
+	if err != nil {
+		return 0, err
+	}
+
+	scriptFlags := txscript.ScriptNoFlags
+	if povDAAScore >= v.scriptPublicKeyVersion1ActivationDAAScore {
+		scriptFlags |= txscript.ScriptVersion1Active
+	}
+	return scriptFlags, nil
+}
+
+func (v *transactionValidator) validateTransactionInContextIgnoringScriptsAndPopulateFee(stagingArea *model.StagingArea,
+	tx *externalapi.DomainTransaction, povBlockHash *externalapi.DomainHash, scriptFlags txscript.ScriptFlags) error {
+
 	err := v.checkTransactionCoinbaseMaturity(stagingArea, povBlockHash, tx)
 	if err != nil {
 		return err
@@ -94,12 +150,7 @@ func (v *transactionValidator) ValidateTransactionInContextAndPopulateFee(stagin
 		return err
 	}
 
-	err = v.validateTransactionSigOpCounts(tx)
-	if err != nil {
-		return err
-	}
-
-	err = v.validateTransactionScripts(tx)
+	err = v.validateTransactionSigOpCounts(tx, scriptFlags)
 	if err != nil {
 		return err
 	}
@@ -228,46 +279,6 @@ func (v *transactionValidator) checkTransactionSequenceLock(stagingArea *model.S
 	return nil
 }
 
-func (v *transactionValidator) validateTransactionScripts(tx *externalapi.DomainTransaction) error {
-	var missingOutpoints []*externalapi.DomainOutpoint
-	sighashReusedValues := &consensushashing.SighashReusedValues{}
-
-	for i, input := range tx.Inputs {
-		// Create a new script engine for the script pair.
-		sigScript := input.SignatureScript
-		utxoEntry := input.UTXOEntry
-		if utxoEntry == nil {
-			missingOutpoints = append(missingOutpoints, &input.PreviousOutpoint)
-			continue
-		}
-
-		scriptPubKey := utxoEntry.ScriptPublicKey()
-		vm, err := txscript.NewEngine(scriptPubKey, tx, i, txscript.ScriptNoFlags, v.sigCache, v.sigCacheECDSA, sighashReusedValues)
-		if err != nil {
-			return errors.Wrapf(ruleerrors.ErrScriptMalformed, "failed to parse input "+
-				"%d which references output %s - "+
-				"%s (input script bytes %x, prev "+
-				"output script bytes %x)",
-				i,
-				input.PreviousOutpoint, err, sigScript, scriptPubKey)
-		}
-
-		// Execute the script pair.
-		if err := vm.Execute(); err != nil {
-			return errors.Wrapf(ruleerrors.ErrScriptValidation, "failed to validate input "+
-				"%d which references output %s - "+
-				"%s (input script bytes %x, prev output "+
-				"script bytes %x)",
-				i,
-				input.PreviousOutpoint, err, sigScript, scriptPubKey)
-		}
-	}
-	if len(missingOutpoints) > 0 {
-		return ruleerrors.NewErrMissingTxOut(missingOutpoints)
-	}
-	return nil
-}
-
 func (v *transactionValidator) calcTxSequenceLockFromReferencedUTXOEntries(stagingArea *model.StagingArea,
 	povBlockHash *externalapi.DomainHash, tx *externalapi.DomainTransaction) (*sequenceLock, error) {
 
@@ -344,7 +355,9 @@ func (v *transactionValidator) sequenceLockActive(sequenceLock *sequenceLock, bl
 	return true
 }
 
-func (v *transactionValidator) validateTransactionSigOpCounts(tx *externalapi.DomainTransaction) error {
+func (v *transactionValidator) validateTransactionSigOpCounts(tx *externalapi.DomainTransaction,
+	scriptFlags txscript.ScriptFlags) error {
+
 	for i, input := range tx.Inputs {
 		utxoEntry := input.UTXOEntry
 
@@ -352,7 +365,7 @@ func (v *transactionValidator) validateTransactionSigOpCounts(tx *externalapi.Do
 		// referenced public key script.
 		sigScript := input.SignatureScript
 		isP2SH := txscript.IsPayToScriptHash(utxoEntry.ScriptPublicKey())
-		sigOpCount := txscript.GetPreciseSigOpCount(sigScript, utxoEntry.ScriptPublicKey(), isP2SH)
+		sigOpCount := txscript.GetPreciseSigOpCount(sigScript, utxoEntry.ScriptPublicKey(), isP2SH, scriptFlags)
 		if sigOpCount != int(input.SigOpCount) {
 			return errors.Wrapf(ruleerrors.ErrWrongSigOpCount,
 				"input %d specifies SigOpCount %d while actual SigOpCount is %d",
@@ -144,7 +144,7 @@ func (v *transactionValidator) checkSubnetworkRegistryTransaction(tx *externalap
 		return nil
 	}
 
-	if len(tx.Payload) != 8 {
+	if len(tx.Payload) != subnetworks.RegistryPayloadLength {
 		return errors.Wrapf(ruleerrors.ErrSubnetworkRegistry, "validation failed: subnetwork registry "+
 			"tx has an invalid payload")
 	}
@@ -0,0 +1,178 @@
+package transactionvalidator
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/pkg/errors"
+)
+
+// scriptValidationJob is the execution of the script of a single transaction input
+type scriptValidationJob struct {
+	transactionIndex int
+	inputIndex       int
+}
+
+// validateTransactionsScripts executes the scripts of all the inputs of txs with the given
+// flags on a pool of GOMAXPROCS workers
+func (v *transactionValidator) validateTransactionsScripts(txs []*externalapi.DomainTransaction,
+	scriptFlags txscript.ScriptFlags) error {
+
+	return v.validateTransactionsScriptsWithWorkers(txs, scriptFlags, runtime.GOMAXPROCS(0))
+}
+
+// validateTransactionsScriptsWithWorkers executes the scripts of all the inputs of txs on a pool
+// of up to workerCount workers. If more than one input is invalid, the error of the first of them
+// is returned, so that the result doesn't depend on the order in which the workers run.
+// Inputs with no UTXO entry are reported with ruleerrors.ErrMissingTxOut.
+func (v *transactionValidator) validateTransactionsScriptsWithWorkers(
+	txs []*externalapi.DomainTransaction, scriptFlags txscript.ScriptFlags, workerCount int) error {
+
+	var jobs []scriptValidationJob
+	transactionsJobsEnd := make([]int, len(txs))
+	missingOutpoints := make([][]*externalapi.DomainOutpoint, len(txs))
+	for transactionIndex, tx := range txs {
+		var transactionMissingOutpoints []*externalapi.DomainOutpoint
+		for inputIndex, input := range tx.Inputs {
+			if input.UTXOEntry == nil {
+				transactionMissingOutpoints = append(transactionMissingOutpoints, &input.PreviousOutpoint)
+				continue
+			}
+			jobs = append(jobs, scriptValidationJob{transactionIndex: transactionIndex, inputIndex: inputIndex})
+		}
+		transactionsJobsEnd[transactionIndex] = len(jobs)
+		missingOutpoints[transactionIndex] = transactionMissingOutpoints
+	}
+
+	if workerCount > len(jobs) {
+		workerCount = len(jobs)
+	}
+
+	jobErrors := make([]error, len(jobs))
+	if workerCount <= 1 {
+		worker := v.newScriptValidationWorker(txs, scriptFlags)
+		for i, job := range jobs {
+			jobErrors[i] = worker.validate(job)
+			if jobErrors[i] != nil {
+				break
+			}
+		}
+	} else {
+		v.runScriptValidationWorkers(txs, scriptFlags, jobs, jobErrors, workerCount)
+	}
+
+	// Report errors in the same order as validating the transactions one after the other would
+	transactionJobsStart := 0
+	for transactionIndex := range txs {
+		for _, err := range jobErrors[transactionJobsStart:transactionsJobsEnd[transactionIndex]] {
+			if err != nil {
+				return err
+			}
+		}
+		if len(missingOutpoints[transactionIndex]) > 0 {
+			return ruleerrors.NewErrMissingTxOut(missingOutpoints[transactionIndex])
+		}
+		transactionJobsStart = transactionsJobsEnd[transactionIndex]
+	}
+	return nil
+}
+
+func (v *transactionValidator) runScriptValidationWorkers(txs []*externalapi.DomainTransaction,
+	scriptFlags txscript.ScriptFlags, jobs []scriptValidationJob, jobErrors []error, workerCount int) {
+
+	// Once an input is found to be invalid, the inputs after it don't have to be validated.
+	// The inputs before it still do, since one of them might be invalid as well.
+	firstFailedJobIndex := int64(len(jobs))
+	nextJobIndex := int64(-1)
+
+	waitGroup := sync.WaitGroup{}
+	waitGroup.Add(workerCount)
+	for i := 0; i < workerCount; i++ {
+		spawn("runScriptValidationWorkers-worker", func() {
+			defer waitGroup.Done()
+
+			worker := v.newScriptValidationWorker(txs, scriptFlags)
+			for {
+				jobIndex := atomic.AddInt64(&nextJobIndex, 1)
+				if jobIndex >= atomic.LoadInt64(&firstFailedJobIndex) {
+					return
+				}
+
+				err := worker.validate(jobs[jobIndex])
+				if err != nil {
+					jobErrors[jobIndex] = err
+					for {
+						failedJobIndex := atomic.LoadInt64(&firstFailedJobIndex)
+						if jobIndex >= failedJobIndex ||
+							atomic.CompareAndSwapInt64(&firstFailedJobIndex, failedJobIndex, jobIndex) {
+							break
+						}
+					}
+				}
+			}
+		})
+	}
+	waitGroup.Wait()
+}
+
+// scriptValidationWorker executes scripts on a single goroutine. The values that are reused
+// between the sighashes of the inputs of a transaction are computed lazily and aren't safe
+// for concurrent use, so each worker keeps its own.
+type scriptValidationWorker struct {
+	*transactionValidator
+	txs                 []*externalapi.DomainTransaction
+	scriptFlags         txscript.ScriptFlags
+	sighashReusedValues map[int]*consensushashing.SighashReusedValues
+}
+
+func (v *transactionValidator) newScriptValidationWorker(txs []*externalapi.DomainTransaction,
+	scriptFlags txscript.ScriptFlags) *scriptValidationWorker {
+
+	return &scriptValidationWorker{
+		transactionValidator: v,
+		txs:                  txs,
+		scriptFlags:          scriptFlags,
+		sighashReusedValues:  make(map[int]*consensushashing.SighashReusedValues),
+	}
+}
+
+func (w *scriptValidationWorker) validate(job scriptValidationJob) error {
+	tx := w.txs[job.transactionIndex]
+	input := tx.Inputs[job.inputIndex]
+
+	sighashReusedValues, ok := w.sighashReusedValues[job.transactionIndex]
+	if !ok {
+		sighashReusedValues = &consensushashing.SighashReusedValues{}
+		w.sighashReusedValues[job.transactionIndex] = sighashReusedValues
+	}
+
+	// Create a new script engine for the script pair.
+	sigScript := input.SignatureScript
+	scriptPubKey := input.UTXOEntry.ScriptPublicKey()
+	vm, err := txscript.NewEngine(scriptPubKey, tx, job.inputIndex, w.scriptFlags,
+		w.sigCache, w.sigCacheECDSA, sighashReusedValues)
+	if err != nil {
+		return errors.Wrapf(ruleerrors.ErrScriptMalformed, "failed to parse input "+
+			"%d which references output %s - "+
+			"%s (input script bytes %x, prev "+
+			"output script bytes %x)",
+			job.inputIndex,
+			input.PreviousOutpoint, err, sigScript, scriptPubKey)
+	}
+
+	// Execute the script pair.
+	if err := vm.Execute(); err != nil {
+		return errors.Wrapf(ruleerrors.ErrScriptValidation, "failed to validate input "+
+			"%d which references output %s - "+
+			"%s (input script bytes %x, prev output "+
+			"script bytes %x)",
+			job.inputIndex,
+			input.PreviousOutpoint, err, sigScript, scriptPubKey)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package transactionvalidator
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/kaspanet/go-secp256k1"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/subnetworks"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
+	"github.com/kaspanet/kaspad/util"
+	"github.com/pkg/errors"
+)
+
+// createSignedTransactions creates transactionCount transactions, each spending inputsPerTransaction
+// P2PK outputs with valid signatures
+func createSignedTransactions(t testing.TB, transactionCount int, inputsPerTransaction int) []*externalapi.DomainTransaction {
+	privateKey, err := secp256k1.GenerateSchnorrKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate a private key: %v", err)
+	}
+	publicKey, err := privateKey.SchnorrPublicKey()
+	if err != nil {
+		t.Fatalf("Failed to generate a public key: %v", err)
+	}
+	publicKeySerialized, err := publicKey.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize public key: %v", err)
+	}
+	addr, err := util.NewAddressPublicKey(publicKeySerialized[:], util.Bech32PrefixKaspaSim)
+	if err != nil {
+		t.Fatalf("Failed to generate p2pk address: %v", err)
+	}
+	scriptPublicKey, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("PayToAddrScript: unexpected error: %v", err)
+	}
+
+	txs := make([]*externalapi.DomainTransaction, transactionCount)
+	for i := range txs {
+		inputs := make([]*externalapi.DomainTransactionInput, inputsPerTransaction)
+		for j := range inputs {
+			inputs[j] = &externalapi.DomainTransactionInput{
+				PreviousOutpoint: externalapi.DomainOutpoint{
+					TransactionID: *externalapi.NewDomainTransactionIDFromByteArray(&[externalapi.DomainHashSize]byte{byte(i), byte(i >> 8)}),
+					Index:         uint32(j),
+				},
+				Sequence:   constants.MaxTxInSequenceNum,
+				SigOpCount: 1,
+				UTXOEntry:  utxo.NewUTXOEntry(100_000_000, scriptPublicKey, false, 0),
+			}
+		}
+		tx := &externalapi.DomainTransaction{
+			Version: constants.MaxTransactionVersion,
+			Inputs:  inputs,
+			Outputs: []*externalapi.DomainTransactionOutput{{
+				Value:           1,
+				ScriptPublicKey: scriptPublicKey,
+			}},
+			SubnetworkID: subnetworks.SubnetworkIDNative,
+		}
+
+		sighashReusedValues := &consensushashing.SighashReusedValues{}
+		for j, input := range tx.Inputs {
+			signatureScript, err := txscript.SignatureScript(tx, j, consensushashing.SigHashAll, privateKey,
+				sighashReusedValues)
+			if err != nil {
+				t.Fatalf("Failed to create a sigScript: %v", err)
+			}
+			input.SignatureScript = signatureScript
+		}
+		txs[i] = tx
+	}
+	return txs
+}
+
+func newTransactionValidatorWithoutSigCache() *transactionValidator {
+	return &transactionValidator{
+		sigCache:      txscript.NewSigCache(0),
+		sigCacheECDSA: txscript.NewSigCacheECDSA(0),
+	}
+}
+
+func TestValidateTransactionsScripts(t *testing.T) {
+	validator := newTransactionValidatorWithoutSigCache()
+	workerCounts := []int{1, 2, 8}
+
+	txs := createSignedTransactions(t, 10, 10)
+	for _, workerCount := range workerCounts {
+		err := validator.validateTransactionsScriptsWithWorkers(txs, txscript.ScriptNoFlags, workerCount)
+		if err != nil {
+			t.Fatalf("validateTransactionsScriptsWithWorkers with %d workers: %+v", workerCount, err)
+		}
+	}
+
+	// Break the signatures of two inputs. No matter how many workers are
+	// used, the error must be the one of the first of them.
+	txs[3].Inputs[7].SignatureScript = txs[3].Inputs[6].SignatureScript
+	txs[8].Inputs[1].SignatureScript = txs[8].Inputs[2].SignatureScript
+	expectedOutpoint := txs[3].Inputs[7].PreviousOutpoint
+	for _, workerCount := range workerCounts {
+		err := validator.validateTransactionsScriptsWithWorkers(txs, txscript.ScriptNoFlags, workerCount)
+		if !errors.Is(err, ruleerrors.ErrScriptValidation) {
+			t.Fatalf("validateTransactionsScriptsWithWorkers with %d workers: expected %s, got %+v",
+				workerCount, ruleerrors.ErrScriptValidation, err)
+		}
+		expectedMessage := fmt.Sprintf("failed to validate input 7 which references output %s", expectedOutpoint)
+		if got := err.Error(); len(got) < len(expectedMessage) || got[:len(expectedMessage)] != expectedMessage {
+			t.Fatalf("validateTransactionsScriptsWithWorkers with %d workers: expected an error starting with "+
+				"%q, got %q", workerCount, expectedMessage, got)
+		}
+	}
+
+	// A missing UTXO entry in an earlier transaction takes precedence
+	// over an invalid script in a later one
+	txs[1].Inputs[4].UTXOEntry = nil
+	for _, workerCount := range workerCounts {
+		err := validator.validateTransactionsScriptsWithWorkers(txs, txscript.ScriptNoFlags, workerCount)
+		if !errors.As(err, &ruleerrors.ErrMissingTxOut{}) {
+			t.Fatalf("validateTransactionsScriptsWithWorkers with %d workers: expected ErrMissingTxOut, got %+v",
+				workerCount, err)
+		}
+	}
+}
+
+func benchmarkValidateTransactionsScripts(b *testing.B, workerCount int) {
+	// A block with a few hundred inputs
+	txs := createSignedTransactions(b, 50, 10)
+	validator := newTransactionValidatorWithoutSigCache()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := validator.validateTransactionsScriptsWithWorkers(txs, txscript.ScriptNoFlags, workerCount)
+		if err != nil {
+			b.Fatalf("validateTransactionsScriptsWithWorkers: %+v", err)
+		}
+	}
+}
+
+func BenchmarkValidateTransactionsScriptsSequential(b *testing.B) {
+	benchmarkValidateTransactionsScripts(b, 1)
+}
+
+func BenchmarkValidateTransactionsScriptsParallel(b *testing.B) {
+	benchmarkValidateTransactionsScripts(b, runtime.GOMAXPROCS(0))
+}
@@ -255,6 +255,113 @@ func TestValidateTransactionInContextAndPopulateFee(t *testing.T) {
 	})
 }
 
+func TestScriptPublicKeyVersion1Activation(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		const activationDAAScore = 1000
+		consensusConfig.ScriptPublicKeyVersion1ActivationDAAScore = activationDAAScore
+
+		factory := consensus.NewFactory()
+		tc, tearDown, err := factory.NewTestConsensus(consensusConfig,
+			"TestScriptPublicKeyVersion1Activation")
+		if err != nil {
+			t.Fatalf("Failed create a NewTestConsensus: %s", err)
+		}
+		defer tearDown(false)
+
+		opTrueScriptPublicKey, _ := testutils.OpTrueScript()
+		txSpending := func(script []byte, sigOpCount byte) *externalapi.DomainTransaction {
+			return &externalapi.DomainTransaction{
+				Version: constants.MaxTransactionVersion,
+				Inputs: []*externalapi.DomainTransactionInput{{
+					PreviousOutpoint: externalapi.DomainOutpoint{Index: 1},
+					SignatureScript:  []byte{},
+					Sequence:         constants.MaxTxInSequenceNum,
+					SigOpCount:       sigOpCount,
+					UTXOEntry: utxo.NewUTXOEntry(
+						100_000_000, // 1 KAS
+						&externalapi.ScriptPublicKey{Script: script, Version: constants.ScriptPublicKeyVersion1},
+						false,
+						0),
+				}},
+				Outputs: []*externalapi.DomainTransactionOutput{{
+					Value:           100_000_000, // 1 KAS
+					ScriptPublicKey: opTrueScriptPublicKey,
+				}},
+				SubnetworkID: subnetworks.SubnetworkIDNative,
+			}
+		}
+
+		// A script that leaves false on the stack
+		txWithFailingScript := txSpending([]byte{txscript.Op0}, 0)
+		// Pushing a script public key to the stack counts as a signature operation
+		// in version 1, but is an unknown opcode in version 0
+		txWithIntrospectionSigOp := txSpending(
+			[]byte{txscript.Op0, txscript.OpTxInputScriptPubKey, txscript.OpDrop, txscript.OpTrue}, 1)
+
+		stagingArea := model.NewStagingArea()
+		stagePOVBlock := func(daaScore uint64) *externalapi.DomainHash {
+			povBlockHash := externalapi.NewDomainHashFromByteArray(&[32]byte{byte(daaScore), byte(daaScore >> 8)})
+			tc.DAABlocksStore().StageDAAScore(stagingArea, povBlockHash, daaScore)
+			// Just use some stub ghostdag data
+			tc.GHOSTDAGDataStore().Stage(stagingArea, povBlockHash, externalapi.NewBlockGHOSTDAGData(
+				0,
+				nil,
+				consensusConfig.GenesisHash,
+				nil,
+				nil,
+				nil), false)
+			return povBlockHash
+		}
+		beforeActivation := stagePOVBlock(activationDAAScore - 1)
+		atActivation := stagePOVBlock(activationDAAScore)
+
+		tests := []struct {
+			name          string
+			tx            *externalapi.DomainTransaction
+			povBlockHash  *externalapi.DomainHash
+			expectedError error
+		}{
+			{
+				// Version 1 is unknown before its activation, so its scripts aren't executed
+				name:         "failing script before the activation",
+				tx:           txWithFailingScript,
+				povBlockHash: beforeActivation,
+			},
+			{
+				name:          "failing script at the activation",
+				tx:            txWithFailingScript,
+				povBlockHash:  atActivation,
+				expectedError: ruleerrors.ErrScriptValidation,
+			},
+			{
+				// Before its activation, version 1 is counted by the rules of version 0
+				name:          "introspection sig op before the activation",
+				tx:            txWithIntrospectionSigOp,
+				povBlockHash:  beforeActivation,
+				expectedError: ruleerrors.ErrWrongSigOpCount,
+			},
+			{
+				name:         "introspection sig op at the activation",
+				tx:           txWithIntrospectionSigOp,
+				povBlockHash: atActivation,
+			},
+		}
+
+		for _, test := range tests {
+			err := tc.TransactionValidator().ValidateTransactionInContextAndPopulateFee(stagingArea, test.tx, test.povBlockHash)
+			if test.expectedError == nil {
+				if err != nil {
+					t.Fatalf("%s: unexpected error: %+v", test.name, err)
+				}
+				continue
+			}
+			if !errors.Is(err, test.expectedError) {
+				t.Fatalf("%s: expected %v, but got: %+v", test.name, test.expectedError, err)
+			}
+		}
+	})
+}
+
 func TestSigningTwoInputs(t *testing.T) {
 	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
 		consensusConfig.BlockCoinbaseMaturity = 0
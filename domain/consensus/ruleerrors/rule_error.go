@@ -240,6 +240,9 @@ var (
 	ErrPruningProofMissesBlocksBelowPruningPoint      = newRuleError("ErrPruningProofMissesBlocksBelowPruningPoint")
 	ErrPruningProofEmpty                              = newRuleError("ErrPruningProofEmpty")
 	ErrWrongCoinbaseSubsidy                           = newRuleError("ErrWrongCoinbaseSubsidy")
+
+	// ErrViolatingCheckpoint indicates that the selected parent chain of a block contradicts one of the checkpoints
+	ErrViolatingCheckpoint = newRuleError("ErrViolatingCheckpoint")
 )
 
 // RuleError identifies a rule violation. It is used to indicate that
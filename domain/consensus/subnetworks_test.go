@@ -0,0 +1,283 @@
+package consensus_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/model/testapi"
+	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/subnetworks"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/testutils"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionhelper"
+)
+
+func TestSubnetworkRegistry(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		consensusConfig.BlockCoinbaseMaturity = 0
+		consensusConfig.EnableNonNativeSubnetworks = true
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestSubnetworkRegistry")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		firstBlockHash, _, err := tc.AddBlock([]*externalapi.DomainHash{consensusConfig.GenesisHash}, nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+		fundingBlockHash, _, err := tc.AddBlock([]*externalapi.DomainHash{firstBlockHash}, nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+		fundingBlock, err := tc.GetBlock(fundingBlockHash)
+		if err != nil {
+			t.Fatalf("GetBlock: %+v", err)
+		}
+
+		const gasLimit = 12345
+		registryTransaction, err := testutils.CreateTransaction(
+			fundingBlock.Transactions[transactionhelper.CoinbaseTransactionIndex], 1000)
+		if err != nil {
+			t.Fatalf("CreateTransaction: %+v", err)
+		}
+		registryTransaction.SubnetworkID = subnetworks.SubnetworkIDRegistry
+		registryTransaction.Payload = subnetworks.RegistryPayload(gasLimit)
+		subnetworkID := subnetworks.FromRegistryTransactionID(consensushashing.TransactionID(registryTransaction))
+
+		registryBlockHash, _, err := tc.AddBlock([]*externalapi.DomainHash{fundingBlockHash}, nil,
+			[]*externalapi.DomainTransaction{registryTransaction})
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+
+		// The registry transaction is only accepted by the block's chain child, so the
+		// subnetwork is not registered yet
+		_, exists, err := tc.GetSubnetworkGasLimit(&subnetworkID)
+		if err != nil {
+			t.Fatalf("GetSubnetworkGasLimit: %+v", err)
+		}
+		if exists {
+			t.Fatalf("Subnetwork %s is not expected to be registered before its registry "+
+				"transaction is accepted", subnetworkID)
+		}
+
+		_, _, err = tc.AddBlock([]*externalapi.DomainHash{registryBlockHash}, nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+		registeredGasLimit, exists, err := tc.GetSubnetworkGasLimit(&subnetworkID)
+		if err != nil {
+			t.Fatalf("GetSubnetworkGasLimit: %+v", err)
+		}
+		if !exists {
+			t.Fatalf("Subnetwork %s is expected to be registered", subnetworkID)
+		}
+		if registeredGasLimit != gasLimit {
+			t.Fatalf("Expected the gas limit of subnetwork %s to be %d, but got %d",
+				subnetworkID, gasLimit, registeredGasLimit)
+		}
+	})
+}
+
+func TestSubnetworkRegistryReorg(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		consensusConfig.BlockCoinbaseMaturity = 0
+		consensusConfig.EnableNonNativeSubnetworks = true
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestSubnetworkRegistryReorg")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		firstBlockHash, _, err := tc.AddBlock([]*externalapi.DomainHash{consensusConfig.GenesisHash}, nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+		fundingBlockHash, _, err := tc.AddBlock([]*externalapi.DomainHash{firstBlockHash}, nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+		registryTransaction, subnetworkID := createRegistryTransaction(t, tc, fundingBlockHash, 12345)
+
+		expectRegistered := func(expected bool) {
+			_, exists, err := tc.GetSubnetworkGasLimit(&subnetworkID)
+			if err != nil {
+				t.Fatalf("GetSubnetworkGasLimit: %+v", err)
+			}
+			if exists != expected {
+				t.Fatalf("Expected subnetwork %s to be registered: %t, but got: %t", subnetworkID, expected, exists)
+			}
+		}
+
+		// Chain A: the registry transaction is accepted by the chain child of its block
+		chainATip, _, err := tc.AddBlock([]*externalapi.DomainHash{fundingBlockHash}, nil,
+			[]*externalapi.DomainTransaction{registryTransaction})
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+		chainATip, _, err = tc.AddBlock([]*externalapi.DomainHash{chainATip}, nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+		expectRegistered(true)
+
+		// Chain B: a longer chain that doesn't contain the registry transaction
+		chainBTip := fundingBlockHash
+		for i := 0; i < 3; i++ {
+			chainBTip, _, err = tc.AddBlock([]*externalapi.DomainHash{chainBTip}, nil, nil)
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+		}
+		virtualSelectedParent, err := tc.GetVirtualSelectedParent()
+		if err != nil {
+			t.Fatalf("GetVirtualSelectedParent: %+v", err)
+		}
+		if !virtualSelectedParent.Equal(chainBTip) {
+			t.Fatalf("Expected chain B to be the virtual selected parent chain")
+		}
+		expectRegistered(false)
+
+		// Extending chain A makes it the virtual selected parent chain again
+		for i := 0; i < 2; i++ {
+			chainATip, _, err = tc.AddBlock([]*externalapi.DomainHash{chainATip}, nil, nil)
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+		}
+		virtualSelectedParent, err = tc.GetVirtualSelectedParent()
+		if err != nil {
+			t.Fatalf("GetVirtualSelectedParent: %+v", err)
+		}
+		if !virtualSelectedParent.Equal(chainATip) {
+			t.Fatalf("Expected chain A to be the virtual selected parent chain")
+		}
+		expectRegistered(true)
+	})
+}
+
+func TestPruningPointSubnetworkRegistrations(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		consensusConfig.BlockCoinbaseMaturity = 0
+		consensusConfig.EnableNonNativeSubnetworks = true
+		// This is done to reduce the pruning depth
+		consensusConfig.FinalityDuration = 5 * consensusConfig.TargetTimePerBlock
+		consensusConfig.K = 0
+		consensusConfig.PruningProofM = 1
+
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestPruningPointSubnetworkRegistrations")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		addBlock := func(parentHash *externalapi.DomainHash,
+			transactions []*externalapi.DomainTransaction) *externalapi.DomainHash {
+
+			blockHash, _, err := tc.AddBlock([]*externalapi.DomainHash{parentHash}, nil, transactions)
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+			return blockHash
+		}
+		pruningPointRegistrations := func() []*externalapi.SubnetworkRegistration {
+			pruningPoint, err := tc.PruningPoint()
+			if err != nil {
+				t.Fatalf("PruningPoint: %+v", err)
+			}
+			registrations, err := tc.GetPruningPointSubnetworkRegistrations(pruningPoint)
+			if err != nil {
+				t.Fatalf("GetPruningPointSubnetworkRegistrations: %+v", err)
+			}
+			return registrations
+		}
+
+		tipHash := addBlock(consensusConfig.GenesisHash, nil)
+		tipHash = addBlock(tipHash, nil)
+		belowPruningPointTransaction, belowPruningPointSubnetworkID := createRegistryTransaction(t, tc, tipHash, 1)
+		tipHash = addBlock(tipHash, []*externalapi.DomainTransaction{belowPruningPointTransaction})
+		tipHash = addBlock(tipHash, nil)
+
+		if len(pruningPointRegistrations()) != 0 {
+			t.Fatalf("Subnetworks that are registered above the pruning point are not expected " +
+				"to be in its registrations")
+		}
+
+		// Move the pruning point above the block that accepted the registry transaction
+		const maxBlocks = 100
+		for i := 0; len(pruningPointRegistrations()) == 0; i++ {
+			if i == maxBlocks {
+				t.Fatalf("The pruning point didn't move above the registry transaction after %d blocks", maxBlocks)
+			}
+			tipHash = addBlock(tipHash, nil)
+		}
+
+		abovePruningPointTransaction, abovePruningPointSubnetworkID := createRegistryTransaction(t, tc, tipHash, 2)
+		tipHash = addBlock(tipHash, []*externalapi.DomainTransaction{abovePruningPointTransaction})
+		addBlock(tipHash, nil)
+		_, exists, err := tc.GetSubnetworkGasLimit(&abovePruningPointSubnetworkID)
+		if err != nil {
+			t.Fatalf("GetSubnetworkGasLimit: %+v", err)
+		}
+		if !exists {
+			t.Fatalf("Subnetwork %s is expected to be registered", abovePruningPointSubnetworkID)
+		}
+
+		registrations := pruningPointRegistrations()
+		if len(registrations) != 1 || registrations[0].SubnetworkID != belowPruningPointSubnetworkID ||
+			registrations[0].GasLimit != 1 {
+
+			t.Fatalf("Expected the pruning point registrations to consist of subnetwork %s "+
+				"with gas limit 1, but got %v", belowPruningPointSubnetworkID, registrations)
+		}
+
+		_, err = tc.GetPruningPointSubnetworkRegistrations(tipHash)
+		if !errors.Is(err, ruleerrors.ErrWrongPruningPointHash) {
+			t.Fatalf("Expected ErrWrongPruningPointHash, but got: %+v", err)
+		}
+
+		tcSyncee, teardownSyncee, err := factory.NewTestConsensus(consensusConfig,
+			"TestPruningPointSubnetworkRegistrationsSyncee")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardownSyncee(false)
+
+		err = tcSyncee.ImportPruningPointSubnetworkRegistrations(registrations)
+		if err != nil {
+			t.Fatalf("ImportPruningPointSubnetworkRegistrations: %+v", err)
+		}
+		gasLimit, exists, err := tcSyncee.GetSubnetworkGasLimit(&belowPruningPointSubnetworkID)
+		if err != nil {
+			t.Fatalf("GetSubnetworkGasLimit: %+v", err)
+		}
+		if !exists || gasLimit != 1 {
+			t.Fatalf("Expected subnetwork %s to be imported with gas limit 1", belowPruningPointSubnetworkID)
+		}
+	})
+}
+
+func createRegistryTransaction(t *testing.T, tc testapi.TestConsensus, fundingBlockHash *externalapi.DomainHash,
+	gasLimit uint64) (*externalapi.DomainTransaction, externalapi.DomainSubnetworkID) {
+
+	fundingBlock, err := tc.GetBlock(fundingBlockHash)
+	if err != nil {
+		t.Fatalf("GetBlock: %+v", err)
+	}
+
+	registryTransaction, err := testutils.CreateTransaction(
+		fundingBlock.Transactions[transactionhelper.CoinbaseTransactionIndex], 1000)
+	if err != nil {
+		t.Fatalf("CreateTransaction: %+v", err)
+	}
+	registryTransaction.SubnetworkID = subnetworks.SubnetworkIDRegistry
+	registryTransaction.Payload = subnetworks.RegistryPayload(gasLimit)
+	subnetworkID := subnetworks.FromRegistryTransactionID(consensushashing.TransactionID(registryTransaction))
+	return registryTransaction, subnetworkID
+}
@@ -10,9 +10,14 @@ const (
 	// MaxTransactionVersion is the current latest supported transaction version.
 	MaxTransactionVersion uint16 = 0
 
-	// MaxScriptPublicKeyVersion is the current latest supported public key script version.
+	// MaxScriptPublicKeyVersion is the latest public key script version that's active since genesis.
 	MaxScriptPublicKeyVersion uint16 = 0
 
+	// ScriptPublicKeyVersion1 is the public key script version that re-enables byte and arithmetic
+	// opcodes and adds transaction introspection. It's only active from its activation DAA score,
+	// and until then it's unknown, like any other version above MaxScriptPublicKeyVersion.
+	ScriptPublicKeyVersion1 uint16 = 1
+
 	// SompiPerKaspa is the number of sompi in one kaspa (1 KAS).
 	SompiPerKaspa = 100_000_000
 
@@ -62,6 +62,13 @@ func (state *State) CalculateProofOfWorkValue() *big.Int {
 	return toBig(heavyHash)
 }
 
+// PrePowHash returns the hash of the header with its timestamp and nonce zeroed out,
+// which is what the PoW of the header is calculated over along with them
+func (state *State) PrePowHash() *externalapi.DomainHash {
+	prePowHash := state.prePowHash
+	return &prePowHash
+}
+
 // IncrementNonce the nonce in State by 1
 func (state *State) IncrementNonce() {
 	state.Nonce++
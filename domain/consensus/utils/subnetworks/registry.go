@@ -0,0 +1,38 @@
+package subnetworks
+
+import (
+	"encoding/binary"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/pkg/errors"
+)
+
+// RegistryPayloadLength is the length of the payload of a subnetwork registry transaction,
+// which consists of the gas limit of the registered subnetwork
+const RegistryPayloadLength = 8
+
+// RegistryPayload returns the payload of a subnetwork registry transaction
+// that registers a subnetwork with the given gas limit
+func RegistryPayload(gasLimit uint64) []byte {
+	payload := make([]byte, RegistryPayloadLength)
+	binary.LittleEndian.PutUint64(payload, gasLimit)
+	return payload
+}
+
+// GasLimitFromRegistryPayload returns the gas limit of the subnetwork registered by
+// a subnetwork registry transaction with the given payload
+func GasLimitFromRegistryPayload(payload []byte) (uint64, error) {
+	if len(payload) != RegistryPayloadLength {
+		return 0, errors.Errorf("invalid subnetwork registry payload length. Want: %d, got: %d",
+			RegistryPayloadLength, len(payload))
+	}
+	return binary.LittleEndian.Uint64(payload), nil
+}
+
+// FromRegistryTransactionID returns the ID of the subnetwork registered by the subnetwork
+// registry transaction with the given ID
+func FromRegistryTransactionID(transactionID *externalapi.DomainTransactionID) externalapi.DomainSubnetworkID {
+	var subnetworkID externalapi.DomainSubnetworkID
+	copy(subnetworkID[:], transactionID.ByteSlice())
+	return subnetworkID
+}
@@ -0,0 +1,117 @@
+package txscript
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
+	"github.com/pkg/errors"
+)
+
+// assemblyOpcodes returns a map from every name an opcode may be given in
+// assembly to its value
+func assemblyOpcodes() map[string]byte {
+	opcodes := make(map[string]byte, 2*len(OpcodeByName))
+	for opcodeName, opcodeValue := range OpcodeByName {
+		opcodes[opcodeName] = opcodeValue
+
+		// The opcodes named OP_# can't have the OP_ prefix stripped or they
+		// would conflict with decimal numbers. OP_FALSE and OP_TRUE are
+		// aliases of OP_0 and OP_1, so they are detected by name instead.
+		if strings.Contains(opcodeName, "OP_UNKNOWN") {
+			continue
+		}
+		if opcodeName == "OP_FALSE" || opcodeName == "OP_TRUE" ||
+			(opcodeValue != Op0 && (opcodeValue < Op1 || opcodeValue > Op16)) {
+
+			opcodes[strings.TrimPrefix(opcodeName, "OP_")] = opcodeValue
+		}
+	}
+	return opcodes
+}
+
+// Assemble returns the script described by the given assembly. The assembly is
+// a whitespace separated list of tokens, each of which is one of:
+//   - An opcode name, with or without the OP_ prefix (e.g. OP_CHECKSIG or
+//     CHECKSIG). The OP_# opcodes must keep their prefix (e.g. OP_2).
+//   - A decimal number, pushed as a minimally encoded number (e.g. 1000).
+//   - Hex data in angle brackets, pushed canonically (e.g. <0102ab>).
+//   - Raw script bytes in hex with a 0x prefix, which are inserted into the
+//     script as they are (e.g. 0x4c0102).
+//   - A string without whitespace in single quotes, pushed canonically
+//     (e.g. 'secret').
+func Assemble(assembly string) ([]byte, error) {
+	opcodes := assemblyOpcodes()
+	builder := NewScriptBuilder()
+	for _, token := range strings.Fields(assembly) {
+		if opcode, ok := opcodes[token]; ok {
+			builder.AddOp(opcode)
+			continue
+		}
+		if number, err := strconv.ParseInt(token, 10, 64); err == nil {
+			builder.AddInt64(number)
+			continue
+		}
+
+		switch {
+		case len(token) >= 2 && token[0] == '<' && token[len(token)-1] == '>':
+			data, err := hex.DecodeString(token[1 : len(token)-1])
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid data push %s", token)
+			}
+			builder.AddData(data)
+		case strings.HasPrefix(token, "0x"):
+			rawBytes, err := hex.DecodeString(token[2:])
+			if err != nil || len(rawBytes) == 0 {
+				return nil, errors.Errorf("invalid raw script bytes %s", token)
+			}
+			builder.AddOps(rawBytes)
+		case len(token) >= 2 && token[0] == '\'' && token[len(token)-1] == '\'':
+			builder.AddData([]byte(token[1 : len(token)-1]))
+		default:
+			return nil, errors.Errorf("unknown token %q", token)
+		}
+	}
+	return builder.Script()
+}
+
+// Disassemble returns the assembly of the given script. Unlike DisasmString, the
+// result is in the format of Assemble, and assembling it returns the original
+// script: canonical data pushes are written as <hex>, while non-canonical and
+// oversized data pushes are written as raw script bytes.
+func Disassemble(version uint16, script []byte) (string, error) {
+	if version > constants.ScriptPublicKeyVersion1 {
+		return "", errors.Errorf("unknown script version %d (max: %d)",
+			version, constants.ScriptPublicKeyVersion1)
+	}
+	parsedOpcodes, err := parseScriptForVersion(script, version)
+	if err != nil {
+		return "", err
+	}
+
+	tokens := make([]string, len(parsedOpcodes))
+	for i, parsedOpcode := range parsedOpcodes {
+		if parsedOpcode.opcode.length == 1 {
+			tokens[i] = parsedOpcode.opcode.name
+			continue
+		}
+
+		opcodeBytes, err := parsedOpcode.bytes()
+		if err != nil {
+			return "", err
+		}
+		canonicalBytes, err := NewScriptBuilder().AddFullData(parsedOpcode.data).Script()
+		if err != nil {
+			return "", err
+		}
+		if len(parsedOpcode.data) <= MaxScriptElementSize && bytes.Equal(opcodeBytes, canonicalBytes) {
+			tokens[i] = fmt.Sprintf("<%x>", parsedOpcode.data)
+		} else {
+			tokens[i] = fmt.Sprintf("0x%x", opcodeBytes)
+		}
+	}
+	return strings.Join(tokens, " "), nil
+}
@@ -0,0 +1,189 @@
+package txscript
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestAssemble(t *testing.T) {
+	pubKey1 := strings.Repeat("11", 32)
+	pubKey2 := strings.Repeat("22", 32)
+	longData := strings.Repeat("33", 80)
+
+	tests := []struct {
+		name        string
+		assembly    string
+		expectedHex string
+		expectError bool
+	}{
+		{
+			name:        "multisig",
+			assembly:    "OP_2 <" + pubKey1 + "> <" + pubKey2 + "> OP_2 OP_CHECKMULTISIG",
+			expectedHex: "52" + "20" + pubKey1 + "20" + pubKey2 + "52" + "ae",
+		},
+		{
+			name:        "opcodes without the OP_ prefix",
+			assembly:    "DUP\tBLAKE2B\n<" + pubKey1 + "> EQUALVERIFY CHECKSIG",
+			expectedHex: "76aa20" + pubKey1 + "88ac",
+		},
+		{
+			name:        "aliases",
+			assembly:    "TRUE OP_FALSE OP_TRUE FALSE",
+			expectedHex: "51005100",
+		},
+		{
+			name:        "numbers",
+			assembly:    "0 1 16 17 -1 1000",
+			expectedHex: "00" + "51" + "60" + "0111" + "4f" + "02e803",
+		},
+		{
+			name:        "canonical data pushes",
+			assembly:    "<> <00> <05> <81> <0102> <" + longData + ">",
+			expectedHex: "00" + "00" + "55" + "4f" + "020102" + "4c50" + longData,
+		},
+		{
+			name:        "raw bytes",
+			assembly:    "0x4c0105 0x51",
+			expectedHex: "4c010551",
+		},
+		{
+			name:        "string",
+			assembly:    "'kaspa' OP_DROP",
+			expectedHex: "056b6173706175",
+		},
+		{
+			name:        "version 1 opcode",
+			assembly:    "OP_TXINPUTCOUNT OP_UNKNOWN179",
+			expectedHex: "b2b3",
+		},
+		{
+			name:        "empty",
+			assembly:    "  ",
+			expectedHex: "",
+		},
+		{
+			name:        "unknown opcode",
+			assembly:    "OP_NOTANOPCODE",
+			expectError: true,
+		},
+		{
+			name:        "opcode with a suffix",
+			assembly:    "OP_CHECKMULTISIG2",
+			expectError: true,
+		},
+		{
+			name:        "invalid data push",
+			assembly:    "<0g>",
+			expectError: true,
+		},
+		{
+			name:        "empty raw bytes",
+			assembly:    "0x",
+			expectError: true,
+		},
+		{
+			name:        "data push over the element size limit",
+			assembly:    "<" + strings.Repeat("00", MaxScriptElementSize+1) + ">",
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		script, err := Assemble(test.assembly)
+		if test.expectError {
+			if err == nil {
+				t.Errorf("%s: expected an error, got script %x", test.name, script)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+		if hex.EncodeToString(script) != test.expectedHex {
+			t.Errorf("%s: expected script %s, got %x", test.name, test.expectedHex, script)
+		}
+	}
+}
+
+func TestDisassemble(t *testing.T) {
+	pubKey := strings.Repeat("11", 32)
+
+	tests := []struct {
+		name             string
+		version          uint16
+		scriptHex        string
+		expectedAssembly string
+		expectError      bool
+	}{
+		{
+			name:             "pay to pubkey",
+			scriptHex:        "20" + pubKey + "ac",
+			expectedAssembly: "<" + pubKey + "> OP_CHECKSIG",
+		},
+		{
+			name:             "small integers",
+			scriptHex:        "00514f60",
+			expectedAssembly: "OP_0 OP_1 OP_1NEGATE OP_16",
+		},
+		{
+			name:             "non-canonical data pushes",
+			scriptHex:        "0105" + "0181" + "4c020102" + "4d0100ff",
+			expectedAssembly: "0x0105 0x0181 0x4c020102 0x4d0100ff",
+		},
+		{
+			name:             "version 0 introspection opcode",
+			scriptHex:        "b2",
+			expectedAssembly: "OP_UNKNOWN178",
+		},
+		{
+			name:             "version 1 introspection opcode",
+			version:          1,
+			scriptHex:        "b2",
+			expectedAssembly: "OP_TXINPUTCOUNT",
+		},
+		{
+			name:        "truncated data push",
+			scriptHex:   "0501",
+			expectError: true,
+		},
+		{
+			name:        "unknown version",
+			version:     2,
+			scriptHex:   "51",
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		script, err := hex.DecodeString(test.scriptHex)
+		if err != nil {
+			t.Fatalf("%s: invalid test script: %s", test.name, err)
+		}
+		assembly, err := Disassemble(test.version, script)
+		if test.expectError {
+			if err == nil {
+				t.Errorf("%s: expected an error, got assembly %q", test.name, assembly)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+		if assembly != test.expectedAssembly {
+			t.Errorf("%s: expected assembly %q, got %q", test.name, test.expectedAssembly, assembly)
+		}
+
+		reassembledScript, err := Assemble(assembly)
+		if err != nil {
+			t.Errorf("%s: error reassembling %q: %s", test.name, assembly, err)
+			continue
+		}
+		if !bytes.Equal(reassembledScript, script) {
+			t.Errorf("%s: expected the reassembled script to be %x, got %x", test.name, script, reassembledScript)
+		}
+	}
+}
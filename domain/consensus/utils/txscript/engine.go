@@ -20,8 +20,23 @@ type ScriptFlags uint32
 const (
 	// ScriptNoFlags is used when you want to use ScriptFlags without raising any flags
 	ScriptNoFlags ScriptFlags = 0
+
+	// ScriptVersion1Active defines whether scripts of version
+	// constants.ScriptPublicKeyVersion1 are executed. Without it, they're
+	// treated like any other unknown script version: they're not executed,
+	// and are always valid.
+	ScriptVersion1Active ScriptFlags = 1 << 0
 )
 
+// maxScriptVersion returns the latest script version that's active under the
+// given flags.
+func maxScriptVersion(flags ScriptFlags) uint16 {
+	if flags&ScriptVersion1Active == ScriptVersion1Active {
+		return constants.ScriptPublicKeyVersion1
+	}
+	return constants.MaxScriptPublicKeyVersion
+}
+
 const (
 	// MaxStackSize is the maximum combined height of stack and alt stack
 	// during execution.
@@ -56,6 +71,12 @@ func (vm *Engine) hasFlag(flag ScriptFlags) bool {
 	return vm.flags&flag == flag
 }
 
+// isScriptVersionActive returns whether the version of the script public key
+// is active, and therefore the scripts are executed.
+func (vm *Engine) isScriptVersionActive() bool {
+	return vm.scriptVersion <= maxScriptVersion(vm.flags)
+}
+
 // isBranchExecuting returns whether or not the current conditional branch is
 // actively executing. For example, when the data stack has an OP_FALSE on it
 // and an OP_IF is encountered, the branch is inactive until an OP_ELSE or
@@ -72,7 +93,7 @@ func (vm *Engine) isBranchExecuting() bool {
 // tested in this case.
 func (vm *Engine) executeOpcode(pop *parsedOpcode) error {
 	// Disabled opcodes are fail on program counter.
-	if pop.isDisabled() {
+	if pop.isDisabled(vm.scriptVersion) {
 		str := fmt.Sprintf("attempt to execute disabled opcode %s",
 			pop.opcode.name)
 		return scriptError(ErrDisabledOpcode, str)
@@ -285,7 +306,7 @@ func (vm *Engine) Step() (done bool, err error) {
 			}
 
 			script := vm.savedFirstStack[len(vm.savedFirstStack)-1]
-			pops, err := parseScript(script)
+			pops, err := parseScriptForVersion(script, vm.scriptVersion)
 			if err != nil {
 				return false, err
 			}
@@ -311,7 +332,7 @@ func (vm *Engine) Step() (done bool, err error) {
 // Execute will execute all scripts in the script engine and return either nil
 // for successful validation or an error if one occurred.
 func (vm *Engine) Execute() (err error) {
-	if vm.scriptVersion > constants.MaxScriptPublicKeyVersion {
+	if !vm.isScriptVersionActive() {
 		log.Tracef("The version of the scriptPublicKey is higher than the known version - the Execute function returns true.")
 		return nil
 	}
@@ -457,10 +478,14 @@ func NewEngine(scriptPubKey *externalapi.ScriptPublicKey, tx *externalapi.Domain
 	}
 	vm := Engine{scriptVersion: scriptPubKey.Version, flags: flags, sigCache: sigCache, sigCacheECDSA: sigCacheECDSA}
 
-	if vm.scriptVersion > constants.MaxScriptPublicKeyVersion {
+	if !vm.isScriptVersionActive() {
 		return &vm, nil
 	}
-	parsedScriptSig, err := parseScriptAndVerifySize(scriptSig)
+	scriptNumLen := scriptNumLenForScriptVersion(vm.scriptVersion)
+	vm.dstack.scriptNumLen = scriptNumLen
+	vm.astack.scriptNumLen = scriptNumLen
+
+	parsedScriptSig, err := parseScriptAndVerifySize(scriptSig, vm.scriptVersion)
 	if err != nil {
 		return nil, err
 	}
@@ -470,7 +495,7 @@ func NewEngine(scriptPubKey *externalapi.ScriptPublicKey, tx *externalapi.Domain
 			"signature script is not push only")
 	}
 
-	parsedScriptPubKey, err := parseScriptAndVerifySize(scriptPubKey.Script)
+	parsedScriptPubKey, err := parseScriptAndVerifySize(scriptPubKey.Script, vm.scriptVersion)
 	if err != nil {
 		return nil, err
 	}
@@ -505,11 +530,11 @@ func NewEngine(scriptPubKey *externalapi.ScriptPublicKey, tx *externalapi.Domain
 	return &vm, nil
 }
 
-func parseScriptAndVerifySize(script []byte) ([]parsedOpcode, error) {
+func parseScriptAndVerifySize(script []byte, scriptVersion uint16) ([]parsedOpcode, error) {
 	if len(script) > MaxScriptSize {
 		str := fmt.Sprintf("script size %d is larger than max "+
 			"allowed size %d", len(script), MaxScriptSize)
 		return nil, scriptError(ErrScriptTooBig, str)
 	}
-	return parseScript(script)
+	return parseScriptForVersion(script, scriptVersion)
 }
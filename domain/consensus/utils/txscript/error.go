@@ -214,6 +214,22 @@ const (
 	// is not either an empty vector or [0x01].
 	ErrMinimalIf
 
+	// ------------------------------------------
+	// Failures related to script version 1.
+	// ------------------------------------------
+
+	// ErrOutOfRange is returned when an opcode is passed an index or a size
+	// that is out of the bounds of the item or list it applies to.
+	ErrOutOfRange
+
+	// ErrOperandSizeMismatch is returned when the operands of a bitwise
+	// opcode are not of the same size.
+	ErrOperandSizeMismatch
+
+	// ErrDivideByZero is returned when the divisor of OP_DIV or OP_MOD
+	// is zero.
+	ErrDivideByZero
+
 	// numErrorCodes is the maximum error code number used in tests. This
 	// entry MUST be the last entry in the enum.
 	numErrorCodes
@@ -260,6 +276,9 @@ var errorCodeStrings = map[ErrorCode]string{
 	ErrNegativeLockTime:      "ErrNegativeLockTime",
 	ErrUnsatisfiedLockTime:   "ErrUnsatisfiedLockTime",
 	ErrMinimalIf:             "ErrMinimalIf",
+	ErrOutOfRange:            "ErrOutOfRange",
+	ErrOperandSizeMismatch:   "ErrOperandSizeMismatch",
+	ErrDivideByZero:          "ErrDivideByZero",
 }
 
 // String returns the ErrorCode as a human-readable name.
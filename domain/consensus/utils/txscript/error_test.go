@@ -55,6 +55,9 @@ func TestErrorCodeStringer(t *testing.T) {
 		{ErrNegativeLockTime, "ErrNegativeLockTime"},
 		{ErrUnsatisfiedLockTime, "ErrUnsatisfiedLockTime"},
 		{ErrMinimalIf, "ErrMinimalIf"},
+		{ErrOutOfRange, "ErrOutOfRange"},
+		{ErrOperandSizeMismatch, "ErrOperandSizeMismatch"},
+		{ErrDivideByZero, "ErrDivideByZero"},
 		{0xffff, "Unknown ErrorCode (65535)"},
 	}
 
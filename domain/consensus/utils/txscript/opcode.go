@@ -497,16 +497,16 @@ var opcodeArray = [256]opcode{
 	// Undefined opcodes.
 	OpUnknown166: {OpUnknown166, "OP_UNKNOWN166", 1, opcodeInvalid},
 	OpUnknown167: {OpUnknown167, "OP_UNKNOWN167", 1, opcodeInvalid},
-	OpUnknown178: {OpUnknown188, "OP_UNKNOWN178", 1, opcodeInvalid},
-	OpUnknown179: {OpUnknown189, "OP_UNKNOWN179", 1, opcodeInvalid},
-	OpUnknown180: {OpUnknown190, "OP_UNKNOWN180", 1, opcodeInvalid},
-	OpUnknown181: {OpUnknown191, "OP_UNKNOWN181", 1, opcodeInvalid},
-	OpUnknown182: {OpUnknown192, "OP_UNKNOWN182", 1, opcodeInvalid},
-	OpUnknown183: {OpUnknown193, "OP_UNKNOWN183", 1, opcodeInvalid},
-	OpUnknown184: {OpUnknown194, "OP_UNKNOWN184", 1, opcodeInvalid},
-	OpUnknown185: {OpUnknown195, "OP_UNKNOWN185", 1, opcodeInvalid},
-	OpUnknown186: {OpUnknown196, "OP_UNKNOWN186", 1, opcodeInvalid},
-	OpUnknown187: {OpUnknown197, "OP_UNKNOWN187", 1, opcodeInvalid},
+	OpUnknown178: {OpUnknown178, "OP_UNKNOWN178", 1, opcodeInvalid},
+	OpUnknown179: {OpUnknown179, "OP_UNKNOWN179", 1, opcodeInvalid},
+	OpUnknown180: {OpUnknown180, "OP_UNKNOWN180", 1, opcodeInvalid},
+	OpUnknown181: {OpUnknown181, "OP_UNKNOWN181", 1, opcodeInvalid},
+	OpUnknown182: {OpUnknown182, "OP_UNKNOWN182", 1, opcodeInvalid},
+	OpUnknown183: {OpUnknown183, "OP_UNKNOWN183", 1, opcodeInvalid},
+	OpUnknown184: {OpUnknown184, "OP_UNKNOWN184", 1, opcodeInvalid},
+	OpUnknown185: {OpUnknown185, "OP_UNKNOWN185", 1, opcodeInvalid},
+	OpUnknown186: {OpUnknown186, "OP_UNKNOWN186", 1, opcodeInvalid},
+	OpUnknown187: {OpUnknown187, "OP_UNKNOWN187", 1, opcodeInvalid},
 	OpUnknown188: {OpUnknown188, "OP_UNKNOWN188", 1, opcodeInvalid},
 	OpUnknown189: {OpUnknown189, "OP_UNKNOWN189", 1, opcodeInvalid},
 	OpUnknown190: {OpUnknown190, "OP_UNKNOWN190", 1, opcodeInvalid},
@@ -611,9 +611,14 @@ type parsedOpcode struct {
 	data   []byte
 }
 
-// isDisabled returns whether or not the opcode is disabled and thus is always
-// bad to see in the instruction stream (even if turned off by a conditional).
-func (pop *parsedOpcode) isDisabled() bool {
+// isDisabled returns whether or not the opcode is disabled in the given script
+// version and thus is always bad to see in the instruction stream (even if turned
+// off by a conditional).
+func (pop *parsedOpcode) isDisabled(scriptVersion uint16) bool {
+	if scriptVersion == 1 {
+		return pop.isDisabledInVersion1()
+	}
+
 	switch pop.opcode.value {
 	case OpCat:
 		return true
@@ -650,6 +655,24 @@ func (pop *parsedOpcode) isDisabled() bool {
 	}
 }
 
+// isDisabledInVersion1 returns whether or not the opcode is disabled in script
+// version 1, where the splice, bitwise and most of the arithmetic opcodes are
+// re-enabled.
+func (pop *parsedOpcode) isDisabledInVersion1() bool {
+	switch pop.opcode.value {
+	case Op2Mul:
+		return true
+	case Op2Div:
+		return true
+	case OpLShift:
+		return true
+	case OpRShift:
+		return true
+	default:
+		return false
+	}
+}
+
 // alwaysIllegal returns whether or not the opcode is always illegal when passed
 // over by the program counter even if in a non-executed branch (it isn't a
 // coincidence that they are conditionals).
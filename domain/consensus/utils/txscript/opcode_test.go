@@ -33,6 +33,41 @@ func TestOpcodeDisabled(t *testing.T) {
 	}
 }
 
+// TestOpcodeValues ensures that every opcode has the value of the byte it's
+// parsed from, so that parsing a script and serializing it back, or
+// disassembling it and assembling it back, returns the original script.
+func TestOpcodeValues(t *testing.T) {
+	t.Parallel()
+
+	opcodeArrays := []*[256]opcode{&opcodeArray, &opcodeArrayVersion1}
+	for version, opcodes := range opcodeArrays {
+		for i, op := range opcodes {
+			if int(op.value) != i {
+				t.Errorf("version %d: %s has the value %d instead of %d", version, op.name, op.value, i)
+			}
+			if int(OpcodeByName[op.name]) != i {
+				t.Errorf("version %d: OpcodeByName maps %s to %d instead of %d", version, op.name,
+					OpcodeByName[op.name], i)
+			}
+		}
+	}
+
+	for i := OpUnknown178; i <= OpUnknown187; i++ {
+		script := []byte{byte(i)}
+		pops, err := parseScript(script)
+		if err != nil {
+			t.Fatalf("parseScript: %s", err)
+		}
+		unparsed, err := unparseScript(pops)
+		if err != nil {
+			t.Fatalf("unparseScript: %s", err)
+		}
+		if !bytes.Equal(unparsed, script) {
+			t.Errorf("%s: script %x was serialized back as %x", pops[0].opcode.name, script, unparsed)
+		}
+	}
+}
+
 // TestOpcodeDisasm tests the print function for all opcodes in both the oneline
 // and full modes to ensure it provides the expected disassembly.
 func TestOpcodeDisasm(t *testing.T) {
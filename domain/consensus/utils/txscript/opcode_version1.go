@@ -0,0 +1,605 @@
+package txscript
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
+)
+
+// These constants are the values of the transaction introspection opcodes.
+// They only exist in script version 1. In script version 0 the same values
+// are the invalid opcodes OP_UNKNOWN178 through OP_UNKNOWN185.
+const (
+	OpTxInputCount         = 0xb2 // 178
+	OpTxOutputCount        = 0xb3 // 179
+	OpTxInputIndex         = 0xb4 // 180
+	OpTxInputAmount        = 0xb5 // 181
+	OpTxInputScriptPubKey  = 0xb6 // 182
+	OpTxInputDAAScore      = 0xb7 // 183
+	OpTxOutputAmount       = 0xb8 // 184
+	OpTxOutputScriptPubKey = 0xb9 // 185
+)
+
+const (
+	// scriptNumLenVersion1 is the number of bytes data being interpreted
+	// as an integer may be in script version 1. It's large enough to hold
+	// any amount of sompi and any DAA score.
+	scriptNumLenVersion1 = 8
+
+	// maxScriptNum is the largest value that can be encoded in
+	// scriptNumLenVersion1 bytes. The smallest is its negation.
+	maxScriptNum = math.MaxInt64
+)
+
+// opcodeArrayVersion1 holds the opcodes of script version 1. It is the same
+// as opcodeArray except for the splice, bitwise and arithmetic opcodes which
+// are re-enabled, and the transaction introspection opcodes which replace some
+// of the undefined ones.
+var opcodeArrayVersion1 = newOpcodeArrayVersion1()
+
+func newOpcodeArrayVersion1() [256]opcode {
+	opcodes := opcodeArray
+
+	// Splice opcodes.
+	opcodes[OpCat] = opcode{OpCat, "OP_CAT", 1, opcodeCat}
+	opcodes[OpSubStr] = opcode{OpSubStr, "OP_SUBSTR", 1, opcodeSubStr}
+	opcodes[OpLeft] = opcode{OpLeft, "OP_LEFT", 1, opcodeLeft}
+	opcodes[OpRight] = opcode{OpRight, "OP_RIGHT", 1, opcodeRight}
+
+	// Bitwise logic opcodes.
+	opcodes[OpInvert] = opcode{OpInvert, "OP_INVERT", 1, opcodeInvert}
+	opcodes[OpAnd] = opcode{OpAnd, "OP_AND", 1, opcodeAnd}
+	opcodes[OpOr] = opcode{OpOr, "OP_OR", 1, opcodeOr}
+	opcodes[OpXor] = opcode{OpXor, "OP_XOR", 1, opcodeXor}
+
+	// Numeric related opcodes. Numbers are 8 bytes long in script version 1,
+	// so the ones that might overflow are replaced by versions that check
+	// for it.
+	opcodes[Op1Add] = opcode{Op1Add, "OP_1ADD", 1, opcode1AddChecked}
+	opcodes[Op1Sub] = opcode{Op1Sub, "OP_1SUB", 1, opcode1SubChecked}
+	opcodes[OpAdd] = opcode{OpAdd, "OP_ADD", 1, opcodeAddChecked}
+	opcodes[OpSub] = opcode{OpSub, "OP_SUB", 1, opcodeSubChecked}
+	opcodes[OpMul] = opcode{OpMul, "OP_MUL", 1, opcodeMul}
+	opcodes[OpDiv] = opcode{OpDiv, "OP_DIV", 1, opcodeDiv}
+	opcodes[OpMod] = opcode{OpMod, "OP_MOD", 1, opcodeMod}
+
+	// Transaction introspection opcodes.
+	opcodes[OpTxInputCount] = opcode{OpTxInputCount, "OP_TXINPUTCOUNT", 1, opcodeTxInputCount}
+	opcodes[OpTxOutputCount] = opcode{OpTxOutputCount, "OP_TXOUTPUTCOUNT", 1, opcodeTxOutputCount}
+	opcodes[OpTxInputIndex] = opcode{OpTxInputIndex, "OP_TXINPUTINDEX", 1, opcodeTxInputIndex}
+	opcodes[OpTxInputAmount] = opcode{OpTxInputAmount, "OP_TXINPUTAMOUNT", 1, opcodeTxInputAmount}
+	opcodes[OpTxInputScriptPubKey] = opcode{OpTxInputScriptPubKey, "OP_TXINPUTSCRIPTPUBKEY", 1, opcodeTxInputScriptPubKey}
+	opcodes[OpTxInputDAAScore] = opcode{OpTxInputDAAScore, "OP_TXINPUTDAASCORE", 1, opcodeTxInputDAAScore}
+	opcodes[OpTxOutputAmount] = opcode{OpTxOutputAmount, "OP_TXOUTPUTAMOUNT", 1, opcodeTxOutputAmount}
+	opcodes[OpTxOutputScriptPubKey] = opcode{OpTxOutputScriptPubKey, "OP_TXOUTPUTSCRIPTPUBKEY", 1, opcodeTxOutputScriptPubKey}
+
+	return opcodes
+}
+
+func init() {
+	for _, op := range opcodeArrayVersion1 {
+		if _, ok := OpcodeByName[op.name]; !ok {
+			OpcodeByName[op.name] = op.value
+		}
+	}
+}
+
+// opcodesForScriptVersion returns the opcodes of the given script version.
+// Unknown script versions are never executed, and are parsed with the opcodes
+// of version 0.
+func opcodesForScriptVersion(version uint16) *[256]opcode {
+	if version == constants.ScriptPublicKeyVersion1 {
+		return &opcodeArrayVersion1
+	}
+	return &opcodeArray
+}
+
+// scriptNumLenForScriptVersion returns the number of bytes data being
+// interpreted as an integer may be in the given script version.
+func scriptNumLenForScriptVersion(version uint16) int {
+	if version == constants.ScriptPublicKeyVersion1 {
+		return scriptNumLenVersion1
+	}
+	return defaultScriptNumLen
+}
+
+// parseScriptForVersion is the same as parseScript, except it uses the opcodes of
+// the given script version.
+func parseScriptForVersion(script []byte, version uint16) ([]parsedOpcode, error) {
+	return parseScriptTemplate(script, opcodesForScriptVersion(version))
+}
+
+// isIntrospectionScriptPubKeyOpcode returns whether pop is one of the introspection
+// opcodes that push a script public key to the stack. Those count as signature
+// operations, so that their cost is reflected in the mass of the transaction.
+func isIntrospectionScriptPubKeyOpcode(pop *parsedOpcode) bool {
+	switch pop.opcode {
+	case &opcodeArrayVersion1[OpTxInputScriptPubKey], &opcodeArrayVersion1[OpTxOutputScriptPubKey]:
+		return true
+	default:
+		return false
+	}
+}
+
+// checkScriptNumRange returns an error if the result of a numeric operation
+// can't be interpreted as an integer by other numeric opcodes in script
+// version 1.
+func checkScriptNumRange(n scriptNum, overflowed bool) error {
+	if overflowed || n < -maxScriptNum {
+		return scriptError(ErrNumberTooBig, "numeric operation overflowed")
+	}
+	return nil
+}
+
+// checkElementSize returns an error if data is too large to be pushed to the stack.
+func checkElementSize(data []byte) error {
+	if len(data) > MaxScriptElementSize {
+		str := fmt.Sprintf("element size %d exceeds max allowed size %d",
+			len(data), MaxScriptElementSize)
+		return scriptError(ErrElementTooBig, str)
+	}
+	return nil
+}
+
+// opcodeCat replaces the top two items on the data stack with their concatenation.
+// The result may not be larger than MaxScriptElementSize.
+//
+// Stack transformation: [... x1 x2] -> [... x1||x2]
+func opcodeCat(op *parsedOpcode, vm *Engine) error {
+	b, err := vm.dstack.PopByteArray()
+	if err != nil {
+		return err
+	}
+	a, err := vm.dstack.PopByteArray()
+	if err != nil {
+		return err
+	}
+
+	result := make([]byte, 0, len(a)+len(b))
+	result = append(result, a...)
+	result = append(result, b...)
+	err = checkElementSize(result)
+	if err != nil {
+		return err
+	}
+
+	vm.dstack.PushByteArray(result)
+	return nil
+}
+
+// opcodeSubStr replaces the third-to-top item on the data stack with the part of
+// it that starts at the index given by the second-to-top item, and is as long as
+// the top item. Both must be inside the bounds of the third-to-top item.
+//
+// Stack transformation: [... x1 begin size] -> [... x1[begin:begin+size]]
+func opcodeSubStr(op *parsedOpcode, vm *Engine) error {
+	size, err := vm.dstack.PopInt()
+	if err != nil {
+		return err
+	}
+	begin, err := vm.dstack.PopInt()
+	if err != nil {
+		return err
+	}
+	data, err := vm.dstack.PopByteArray()
+	if err != nil {
+		return err
+	}
+
+	if begin < 0 || size < 0 || begin > scriptNum(len(data)) || size > scriptNum(len(data))-begin {
+		str := fmt.Sprintf("substring [%d:%d+%d] is out of the bounds of "+
+			"an item of size %d", begin, begin, size, len(data))
+		return scriptError(ErrOutOfRange, str)
+	}
+
+	vm.dstack.PushByteArray(data[begin : begin+size])
+	return nil
+}
+
+// popSpliceSize pops the size operand of OP_LEFT and OP_RIGHT, and the item it
+// applies to, and makes sure the size is inside the bounds of the item.
+func popSpliceSize(op *parsedOpcode, vm *Engine) (data []byte, size int, err error) {
+	n, err := vm.dstack.PopInt()
+	if err != nil {
+		return nil, 0, err
+	}
+	data, err = vm.dstack.PopByteArray()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if n < 0 || n > scriptNum(len(data)) {
+		str := fmt.Sprintf("%s of %d bytes is out of the bounds of "+
+			"an item of size %d", op.opcode.name, n, len(data))
+		return nil, 0, scriptError(ErrOutOfRange, str)
+	}
+	return data, int(n), nil
+}
+
+// opcodeLeft replaces the second-to-top item on the data stack with its first
+// bytes. The number of bytes to keep is given by the top item.
+//
+// Stack transformation: [... x1 size] -> [... x1[:size]]
+func opcodeLeft(op *parsedOpcode, vm *Engine) error {
+	data, size, err := popSpliceSize(op, vm)
+	if err != nil {
+		return err
+	}
+
+	vm.dstack.PushByteArray(data[:size])
+	return nil
+}
+
+// opcodeRight replaces the second-to-top item on the data stack with its last
+// bytes. The number of bytes to keep is given by the top item.
+//
+// Stack transformation: [... x1 size] -> [... x1[len(x1)-size:]]
+func opcodeRight(op *parsedOpcode, vm *Engine) error {
+	data, size, err := popSpliceSize(op, vm)
+	if err != nil {
+		return err
+	}
+
+	vm.dstack.PushByteArray(data[len(data)-size:])
+	return nil
+}
+
+// opcodeInvert replaces the top item on the data stack with its bitwise inversion.
+//
+// Stack transformation: [... x1] -> [... ~x1]
+func opcodeInvert(op *parsedOpcode, vm *Engine) error {
+	data, err := vm.dstack.PopByteArray()
+	if err != nil {
+		return err
+	}
+
+	result := make([]byte, len(data))
+	for i := range data {
+		result[i] = ^data[i]
+	}
+	vm.dstack.PushByteArray(result)
+	return nil
+}
+
+// bitwiseOperation replaces the top two items on the data stack, which must be
+// of the same size, with the result of applying operation to each pair of their
+// bytes.
+func bitwiseOperation(op *parsedOpcode, vm *Engine, operation func(a, b byte) byte) error {
+	b, err := vm.dstack.PopByteArray()
+	if err != nil {
+		return err
+	}
+	a, err := vm.dstack.PopByteArray()
+	if err != nil {
+		return err
+	}
+
+	if len(a) != len(b) {
+		str := fmt.Sprintf("%s operands are of different sizes %d and %d",
+			op.opcode.name, len(a), len(b))
+		return scriptError(ErrOperandSizeMismatch, str)
+	}
+
+	result := make([]byte, len(a))
+	for i := range a {
+		result[i] = operation(a[i], b[i])
+	}
+	vm.dstack.PushByteArray(result)
+	return nil
+}
+
+// opcodeAnd replaces the top two items on the data stack with their bitwise AND.
+//
+// Stack transformation: [... x1 x2] -> [... x1&x2]
+func opcodeAnd(op *parsedOpcode, vm *Engine) error {
+	return bitwiseOperation(op, vm, func(a, b byte) byte { return a & b })
+}
+
+// opcodeOr replaces the top two items on the data stack with their bitwise OR.
+//
+// Stack transformation: [... x1 x2] -> [... x1|x2]
+func opcodeOr(op *parsedOpcode, vm *Engine) error {
+	return bitwiseOperation(op, vm, func(a, b byte) byte { return a | b })
+}
+
+// opcodeXor replaces the top two items on the data stack with their bitwise XOR.
+//
+// Stack transformation: [... x1 x2] -> [... x1^x2]
+func opcodeXor(op *parsedOpcode, vm *Engine) error {
+	return bitwiseOperation(op, vm, func(a, b byte) byte { return a ^ b })
+}
+
+// popTwoInts pops the top two items on the data stack as integers. a is the
+// second-to-top item and b is the top one.
+func popTwoInts(vm *Engine) (a, b scriptNum, err error) {
+	b, err = vm.dstack.PopInt()
+	if err != nil {
+		return 0, 0, err
+	}
+	a, err = vm.dstack.PopInt()
+	if err != nil {
+		return 0, 0, err
+	}
+	return a, b, nil
+}
+
+// opcode1AddChecked is the same as opcode1Add, except it fails when the result
+// overflows.
+//
+// Stack transformation: [... x1 x2] -> [... x1 x2+1]
+func opcode1AddChecked(op *parsedOpcode, vm *Engine) error {
+	m, err := vm.dstack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	err = checkScriptNumRange(m, m == maxScriptNum)
+	if err != nil {
+		return err
+	}
+	vm.dstack.PushInt(m + 1)
+	return nil
+}
+
+// opcode1SubChecked is the same as opcode1Sub, except it fails when the result
+// overflows.
+//
+// Stack transformation: [... x1 x2] -> [... x1 x2-1]
+func opcode1SubChecked(op *parsedOpcode, vm *Engine) error {
+	m, err := vm.dstack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	result := m - 1
+	err = checkScriptNumRange(result, false)
+	if err != nil {
+		return err
+	}
+	vm.dstack.PushInt(result)
+	return nil
+}
+
+// opcodeAddChecked is the same as opcodeAdd, except it fails when the result
+// overflows.
+//
+// Stack transformation: [... x1 x2] -> [... x1+x2]
+func opcodeAddChecked(op *parsedOpcode, vm *Engine) error {
+	a, b, err := popTwoInts(vm)
+	if err != nil {
+		return err
+	}
+
+	result := a + b
+	err = checkScriptNumRange(result, (b > 0 && result < a) || (b < 0 && result > a))
+	if err != nil {
+		return err
+	}
+	vm.dstack.PushInt(result)
+	return nil
+}
+
+// opcodeSubChecked is the same as opcodeSub, except it fails when the result
+// overflows.
+//
+// Stack transformation: [... x1 x2] -> [... x1-x2]
+func opcodeSubChecked(op *parsedOpcode, vm *Engine) error {
+	a, b, err := popTwoInts(vm)
+	if err != nil {
+		return err
+	}
+
+	result := a - b
+	err = checkScriptNumRange(result, (b > 0 && result > a) || (b < 0 && result < a))
+	if err != nil {
+		return err
+	}
+	vm.dstack.PushInt(result)
+	return nil
+}
+
+// opcodeMul treats the top two items on the data stack as integers and replaces
+// them with their product. It fails when the product overflows.
+//
+// Stack transformation: [... x1 x2] -> [... x1*x2]
+func opcodeMul(op *parsedOpcode, vm *Engine) error {
+	a, b, err := popTwoInts(vm)
+	if err != nil {
+		return err
+	}
+
+	result := a * b
+	err = checkScriptNumRange(result, a != 0 && result/a != b)
+	if err != nil {
+		return err
+	}
+	vm.dstack.PushInt(result)
+	return nil
+}
+
+// popDivisionOperands pops the operands of OP_DIV and OP_MOD and makes sure
+// the divisor isn't zero.
+func popDivisionOperands(vm *Engine) (dividend, divisor scriptNum, err error) {
+	dividend, divisor, err = popTwoInts(vm)
+	if err != nil {
+		return 0, 0, err
+	}
+	if divisor == 0 {
+		return 0, 0, scriptError(ErrDivideByZero, "division by zero")
+	}
+	return dividend, divisor, nil
+}
+
+// opcodeDiv treats the top two items on the data stack as integers and replaces
+// them with the result of dividing the second-to-top entry by the top entry,
+// rounded towards zero.
+//
+// Stack transformation: [... x1 x2] -> [... x1/x2]
+func opcodeDiv(op *parsedOpcode, vm *Engine) error {
+	dividend, divisor, err := popDivisionOperands(vm)
+	if err != nil {
+		return err
+	}
+
+	vm.dstack.PushInt(dividend / divisor)
+	return nil
+}
+
+// opcodeMod treats the top two items on the data stack as integers and replaces
+// them with the remainder of dividing the second-to-top entry by the top entry.
+// The remainder has the sign of the dividend.
+//
+// Stack transformation: [... x1 x2] -> [... x1%x2]
+func opcodeMod(op *parsedOpcode, vm *Engine) error {
+	dividend, divisor, err := popDivisionOperands(vm)
+	if err != nil {
+		return err
+	}
+
+	vm.dstack.PushInt(dividend % divisor)
+	return nil
+}
+
+// pushUint64 pushes value, which may be an amount or a DAA score, to the data stack
+// as an integer.
+func pushUint64(vm *Engine, value uint64) error {
+	if value > maxScriptNum {
+		str := fmt.Sprintf("value %d can't be represented as a number", value)
+		return scriptError(ErrNumberTooBig, str)
+	}
+	vm.dstack.PushInt(scriptNum(value))
+	return nil
+}
+
+// pushScriptPubKey pushes the serialization of a script public key to the data stack:
+// its version as a 2 byte little endian number, followed by its script.
+func pushScriptPubKey(vm *Engine, version uint16, script []byte) error {
+	serialized := make([]byte, 2, 2+len(script))
+	binary.LittleEndian.PutUint16(serialized, version)
+	serialized = append(serialized, script...)
+	err := checkElementSize(serialized)
+	if err != nil {
+		return err
+	}
+
+	vm.dstack.PushByteArray(serialized)
+	return nil
+}
+
+// popIndex pops the top item on the data stack as an index, and makes sure it's
+// lower than count.
+func popIndex(op *parsedOpcode, vm *Engine, count int) (int, error) {
+	index, err := vm.dstack.PopInt()
+	if err != nil {
+		return 0, err
+	}
+	if index < 0 || index >= scriptNum(count) {
+		str := fmt.Sprintf("%s index %d is out of range [0, %d)",
+			op.opcode.name, index, count)
+		return 0, scriptError(ErrOutOfRange, str)
+	}
+	return int(index), nil
+}
+
+// popInputUTXOEntry pops an input index from the data stack, and makes sure
+// the UTXO entry spent by that input is available.
+func popInputUTXOEntry(op *parsedOpcode, vm *Engine) (int, error) {
+	index, err := popIndex(op, vm, len(vm.tx.Inputs))
+	if err != nil {
+		return 0, err
+	}
+	if vm.tx.Inputs[index].UTXOEntry == nil {
+		str := fmt.Sprintf("the UTXO entry of input %d is not available", index)
+		return 0, scriptError(ErrInternal, str)
+	}
+	return index, nil
+}
+
+// opcodeTxInputCount pushes the number of inputs of the transaction to the data stack.
+//
+// Stack transformation: [...] -> [... count]
+func opcodeTxInputCount(op *parsedOpcode, vm *Engine) error {
+	vm.dstack.PushInt(scriptNum(len(vm.tx.Inputs)))
+	return nil
+}
+
+// opcodeTxOutputCount pushes the number of outputs of the transaction to the data stack.
+//
+// Stack transformation: [...] -> [... count]
+func opcodeTxOutputCount(op *parsedOpcode, vm *Engine) error {
+	vm.dstack.PushInt(scriptNum(len(vm.tx.Outputs)))
+	return nil
+}
+
+// opcodeTxInputIndex pushes the index of the input being validated to the data stack.
+//
+// Stack transformation: [...] -> [... index]
+func opcodeTxInputIndex(op *parsedOpcode, vm *Engine) error {
+	vm.dstack.PushInt(scriptNum(vm.txIdx))
+	return nil
+}
+
+// opcodeTxInputAmount replaces the input index on top of the data stack with the
+// amount of the UTXO spent by that input.
+//
+// Stack transformation: [... index] -> [... amount]
+func opcodeTxInputAmount(op *parsedOpcode, vm *Engine) error {
+	index, err := popInputUTXOEntry(op, vm)
+	if err != nil {
+		return err
+	}
+	return pushUint64(vm, vm.tx.Inputs[index].UTXOEntry.Amount())
+}
+
+// opcodeTxInputScriptPubKey replaces the input index on top of the data stack with
+// the script public key of the UTXO spent by that input.
+//
+// Stack transformation: [... index] -> [... version||script]
+func opcodeTxInputScriptPubKey(op *parsedOpcode, vm *Engine) error {
+	index, err := popInputUTXOEntry(op, vm)
+	if err != nil {
+		return err
+	}
+	scriptPubKey := vm.tx.Inputs[index].UTXOEntry.ScriptPublicKey()
+	return pushScriptPubKey(vm, scriptPubKey.Version, scriptPubKey.Script)
+}
+
+// opcodeTxInputDAAScore replaces the input index on top of the data stack with
+// the DAA score of the block that created the UTXO spent by that input. It fails
+// if the UTXO has not been accepted by a block yet.
+//
+// Stack transformation: [... index] -> [... daaScore]
+func opcodeTxInputDAAScore(op *parsedOpcode, vm *Engine) error {
+	index, err := popInputUTXOEntry(op, vm)
+	if err != nil {
+		return err
+	}
+	return pushUint64(vm, vm.tx.Inputs[index].UTXOEntry.BlockDAAScore())
+}
+
+// opcodeTxOutputAmount replaces the output index on top of the data stack with
+// the amount of that output.
+//
+// Stack transformation: [... index] -> [... amount]
+func opcodeTxOutputAmount(op *parsedOpcode, vm *Engine) error {
+	index, err := popIndex(op, vm, len(vm.tx.Outputs))
+	if err != nil {
+		return err
+	}
+	return pushUint64(vm, vm.tx.Outputs[index].Value)
+}
+
+// opcodeTxOutputScriptPubKey replaces the output index on top of the data stack
+// with the script public key of that output.
+//
+// Stack transformation: [... index] -> [... version||script]
+func opcodeTxOutputScriptPubKey(op *parsedOpcode, vm *Engine) error {
+	index, err := popIndex(op, vm, len(vm.tx.Outputs))
+	if err != nil {
+		return err
+	}
+	scriptPubKey := vm.tx.Outputs[index].ScriptPublicKey
+	return pushScriptPubKey(vm, scriptPubKey.Version, scriptPubKey.Script)
+}
@@ -0,0 +1,282 @@
+package txscript
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
+)
+
+// version1TestTransaction returns a transaction with two inputs and two outputs
+// for the introspection opcodes to look at. The script public key of its first
+// output is scriptPubKey.
+func version1TestTransaction(scriptPubKey *externalapi.ScriptPublicKey) *externalapi.DomainTransaction {
+	return &externalapi.DomainTransaction{
+		Version: 0,
+		Inputs: []*externalapi.DomainTransactionInput{
+			{
+				PreviousOutpoint: externalapi.DomainOutpoint{Index: 0},
+				UTXOEntry:        utxo.NewUTXOEntry(5_000_000_000, scriptPubKey, false, 1234),
+			},
+			{
+				PreviousOutpoint: externalapi.DomainOutpoint{Index: 1},
+				UTXOEntry: utxo.NewUTXOEntry(7, &externalapi.ScriptPublicKey{Script: []byte{OpTrue}, Version: 0},
+					false, math.MaxUint64),
+			},
+		},
+		Outputs: []*externalapi.DomainTransactionOutput{
+			{Value: 4_999_999_000, ScriptPublicKey: scriptPubKey},
+			{Value: 1000, ScriptPublicKey: &externalapi.ScriptPublicKey{Script: []byte{OpTrue, OpTrue}, Version: 0}},
+		},
+	}
+}
+
+// executeVersion1TestScript executes script as the script public key of the
+// first input of version1TestTransaction, with script version 1 active
+func executeVersion1TestScript(script []byte, version uint16) error {
+	return executeVersion1TestScriptWithFlags(script, version, ScriptVersion1Active)
+}
+
+func executeVersion1TestScriptWithFlags(script []byte, version uint16, flags ScriptFlags) error {
+	scriptPubKey := &externalapi.ScriptPublicKey{Script: script, Version: version}
+	tx := version1TestTransaction(scriptPubKey)
+	vm, err := NewEngine(scriptPubKey, tx, 0, flags, nil, nil, &consensushashing.SighashReusedValues{})
+	if err != nil {
+		return err
+	}
+	return vm.Execute()
+}
+
+func TestScriptVersion1Opcodes(t *testing.T) {
+	tests := []struct {
+		name        string
+		script      string
+		version0    bool
+		expectedErr *ErrorCode
+	}{
+		// Splice opcodes
+		{name: "cat", script: "'ab' 'cd' CAT 'abcd' EQUAL"},
+		{name: "cat of empty items", script: "0 0 CAT 0 EQUAL"},
+		{name: "substr", script: "'abcdef' 1 3 SUBSTR 'bcd' EQUAL"},
+		{name: "substr of everything", script: "'abcdef' 0 6 SUBSTR 'abcdef' EQUAL"},
+		{name: "empty substr at the end", script: "'abcdef' 6 0 SUBSTR 0 EQUAL"},
+		{name: "substr past the end", script: "'abc' 2 2 SUBSTR", expectedErr: errorCode(ErrOutOfRange)},
+		{name: "substr with a negative begin", script: "'abc' -1 2 SUBSTR", expectedErr: errorCode(ErrOutOfRange)},
+		{name: "substr with a negative size", script: "'abc' 1 -1 SUBSTR", expectedErr: errorCode(ErrOutOfRange)},
+		{name: "left", script: "'abcdef' 2 LEFT 'ab' EQUAL"},
+		{name: "right", script: "'abcdef' 2 RIGHT 'ef' EQUAL"},
+		{name: "left past the end", script: "'abc' 4 LEFT", expectedErr: errorCode(ErrOutOfRange)},
+		{name: "right with a negative size", script: "'abc' -1 RIGHT", expectedErr: errorCode(ErrOutOfRange)},
+
+		// Bitwise logic opcodes
+		{name: "invert", script: "0x02 0x0ff0 INVERT 0x02 0xf00f EQUAL"},
+		{name: "and", script: "0x02 0x0ff0 0x02 0x3c3c AND 0x02 0x0c30 EQUAL"},
+		{name: "or", script: "0x02 0x0ff0 0x02 0x3c3c OR 0x02 0x3ffc EQUAL"},
+		{name: "xor", script: "0x02 0x0ff0 0x02 0x3c3c XOR 0x02 0x33cc EQUAL"},
+		{name: "and of different sizes", script: "0x02 0x0ff0 0x01 0x3c AND", expectedErr: errorCode(ErrOperandSizeMismatch)},
+
+		// Numeric opcodes
+		{name: "mul", script: "6 -7 MUL -42 NUMEQUAL"},
+		{name: "div", script: "7 2 DIV 3 NUMEQUAL"},
+		{name: "negative div rounds towards zero", script: "-7 2 DIV -3 NUMEQUAL"},
+		{name: "mod", script: "7 2 MOD 1 NUMEQUAL"},
+		{name: "mod has the sign of the dividend", script: "-7 2 MOD -1 NUMEQUAL"},
+		{name: "div by zero", script: "1 0 DIV", expectedErr: errorCode(ErrDivideByZero)},
+		{name: "mod by zero", script: "1 0 MOD", expectedErr: errorCode(ErrDivideByZero)},
+		{name: "8 byte numbers", script: "4294967296 4294967296 ADD 8589934592 NUMEQUAL"},
+		{name: "9 byte numbers", script: "0x09 0x000000000000000001 1ADD", expectedErr: errorCode(ErrNumberTooBig)},
+		{name: "add overflow", script: "9223372036854775807 1 ADD", expectedErr: errorCode(ErrNumberTooBig)},
+		{name: "sub overflow", script: "-9223372036854775807 1 SUB", expectedErr: errorCode(ErrNumberTooBig)},
+		{name: "1add overflow", script: "9223372036854775807 1ADD", expectedErr: errorCode(ErrNumberTooBig)},
+		{name: "1sub overflow", script: "-9223372036854775807 1SUB", expectedErr: errorCode(ErrNumberTooBig)},
+		{name: "mul overflow", script: "4294967296 4294967296 MUL", expectedErr: errorCode(ErrNumberTooBig)},
+		{name: "negative mul overflow", script: "-4611686018427387904 2 MUL", expectedErr: errorCode(ErrNumberTooBig)},
+		{name: "largest mul", script: "3074457345618258602 3 MUL 9223372036854775806 NUMEQUAL"},
+		{name: "2mul is still disabled", script: "1 2MUL", expectedErr: errorCode(ErrDisabledOpcode)},
+		{name: "lshift is disabled in an unexecuted branch", script: "0 IF LSHIFT ENDIF 1",
+			expectedErr: errorCode(ErrDisabledOpcode)},
+
+		// Transaction introspection opcodes
+		{name: "input count", script: "TXINPUTCOUNT 2 NUMEQUAL"},
+		{name: "output count", script: "TXOUTPUTCOUNT 2 NUMEQUAL"},
+		{name: "input index", script: "TXINPUTINDEX 0 NUMEQUAL"},
+		{name: "input amount", script: "0 TXINPUTAMOUNT 5000000000 NUMEQUAL"},
+		{name: "input script public key", script: "1 TXINPUTSCRIPTPUBKEY 0x03 0x000051 EQUAL"},
+		{name: "input DAA score", script: "0 TXINPUTDAASCORE 1234 NUMEQUAL"},
+		{name: "DAA score of an unaccepted input", script: "1 TXINPUTDAASCORE", expectedErr: errorCode(ErrNumberTooBig)},
+		{name: "output amount", script: "1 TXOUTPUTAMOUNT 1000 NUMEQUAL"},
+		{name: "output script public key", script: "1 TXOUTPUTSCRIPTPUBKEY 0x04 0x00005151 EQUAL"},
+		{name: "input index out of range", script: "2 TXINPUTAMOUNT", expectedErr: errorCode(ErrOutOfRange)},
+		{name: "negative output index", script: "-1 TXOUTPUTAMOUNT", expectedErr: errorCode(ErrOutOfRange)},
+		{
+			// The coins of this input may only be sent back to the same script,
+			// with a fee of up to 1000 sompi
+			name: "covenant",
+			script: "TXINPUTINDEX TXINPUTSCRIPTPUBKEY TXINPUTINDEX TXOUTPUTSCRIPTPUBKEY EQUALVERIFY " +
+				"TXINPUTINDEX TXOUTPUTAMOUNT 1000 ADD TXINPUTINDEX TXINPUTAMOUNT GREATERTHANOREQUAL",
+		},
+
+		// Script version 0 is not affected
+		{name: "version 0 cat", script: "'ab' 'cd' CAT 'abcd' EQUAL", version0: true, expectedErr: errorCode(ErrDisabledOpcode)},
+		{name: "version 0 mul", script: "2 3 MUL 6 NUMEQUAL", version0: true, expectedErr: errorCode(ErrDisabledOpcode)},
+		{name: "version 0 introspection", script: "TXINPUTCOUNT 2 NUMEQUAL", version0: true,
+			expectedErr: errorCode(ErrReservedOpcode)},
+		{name: "version 0 8 byte numbers", script: "4294967296 1ADD", version0: true, expectedErr: errorCode(ErrNumberTooBig)},
+	}
+
+	for _, test := range tests {
+		version := uint16(1)
+		if test.version0 {
+			version = 0
+		}
+		err := executeVersion1TestScript(mustParseShortForm(test.script, version), version)
+		if test.expectedErr == nil {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %s", test.name, err)
+			}
+			continue
+		}
+		if !IsErrorCode(err, *test.expectedErr) {
+			t.Errorf("%s: expected error code %s, got: %v", test.name, *test.expectedErr, err)
+		}
+	}
+}
+
+func TestScriptVersion1Activation(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+	}{
+		{name: "false", script: "0"},
+		{name: "wrong cat", script: "'ab' 'cd' CAT 'abce' EQUAL"},
+		{name: "wrong introspection", script: "TXINPUTCOUNT 3 NUMEQUAL"},
+	}
+
+	for _, test := range tests {
+		script := mustParseShortForm(test.script, 1)
+
+		// Until version 1 is active it's an unknown version, so its scripts
+		// aren't executed and are always valid
+		err := executeVersion1TestScriptWithFlags(script, 1, ScriptNoFlags)
+		if err != nil {
+			t.Errorf("%s: unexpected error before the activation: %s", test.name, err)
+		}
+
+		err = executeVersion1TestScriptWithFlags(script, 1, ScriptVersion1Active)
+		if !IsErrorCode(err, ErrEvalFalse) {
+			t.Errorf("%s: expected error code %s after the activation, got: %v", test.name, ErrEvalFalse, err)
+		}
+	}
+
+	// Versions above 1 remain unknown after the activation
+	err := executeVersion1TestScriptWithFlags(mustParseShortForm("0", 0), 2, ScriptVersion1Active)
+	if err != nil {
+		t.Errorf("version 2: unexpected error: %s", err)
+	}
+}
+
+func errorCode(code ErrorCode) *ErrorCode {
+	return &code
+}
+
+func TestScriptVersion1ElementSizeLimits(t *testing.T) {
+	maxElement := bytes.Repeat([]byte{1}, MaxScriptElementSize)
+
+	script, err := NewScriptBuilder().AddData(maxElement).AddData([]byte{1}).AddOp(OpCat).Script()
+	if err != nil {
+		t.Fatalf("Script: %s", err)
+	}
+	err = executeVersion1TestScript(script, 1)
+	if !IsErrorCode(err, ErrElementTooBig) {
+		t.Fatalf("Expected the concatenation to be too big, got: %v", err)
+	}
+
+	// The script public key of the first input is the executed script itself,
+	// so pushing it fails once the script is long enough.
+	script, err = NewScriptBuilder().AddData(maxElement[:MaxScriptElementSize-7]).AddOp(OpDrop).
+		AddOp(OpTxInputIndex).AddOp(OpTxInputScriptPubKey).Script()
+	if err != nil {
+		t.Fatalf("Script: %s", err)
+	}
+	err = executeVersion1TestScript(script, 1)
+	if !IsErrorCode(err, ErrElementTooBig) {
+		t.Fatalf("Expected the script public key to be too big, got: %v", err)
+	}
+}
+
+func TestScriptVersion1SigOpCount(t *testing.T) {
+	script := mustParseShortForm("0 TXINPUTSCRIPTPUBKEY 0 TXOUTPUTSCRIPTPUBKEY EQUALVERIFY 0 TXINPUTAMOUNT DROP CHECKSIG", 1)
+
+	// Until version 1 is active, its scripts are counted by the rules of version 0
+	tests := []struct {
+		version            uint16
+		flags              ScriptFlags
+		expectedSigOpCount int
+	}{
+		{version: 0, flags: ScriptNoFlags, expectedSigOpCount: 1},
+		{version: 0, flags: ScriptVersion1Active, expectedSigOpCount: 1},
+		{version: 1, flags: ScriptNoFlags, expectedSigOpCount: 1},
+		{version: 1, flags: ScriptVersion1Active, expectedSigOpCount: 3},
+		{version: 2, flags: ScriptVersion1Active, expectedSigOpCount: 1},
+	}
+	for _, test := range tests {
+		scriptPubKey := &externalapi.ScriptPublicKey{Script: script, Version: test.version}
+		sigOpCount := GetPreciseSigOpCount(nil, scriptPubKey, false, test.flags)
+		if sigOpCount != test.expectedSigOpCount {
+			t.Errorf("version %d with flags %d: expected %d sig ops, got %d",
+				test.version, test.flags, test.expectedSigOpCount, sigOpCount)
+		}
+	}
+
+	// The redeem script of a pay-to-script-hash is counted with the opcodes
+	// of the version of the script public key
+	redeemScript := mustParseShortForm("TXINPUTINDEX TXINPUTSCRIPTPUBKEY DROP 1", 1)
+	scriptPubKeyScript, err := PayToScriptHashScript(redeemScript)
+	if err != nil {
+		t.Fatalf("PayToScriptHashScript: %s", err)
+	}
+	sigScript, err := PayToScriptHashSignatureScript(redeemScript, nil)
+	if err != nil {
+		t.Fatalf("PayToScriptHashSignatureScript: %s", err)
+	}
+	for _, test := range []struct {
+		version            uint16
+		flags              ScriptFlags
+		expectedSigOpCount int
+	}{
+		{version: 0, flags: ScriptVersion1Active, expectedSigOpCount: 0},
+		{version: 1, flags: ScriptNoFlags, expectedSigOpCount: 0},
+		{version: 1, flags: ScriptVersion1Active, expectedSigOpCount: 1},
+	} {
+		scriptPubKey := &externalapi.ScriptPublicKey{Script: scriptPubKeyScript, Version: test.version}
+		sigOpCount := GetPreciseSigOpCount(sigScript, scriptPubKey, true, test.flags)
+		if sigOpCount != test.expectedSigOpCount {
+			t.Errorf("P2SH version %d with flags %d: expected %d sig ops, got %d",
+				test.version, test.flags, test.expectedSigOpCount, sigOpCount)
+		}
+	}
+}
+
+func TestScriptVersion1Disasm(t *testing.T) {
+	script := mustParseShortForm("TXINPUTINDEX TXINPUTAMOUNT 2 MUL", 1)
+
+	tests := []struct {
+		version  uint16
+		expected string
+	}{
+		{version: 0, expected: "OP_UNKNOWN180 OP_UNKNOWN181 2 OP_MUL"},
+		{version: 1, expected: "OP_TXINPUTINDEX OP_TXINPUTAMOUNT 2 OP_MUL"},
+	}
+	for _, test := range tests {
+		disasm, err := DisasmString(test.version, script)
+		if err != nil {
+			t.Fatalf("DisasmString: %s", err)
+		}
+		if disasm != test.expected {
+			t.Errorf("version %d: expected %q, got %q", test.version, test.expected, disasm)
+		}
+	}
+}
@@ -65,9 +65,9 @@ var shortFormOps map[string]byte
 //   - Single quoted strings are pushed as data
 //   - Anything else is an error
 func parseShortForm(script string, version uint16) ([]byte, error) {
-	if version > constants.MaxScriptPublicKeyVersion {
+	if version > constants.ScriptPublicKeyVersion1 {
 		return nil, errors.Errorf("unknown version %d (max: %d)",
-			version, constants.MaxScriptPublicKeyVersion)
+			version, constants.ScriptPublicKeyVersion1)
 	}
 
 	// Only create the short form opcode map once.
@@ -179,10 +179,9 @@ func unparseScript(pops []parsedOpcode) ([]byte, error) {
 // appended. In addition, the reason the script failed to parse is returned
 // if the caller wants more information about the failure.
 func DisasmString(version uint16, buf []byte) (string, error) {
-	// currently, there is only one version exists so it equals to the max version.
-	if version == constants.MaxScriptPublicKeyVersion {
+	if version <= constants.ScriptPublicKeyVersion1 {
 		var disbuf bytes.Buffer
-		opcodes, err := parseScript(buf)
+		opcodes, err := parseScriptForVersion(buf, version)
 		for _, pop := range opcodes {
 			disbuf.WriteString(pop.print(true))
 			disbuf.WriteByte(' ')
@@ -244,6 +243,10 @@ func getSigOpCount(pops []parsedOpcode, precise bool) int {
 		switch pop.opcode.value {
 		case OpCheckSig, OpCheckSigVerify, OpCheckSigECDSA:
 			nSigs++
+		case OpTxInputScriptPubKey, OpTxOutputScriptPubKey:
+			if isIntrospectionScriptPubKeyOpcode(&pops[i]) {
+				nSigs++
+			}
 		case OpCheckMultiSig, OpCheckMultiSigVerify, OpCheckMultiSigECDSA:
 			// If we are being precise then look for familiar
 			// patterns for multisig, for now all we recognize is
@@ -279,11 +282,19 @@ func GetSigOpCount(script []byte) int {
 // scriptPubKey. If p2sh is true then scriptSig may be searched for the
 // Pay-To-Script-Hash script in order to find the precise number of signature
 // operations in the transaction. If the script fails to parse, then the count
-// up to the point of failure is returned.
-func GetPreciseSigOpCount(scriptSig []byte, scriptPubKey *externalapi.ScriptPublicKey, isP2SH bool) int {
+// up to the point of failure is returned. Scripts of versions that aren't active
+// under the given flags are counted by the rules of version 0.
+func GetPreciseSigOpCount(scriptSig []byte, scriptPubKey *externalapi.ScriptPublicKey, isP2SH bool,
+	flags ScriptFlags) int {
+
+	version := scriptPubKey.Version
+	if version > maxScriptVersion(flags) {
+		version = constants.MaxScriptPublicKeyVersion
+	}
+
 	// Don't check error since parseScript returns the parsed-up-to-error
 	// list of pops.
-	pops, _ := parseScript(scriptPubKey.Script)
+	pops, _ := parseScriptForVersion(scriptPubKey.Script, version)
 
 	// Treat non P2SH transactions as normal.
 	if !(isP2SH && isScriptHash(pops)) {
@@ -316,7 +327,7 @@ func GetPreciseSigOpCount(scriptSig []byte, scriptPubKey *externalapi.ScriptPubl
 	// returns the parsed-up-to-error list of pops and the consensus rules
 	// dictate signature operations are counted up to the first parse
 	// failure.
-	shPops, _ := parseScript(shScript)
+	shPops, _ := parseScriptForVersion(shScript, version)
 	return getSigOpCount(shPops, true)
 }
 
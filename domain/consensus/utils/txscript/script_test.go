@@ -3624,7 +3624,7 @@ func TestGetPreciseSigOps(t *testing.T) {
 		"27f564529c57197f9ae88 EQUAL", 0)
 	scriptPubKey := &externalapi.ScriptPublicKey{scriptOnly, 0}
 	for _, test := range tests {
-		count := GetPreciseSigOpCount(test.scriptSig, scriptPubKey, true)
+		count := GetPreciseSigOpCount(test.scriptSig, scriptPubKey, true, ScriptNoFlags)
 		if count != test.nSigOps {
 			t.Errorf("%s: expected count of %d, got %d", test.name,
 				test.nSigOps, count)
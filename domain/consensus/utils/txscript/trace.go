@@ -0,0 +1,68 @@
+package txscript
+
+// TraceStep describes the execution of a single opcode by the script engine
+type TraceStep struct {
+	// ScriptIndex is the index of the script the opcode belongs to: 0 is the
+	// signature script, 1 is the public key script and 2 is the redeem script
+	// of a pay-to-script-hash
+	ScriptIndex int
+
+	// ScriptOffset is the index of the opcode in its script
+	ScriptOffset int
+
+	// Disassembly is the disassembly of the opcode, in the same format as DisasmPC
+	Disassembly string
+
+	// DataStack, AltStack and CondStack are the contents of the stacks after
+	// the opcode was executed, where the last item is the top of the stack
+	DataStack [][]byte
+	AltStack  [][]byte
+	CondStack []int
+
+	// Err is the error the opcode failed with, if any
+	Err error
+}
+
+// GetCondStack returns the contents of the condition stack as an array where the
+// last item in the array is the top of the stack. Each item is one of OpCondFalse,
+// OpCondTrue and OpCondSkip.
+func (vm *Engine) GetCondStack() []int {
+	condStack := make([]int, len(vm.condStack))
+	copy(condStack, vm.condStack)
+	return condStack
+}
+
+// Trace is the same as Execute, except it also returns a TraceStep for every
+// executed opcode. When execution fails, the error is returned, and if it was
+// caused by an opcode it's also set on the last step of the trace.
+func (vm *Engine) Trace() ([]*TraceStep, error) {
+	if !vm.isScriptVersionActive() {
+		return nil, vm.Execute()
+	}
+
+	var trace []*TraceStep
+	done := false
+	for !done {
+		scriptIndex, scriptOffset, err := vm.curPC()
+		if err != nil {
+			return trace, err
+		}
+		step := &TraceStep{
+			ScriptIndex:  scriptIndex,
+			ScriptOffset: scriptOffset,
+			Disassembly:  vm.disasm(scriptIndex, scriptOffset),
+		}
+
+		done, err = vm.Step()
+		step.DataStack = vm.GetStack()
+		step.AltStack = vm.GetAltStack()
+		step.CondStack = vm.GetCondStack()
+		step.Err = err
+		trace = append(trace, step)
+		if err != nil {
+			return trace, err
+		}
+	}
+
+	return trace, vm.CheckErrorCondition(true)
+}
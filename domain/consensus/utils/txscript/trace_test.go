@@ -0,0 +1,130 @@
+package txscript
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+)
+
+// newTraceTestEngine returns an engine that spends a pay-to-script-hash of
+// redeemScript
+func newTraceTestEngine(t *testing.T, redeemScript []byte) *Engine {
+	scriptPubKeyScript, err := PayToScriptHashScript(redeemScript)
+	if err != nil {
+		t.Fatalf("PayToScriptHashScript: %s", err)
+	}
+	sigScript, err := PayToScriptHashSignatureScript(redeemScript, nil)
+	if err != nil {
+		t.Fatalf("PayToScriptHashSignatureScript: %s", err)
+	}
+	scriptPubKey := &externalapi.ScriptPublicKey{Script: scriptPubKeyScript, Version: 0}
+
+	tx := &externalapi.DomainTransaction{
+		Inputs: []*externalapi.DomainTransactionInput{{SignatureScript: sigScript}},
+	}
+	vm, err := NewEngine(scriptPubKey, tx, 0, ScriptNoFlags, nil, nil, &consensushashing.SighashReusedValues{})
+	if err != nil {
+		t.Fatalf("NewEngine: %s", err)
+	}
+	return vm
+}
+
+func TestTrace(t *testing.T) {
+	redeemScript := mustParseShortForm("1 2 ADD 3 EQUAL", 0)
+	scriptPubKeyScript, err := PayToScriptHashScript(redeemScript)
+	if err != nil {
+		t.Fatalf("PayToScriptHashScript: %s", err)
+	}
+	trace, err := newTraceTestEngine(t, redeemScript).Trace()
+	if err != nil {
+		t.Fatalf("Trace: %s", err)
+	}
+
+	expectedDisassemblies := []string{
+		// Signature script
+		"00:0000: OP_DATA_5 0x5152935387",
+		// Script public key
+		"01:0000: OP_BLAKE2B",
+		fmt.Sprintf("01:0001: OP_DATA_32 0x%x", scriptPubKeyScript[2:34]),
+		"01:0002: OP_EQUAL",
+		// Redeem script
+		"02:0000: OP_1",
+		"02:0001: OP_2",
+		"02:0002: OP_ADD",
+		"02:0003: OP_3",
+		"02:0004: OP_EQUAL",
+	}
+	if len(trace) != len(expectedDisassemblies) {
+		t.Fatalf("Expected %d steps, got %d", len(expectedDisassemblies), len(trace))
+	}
+	for i, step := range trace {
+		if step.Disassembly != expectedDisassemblies[i] {
+			t.Errorf("Step %d: expected %q, got %q", i, expectedDisassemblies[i], step.Disassembly)
+		}
+		if step.Err != nil {
+			t.Errorf("Step %d: unexpected error %s", i, step.Err)
+		}
+	}
+
+	addStep := trace[6]
+	if addStep.ScriptIndex != 2 || addStep.ScriptOffset != 2 {
+		t.Errorf("Expected OP_ADD to be at 2:2, got %d:%d", addStep.ScriptIndex, addStep.ScriptOffset)
+	}
+	if !reflect.DeepEqual(addStep.DataStack, [][]byte{{3}}) {
+		t.Errorf("Unexpected data stack after OP_ADD: %x", addStep.DataStack)
+	}
+}
+
+func TestTraceFailure(t *testing.T) {
+	// A script that evaluates to false fails only after the last step
+	trace, err := newTraceTestEngine(t, mustParseShortForm("1 2 ADD 4 EQUAL", 0)).Trace()
+	if !IsErrorCode(err, ErrEvalFalse) {
+		t.Fatalf("Expected ErrEvalFalse, got: %v", err)
+	}
+	if len(trace) != 9 || trace[8].Err != nil {
+		t.Fatalf("Expected a trace of 9 successful steps")
+	}
+
+	// A failing opcode is the last step of the trace
+	trace, err = newTraceTestEngine(t, mustParseShortForm("1 VERIFY 0 VERIFY 1", 0)).Trace()
+	if !IsErrorCode(err, ErrVerify) {
+		t.Fatalf("Expected ErrVerify, got: %v", err)
+	}
+	lastStep := trace[len(trace)-1]
+	if lastStep.Disassembly != "02:0003: OP_VERIFY" || !IsErrorCode(lastStep.Err, ErrVerify) {
+		t.Fatalf("Expected the trace to end with the failing OP_VERIFY, got %q: %v",
+			lastStep.Disassembly, lastStep.Err)
+	}
+}
+
+func TestTraceCondStack(t *testing.T) {
+	trace, err := newTraceTestEngine(t, mustParseShortForm("1 IF 0 IF 2 ENDIF ELSE 3 ENDIF", 0)).Trace()
+	if err == nil {
+		t.Fatalf("Expected the script to fail")
+	}
+
+	expectedCondStacks := map[string][]int{
+		"02:0001: OP_IF":    {OpCondTrue},
+		"02:0003: OP_IF":    {OpCondTrue, OpCondFalse},
+		"02:0004: OP_2":     {OpCondTrue, OpCondFalse},
+		"02:0005: OP_ENDIF": {OpCondTrue},
+		"02:0006: OP_ELSE":  {OpCondFalse},
+		"02:0008: OP_ENDIF": {},
+	}
+	for _, step := range trace {
+		expectedCondStack, ok := expectedCondStacks[step.Disassembly]
+		if !ok {
+			continue
+		}
+		if !reflect.DeepEqual(step.CondStack, expectedCondStack) {
+			t.Errorf("%s: expected condition stack %v, got %v", step.Disassembly, expectedCondStack, step.CondStack)
+		}
+		delete(expectedCondStacks, step.Disassembly)
+	}
+	if len(expectedCondStacks) != 0 {
+		t.Errorf("Steps missing from the trace: %v", expectedCondStacks)
+	}
+}
@@ -0,0 +1,52 @@
+package dagconfig
+
+import (
+	"math/big"
+
+	"github.com/kaspanet/go-muhash"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/blockheader"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/merkle"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/subnetworks"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionhelper"
+)
+
+// genesisTxPayloadPrefix is the part of the coinbase payload that all genesis blocks share
+var genesisTxPayloadPrefix = []byte{
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Blue score
+	0x00, 0xE1, 0xF5, 0x05, 0x00, 0x00, 0x00, 0x00, // Subsidy
+	0x00, 0x00, // Script version
+	0x01, // Varint
+	0x00, // OP-FALSE
+}
+
+// NewGenesisBlock builds a genesis block the same way the genesis blocks of the built-in
+// networks are built, with the given extra data at the end of its coinbase payload.
+// The proof of work of genesis blocks is never validated, so the nonce may be anything.
+func NewGenesisBlock(extraData []byte, timeInMilliseconds int64, bits uint32, nonce uint64) *externalapi.DomainBlock {
+	payload := make([]byte, 0, len(genesisTxPayloadPrefix)+len(extraData))
+	payload = append(payload, genesisTxPayloadPrefix...)
+	payload = append(payload, extraData...)
+
+	coinbaseTx := transactionhelper.NewSubnetworkTransaction(0, []*externalapi.DomainTransactionInput{},
+		[]*externalapi.DomainTransactionOutput{}, &subnetworks.SubnetworkIDCoinbase, 0, payload)
+	transactions := []*externalapi.DomainTransaction{coinbaseTx}
+
+	return &externalapi.DomainBlock{
+		Header: blockheader.NewImmutableBlockHeader(
+			0,
+			[]externalapi.BlockLevelParents{},
+			merkle.CalculateHashMerkleRoot(transactions),
+			&externalapi.DomainHash{},
+			externalapi.NewDomainHashFromByteArray(muhash.EmptyMuHashHash.AsArray()),
+			timeInMilliseconds,
+			bits,
+			nonce,
+			0,
+			0,
+			big.NewInt(0),
+			&externalapi.DomainHash{},
+		),
+		Transactions: transactions,
+	}
+}
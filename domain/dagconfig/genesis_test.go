@@ -56,3 +56,14 @@ func TestDevnetGenesisBlock(t *testing.T) {
 			DevnetParams.GenesisHash)
 	}
 }
+
+// TestNewGenesisBlock tests that NewGenesisBlock derives the genesis block of the
+// development network from its parameters
+func TestNewGenesisBlock(t *testing.T) {
+	header := DevnetParams.GenesisBlock.Header
+	genesisBlock := NewGenesisBlock([]byte("kaspa-devnet"), header.TimeInMilliseconds(), header.Bits(), header.Nonce())
+	hash := consensushashing.BlockHash(genesisBlock)
+	if !DevnetParams.GenesisHash.Equal(hash) {
+		t.Fatalf("TestNewGenesisBlock: derived genesis block hash is %v, want %v", hash, DevnetParams.GenesisHash)
+	}
+}
@@ -5,6 +5,7 @@
 package dagconfig
 
 import (
+	"math"
 	"math/big"
 	"time"
 
@@ -80,6 +81,16 @@ type Params struct {
 	// GenesisHash is the starting block hash.
 	GenesisHash *externalapi.DomainHash
 
+	// Checkpoints are blocks that are known to be in the selected parent chain of the network.
+	// A block header is rejected if its finality point is above a checkpoint, but the checkpoint
+	// is not in the selected parent chain of the finality point.
+	//
+	// The built-in networks intentionally ship without checkpoints for now. Mainnet and testnet
+	// checkpoints are added by the maintainers when preparing a release, only for blocks that are
+	// already below the pruning point of every supported release. Until then, node operators can
+	// pin blocks they trust with --addcheckpoint.
+	Checkpoints []externalapi.Checkpoint
+
 	// PowMax defines the highest allowed proof of work value for a block
 	// as a uint256.
 	PowMax *big.Int
@@ -186,6 +197,11 @@ type Params struct {
 	FixedSubsidySwitchPruningPointInterval uint64
 
 	FixedSubsidySwitchHashRateThreshold *big.Int
+
+	// ScriptPublicKeyVersion1ActivationDAAScore is the DAA score from which scripts of version
+	// constants.ScriptPublicKeyVersion1 are executed. Before it, version 1 is unknown, and
+	// like any other unknown version it's never executed.
+	ScriptPublicKeyVersion1ActivationDAAScore uint64
 }
 
 // NormalizeRPCServerAddress returns addr with the current network default
@@ -224,6 +240,7 @@ var MainnetParams = Params{
 	// DAG parameters
 	GenesisBlock:                    &genesisBlock,
 	GenesisHash:                     genesisHash,
+	Checkpoints:                     nil, // Added by the maintainers when preparing a release, see Params.Checkpoints
 	PowMax:                          mainPowMax,
 	BlockCoinbaseMaturity:           100,
 	SubsidyGenesisReward:            defaultSubsidyGenesisReward,
@@ -261,17 +278,18 @@ var MainnetParams = Params{
 
 	DisableDifficultyAdjustment: false,
 
-	MaxCoinbasePayloadLength:                defaultMaxCoinbasePayloadLength,
-	MaxBlockMass:                            defaultMaxBlockMass,
-	MaxBlockParents:                         defaultMaxBlockParents,
-	MassPerTxByte:                           defaultMassPerTxByte,
-	MassPerScriptPubKeyByte:                 defaultMassPerScriptPubKeyByte,
-	MassPerSigOp:                            defaultMassPerSigOp,
-	MergeSetSizeLimit:                       defaultMergeSetSizeLimit,
-	CoinbasePayloadScriptPublicKeyMaxLength: defaultCoinbasePayloadScriptPublicKeyMaxLength,
-	PruningProofM:                           defaultPruningProofM,
-	FixedSubsidySwitchPruningPointInterval:  defaultFixedSubsidySwitchPruningPointInterval,
-	FixedSubsidySwitchHashRateThreshold:     big.NewInt(150_000_000_000),
+	MaxCoinbasePayloadLength:                  defaultMaxCoinbasePayloadLength,
+	MaxBlockMass:                              defaultMaxBlockMass,
+	MaxBlockParents:                           defaultMaxBlockParents,
+	MassPerTxByte:                             defaultMassPerTxByte,
+	MassPerScriptPubKeyByte:                   defaultMassPerScriptPubKeyByte,
+	MassPerSigOp:                              defaultMassPerSigOp,
+	MergeSetSizeLimit:                         defaultMergeSetSizeLimit,
+	CoinbasePayloadScriptPublicKeyMaxLength:   defaultCoinbasePayloadScriptPublicKeyMaxLength,
+	PruningProofM:                             defaultPruningProofM,
+	FixedSubsidySwitchPruningPointInterval:    defaultFixedSubsidySwitchPruningPointInterval,
+	FixedSubsidySwitchHashRateThreshold:       big.NewInt(150_000_000_000),
+	ScriptPublicKeyVersion1ActivationDAAScore: math.MaxUint64,
 }
 
 // TestnetParams defines the network parameters for the test Kaspa network.
@@ -286,6 +304,7 @@ var TestnetParams = Params{
 	// DAG parameters
 	GenesisBlock:                    &testnetGenesisBlock,
 	GenesisHash:                     testnetGenesisHash,
+	Checkpoints:                     nil, // Added by the maintainers when preparing a release, see Params.Checkpoints
 	PowMax:                          testnetPowMax,
 	BlockCoinbaseMaturity:           100,
 	SubsidyGenesisReward:            defaultSubsidyGenesisReward,
@@ -323,17 +342,18 @@ var TestnetParams = Params{
 
 	DisableDifficultyAdjustment: false,
 
-	MaxCoinbasePayloadLength:                defaultMaxCoinbasePayloadLength,
-	MaxBlockMass:                            defaultMaxBlockMass,
-	MaxBlockParents:                         defaultMaxBlockParents,
-	MassPerTxByte:                           defaultMassPerTxByte,
-	MassPerScriptPubKeyByte:                 defaultMassPerScriptPubKeyByte,
-	MassPerSigOp:                            defaultMassPerSigOp,
-	MergeSetSizeLimit:                       defaultMergeSetSizeLimit,
-	CoinbasePayloadScriptPublicKeyMaxLength: defaultCoinbasePayloadScriptPublicKeyMaxLength,
-	PruningProofM:                           defaultPruningProofM,
-	FixedSubsidySwitchPruningPointInterval:  defaultFixedSubsidySwitchPruningPointInterval,
-	FixedSubsidySwitchHashRateThreshold:     big.NewInt(150_000_000_000),
+	MaxCoinbasePayloadLength:                  defaultMaxCoinbasePayloadLength,
+	MaxBlockMass:                              defaultMaxBlockMass,
+	MaxBlockParents:                           defaultMaxBlockParents,
+	MassPerTxByte:                             defaultMassPerTxByte,
+	MassPerScriptPubKeyByte:                   defaultMassPerScriptPubKeyByte,
+	MassPerSigOp:                              defaultMassPerSigOp,
+	MergeSetSizeLimit:                         defaultMergeSetSizeLimit,
+	CoinbasePayloadScriptPublicKeyMaxLength:   defaultCoinbasePayloadScriptPublicKeyMaxLength,
+	PruningProofM:                             defaultPruningProofM,
+	FixedSubsidySwitchPruningPointInterval:    defaultFixedSubsidySwitchPruningPointInterval,
+	FixedSubsidySwitchHashRateThreshold:       big.NewInt(150_000_000_000),
+	ScriptPublicKeyVersion1ActivationDAAScore: math.MaxUint64,
 }
 
 // SimnetParams defines the network parameters for the simulation test Kaspa
@@ -389,17 +409,18 @@ var SimnetParams = Params{
 
 	DisableDifficultyAdjustment: true,
 
-	MaxCoinbasePayloadLength:                defaultMaxCoinbasePayloadLength,
-	MaxBlockMass:                            defaultMaxBlockMass,
-	MaxBlockParents:                         defaultMaxBlockParents,
-	MassPerTxByte:                           defaultMassPerTxByte,
-	MassPerScriptPubKeyByte:                 defaultMassPerScriptPubKeyByte,
-	MassPerSigOp:                            defaultMassPerSigOp,
-	MergeSetSizeLimit:                       defaultMergeSetSizeLimit,
-	CoinbasePayloadScriptPublicKeyMaxLength: defaultCoinbasePayloadScriptPublicKeyMaxLength,
-	PruningProofM:                           defaultPruningProofM,
-	FixedSubsidySwitchPruningPointInterval:  defaultFixedSubsidySwitchPruningPointInterval,
-	FixedSubsidySwitchHashRateThreshold:     big.NewInt(150_000_000_000),
+	MaxCoinbasePayloadLength:                  defaultMaxCoinbasePayloadLength,
+	MaxBlockMass:                              defaultMaxBlockMass,
+	MaxBlockParents:                           defaultMaxBlockParents,
+	MassPerTxByte:                             defaultMassPerTxByte,
+	MassPerScriptPubKeyByte:                   defaultMassPerScriptPubKeyByte,
+	MassPerSigOp:                              defaultMassPerSigOp,
+	MergeSetSizeLimit:                         defaultMergeSetSizeLimit,
+	CoinbasePayloadScriptPublicKeyMaxLength:   defaultCoinbasePayloadScriptPublicKeyMaxLength,
+	PruningProofM:                             defaultPruningProofM,
+	FixedSubsidySwitchPruningPointInterval:    defaultFixedSubsidySwitchPruningPointInterval,
+	FixedSubsidySwitchHashRateThreshold:       big.NewInt(150_000_000_000),
+	ScriptPublicKeyVersion1ActivationDAAScore: 0,
 }
 
 // DevnetParams defines the network parameters for the development Kaspa network.
@@ -451,17 +472,18 @@ var DevnetParams = Params{
 
 	DisableDifficultyAdjustment: false,
 
-	MaxCoinbasePayloadLength:                defaultMaxCoinbasePayloadLength,
-	MaxBlockMass:                            defaultMaxBlockMass,
-	MaxBlockParents:                         defaultMaxBlockParents,
-	MassPerTxByte:                           defaultMassPerTxByte,
-	MassPerScriptPubKeyByte:                 defaultMassPerScriptPubKeyByte,
-	MassPerSigOp:                            defaultMassPerSigOp,
-	MergeSetSizeLimit:                       defaultMergeSetSizeLimit,
-	CoinbasePayloadScriptPublicKeyMaxLength: defaultCoinbasePayloadScriptPublicKeyMaxLength,
-	PruningProofM:                           defaultPruningProofM,
-	FixedSubsidySwitchPruningPointInterval:  defaultFixedSubsidySwitchPruningPointInterval,
-	FixedSubsidySwitchHashRateThreshold:     big.NewInt(150_000_000_000),
+	MaxCoinbasePayloadLength:                  defaultMaxCoinbasePayloadLength,
+	MaxBlockMass:                              defaultMaxBlockMass,
+	MaxBlockParents:                           defaultMaxBlockParents,
+	MassPerTxByte:                             defaultMassPerTxByte,
+	MassPerScriptPubKeyByte:                   defaultMassPerScriptPubKeyByte,
+	MassPerSigOp:                              defaultMassPerSigOp,
+	MergeSetSizeLimit:                         defaultMergeSetSizeLimit,
+	CoinbasePayloadScriptPublicKeyMaxLength:   defaultCoinbasePayloadScriptPublicKeyMaxLength,
+	PruningProofM:                             defaultPruningProofM,
+	FixedSubsidySwitchPruningPointInterval:    defaultFixedSubsidySwitchPruningPointInterval,
+	FixedSubsidySwitchHashRateThreshold:       big.NewInt(150_000_000_000),
+	ScriptPublicKeyVersion1ActivationDAAScore: 0,
 }
 
 var (
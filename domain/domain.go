@@ -13,6 +13,7 @@ import (
 	"github.com/kaspanet/kaspad/domain/prefixmanager"
 	"github.com/kaspanet/kaspad/domain/prefixmanager/prefix"
 	infrastructuredatabase "github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/util/mstime"
 	"github.com/pkg/errors"
 )
 
@@ -24,6 +25,7 @@ type Domain interface {
 	InitStagingConsensus() error
 	CommitStagingConsensus() error
 	DeleteStagingConsensus() error
+	Clock() mstime.Clock
 }
 
 type domain struct {
@@ -33,6 +35,7 @@ type domain struct {
 	stagingConsensusLock sync.RWMutex
 	consensusConfig      *consensus.Config
 	db                   infrastructuredatabase.Database
+	clock                mstime.Clock
 }
 
 func (d *domain) Consensus() externalapi.Consensus {
@@ -49,6 +52,11 @@ func (d *domain) MiningManager() miningmanager.MiningManager {
 	return d.miningManager
 }
 
+// Clock returns the clock consensus and the mempool take the current time from
+func (d *domain) Clock() mstime.Clock {
+	return d.clock
+}
+
 func (d *domain) InitStagingConsensus() error {
 	d.stagingConsensusLock.Lock()
 	defer d.stagingConsensusLock.Unlock()
@@ -188,10 +196,16 @@ func New(consensusConfig *consensus.Config, mempoolConfig *mempool.Config, db in
 		return nil, err
 	}
 
+	clock := consensusConfig.Clock
+	if clock == nil {
+		clock = mstime.NewSystemClock()
+	}
+
 	domainInstance := &domain{
 		consensus:       &consensusInstance,
 		consensusConfig: consensusConfig,
 		db:              db,
+		clock:           clock,
 	}
 
 	miningManagerFactory := miningmanager.NewFactory()
@@ -201,3 +215,21 @@ func New(consensusConfig *consensus.Config, mempoolConfig *mempool.Config, db in
 	domainInstance.miningManager = miningManagerFactory.NewMiningManager(consensusReference, &consensusConfig.Params, mempoolConfig)
 	return domainInstance, nil
 }
+
+// NewReadOnlyConsensus opens the active consensus in the given database for inspection only.
+// Unlike New, it doesn't delete a leftover staging consensus, doesn't initialize the consensus
+// and never writes to the database.
+func NewReadOnlyConsensus(consensusConfig *consensus.Config, db infrastructuredatabase.Database) (
+	externalapi.Consensus, error) {
+
+	activePrefix, exists, err := prefixmanager.ActivePrefix(db)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, errors.Errorf("the database has no active consensus")
+	}
+
+	return consensus.NewFactory().NewReadOnlyConsensus(consensusConfig, db, activePrefix)
+}
@@ -11,9 +11,11 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/testutils"
 	"github.com/kaspanet/kaspad/domain/miningmanager/mempool"
+	infrastructuredatabase "github.com/kaspanet/kaspad/infrastructure/db/database"
 	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
 	"io/ioutil"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -155,3 +157,102 @@ func TestCreateStagingConsensus(t *testing.T) {
 		}
 	})
 }
+
+func TestNewReadOnlyConsensus(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		dataDir, err := ioutil.TempDir("", fmt.Sprintf("TestNewReadOnlyConsensus-%s", consensusConfig.Name))
+		if err != nil {
+			t.Fatalf("ioutil.TempDir: %+v", err)
+		}
+		defer os.RemoveAll(dataDir)
+
+		db, err := ldb.NewLevelDB(dataDir, 8)
+		if err != nil {
+			t.Fatalf("NewLevelDB: %+v", err)
+		}
+		defer db.Close()
+
+		_, err = domain.NewReadOnlyConsensus(consensusConfig, db)
+		if err == nil || !strings.Contains(err.Error(), "the database has no active consensus") {
+			t.Fatalf("Expected opening an empty database to fail, but got: %+v", err)
+		}
+
+		domainInstance, err := domain.New(consensusConfig, mempool.DefaultConfig(&consensusConfig.Params), db)
+		if err != nil {
+			t.Fatalf("New: %+v", err)
+		}
+
+		coinbaseData := &externalapi.DomainCoinbaseData{
+			ScriptPublicKey: &externalapi.ScriptPublicKey{},
+			ExtraData:       []byte{},
+		}
+		var blockHash *externalapi.DomainHash
+		for i := 0; i < 3; i++ {
+			block, err := domainInstance.Consensus().BuildBlock(coinbaseData, nil)
+			if err != nil {
+				t.Fatalf("BuildBlock: %+v", err)
+			}
+			_, err = domainInstance.Consensus().ValidateAndInsertBlock(block, true)
+			if err != nil {
+				t.Fatalf("ValidateAndInsertBlock: %+v", err)
+			}
+			blockHash = consensushashing.BlockHash(block)
+		}
+
+		// Leave a staging consensus behind, which domain.New would have deleted
+		err = domainInstance.InitStagingConsensus()
+		if err != nil {
+			t.Fatalf("InitStagingConsensus: %+v", err)
+		}
+
+		entriesBefore := readAllDatabaseEntries(t, db)
+
+		readOnlyConsensus, err := domain.NewReadOnlyConsensus(consensusConfig, db)
+		if err != nil {
+			t.Fatalf("NewReadOnlyConsensus: %+v", err)
+		}
+		blockInfo, err := readOnlyConsensus.GetBlockInfo(blockHash)
+		if err != nil {
+			t.Fatalf("GetBlockInfo: %+v", err)
+		}
+		if !blockInfo.Exists {
+			t.Fatalf("a block of the active consensus was not found on the read-only consensus")
+		}
+		report, err := readOnlyConsensus.CheckIntegrity()
+		if err != nil {
+			t.Fatalf("CheckIntegrity: %+v", err)
+		}
+		if len(report.Issues) != 0 {
+			t.Fatalf("Expected no integrity issues, but got %d. The first: %s: %s",
+				len(report.Issues), report.Issues[0].Check, report.Issues[0].Description)
+		}
+
+		entriesAfter := readAllDatabaseEntries(t, db)
+		if !reflect.DeepEqual(entriesBefore, entriesAfter) {
+			t.Fatalf("the read-only consensus changed the database: it had %d entries before and %d after",
+				len(entriesBefore), len(entriesAfter))
+		}
+	})
+}
+
+func readAllDatabaseEntries(t *testing.T, db infrastructuredatabase.Database) map[string]string {
+	cursor, err := db.Cursor(infrastructuredatabase.MakeBucket(nil))
+	if err != nil {
+		t.Fatalf("Cursor: %+v", err)
+	}
+	defer cursor.Close()
+
+	entries := make(map[string]string)
+	for cursor.Next() {
+		key, err := cursor.Key()
+		if err != nil {
+			t.Fatalf("Key: %+v", err)
+		}
+		value, err := cursor.Value()
+		if err != nil {
+			t.Fatalf("Value: %+v", err)
+		}
+		entries[string(key.Bytes())] = string(value)
+	}
+	return entries
+}
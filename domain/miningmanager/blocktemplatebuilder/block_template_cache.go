@@ -0,0 +1,79 @@
+package blocktemplatebuilder
+
+import (
+	"time"
+
+	consensusexternalapi "github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/util/mstime"
+)
+
+// blockTemplateCacheMaxAge is the time after which the transactions of a cached block template are selected
+// from scratch even if the virtual didn't change, so that neither its timestamp nor the transactions
+// patched into it grow stale
+const blockTemplateCacheMaxAge = 10 * time.Second
+
+// blockTemplateCache holds the last block template built, along with the state it was built from.
+// The template remains valid as long as the virtual and the mempool transactions don't change,
+// and until its transactions were selected more than blockTemplateCacheMaxAge ago.
+type blockTemplateCache struct {
+	block                  *consensusexternalapi.DomainBlock
+	coinbaseData           *consensusexternalapi.DomainCoinbaseData
+	mempoolVersion         uint64
+	transactionsSelectedAt mstime.Time
+	selectedTransactions   selectedTransactions
+	selectedTransactionIDs map[consensusexternalapi.DomainTransactionID]struct{}
+}
+
+func newBlockTemplateCache(block *consensusexternalapi.DomainBlock,
+	coinbaseData *consensusexternalapi.DomainCoinbaseData, mempoolVersion uint64, transactionsSelectedAt mstime.Time,
+	selectedTransactions selectedTransactions) *blockTemplateCache {
+
+	selectedTransactionIDs := make(map[consensusexternalapi.DomainTransactionID]struct{}, len(selectedTransactions.selectedTxs))
+	for _, transaction := range selectedTransactions.selectedTxs {
+		selectedTransactionIDs[*consensushashing.TransactionID(transaction)] = struct{}{}
+	}
+
+	return &blockTemplateCache{
+		block:                  block,
+		coinbaseData:           coinbaseData.Clone(),
+		mempoolVersion:         mempoolVersion,
+		transactionsSelectedAt: transactionsSelectedAt,
+		selectedTransactions:   selectedTransactions,
+		selectedTransactionIDs: selectedTransactionIDs,
+	}
+}
+
+// isBuiltOn returns whether the cached template was built on top of the given virtual
+func (btc *blockTemplateCache) isBuiltOn(virtualInfo *consensusexternalapi.VirtualInfo) bool {
+	header := btc.block.Header
+	return consensusexternalapi.HashesEqual(header.DirectParents(), virtualInfo.ParentHashes) &&
+		header.DAAScore() == virtualInfo.DAAScore &&
+		header.BlueScore() == virtualInfo.BlueScore &&
+		header.Bits() == virtualInfo.Bits
+}
+
+// isExpired returns whether the transactions of the cached template were selected more than
+// blockTemplateCacheMaxAge before the given time
+func (btc *blockTemplateCache) isExpired(now mstime.Time) bool {
+	return now.Sub(btc.transactionsSelectedAt) > blockTemplateCacheMaxAge
+}
+
+// splitCandidates splits the given candidates into the ones that were selected for the cached
+// template and the rest.
+// If some of the transactions selected for the cached template are no longer candidates, areAllSelectedTxsCandidates
+// is false
+func (btc *blockTemplateCache) splitCandidates(candidateTxs []*candidateTx) (
+	previouslySelectedTxs []*candidateTx, otherTxs []*candidateTx, areAllSelectedTxsCandidates bool) {
+
+	previouslySelectedTxs = make([]*candidateTx, 0, len(btc.selectedTransactionIDs))
+	otherTxs = make([]*candidateTx, 0, len(candidateTxs))
+	for _, candidateTx := range candidateTxs {
+		if _, ok := btc.selectedTransactionIDs[*consensushashing.TransactionID(candidateTx.DomainTransaction)]; ok {
+			previouslySelectedTxs = append(previouslySelectedTxs, candidateTx)
+			continue
+		}
+		otherTxs = append(otherTxs, candidateTx)
+	}
+	return previouslySelectedTxs, otherTxs, len(previouslySelectedTxs) == len(btc.selectedTransactionIDs)
+}
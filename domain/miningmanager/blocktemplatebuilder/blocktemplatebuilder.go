@@ -2,13 +2,15 @@ package blocktemplatebuilder
 
 import (
 	"github.com/kaspanet/kaspad/domain/consensusreference"
-	"math"
 	"sort"
+	"sync"
 
 	"github.com/kaspanet/kaspad/util/difficulty"
+	"github.com/kaspanet/kaspad/util/mstime"
 
 	consensusexternalapi "github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/subnetworks"
 	miningmanagerapi "github.com/kaspanet/kaspad/domain/miningmanager/model"
 	"github.com/pkg/errors"
@@ -31,14 +33,21 @@ type blockTemplateBuilder struct {
 	consensusReference consensusreference.ConsensusReference
 	mempool            miningmanagerapi.Mempool
 	policy             policy
+	clock              mstime.Clock
+
+	mtx   sync.Mutex
+	cache *blockTemplateCache
 }
 
-// New creates a new blockTemplateBuilder
-func New(consensusReference consensusreference.ConsensusReference, mempool miningmanagerapi.Mempool, blockMaxMass uint64) miningmanagerapi.BlockTemplateBuilder {
+// New creates a new blockTemplateBuilder. clock is used to expire the cached block template
+func New(consensusReference consensusreference.ConsensusReference, mempool miningmanagerapi.Mempool,
+	blockMaxMass uint64, clock mstime.Clock) miningmanagerapi.BlockTemplateBuilder {
+
 	return &blockTemplateBuilder{
 		consensusReference: consensusReference,
 		mempool:            mempool,
 		policy:             policy{BlockMaxMass: blockMaxMass},
+		clock:              clock,
 	}
 }
 
@@ -106,18 +115,96 @@ func New(consensusReference consensusreference.ConsensusReference, mempool minin
 //  |  <= policy.BlockMinSize)          |   |
 //   -----------------------------------  --
 
+// GetBlockTemplate caches the last template, and returns it as long as the virtual, the mempool
+// transactions and the coinbase data remain unchanged. If transactions were only added to the mempool,
+// the cached transaction selection is patched: the remaining block space is filled from the new
+// candidates. A change in the virtual, the removal of a selected transaction from the mempool, or a
+// cached template older than blockTemplateCacheMaxAge always rebuilds the template from scratch.
 func (btb *blockTemplateBuilder) GetBlockTemplate(coinbaseData *consensusexternalapi.DomainCoinbaseData) (*consensusexternalapi.DomainBlock, error) {
+	btb.mtx.Lock()
+	defer btb.mtx.Unlock()
+
+	return btb.getBlockTemplate(coinbaseData)
+}
+
+func (btb *blockTemplateBuilder) getBlockTemplate(coinbaseData *consensusexternalapi.DomainCoinbaseData) (*consensusexternalapi.DomainBlock, error) {
+	virtualInfo, err := btb.consensusReference.Consensus().GetVirtualInfo()
+	if err != nil {
+		return nil, err
+	}
+	// The version is read before the candidates, so that a change that happens in between
+	// will only cause the next call to needlessly patch the template, rather than to miss a change
+	mempoolVersion := btb.mempool.TransactionsVersion()
+
+	cache := btb.cache
+	if cache == nil || !cache.isBuiltOn(virtualInfo) || cache.isExpired(btb.clock.Now()) {
+		log.Debugf("Virtual changed or the cached block template expired, building a new block template")
+		candidateTxs, err := btb.candidateTransactions()
+		if err != nil {
+			return nil, err
+		}
+		return btb.buildBlockTemplate(coinbaseData, mempoolVersion, btb.selectTransactions(candidateTxs),
+			btb.clock.Now())
+	}
+
+	if cache.mempoolVersion != mempoolVersion {
+		candidateTxs, err := btb.candidateTransactions()
+		if err != nil {
+			return nil, err
+		}
+		previouslySelectedTxs, otherTxs, areAllSelectedTxsCandidates := cache.splitCandidates(candidateTxs)
+		if !areAllSelectedTxsCandidates {
+			log.Debugf("Selected transactions left the mempool, building a new block template")
+			return btb.buildBlockTemplate(coinbaseData, mempoolVersion, btb.selectTransactions(candidateTxs),
+				btb.clock.Now())
+		}
+		log.Debugf("Mempool changed, patching the cached block template")
+		return btb.buildBlockTemplate(coinbaseData, mempoolVersion,
+			btb.selectAdditionalTransactions(previouslySelectedTxs, otherTxs), cache.transactionsSelectedAt)
+	}
+
+	if !cache.coinbaseData.Equal(coinbaseData) {
+		log.Debugf("Coinbase data changed, rebuilding the cached block template")
+		return btb.buildBlockTemplate(coinbaseData, mempoolVersion, cache.selectedTransactions,
+			cache.transactionsSelectedAt)
+	}
+
+	log.Debugf("Returning the cached block template")
+	return cache.block.Clone(), nil
+}
+
+// candidateTransactions returns the mempool's block candidate transactions, sorted by subnetwork ID
+func (btb *blockTemplateBuilder) candidateTransactions() ([]*candidateTx, error) {
 	mempoolTransactions := btb.mempool.BlockCandidateTransactions()
 	candidateTxs := make([]*candidateTx, 0, len(mempoolTransactions))
-	for _, tx := range mempoolTransactions {
-		// Calculate the tx value
+	gasLimits := make(map[consensusexternalapi.DomainSubnetworkID]uint64)
+	for _, mempoolTransaction := range mempoolTransactions {
+		tx := mempoolTransaction.Transaction
 		gasLimit := uint64(0)
 		if !subnetworks.IsBuiltInOrNative(tx.SubnetworkID) {
-			panic("We currently don't support non native subnetworks")
+			var ok bool
+			gasLimit, ok = gasLimits[tx.SubnetworkID]
+			if !ok {
+				var exists bool
+				var err error
+				gasLimit, exists, err = btb.consensusReference.Consensus().GetSubnetworkGasLimit(&tx.SubnetworkID)
+				if err != nil {
+					return nil, err
+				}
+				if !exists {
+					// The mempool only accepts transactions of registered subnetworks, and subnetworks
+					// are never unregistered, so this should never happen
+					log.Warnf("Transaction %s belongs to subnetwork %s, which is not registered. Skipping it",
+						consensushashing.TransactionID(tx), tx.SubnetworkID)
+					continue
+				}
+				gasLimits[tx.SubnetworkID] = gasLimit
+			}
 		}
+		// Calculate the tx value
 		candidateTxs = append(candidateTxs, &candidateTx{
 			DomainTransaction: tx,
-			txValue:           btb.calcTxValue(tx),
+			txValue:           btb.calcTxValue(mempoolTransaction, gasLimit),
 			gasLimit:          gasLimit,
 		})
 	}
@@ -130,7 +217,15 @@ func (btb *blockTemplateBuilder) GetBlockTemplate(coinbaseData *consensusexterna
 	log.Debugf("Considering %d transactions for inclusion to new block",
 		len(candidateTxs))
 
-	blockTxs := btb.selectTransactions(candidateTxs)
+	return candidateTxs, nil
+}
+
+// buildBlockTemplate builds a block template out of blockTxs and caches it. transactionsSelectedAt is the time
+// blockTxs were last selected from scratch, rather than patched
+func (btb *blockTemplateBuilder) buildBlockTemplate(coinbaseData *consensusexternalapi.DomainCoinbaseData,
+	mempoolVersion uint64, blockTxs selectedTransactions, transactionsSelectedAt mstime.Time) (
+	*consensusexternalapi.DomainBlock, error) {
+
 	blk, err := btb.consensusReference.Consensus().BuildBlock(coinbaseData, blockTxs.selectedTxs)
 
 	invalidTxsErr := ruleerrors.ErrInvalidTransactionsInNewBlock{}
@@ -148,31 +243,37 @@ func (btb *blockTemplateBuilder) GetBlockTemplate(coinbaseData *consensusexterna
 			log.Criticalf("Error from mempool.RemoveTransactions: %+v", err)
 		}
 		// We can call this recursively without worry because this should almost never happen
-		return btb.GetBlockTemplate(coinbaseData)
+		btb.cache = nil
+		return btb.getBlockTemplate(coinbaseData)
 	}
 
 	if err != nil {
 		return nil, err
 	}
 
+	btb.cache = newBlockTemplateCache(blk, coinbaseData, mempoolVersion, transactionsSelectedAt, blockTxs)
+
 	log.Debugf("Created new block template (%d transactions, %d in fees, %d mass, target difficulty %064x)",
 		len(blk.Transactions), blockTxs.totalFees, blockTxs.totalMass, difficulty.CompactToBig(blk.Header.Bits()))
 
-	return blk, nil
+	return blk.Clone(), nil
 }
 
 // calcTxValue calculates a value to be used in transaction selection.
 // The higher the number the more likely it is that the transaction will be
 // included in the block.
-func (btb *blockTemplateBuilder) calcTxValue(tx *consensusexternalapi.DomainTransaction) float64 {
+// The value is derived from the fee rate of the transaction's package rather than
+// the transaction's own fee rate, so that a high-fee child can pay for its parent.
+// Transactions in non-native subnetworks also consume the gas of their subnetwork,
+// whose limit is given by gasLimit.
+func (btb *blockTemplateBuilder) calcTxValue(candidate *miningmanagerapi.BlockCandidateTransaction, gasLimit uint64) float64 {
 	massLimit := btb.policy.BlockMaxMass
 
-	mass := tx.Mass
-	fee := tx.Fee
-	if subnetworks.IsBuiltInOrNative(tx.SubnetworkID) {
+	tx := candidate.Transaction
+	mass := candidate.PackageMass
+	fee := candidate.PackageFee
+	if subnetworks.IsBuiltInOrNative(tx.SubnetworkID) || gasLimit == 0 {
 		return float64(fee) / (float64(mass) / float64(massLimit))
 	}
-	// TODO: Replace with real gas once implemented
-	gasLimit := uint64(math.MaxUint64)
 	return float64(fee) / (float64(mass)/float64(massLimit) + float64(tx.Gas)/float64(gasLimit))
 }
@@ -57,16 +57,34 @@ type selectedTransactions struct {
 // txsForBlockTemplates.
 // See selectTxs for further details.
 func (btb *blockTemplateBuilder) selectTransactions(candidateTxs []*candidateTx) selectedTransactions {
+	return btb.selectAdditionalTransactions(nil, candidateTxs)
+}
+
+// selectAdditionalTransactions is like selectTransactions, except that the block space and
+// gas used by alreadySelectedTxs are reserved for them, and they are included in the result.
+func (btb *blockTemplateBuilder) selectAdditionalTransactions(
+	alreadySelectedTxs []*candidateTx, candidateTxs []*candidateTx) selectedTransactions {
+
 	txsForBlockTemplate := selectedTransactions{
-		selectedTxs: make([]*consensusexternalapi.DomainTransaction, 0, len(candidateTxs)),
-		txMasses:    make([]uint64, 0, len(candidateTxs)),
-		txFees:      make([]uint64, 0, len(candidateTxs)),
+		selectedTxs: make([]*consensusexternalapi.DomainTransaction, 0, len(alreadySelectedTxs)+len(candidateTxs)),
+		txMasses:    make([]uint64, 0, len(alreadySelectedTxs)+len(candidateTxs)),
+		txFees:      make([]uint64, 0, len(alreadySelectedTxs)+len(candidateTxs)),
 		totalMass:   0,
 		totalFees:   0,
 	}
+	gasUsageMap := make(map[consensusexternalapi.DomainSubnetworkID]uint64)
+	selectedTxs := make([]*candidateTx, 0, len(alreadySelectedTxs))
+	for _, alreadySelectedTx := range alreadySelectedTxs {
+		selectedTxs = append(selectedTxs, alreadySelectedTx)
+		txsForBlockTemplate.totalMass += alreadySelectedTx.Mass
+		txsForBlockTemplate.totalFees += alreadySelectedTx.Fee
+		if !subnetworks.IsBuiltInOrNative(alreadySelectedTx.SubnetworkID) {
+			gasUsageMap[alreadySelectedTx.SubnetworkID] += alreadySelectedTx.Gas
+		}
+	}
+
 	usedCount, usedP := 0, 0.0
 	candidateTxs, totalP := rebalanceCandidates(candidateTxs, true)
-	gasUsageMap := make(map[consensusexternalapi.DomainSubnetworkID]uint64)
 
 	markCandidateTxForDeletion := func(candidateTx *candidateTx) {
 		candidateTx.isMarkedForDeletion = true
@@ -74,7 +92,6 @@ func (btb *blockTemplateBuilder) selectTransactions(candidateTxs []*candidateTx)
 		usedP += candidateTx.p
 	}
 
-	selectedTxs := make([]*candidateTx, 0)
 	for len(candidateTxs)-usedCount > 0 {
 		// Rebalance the candidates if it's required
 		if usedP >= rebalanceThreshold*totalP {
@@ -0,0 +1,101 @@
+package blocktemplatebuilder
+
+import (
+	"math/rand"
+	"testing"
+
+	consensusexternalapi "github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/subnetworks"
+	miningmanagerapi "github.com/kaspanet/kaspad/domain/miningmanager/model"
+)
+
+const (
+	benchmarkBlockMaxMass        = 10_000_000
+	benchmarkMempoolSize         = 100_000
+	benchmarkChildPaysForParentP = 0.2
+)
+
+// syntheticCandidate is a block candidate transaction along with the fee of the
+// descendant that is made minable once the candidate is mined
+type syntheticCandidate struct {
+	candidate     *miningmanagerapi.BlockCandidateTransaction
+	descendantFee uint64
+}
+
+// generateSyntheticMempool generates a mempool with benchmarkMempoolSize ready transactions,
+// benchmarkChildPaysForParentP of which are low-fee parents of high-fee children
+func generateSyntheticMempool() []*syntheticCandidate {
+	random := rand.New(rand.NewSource(0))
+	syntheticMempool := make([]*syntheticCandidate, benchmarkMempoolSize)
+	for i := range syntheticMempool {
+		mass := uint64(1000 + random.Intn(9000))
+		fee := mass * uint64(1+random.Intn(10))
+		transaction := &consensusexternalapi.DomainTransaction{
+			SubnetworkID: subnetworks.SubnetworkIDNative,
+			Fee:          fee,
+			Mass:         mass,
+		}
+		candidate := &syntheticCandidate{
+			candidate: &miningmanagerapi.BlockCandidateTransaction{
+				Transaction: transaction,
+				PackageFee:  fee,
+				PackageMass: mass,
+			},
+		}
+		if random.Float64() < benchmarkChildPaysForParentP {
+			transaction.Fee = mass
+			childMass := uint64(1000 + random.Intn(9000))
+			candidate.descendantFee = childMass * uint64(50+random.Intn(50))
+			candidate.candidate.PackageFee = transaction.Fee + candidate.descendantFee
+			candidate.candidate.PackageMass = mass + childMass
+		}
+		syntheticMempool[i] = candidate
+	}
+	return syntheticMempool
+}
+
+func benchmarkSelectTransactions(b *testing.B, valueByPackage bool) {
+	btb := &blockTemplateBuilder{policy: policy{BlockMaxMass: benchmarkBlockMaxMass}}
+	syntheticMempool := generateSyntheticMempool()
+	descendantFees := make(map[*consensusexternalapi.DomainTransaction]uint64, len(syntheticMempool))
+	for _, syntheticCandidate := range syntheticMempool {
+		descendantFees[syntheticCandidate.candidate.Transaction] = syntheticCandidate.descendantFee
+	}
+
+	totalFees := uint64(0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		candidateTxs := make([]*candidateTx, len(syntheticMempool))
+		for j, syntheticCandidate := range syntheticMempool {
+			candidate := syntheticCandidate.candidate
+			if !valueByPackage {
+				candidate = &miningmanagerapi.BlockCandidateTransaction{
+					Transaction: candidate.Transaction,
+					PackageFee:  candidate.Transaction.Fee,
+					PackageMass: candidate.Transaction.Mass,
+				}
+			}
+			candidateTxs[j] = &candidateTx{
+				DomainTransaction: candidate.Transaction,
+				txValue:           btb.calcTxValue(candidate, 0),
+			}
+		}
+
+		selected := btb.selectTransactions(candidateTxs)
+
+		// Count the fees of both the selected transactions and of the descendants they made minable
+		totalFees += selected.totalFees
+		for _, selectedTx := range selected.selectedTxs {
+			totalFees += descendantFees[selectedTx]
+		}
+	}
+	b.ReportMetric(float64(totalFees)/float64(b.N), "fees/op")
+}
+
+func BenchmarkSelectTransactionsByTransactionFeeRate(b *testing.B) {
+	benchmarkSelectTransactions(b, false)
+}
+
+func BenchmarkSelectTransactionsByPackageFeeRate(b *testing.B) {
+	benchmarkSelectTransactions(b, true)
+}
@@ -161,7 +161,7 @@ func (mp *mempool) checkTransactionStandardInContext(transaction *externalapi.Do
 		switch txscript.GetScriptClass(originScriptPubKey.Script) {
 		case txscript.ScriptHashTy:
 			numSigOps := txscript.GetPreciseSigOpCount(
-				input.SignatureScript, originScriptPubKey, true)
+				input.SignatureScript, originScriptPubKey, true, txscript.ScriptNoFlags)
 			if numSigOps > maxStandardP2SHSigOps {
 				str := fmt.Sprintf("transaction input #%d has %d signature operations which is more "+
 					"than the allowed max amount of %d", i, numSigOps, maxStandardP2SHSigOps)
@@ -188,12 +188,13 @@ func (mp *mempool) checkTransactionStandardInContext(transaction *externalapi.Do
 // transaction with the passed mass to be accepted into the mampool and relayed.
 func (mp *mempool) minimumRequiredTransactionRelayFee(mass uint64) uint64 {
 	// Calculate the minimum fee for a transaction to be allowed into the
-	// mempool and relayed by scaling the base fee. MinimumRelayTransactionFee is in
+	// mempool and relayed by scaling the base fee. The minimum fee rate is in
 	// sompi/kg so multiply by mass (which is in grams) and divide by 1000 to get minimum sompis.
-	minimumFee := (mass * uint64(mp.config.MinimumRelayTransactionFee)) / 1000
+	minimumFeeRate := mp.minimumFeeRate()
+	minimumFee := (mass * minimumFeeRate) / 1000
 
-	if minimumFee == 0 && mp.config.MinimumRelayTransactionFee > 0 {
-		minimumFee = uint64(mp.config.MinimumRelayTransactionFee)
+	if minimumFee == 0 && minimumFeeRate > 0 {
+		minimumFee = minimumFeeRate
 	}
 
 	// Set the minimum fee to the maximum possible value if the calculated
@@ -204,3 +205,15 @@ func (mp *mempool) minimumRequiredTransactionRelayFee(mass uint64) uint64 {
 
 	return minimumFee
 }
+
+// minimumFeeRate returns the minimum fee rate, in sompi/kg, required for a transaction to be accepted
+// into the mempool. That is the higher of MinimumRelayTransactionFee and the dynamic minimum fee rate,
+// which rises when the mempool is full.
+func (mp *mempool) minimumFeeRate() uint64 {
+	minimumFeeRate := uint64(mp.config.MinimumRelayTransactionFee)
+	dynamicMinimumFeeRate := mp.transactionsPool.dynamicMinimumFeeRate.current()
+	if dynamicMinimumFeeRate > minimumFeeRate {
+		return dynamicMinimumFeeRate
+	}
+	return minimumFeeRate
+}
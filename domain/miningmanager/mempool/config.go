@@ -6,6 +6,7 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
 
 	"github.com/kaspanet/kaspad/util"
+	"github.com/kaspanet/kaspad/util/mstime"
 
 	"github.com/kaspanet/kaspad/domain/dagconfig"
 )
@@ -13,6 +14,14 @@ import (
 const (
 	defaultMaximumTransactionCount = 1_000_000
 
+	// defaultMaximumTotalTransactionMass limits the total mass of all the transactions in the mempool,
+	// so that a flood of high-mass transactions can't exhaust the node's memory
+	defaultMaximumTotalTransactionMass = 500_000_000
+
+	// defaultDynamicMinimumFeeRateHalfLifeSeconds is the time it takes the dynamic minimum fee rate,
+	// which rises whenever transactions are evicted from a full mempool, to decay by half
+	defaultDynamicMinimumFeeRateHalfLifeSeconds = 600
+
 	defaultTransactionExpireIntervalSeconds     uint64 = 60
 	defaultTransactionExpireScanIntervalSeconds uint64 = 10
 	defaultOrphanExpireIntervalSeconds          uint64 = 60
@@ -27,6 +36,15 @@ const (
 	// the mempool and relayed. It is specified in sompi per 1kg (or 1000 grams) of transaction mass.
 	defaultMinimumRelayTransactionFee = util.Amount(1000)
 
+	// defaultMinimumReplacementFeeRateIncrement specifies the amount by which a replacement transaction's fee rate
+	// must exceed the fee rates of the transactions it replaces. Like defaultMinimumRelayTransactionFee, it is
+	// specified in sompi per 1kg of transaction mass.
+	defaultMinimumReplacementFeeRateIncrement = util.Amount(1000)
+
+	// defaultMaximumReplacedTransactionCount limits the number of transactions (conflicting transactions and their
+	// redeemers) a single replacement transaction may evict from the mempool.
+	defaultMaximumReplacedTransactionCount = 100
+
 	// Standard transaction version range might be different from what consensus accepts, therefore
 	// we define separate values in mempool.
 	// However, currently there's exactly one transaction version, so mempool accepts the same version
@@ -38,6 +56,8 @@ const (
 // Config represents a mempool configuration
 type Config struct {
 	MaximumTransactionCount               uint64
+	MaximumTotalTransactionMass           uint64
+	DynamicMinimumFeeRateHalfLifeSeconds  uint64
 	TransactionExpireIntervalDAAScore     uint64
 	TransactionExpireScanIntervalDAAScore uint64
 	TransactionExpireScanIntervalSeconds  uint64
@@ -50,6 +70,11 @@ type Config struct {
 	MinimumRelayTransactionFee            util.Amount
 	MinimumStandardTransactionVersion     uint16
 	MaximumStandardTransactionVersion     uint16
+	AllowReplaceByFee                     bool
+	MinimumReplacementFeeRateIncrement    util.Amount
+	MaximumReplacedTransactionCount       uint64
+	// Clock is the source of the current time for the time-based expiry and fee rate decay
+	Clock mstime.Clock
 }
 
 // DefaultConfig returns the default mempool configuration
@@ -58,6 +83,8 @@ func DefaultConfig(dagParams *dagconfig.Params) *Config {
 
 	return &Config{
 		MaximumTransactionCount:               defaultMaximumTransactionCount,
+		MaximumTotalTransactionMass:           defaultMaximumTotalTransactionMass,
+		DynamicMinimumFeeRateHalfLifeSeconds:  defaultDynamicMinimumFeeRateHalfLifeSeconds,
 		TransactionExpireIntervalDAAScore:     uint64(float64(defaultTransactionExpireIntervalSeconds) / targetBlocksPerSecond),
 		TransactionExpireScanIntervalDAAScore: uint64(float64(defaultTransactionExpireScanIntervalSeconds) / targetBlocksPerSecond),
 		TransactionExpireScanIntervalSeconds:  defaultTransactionExpireScanIntervalSeconds,
@@ -70,5 +97,9 @@ func DefaultConfig(dagParams *dagconfig.Params) *Config {
 		MinimumRelayTransactionFee:            defaultMinimumRelayTransactionFee,
 		MinimumStandardTransactionVersion:     defaultMinimumStandardTransactionVersion,
 		MaximumStandardTransactionVersion:     defaultMaximumStandardTransactionVersion,
+		AllowReplaceByFee:                     false,
+		MinimumReplacementFeeRateIncrement:    defaultMinimumReplacementFeeRateIncrement,
+		MaximumReplacedTransactionCount:       defaultMaximumReplacedTransactionCount,
+		Clock:                                 mstime.NewSystemClock(),
 	}
 }
@@ -0,0 +1,64 @@
+package mempool
+
+import (
+	"math"
+
+	"github.com/kaspanet/kaspad/util/mstime"
+)
+
+// dynamicMinimumFeeRate is a minimum fee rate, in sompi/kg, which is raised whenever transactions
+// are evicted from a full mempool, and decays exponentially over time once the pressure is gone.
+type dynamicMinimumFeeRate struct {
+	mempool    *mempool
+	feeRate    float64
+	updateTime mstime.Time
+}
+
+func newDynamicMinimumFeeRate(mp *mempool) *dynamicMinimumFeeRate {
+	return &dynamicMinimumFeeRate{
+		mempool:    mp,
+		feeRate:    0,
+		updateTime: mp.config.Clock.Now(),
+	}
+}
+
+// current returns the dynamic minimum fee rate, in sompi/kg, decayed according to
+// the time passed since it was last raised
+func (dmfr *dynamicMinimumFeeRate) current() uint64 {
+	return uint64(dmfr.decayedFeeRate())
+}
+
+func (dmfr *dynamicMinimumFeeRate) decayedFeeRate() float64 {
+	if dmfr.feeRate == 0 {
+		return 0
+	}
+
+	halfLife := float64(dmfr.mempool.config.DynamicMinimumFeeRateHalfLifeSeconds)
+	if halfLife == 0 {
+		return 0
+	}
+	elapsed := dmfr.mempool.config.Clock.Now().Sub(dmfr.updateTime).Seconds()
+	feeRate := dmfr.feeRate * math.Pow(0.5, elapsed/halfLife)
+
+	// Once the fee rate has decayed well below the static minimum, there's no
+	// reason to keep it around
+	if feeRate < float64(dmfr.mempool.config.MinimumRelayTransactionFee)/2 {
+		return 0
+	}
+	return feeRate
+}
+
+// raise raises the dynamic minimum fee rate above the fee rate, in sompi/kg, of a transaction
+// that was evicted from the mempool, so that transactions that would immediately be evicted
+// again are not accepted
+func (dmfr *dynamicMinimumFeeRate) raise(evictedFeeRate float64) {
+	newFeeRate := evictedFeeRate + float64(dmfr.mempool.config.MinimumRelayTransactionFee)
+	decayedFeeRate := dmfr.decayedFeeRate()
+	if newFeeRate < decayedFeeRate {
+		newFeeRate = decayedFeeRate
+	}
+
+	dmfr.feeRate = newFeeRate
+	dmfr.updateTime = dmfr.mempool.config.Clock.Now()
+	log.Debugf("Dynamic minimum fee rate raised to %f sompi/kg", newFeeRate)
+}
@@ -63,6 +63,30 @@ func (mp *mempool) TransactionCount() int {
 	return mp.transactionsPool.transactionCount()
 }
 
+// TransactionsVersion returns a counter that changes whenever the set of transactions in the mempool
+// (not including orphans) changes
+func (mp *mempool) TransactionsVersion() uint64 {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	return mp.transactionsPool.transactionsVersion()
+}
+
+func (mp *mempool) TotalTransactionMass() uint64 {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	return mp.transactionsPool.totalTransactionMass()
+}
+
+// MinimumFeeRate returns the fee rate, in sompi/kg, currently required for a transaction to be accepted
+func (mp *mempool) MinimumFeeRate() uint64 {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	return mp.minimumFeeRate()
+}
+
 func (mp *mempool) HandleNewBlockTransactions(transactions []*externalapi.DomainTransaction) (
 	acceptedOrphans []*externalapi.DomainTransaction, err error) {
 
@@ -72,7 +96,7 @@ func (mp *mempool) HandleNewBlockTransactions(transactions []*externalapi.Domain
 	return mp.handleNewBlockTransactions(transactions)
 }
 
-func (mp *mempool) BlockCandidateTransactions() []*externalapi.DomainTransaction {
+func (mp *mempool) BlockCandidateTransactions() []*miningmanagermodel.BlockCandidateTransaction {
 	mp.mtx.RLock()
 	defer mp.mtx.RUnlock()
 
@@ -11,6 +11,14 @@ type MempoolTransaction struct {
 	parentTransactionsInPool OutpointToTransactionMap
 	isHighPriority           bool
 	addedAtDAAScore          uint64
+
+	ancestorFee   uint64
+	ancestorMass  uint64
+	ancestorCount uint64
+
+	descendantFee   uint64
+	descendantMass  uint64
+	descendantCount uint64
 }
 
 // NewMempoolTransaction constructs a new MempoolTransaction
@@ -25,6 +33,12 @@ func NewMempoolTransaction(
 		parentTransactionsInPool: parentTransactionsInPool,
 		isHighPriority:           isHighPriority,
 		addedAtDAAScore:          addedAtDAAScore,
+		ancestorFee:              transaction.Fee,
+		ancestorMass:             transaction.Mass,
+		ancestorCount:            1,
+		descendantFee:            transaction.Fee,
+		descendantMass:           transaction.Mass,
+		descendantCount:          1,
 	}
 }
 
@@ -43,6 +57,12 @@ func (mt *MempoolTransaction) ParentTransactionsInPool() OutpointToTransactionMa
 	return mt.parentTransactionsInPool
 }
 
+// RemoveParentTransactionInPool removes the parent transaction that created the given outpoint
+// from this MempoolTransaction's ParentTransactionsInPool
+func (mt *MempoolTransaction) RemoveParentTransactionInPool(outpoint externalapi.DomainOutpoint) {
+	delete(mt.parentTransactionsInPool, outpoint)
+}
+
 // IsHighPriority returns whether this MempoolTransaction is a high-priority one
 func (mt *MempoolTransaction) IsHighPriority() bool {
 	return mt.isHighPriority
@@ -52,3 +72,51 @@ func (mt *MempoolTransaction) IsHighPriority() bool {
 func (mt *MempoolTransaction) AddedAtDAAScore() uint64 {
 	return mt.addedAtDAAScore
 }
+
+// AncestorFee returns the total fee of this MempoolTransaction and all its ancestors in the mempool
+func (mt *MempoolTransaction) AncestorFee() uint64 {
+	return mt.ancestorFee
+}
+
+// AncestorMass returns the total mass of this MempoolTransaction and all its ancestors in the mempool
+func (mt *MempoolTransaction) AncestorMass() uint64 {
+	return mt.ancestorMass
+}
+
+// AncestorCount returns the number of transactions in the ancestor package of this MempoolTransaction,
+// including itself
+func (mt *MempoolTransaction) AncestorCount() uint64 {
+	return mt.ancestorCount
+}
+
+// SetAncestorData sets the total fee, total mass and number of transactions of the ancestor package
+// of this MempoolTransaction
+func (mt *MempoolTransaction) SetAncestorData(ancestorFee uint64, ancestorMass uint64, ancestorCount uint64) {
+	mt.ancestorFee = ancestorFee
+	mt.ancestorMass = ancestorMass
+	mt.ancestorCount = ancestorCount
+}
+
+// DescendantFee returns the total fee of this MempoolTransaction and all its descendants in the mempool
+func (mt *MempoolTransaction) DescendantFee() uint64 {
+	return mt.descendantFee
+}
+
+// DescendantMass returns the total mass of this MempoolTransaction and all its descendants in the mempool
+func (mt *MempoolTransaction) DescendantMass() uint64 {
+	return mt.descendantMass
+}
+
+// DescendantCount returns the number of transactions in the descendant package of this MempoolTransaction,
+// including itself
+func (mt *MempoolTransaction) DescendantCount() uint64 {
+	return mt.descendantCount
+}
+
+// SetDescendantData sets the total fee, total mass and number of transactions of the descendant package
+// of this MempoolTransaction
+func (mt *MempoolTransaction) SetDescendantData(descendantFee uint64, descendantMass uint64, descendantCount uint64) {
+	mt.descendantFee = descendantFee
+	mt.descendantMass = descendantMass
+	mt.descendantCount = descendantCount
+}
@@ -52,7 +52,7 @@ func (mp *mempool) removeTransaction(transactionID *externalapi.DomainTransactio
 func (mp *mempool) removeTransactionFromSets(mempoolTransaction *model.MempoolTransaction, removeRedeemers bool) error {
 	mp.mempoolUTXOSet.removeTransaction(mempoolTransaction)
 
-	err := mp.transactionsPool.removeTransaction(mempoolTransaction)
+	err := mp.transactionsPool.removeTransaction(mempoolTransaction, removeRedeemers)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,154 @@
+package mempool
+
+import (
+	"fmt"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool/model"
+)
+
+// validateReplaceByFee checks whether the given transaction may replace the mempool transactions it
+// double-spends, and returns the transactions that have to be evicted from the mempool in order to accept it:
+// the conflicting transactions as well as all their redeemers.
+// A replacement is valid only if:
+//  1. Its fee rate exceeds the fee rate of every transaction it evicts, including the redeemers
+//     of the conflicting transactions, by at least MinimumReplacementFeeRateIncrement
+//  2. Its fee exceeds the total fee of all evicted transactions by at least the fee
+//     MinimumReplacementFeeRateIncrement requires for its own mass
+//  3. It does not evict more than MaximumReplacedTransactionCount transactions
+//  4. It does not spend any of the outputs of the transactions it evicts
+//  5. It does not evict any high-priority transaction, unless it is high-priority itself
+//
+// This function expects the transaction's fee and mass to be populated.
+func (mp *mempool) validateReplaceByFee(transaction *externalapi.DomainTransaction, isHighPriority bool,
+	parentsInPool model.OutpointToTransactionMap) ([]*model.MempoolTransaction, error) {
+
+	conflictingTransactions := mp.mempoolUTXOSet.getConflictingTransactions(transaction)
+	if len(conflictingTransactions) == 0 {
+		return nil, nil
+	}
+	if !mp.config.AllowReplaceByFee {
+		str := fmt.Sprintf("transaction %s double spends transaction %s in the memory pool",
+			consensushashing.TransactionID(transaction), conflictingTransactions[0].TransactionID())
+		return nil, transactionRuleError(RejectDuplicate, str)
+	}
+
+	transactionID := consensushashing.TransactionID(transaction)
+
+	// Every conflicting transaction is evicted together with its whole descendant package, so the largest
+	// such package is a lower bound on the number of evicted transactions, which is known without
+	// walking the redeemers
+	for _, conflictingTransaction := range conflictingTransactions {
+		if conflictingTransaction.DescendantCount() > mp.config.MaximumReplacedTransactionCount {
+			str := fmt.Sprintf("replacement transaction %s would evict at least %d transactions from the "+
+				"memory pool, which is more than the maximum allowed %d",
+				transactionID, conflictingTransaction.DescendantCount(), mp.config.MaximumReplacedTransactionCount)
+			return nil, transactionRuleError(RejectDuplicate, str)
+		}
+	}
+
+	transactionsToReplace := model.IDToTransactionMap{}
+	for _, conflictingTransaction := range conflictingTransactions {
+		transactionsToReplace[*conflictingTransaction.TransactionID()] = conflictingTransaction
+		for _, redeemer := range mp.transactionsPool.getRedeemers(conflictingTransaction) {
+			transactionsToReplace[*redeemer.TransactionID()] = redeemer
+		}
+	}
+
+	if uint64(len(transactionsToReplace)) > mp.config.MaximumReplacedTransactionCount {
+		str := fmt.Sprintf("replacement transaction %s would evict %d transactions from the memory pool, "+
+			"which is more than the maximum allowed %d",
+			transactionID, len(transactionsToReplace), mp.config.MaximumReplacedTransactionCount)
+		return nil, transactionRuleError(RejectDuplicate, str)
+	}
+
+	if !isHighPriority {
+		for _, transactionToReplace := range transactionsToReplace {
+			if transactionToReplace.IsHighPriority() {
+				str := fmt.Sprintf("replacement transaction %s would evict the high-priority transaction %s "+
+					"from the memory pool", transactionID, transactionToReplace.TransactionID())
+				return nil, transactionRuleError(RejectDuplicate, str)
+			}
+		}
+	}
+
+	for outpoint, parentInPool := range parentsInPool {
+		if _, ok := transactionsToReplace[*parentInPool.TransactionID()]; ok {
+			str := fmt.Sprintf("replacement transaction %s spends output %s of transaction %s, which it replaces",
+				transactionID, outpoint, parentInPool.TransactionID())
+			return nil, transactionRuleError(RejectInvalid, str)
+		}
+	}
+
+	transactionFeeRate := feeRate(transaction)
+	// MinimumReplacementFeeRateIncrement is in sompi/kg, while fee rates are in sompi/gram
+	minimumFeeRateIncrement := float64(mp.config.MinimumReplacementFeeRateIncrement) / 1000
+
+	replacedFees := uint64(0)
+	result := make([]*model.MempoolTransaction, 0, len(transactionsToReplace))
+	for _, transactionToReplace := range transactionsToReplace {
+		replacedFeeRate := feeRate(transactionToReplace.Transaction())
+		if transactionFeeRate <= replacedFeeRate || transactionFeeRate < replacedFeeRate+minimumFeeRateIncrement {
+			str := fmt.Sprintf("replacement transaction %s has a fee rate of %f sompi/gram, which does not "+
+				"exceed the fee rate of transaction %s (%f sompi/gram), which it would evict, by at least %f sompi/gram",
+				transactionID, transactionFeeRate, transactionToReplace.TransactionID(), replacedFeeRate,
+				minimumFeeRateIncrement)
+			return nil, transactionRuleError(RejectInsufficientFee, str)
+		}
+
+		replacedFees += transactionToReplace.Transaction().Fee
+		result = append(result, transactionToReplace)
+	}
+
+	minimumFee := replacedFees + mp.minimumReplacementFeeIncrement(transaction.Mass)
+	if transaction.Fee <= replacedFees || transaction.Fee < minimumFee {
+		str := fmt.Sprintf("replacement transaction %s has %d fees, which is under the required amount of %d "+
+			"for replacing %d transactions paying %d fees in total",
+			transactionID, transaction.Fee, minimumFee, len(transactionsToReplace), replacedFees)
+		return nil, transactionRuleError(RejectInsufficientFee, str)
+	}
+
+	return result, nil
+}
+
+// replaceTransactions evicts the given transactionsToReplace, which were returned by
+// validateReplaceByFee, from the mempool in favor of replacingTransaction.
+// It must be called only once nothing can prevent replacingTransaction from entering the
+// mempool, since the evicted transactions are not restored.
+func (mp *mempool) replaceTransactions(replacingTransaction *externalapi.DomainTransaction,
+	transactionsToReplace []*model.MempoolTransaction) error {
+
+	for _, transactionToReplace := range transactionsToReplace {
+		log.Debugf("Removing transaction %s, because it was replaced by transaction %s",
+			transactionToReplace.TransactionID(), consensushashing.TransactionID(replacingTransaction))
+
+		// All the redeemers are already included in transactionsToReplace, however the orphan
+		// redeemers have to be removed as well, so we still ask for removeRedeemers
+		err := mp.removeTransaction(transactionToReplace.TransactionID(), true)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// minimumReplacementFeeIncrement returns the minimum amount by which the fee of a replacement
+// transaction with the passed mass must exceed the fees of the transactions it replaces
+func (mp *mempool) minimumReplacementFeeIncrement(mass uint64) uint64 {
+	// MinimumReplacementFeeRateIncrement is in sompi/kg so multiply by mass (which is in grams) and
+	// divide by 1000 to get sompis.
+	minimumFeeIncrement := (mass * uint64(mp.config.MinimumReplacementFeeRateIncrement)) / 1000
+
+	if minimumFeeIncrement > constants.MaxSompi {
+		minimumFeeIncrement = constants.MaxSompi
+	}
+
+	return minimumFeeIncrement
+}
+
+func feeRate(transaction *externalapi.DomainTransaction) float64 {
+	return float64(transaction.Fee) / float64(transaction.Mass)
+}
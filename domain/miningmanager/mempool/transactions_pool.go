@@ -1,19 +1,27 @@
 package mempool
 
 import (
+	"fmt"
+
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/miningmanager/mempool/model"
-	"time"
+	miningmanagermodel "github.com/kaspanet/kaspad/domain/miningmanager/model"
+	"github.com/kaspanet/kaspad/util/mstime"
 )
 
 type transactionsPool struct {
-	mempool                               *mempool
-	allTransactions                       model.IDToTransactionMap
-	highPriorityTransactions              model.IDToTransactionMap
+	mempool                  *mempool
+	allTransactions          model.IDToTransactionMap
+	highPriorityTransactions model.IDToTransactionMap
+	// chainedTransactionsByPreviousOutpoint maps every outpoint created by a transaction in the pool
+	// to the transaction in the pool that redeems it
 	chainedTransactionsByPreviousOutpoint model.OutpointToTransactionMap
 	transactionsOrderedByFeeRate          model.TransactionsOrderedByFeeRate
+	totalMass                             uint64
+	version                               uint64
+	dynamicMinimumFeeRate                 *dynamicMinimumFeeRate
 	lastExpireScanDAAScore                uint64
-	lastExpireScanTime                    time.Time
+	lastExpireScanTime                    mstime.Time
 }
 
 func newTransactionsPool(mp *mempool) *transactionsPool {
@@ -23,39 +31,31 @@ func newTransactionsPool(mp *mempool) *transactionsPool {
 		highPriorityTransactions:              model.IDToTransactionMap{},
 		chainedTransactionsByPreviousOutpoint: model.OutpointToTransactionMap{},
 		transactionsOrderedByFeeRate:          model.TransactionsOrderedByFeeRate{},
+		totalMass:                             0,
+		version:                               0,
+		dynamicMinimumFeeRate:                 newDynamicMinimumFeeRate(mp),
 		lastExpireScanDAAScore:                0,
-		lastExpireScanTime:                    time.Now(),
+		lastExpireScanTime:                    mp.config.Clock.Now(),
 	}
 }
 
-func (tp *transactionsPool) addTransaction(transaction *externalapi.DomainTransaction,
-	parentTransactionsInPool model.OutpointToTransactionMap, isHighPriority bool) (*model.MempoolTransaction, error) {
-
-	virtualDAAScore, err := tp.mempool.consensusReference.Consensus().GetVirtualDAAScore()
-	if err != nil {
-		return nil, err
-	}
-
-	mempoolTransaction := model.NewMempoolTransaction(
-		transaction, parentTransactionsInPool, isHighPriority, virtualDAAScore)
-
-	err = tp.addMempoolTransaction(mempoolTransaction)
-	if err != nil {
-		return nil, err
-	}
-
-	return mempoolTransaction, nil
-}
-
 func (tp *transactionsPool) addMempoolTransaction(transaction *model.MempoolTransaction) error {
 	tp.allTransactions[*transaction.TransactionID()] = transaction
 
-	for outpoint, parentTransactionInPool := range transaction.ParentTransactionsInPool() {
-		tp.chainedTransactionsByPreviousOutpoint[outpoint] = parentTransactionInPool
+	for outpoint := range transaction.ParentTransactionsInPool() {
+		tp.chainedTransactionsByPreviousOutpoint[outpoint] = transaction
 	}
 
 	tp.mempool.mempoolUTXOSet.addTransaction(transaction)
 
+	// A new transaction has no redeemers in the pool yet, so it only joins the descendant
+	// packages of its ancestors
+	ancestors := tp.getAncestors(transaction)
+	tp.setAncestorData(transaction, ancestors)
+	for _, ancestor := range ancestors {
+		addToDescendantData(ancestor, transaction)
+	}
+
 	err := tp.transactionsOrderedByFeeRate.Push(transaction)
 	if err != nil {
 		return err
@@ -65,10 +65,16 @@ func (tp *transactionsPool) addMempoolTransaction(transaction *model.MempoolTran
 		tp.highPriorityTransactions[*transaction.TransactionID()] = transaction
 	}
 
+	tp.totalMass += transaction.Transaction().Mass
+	tp.version++
+
 	return nil
 }
 
-func (tp *transactionsPool) removeTransaction(transaction *model.MempoolTransaction) error {
+// removeTransaction removes the given transaction from the pool and updates the ancestor and
+// descendant data of the transactions related to it.
+// removeRedeemers must be true if the redeemers of transaction are about to be removed as well.
+func (tp *transactionsPool) removeTransaction(transaction *model.MempoolTransaction, removeRedeemers bool) error {
 	delete(tp.allTransactions, *transaction.TransactionID())
 
 	err := tp.transactionsOrderedByFeeRate.Remove(transaction)
@@ -78,10 +84,53 @@ func (tp *transactionsPool) removeTransaction(transaction *model.MempoolTransact
 
 	delete(tp.highPriorityTransactions, *transaction.TransactionID())
 
+	tp.totalMass -= transaction.Transaction().Mass
+	tp.version++
+
 	for outpoint := range transaction.ParentTransactionsInPool() {
 		delete(tp.chainedTransactionsByPreviousOutpoint, outpoint)
 	}
 
+	ancestors := tp.getAncestors(transaction)
+	if removeRedeemers {
+		// Every redeemer removes itself from the descendant data of its own ancestors once it is
+		// removed, so only transaction itself has to be removed from the descendant data of its ancestors
+		for _, ancestor := range ancestors {
+			subtractFromDescendantData(ancestor, transaction)
+		}
+		return nil
+	}
+
+	// Redeemers that remain in the pool (e.g. when transaction was included in a block) no longer
+	// have transaction as a parent in the pool
+	descendants := tp.getRedeemers(transaction)
+	outpoint := externalapi.DomainOutpoint{TransactionID: *transaction.TransactionID()}
+	for i := range transaction.Transaction().Outputs {
+		outpoint.Index = uint32(i)
+		if redeemer, ok := tp.chainedTransactionsByPreviousOutpoint[outpoint]; ok {
+			redeemer.RemoveParentTransactionInPool(outpoint)
+			delete(tp.chainedTransactionsByPreviousOutpoint, outpoint)
+		}
+	}
+
+	if len(ancestors) == 0 {
+		// transaction appears exactly once in the ancestor package of each of its descendants. This is
+		// always the case for transactions included in a block, since their ancestors were mined before them
+		for _, descendant := range descendants {
+			subtractFromAncestorData(descendant, transaction)
+		}
+		return nil
+	}
+
+	// Removing a transaction from the middle of a chain may or may not disconnect its ancestors from
+	// its descendants, so the packages around it are recalculated
+	for _, ancestor := range ancestors {
+		tp.setDescendantData(ancestor, tp.getRedeemers(ancestor))
+	}
+	for _, descendant := range descendants {
+		tp.setAncestorData(descendant, tp.getAncestors(descendant))
+	}
+
 	return nil
 }
 
@@ -92,7 +141,7 @@ func (tp *transactionsPool) expireOldTransactions() error {
 	}
 
 	if virtualDAAScore-tp.lastExpireScanDAAScore < tp.mempool.config.TransactionExpireScanIntervalDAAScore ||
-		time.Since(tp.lastExpireScanTime).Seconds() < float64(tp.mempool.config.TransactionExpireScanIntervalSeconds) {
+		tp.mempool.config.Clock.Now().Sub(tp.lastExpireScanTime).Seconds() < float64(tp.mempool.config.TransactionExpireScanIntervalSeconds) {
 		return nil
 	}
 
@@ -115,22 +164,75 @@ func (tp *transactionsPool) expireOldTransactions() error {
 	}
 
 	tp.lastExpireScanDAAScore = virtualDAAScore
-	tp.lastExpireScanTime = time.Now()
+	tp.lastExpireScanTime = tp.mempool.config.Clock.Now()
 	return nil
 }
 
-func (tp *transactionsPool) allReadyTransactions() []*externalapi.DomainTransaction {
-	result := []*externalapi.DomainTransaction{}
+// allReadyTransactions returns all the transactions that have no parents in the pool, along with the
+// best ancestor package each of them is a part of.
+// Consensus does not allow a block to contain chained transactions, so a transaction can only be mined
+// after all of its ancestors were mined. Therefore, instead of including a child transaction together
+// with its ancestors, the child's fee is used to prioritize its ready ancestors, so that a high-fee child
+// can pay for a low-fee parent.
+func (tp *transactionsPool) allReadyTransactions() []*miningmanagermodel.BlockCandidateTransaction {
+	result := []*miningmanagermodel.BlockCandidateTransaction{}
 
+	bestPackages := make(map[externalapi.DomainTransactionID]*transactionPackage, len(tp.allTransactions))
 	for _, mempoolTransaction := range tp.allTransactions {
-		if len(mempoolTransaction.ParentTransactionsInPool()) == 0 {
-			result = append(result, mempoolTransaction.Transaction())
+		if len(mempoolTransaction.ParentTransactionsInPool()) != 0 {
+			continue
 		}
+
+		bestPackage := tp.bestAncestorPackage(mempoolTransaction, bestPackages)
+		result = append(result, &miningmanagermodel.BlockCandidateTransaction{
+			Transaction: mempoolTransaction.Transaction(),
+			PackageFee:  bestPackage.fee,
+			PackageMass: bestPackage.mass,
+		})
 	}
 
 	return result
 }
 
+type transactionPackage struct {
+	fee  uint64
+	mass uint64
+}
+
+// bestAncestorPackage returns the ancestor package with the highest fee rate out of the ancestor packages
+// of the given transaction and of all its descendants.
+// bestPackages memoizes the results, so that every transaction in the pool is visited only once
+// over all the calls that share it.
+func (tp *transactionsPool) bestAncestorPackage(transaction *model.MempoolTransaction,
+	bestPackages map[externalapi.DomainTransactionID]*transactionPackage) *transactionPackage {
+
+	if bestPackage, ok := bestPackages[*transaction.TransactionID()]; ok {
+		return bestPackage
+	}
+
+	bestPackage := &transactionPackage{fee: transaction.AncestorFee(), mass: transaction.AncestorMass()}
+	outpoint := externalapi.DomainOutpoint{TransactionID: *transaction.TransactionID()}
+	for i := range transaction.Transaction().Outputs {
+		outpoint.Index = uint32(i)
+		redeemer, ok := tp.chainedTransactionsByPreviousOutpoint[outpoint]
+		if !ok {
+			continue
+		}
+		redeemerBestPackage := tp.bestAncestorPackage(redeemer, bestPackages)
+		if isFeeRateHigher(redeemerBestPackage.fee, redeemerBestPackage.mass, bestPackage.fee, bestPackage.mass) {
+			bestPackage = redeemerBestPackage
+		}
+	}
+
+	bestPackages[*transaction.TransactionID()] = bestPackage
+	return bestPackage
+}
+
+// isFeeRateHigher returns whether fee / mass is higher than otherFee / otherMass
+func isFeeRateHigher(fee uint64, mass uint64, otherFee uint64, otherMass uint64) bool {
+	return float64(fee)/float64(mass) > float64(otherFee)/float64(otherMass)
+}
+
 func (tp *transactionsPool) getParentTransactionsInPool(
 	transaction *externalapi.DomainTransaction) model.OutpointToTransactionMap {
 
@@ -145,7 +247,69 @@ func (tp *transactionsPool) getParentTransactionsInPool(
 	return parentsTransactionsInPool
 }
 
+// getAncestors returns all the ancestors of the given transaction that are in the pool
+func (tp *transactionsPool) getAncestors(transaction *model.MempoolTransaction) []*model.MempoolTransaction {
+	visited := model.IDToTransactionMap{}
+	stack := []*model.MempoolTransaction{transaction}
+	ancestors := []*model.MempoolTransaction{}
+	for len(stack) > 0 {
+		var current *model.MempoolTransaction
+		last := len(stack) - 1
+		current, stack = stack[last], stack[:last]
+
+		for _, parentTransactionInPool := range current.ParentTransactionsInPool() {
+			if _, ok := visited[*parentTransactionInPool.TransactionID()]; ok {
+				continue
+			}
+			visited[*parentTransactionInPool.TransactionID()] = parentTransactionInPool
+			stack = append(stack, parentTransactionInPool)
+			ancestors = append(ancestors, parentTransactionInPool)
+		}
+	}
+	return ancestors
+}
+
+// setAncestorData sets the fee, mass and count of the ancestor package of the given transaction
+// out of its given ancestors
+func (tp *transactionsPool) setAncestorData(transaction *model.MempoolTransaction, ancestors []*model.MempoolTransaction) {
+	ancestorFee := transaction.Transaction().Fee
+	ancestorMass := transaction.Transaction().Mass
+	for _, ancestor := range ancestors {
+		ancestorFee += ancestor.Transaction().Fee
+		ancestorMass += ancestor.Transaction().Mass
+	}
+	transaction.SetAncestorData(ancestorFee, ancestorMass, uint64(len(ancestors))+1)
+}
+
+// setDescendantData sets the fee, mass and count of the descendant package of the given transaction
+// out of its given descendants
+func (tp *transactionsPool) setDescendantData(transaction *model.MempoolTransaction, descendants []*model.MempoolTransaction) {
+	descendantFee := transaction.Transaction().Fee
+	descendantMass := transaction.Transaction().Mass
+	for _, descendant := range descendants {
+		descendantFee += descendant.Transaction().Fee
+		descendantMass += descendant.Transaction().Mass
+	}
+	transaction.SetDescendantData(descendantFee, descendantMass, uint64(len(descendants))+1)
+}
+
+func addToDescendantData(transaction *model.MempoolTransaction, descendant *model.MempoolTransaction) {
+	transaction.SetDescendantData(transaction.DescendantFee()+descendant.Transaction().Fee,
+		transaction.DescendantMass()+descendant.Transaction().Mass, transaction.DescendantCount()+1)
+}
+
+func subtractFromDescendantData(transaction *model.MempoolTransaction, descendant *model.MempoolTransaction) {
+	transaction.SetDescendantData(transaction.DescendantFee()-descendant.Transaction().Fee,
+		transaction.DescendantMass()-descendant.Transaction().Mass, transaction.DescendantCount()-1)
+}
+
+func subtractFromAncestorData(transaction *model.MempoolTransaction, ancestor *model.MempoolTransaction) {
+	transaction.SetAncestorData(transaction.AncestorFee()-ancestor.Transaction().Fee,
+		transaction.AncestorMass()-ancestor.Transaction().Mass, transaction.AncestorCount()-1)
+}
+
 func (tp *transactionsPool) getRedeemers(transaction *model.MempoolTransaction) []*model.MempoolTransaction {
+	visited := model.IDToTransactionMap{}
 	stack := []*model.MempoolTransaction{transaction}
 	redeemers := []*model.MempoolTransaction{}
 	for len(stack) > 0 {
@@ -157,6 +321,10 @@ func (tp *transactionsPool) getRedeemers(transaction *model.MempoolTransaction)
 		for i := range current.Transaction().Outputs {
 			outpoint.Index = uint32(i)
 			if redeemerTransaction, ok := tp.chainedTransactionsByPreviousOutpoint[outpoint]; ok {
+				if _, ok := visited[*redeemerTransaction.TransactionID()]; ok {
+					continue
+				}
+				visited[*redeemerTransaction.TransactionID()] = redeemerTransaction
 				stack = append(stack, redeemerTransaction)
 				redeemers = append(redeemers, redeemerTransaction)
 			}
@@ -165,38 +333,125 @@ func (tp *transactionsPool) getRedeemers(transaction *model.MempoolTransaction)
 	return redeemers
 }
 
-func (tp *transactionsPool) limitTransactionCount() error {
+// checkTransactionFitsInPool checks whether the pool would be within its limits after adding the given
+// transaction and evicting transactionsToReplace, once limitTransactionPoolSize evicts the transactions
+// with lower fee rates than the given transaction's.
+// It returns a RejectInsufficientFee error if limitTransactionPoolSize would have to evict a transaction
+// paying a fee rate at least as high as the given transaction's.
+func (tp *transactionsPool) checkTransactionFitsInPool(transaction *model.MempoolTransaction,
+	transactionsToReplace []*model.MempoolTransaction) error {
+
+	// High-priority transactions are never evicted, so there's no point in rejecting them
+	if transaction.IsHighPriority() {
+		return nil
+	}
+
+	evictedTransactions := model.IDToTransactionMap{}
+	count := uint64(len(tp.allTransactions)) + 1
+	mass := tp.totalMass + transaction.Transaction().Mass
+	evict := func(evictedTransaction *model.MempoolTransaction) {
+		if _, ok := evictedTransactions[*evictedTransaction.TransactionID()]; ok {
+			return
+		}
+		evictedTransactions[*evictedTransaction.TransactionID()] = evictedTransaction
+		count--
+		mass -= evictedTransaction.Transaction().Mass
+	}
+	for _, transactionToReplace := range transactionsToReplace {
+		evict(transactionToReplace)
+	}
+
+	protectedTransactions := tp.protectedTransactions(transaction)
+	for i := 0; tp.isOverLimitWith(count, mass); i++ {
+		if i >= len(tp.allTransactions) {
+			str := fmt.Sprintf("transaction %s was not accepted: the mempool is full with transactions "+
+				"that may not be evicted", transaction.TransactionID())
+			return transactionRuleError(RejectInsufficientFee, str)
+		}
+
+		candidate := tp.transactionsOrderedByFeeRate.GetByIndex(i)
+		if _, ok := evictedTransactions[*candidate.TransactionID()]; ok {
+			continue
+		}
+		if _, ok := protectedTransactions[*candidate.TransactionID()]; ok || candidate.IsHighPriority() {
+			continue
+		}
+		if !isFeeRateHigher(transaction.Transaction().Fee, transaction.Transaction().Mass,
+			candidate.Transaction().Fee, candidate.Transaction().Mass) {
+
+			str := fmt.Sprintf("transaction %s was not accepted: the mempool is full and its fee rate "+
+				"is not higher than that of transaction %s, which would have to be evicted to make room for it",
+				transaction.TransactionID(), candidate.TransactionID())
+			return transactionRuleError(RejectInsufficientFee, str)
+		}
+
+		evict(candidate)
+		for _, redeemer := range tp.getRedeemers(candidate) {
+			evict(redeemer)
+		}
+	}
+
+	return nil
+}
+
+// limitTransactionPoolSize evicts the transactions with the lowest fee rates, along with their redeemers,
+// until both the transaction count and the total transaction mass are within the configured limits.
+// High-priority transactions, as well as protectedTransaction and its ancestors, are never evicted.
+// Every eviction raises the dynamic minimum fee rate above the fee rate of the evicted transaction.
+func (tp *transactionsPool) limitTransactionPoolSize(protectedTransaction *model.MempoolTransaction) error {
+	protectedTransactions := tp.protectedTransactions(protectedTransaction)
 	currentIndex := 0
 
-	for uint64(len(tp.allTransactions)) > tp.mempool.config.MaximumTransactionCount {
+	for tp.isOverLimit() {
 		var transactionToRemove *model.MempoolTransaction
 		for {
-			transactionToRemove = tp.transactionsOrderedByFeeRate.GetByIndex(currentIndex)
-			if !transactionToRemove.IsHighPriority() {
-				break
-			}
-			currentIndex++
 			if currentIndex >= len(tp.allTransactions) {
 				log.Warnf(
-					"Number of high-priority transactions in mempool (%d) is higher than maximum allowed (%d)",
-					len(tp.allTransactions), tp.mempool.config.MaximumTransactionCount)
+					"Transactions that may not be evicted from the mempool (count: %d, mass: %d) exceed "+
+						"the maximum allowed (count: %d, mass: %d)", len(tp.allTransactions), tp.totalMass,
+					tp.mempool.config.MaximumTransactionCount, tp.mempool.config.MaximumTotalTransactionMass)
 				return nil
 			}
+			transactionToRemove = tp.transactionsOrderedByFeeRate.GetByIndex(currentIndex)
+			_, isProtected := protectedTransactions[*transactionToRemove.TransactionID()]
+			if !isProtected && !transactionToRemove.IsHighPriority() {
+				break
+			}
+			currentIndex++
 		}
 
-		log.Debugf("Removing transaction %s, because mempoolTransaction count (%d) exceeded the limit (%d)",
-			transactionToRemove.TransactionID(), len(tp.allTransactions), tp.mempool.config.MaximumTransactionCount)
+		log.Debugf("Removing transaction %s, because the mempool (count: %d, mass: %d) exceeded the limit "+
+			"(count: %d, mass: %d)", transactionToRemove.TransactionID(), len(tp.allTransactions), tp.totalMass,
+			tp.mempool.config.MaximumTransactionCount, tp.mempool.config.MaximumTotalTransactionMass)
+		evictedFeeRate := float64(transactionToRemove.Transaction().Fee) * 1000 /
+			float64(transactionToRemove.Transaction().Mass)
 		err := tp.mempool.removeTransaction(transactionToRemove.TransactionID(), true)
 		if err != nil {
 			return err
 		}
-		if currentIndex >= len(tp.allTransactions) {
-			break
-		}
+		tp.dynamicMinimumFeeRate.raise(evictedFeeRate)
 	}
 	return nil
 }
 
+// protectedTransactions returns the given transaction along with its ancestors, none of which may be
+// evicted without evicting the given transaction as well
+func (tp *transactionsPool) protectedTransactions(transaction *model.MempoolTransaction) model.IDToTransactionMap {
+	protectedTransactions := model.IDToTransactionMap{*transaction.TransactionID(): transaction}
+	for _, ancestor := range tp.getAncestors(transaction) {
+		protectedTransactions[*ancestor.TransactionID()] = ancestor
+	}
+	return protectedTransactions
+}
+
+func (tp *transactionsPool) isOverLimit() bool {
+	return tp.isOverLimitWith(uint64(len(tp.allTransactions)), tp.totalMass)
+}
+
+func (tp *transactionsPool) isOverLimitWith(count uint64, mass uint64) bool {
+	return count > tp.mempool.config.MaximumTransactionCount || mass > tp.mempool.config.MaximumTotalTransactionMass
+}
+
 func (tp *transactionsPool) getTransaction(transactionID *externalapi.DomainTransactionID) (*externalapi.DomainTransaction, bool) {
 	if mempoolTransaction, ok := tp.allTransactions[*transactionID]; ok {
 		return mempoolTransaction.Transaction(), true
@@ -215,3 +470,13 @@ func (tp *transactionsPool) getAllTransactions() []*externalapi.DomainTransactio
 func (tp *transactionsPool) transactionCount() int {
 	return len(tp.allTransactions)
 }
+
+func (tp *transactionsPool) totalTransactionMass() uint64 {
+	return tp.totalMass
+}
+
+// transactionsVersion returns a counter that is incremented whenever a transaction is added to
+// or removed from the pool
+func (tp *transactionsPool) transactionsVersion() uint64 {
+	return tp.version
+}
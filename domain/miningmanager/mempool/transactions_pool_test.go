@@ -0,0 +1,315 @@
+package mempool
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/subnetworks"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool/model"
+)
+
+// TestChainedTransactionsByPreviousOutpoint verifies that every outpoint created by a transaction in the
+// pool maps to the transaction in the pool that redeems it, so that the redeemers of a transaction are
+// found by following its outputs
+func TestChainedTransactionsByPreviousOutpoint(t *testing.T) {
+	mp := newTestMempool()
+
+	parent := addTestTransaction(t, mp, createTestTransaction(1000, 1000, randomOutpoint()))
+	child := addTestTransaction(t, mp, createTestTransaction(1000, 1000, testOutpoint(parent, 0)))
+	grandchild := addTestTransaction(t, mp, createTestTransaction(1000, 1000, testOutpoint(child, 0)))
+
+	if redeemer := mp.transactionsPool.chainedTransactionsByPreviousOutpoint[testOutpoint(parent, 0)]; redeemer != child {
+		t.Fatalf("Expected the output of the parent to be redeemed by the child")
+	}
+	if redeemer := mp.transactionsPool.chainedTransactionsByPreviousOutpoint[testOutpoint(child, 0)]; redeemer != grandchild {
+		t.Fatalf("Expected the output of the child to be redeemed by the grandchild")
+	}
+
+	redeemers := mp.transactionsPool.getRedeemers(parent)
+	if len(redeemers) != 2 || !containsMempoolTransaction(redeemers, child) ||
+		!containsMempoolTransaction(redeemers, grandchild) {
+		t.Fatalf("Expected the redeemers of the parent to be the child and the grandchild, but got %d redeemers",
+			len(redeemers))
+	}
+
+	err := mp.removeTransaction(parent.TransactionID(), true)
+	if err != nil {
+		t.Fatalf("removeTransaction: %+v", err)
+	}
+	if mp.transactionsPool.transactionCount() != 0 {
+		t.Fatalf("Expected removing the parent with its redeemers to empty the pool, but %d transactions remain",
+			mp.transactionsPool.transactionCount())
+	}
+	if len(mp.transactionsPool.chainedTransactionsByPreviousOutpoint) != 0 {
+		t.Fatalf("Expected no chained outpoints to remain, but %d remain",
+			len(mp.transactionsPool.chainedTransactionsByPreviousOutpoint))
+	}
+}
+
+// TestPackageData verifies that the ancestor and descendant data of the transactions in the pool are kept
+// up to date as transactions are added and removed
+func TestPackageData(t *testing.T) {
+	mp := newTestMempool()
+
+	// a is spent by b and c, and d spends both b and c
+	a := addTestTransaction(t, mp, createTestTransaction(100, 1000, randomOutpoint()))
+	b := addTestTransaction(t, mp, createTestTransaction(200, 1000, testOutpoint(a, 0)))
+	c := addTestTransaction(t, mp, createTestTransaction(300, 1000, testOutpoint(a, 1)))
+	d := addTestTransaction(t, mp, createTestTransaction(400, 1000, testOutpoint(b, 0), testOutpoint(c, 0)))
+	checkPackageData(t, mp)
+	if d.AncestorFee() != 1000 || d.AncestorMass() != 4000 || d.AncestorCount() != 4 {
+		t.Fatalf("Unexpected ancestor data of d: fee %d, mass %d, count %d",
+			d.AncestorFee(), d.AncestorMass(), d.AncestorCount())
+	}
+	if a.DescendantFee() != 1000 || a.DescendantMass() != 4000 || a.DescendantCount() != 4 {
+		t.Fatalf("Unexpected descendant data of a: fee %d, mass %d, count %d",
+			a.DescendantFee(), a.DescendantMass(), a.DescendantCount())
+	}
+
+	// Removing b along with its redeemers removes d as well
+	err := mp.removeTransaction(b.TransactionID(), true)
+	if err != nil {
+		t.Fatalf("removeTransaction: %+v", err)
+	}
+	checkPackageData(t, mp)
+	if a.DescendantCount() != 2 {
+		t.Fatalf("Expected a to have 2 transactions in its descendant package, but got %d", a.DescendantCount())
+	}
+
+	// e spends c, and c is then removed from the middle of the chain a -> c -> e without its redeemers
+	e := addTestTransaction(t, mp, createTestTransaction(500, 1000, testOutpoint(c, 1)))
+	checkPackageData(t, mp)
+	err = mp.removeTransaction(c.TransactionID(), false)
+	if err != nil {
+		t.Fatalf("removeTransaction: %+v", err)
+	}
+	checkPackageData(t, mp)
+	if a.DescendantCount() != 1 || e.AncestorCount() != 1 {
+		t.Fatalf("Expected a and e to be unrelated, but a has %d transactions in its descendant package "+
+			"and e has %d in its ancestor package", a.DescendantCount(), e.AncestorCount())
+	}
+
+	// f spends a and is spent by g. Removing a, as if it was mined, leaves f and g in the pool
+	f := addTestTransaction(t, mp, createTestTransaction(600, 1000, testOutpoint(a, 0)))
+	g := addTestTransaction(t, mp, createTestTransaction(700, 1000, testOutpoint(f, 0)))
+	checkPackageData(t, mp)
+	err = mp.removeTransaction(a.TransactionID(), false)
+	if err != nil {
+		t.Fatalf("removeTransaction: %+v", err)
+	}
+	checkPackageData(t, mp)
+	if g.AncestorFee() != 1300 || g.AncestorCount() != 2 {
+		t.Fatalf("Unexpected ancestor data of g: fee %d, count %d", g.AncestorFee(), g.AncestorCount())
+	}
+}
+
+// TestAllReadyTransactions verifies that every ready transaction is prioritized by the best ancestor package
+// it is a part of
+func TestAllReadyTransactions(t *testing.T) {
+	mp := newTestMempool()
+
+	// A low fee parent with a high fee child, and a grandchild with a lower fee rate than the child's package
+	parent := addTestTransaction(t, mp, createTestTransaction(1000, 1000, randomOutpoint()))
+	child := addTestTransaction(t, mp, createTestTransaction(100_000, 1000, testOutpoint(parent, 0)))
+	addTestTransaction(t, mp, createTestTransaction(1000, 1000, testOutpoint(child, 0)))
+	// An unrelated transaction with no descendants
+	unrelated := addTestTransaction(t, mp, createTestTransaction(5000, 1000, randomOutpoint()))
+
+	readyTransactions := mp.transactionsPool.allReadyTransactions()
+	if len(readyTransactions) != 2 {
+		t.Fatalf("Expected 2 ready transactions, but got %d", len(readyTransactions))
+	}
+	for _, readyTransaction := range readyTransactions {
+		transactionID := consensushashing.TransactionID(readyTransaction.Transaction)
+		switch {
+		case transactionID.Equal(parent.TransactionID()):
+			if readyTransaction.PackageFee != 101_000 || readyTransaction.PackageMass != 2000 {
+				t.Fatalf("Expected the parent to be prioritized by its child's package, but got fee %d, mass %d",
+					readyTransaction.PackageFee, readyTransaction.PackageMass)
+			}
+		case transactionID.Equal(unrelated.TransactionID()):
+			if readyTransaction.PackageFee != 5000 || readyTransaction.PackageMass != 1000 {
+				t.Fatalf("Expected the unrelated transaction to be prioritized by itself, but got fee %d, mass %d",
+					readyTransaction.PackageFee, readyTransaction.PackageMass)
+			}
+		default:
+			t.Fatalf("Unexpected ready transaction %s", transactionID)
+		}
+	}
+}
+
+// BenchmarkTransactionsPool measures the transactions pool operations whose cost depends on the length of
+// the transaction chains in the pool
+func BenchmarkTransactionsPool(b *testing.B) {
+	const chainCount = 100
+	const chainLength = 100
+
+	// fillPool fills the pool with chainCount chains of chainLength transactions, and returns their roots
+	fillPool := func(b *testing.B, mp *mempool) []*model.MempoolTransaction {
+		roots := make([]*model.MempoolTransaction, chainCount)
+		for i := range roots {
+			roots[i] = addTestTransaction(b, mp, createTestTransaction(uint64(rand.Intn(10000)+1), 1000, randomOutpoint()))
+			tip := roots[i]
+			for j := 1; j < chainLength; j++ {
+				tip = addTestTransaction(b, mp, createTestTransaction(uint64(rand.Intn(10000)+1), 1000, testOutpoint(tip, 0)))
+			}
+		}
+		return roots
+	}
+
+	b.Run("AddTransactions", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			fillPool(b, newTestMempool())
+		}
+	})
+
+	b.Run("AllReadyTransactions", func(b *testing.B) {
+		mp := newTestMempool()
+		fillPool(b, mp)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			mp.transactionsPool.allReadyTransactions()
+		}
+	})
+
+	b.Run("RemoveMinedTransactions", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			mp := newTestMempool()
+			tips := fillPool(b, mp)
+			b.StartTimer()
+			// Mine the chains one transaction after the other, removing each without its redeemers
+			for len(tips) > 0 {
+				nextTips := make([]*model.MempoolTransaction, 0, len(tips))
+				for _, tip := range tips {
+					redeemer, hasRedeemer := mp.transactionsPool.chainedTransactionsByPreviousOutpoint[testOutpoint(tip, 0)]
+					err := mp.removeTransaction(tip.TransactionID(), false)
+					if err != nil {
+						b.Fatalf("removeTransaction: %+v", err)
+					}
+					if hasRedeemer {
+						nextTips = append(nextTips, redeemer)
+					}
+				}
+				tips = nextTips
+			}
+		}
+	})
+
+	b.Run("RemoveWithRedeemers", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			mp := newTestMempool()
+			roots := fillPool(b, mp)
+			b.StartTimer()
+			for _, root := range roots {
+				err := mp.removeTransaction(root.TransactionID(), true)
+				if err != nil {
+					b.Fatalf("removeTransaction: %+v", err)
+				}
+			}
+		}
+	})
+}
+
+func newTestMempool() *mempool {
+	mp := &mempool{
+		config: DefaultConfig(&dagconfig.SimnetParams),
+	}
+	mp.mempoolUTXOSet = newMempoolUTXOSet(mp)
+	mp.transactionsPool = newTransactionsPool(mp)
+	mp.orphansPool = newOrphansPool(mp)
+	return mp
+}
+
+// createTestTransaction creates a transaction with two outputs that spends the given outpoints and has
+// the given fee and mass
+func createTestTransaction(fee uint64, mass uint64,
+	previousOutpoints ...externalapi.DomainOutpoint) *externalapi.DomainTransaction {
+
+	inputs := make([]*externalapi.DomainTransactionInput, len(previousOutpoints))
+	for i, previousOutpoint := range previousOutpoints {
+		inputs[i] = &externalapi.DomainTransactionInput{
+			PreviousOutpoint: previousOutpoint,
+			SignatureScript:  []byte{},
+			Sequence:         0,
+		}
+	}
+	outputs := make([]*externalapi.DomainTransactionOutput, 2)
+	for i := range outputs {
+		outputs[i] = &externalapi.DomainTransactionOutput{
+			Value:           1,
+			ScriptPublicKey: &externalapi.ScriptPublicKey{Script: []byte{}, Version: 0},
+		}
+	}
+	return &externalapi.DomainTransaction{
+		Version:      0,
+		Inputs:       inputs,
+		Outputs:      outputs,
+		SubnetworkID: subnetworks.SubnetworkIDNative,
+		Payload:      []byte{},
+		Fee:          fee,
+		Mass:         mass,
+	}
+}
+
+// addTestTransaction adds the given transaction directly to the transactions pool, bypassing validation
+func addTestTransaction(t testing.TB, mp *mempool, transaction *externalapi.DomainTransaction) *model.MempoolTransaction {
+	parentsInPool := mp.transactionsPool.getParentTransactionsInPool(transaction)
+	mempoolTransaction := model.NewMempoolTransaction(transaction, parentsInPool, false, 0)
+	err := mp.transactionsPool.addMempoolTransaction(mempoolTransaction)
+	if err != nil {
+		t.Fatalf("addMempoolTransaction: %+v", err)
+	}
+	return mempoolTransaction
+}
+
+// checkPackageData verifies the ancestor and descendant data of every transaction in the pool against data
+// recalculated from scratch
+func checkPackageData(t *testing.T, mp *mempool) {
+	for _, transaction := range mp.transactionsPool.allTransactions {
+		expected := model.NewMempoolTransaction(transaction.Transaction(), nil, false, 0)
+		mp.transactionsPool.setAncestorData(expected, mp.transactionsPool.getAncestors(transaction))
+		mp.transactionsPool.setDescendantData(expected, mp.transactionsPool.getRedeemers(transaction))
+
+		if transaction.AncestorFee() != expected.AncestorFee() || transaction.AncestorMass() != expected.AncestorMass() ||
+			transaction.AncestorCount() != expected.AncestorCount() {
+			t.Fatalf("Unexpected ancestor data of transaction %s: got fee %d, mass %d, count %d, "+
+				"but expected fee %d, mass %d, count %d", transaction.TransactionID(),
+				transaction.AncestorFee(), transaction.AncestorMass(), transaction.AncestorCount(),
+				expected.AncestorFee(), expected.AncestorMass(), expected.AncestorCount())
+		}
+		if transaction.DescendantFee() != expected.DescendantFee() || transaction.DescendantMass() != expected.DescendantMass() ||
+			transaction.DescendantCount() != expected.DescendantCount() {
+			t.Fatalf("Unexpected descendant data of transaction %s: got fee %d, mass %d, count %d, "+
+				"but expected fee %d, mass %d, count %d", transaction.TransactionID(),
+				transaction.DescendantFee(), transaction.DescendantMass(), transaction.DescendantCount(),
+				expected.DescendantFee(), expected.DescendantMass(), expected.DescendantCount())
+		}
+	}
+}
+
+func testOutpoint(transaction *model.MempoolTransaction, index uint32) externalapi.DomainOutpoint {
+	return externalapi.DomainOutpoint{TransactionID: *transaction.TransactionID(), Index: index}
+}
+
+func randomOutpoint() externalapi.DomainOutpoint {
+	var transactionIDBytes [externalapi.DomainHashSize]byte
+	rand.Read(transactionIDBytes[:])
+	return externalapi.DomainOutpoint{
+		TransactionID: *externalapi.NewDomainTransactionIDFromByteArray(&transactionIDBytes),
+		Index:         0,
+	}
+}
+
+func containsMempoolTransaction(transactions []*model.MempoolTransaction, transaction *model.MempoolTransaction) bool {
+	for _, candidate := range transactions {
+		if consensushashing.TransactionID(candidate.Transaction()).Equal(transaction.TransactionID()) {
+			return true
+		}
+	}
+	return false
+}
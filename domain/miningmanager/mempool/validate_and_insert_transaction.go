@@ -7,6 +7,7 @@ import (
 
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool/model"
 )
 
 func (mp *mempool) validateAndInsertTransaction(transaction *externalapi.DomainTransaction, isHighPriority bool,
@@ -36,6 +37,14 @@ func (mp *mempool) validateAndInsertTransaction(transaction *externalapi.DomainT
 			return nil, transactionRuleError(RejectBadOrphan, str)
 		}
 
+		// An orphan's fee is unknown, so it may never replace transactions in the mempool
+		if mp.config.AllowReplaceByFee {
+			err = mp.mempoolUTXOSet.checkDoubleSpends(transaction)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		return nil, mp.orphansPool.maybeAddOrphan(transaction, isHighPriority)
 	}
 
@@ -44,7 +53,30 @@ func (mp *mempool) validateAndInsertTransaction(transaction *externalapi.DomainT
 		return nil, err
 	}
 
-	mempoolTransaction, err := mp.transactionsPool.addTransaction(transaction, parentsInPool, isHighPriority)
+	transactionsToReplace, err := mp.validateReplaceByFee(transaction, isHighPriority, parentsInPool)
+	if err != nil {
+		return nil, err
+	}
+
+	virtualDAAScore, err := mp.consensusReference.Consensus().GetVirtualDAAScore()
+	if err != nil {
+		return nil, err
+	}
+	mempoolTransaction := model.NewMempoolTransaction(transaction, parentsInPool, isHighPriority, virtualDAAScore)
+
+	err = mp.transactionsPool.checkTransactionFitsInPool(mempoolTransaction, transactionsToReplace)
+	if err != nil {
+		return nil, err
+	}
+
+	// Everything that may reject the transaction was checked above, so that the transactions it
+	// replaces are never evicted in favor of a transaction that doesn't enter the mempool
+	err = mp.replaceTransactions(transaction, transactionsToReplace)
+	if err != nil {
+		return nil, err
+	}
+
+	err = mp.transactionsPool.addMempoolTransaction(mempoolTransaction)
 	if err != nil {
 		return nil, err
 	}
@@ -56,7 +88,9 @@ func (mp *mempool) validateAndInsertTransaction(transaction *externalapi.DomainT
 
 	acceptedTransactions = append([]*externalapi.DomainTransaction{transaction}, acceptedOrphans...)
 
-	err = mp.transactionsPool.limitTransactionCount()
+	// The transaction itself was already checked to fit in the mempool, but the accepted orphans might
+	// push the mempool over its limits again
+	err = mp.transactionsPool.limitTransactionPoolSize(mempoolTransaction)
 	if err != nil {
 		return nil, err
 	}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/subnetworks"
 )
 
 func (mp *mempool) validateTransactionPreUTXOEntry(transaction *externalapi.DomainTransaction) error {
@@ -13,8 +14,12 @@ func (mp *mempool) validateTransactionPreUTXOEntry(transaction *externalapi.Doma
 		return err
 	}
 
-	if err := mp.mempoolUTXOSet.checkDoubleSpends(transaction); err != nil {
-		return err
+	// When replace-by-fee is allowed, double spends are resolved by validateReplaceByFee
+	// once the transaction's fee is known
+	if !mp.config.AllowReplaceByFee {
+		if err := mp.mempoolUTXOSet.checkDoubleSpends(transaction); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -26,6 +31,11 @@ func (mp *mempool) validateTransactionInIsolation(transaction *externalapi.Domai
 			fmt.Sprintf("transaction %s is already in the mempool", transactionID))
 	}
 
+	err := mp.validateTransactionGas(transaction)
+	if err != nil {
+		return err
+	}
+
 	if !mp.config.AcceptNonStandard {
 		if err := mp.checkTransactionStandardInIsolation(transaction); err != nil {
 			// Attempt to extract a reject code from the error so
@@ -43,6 +53,29 @@ func (mp *mempool) validateTransactionInIsolation(transaction *externalapi.Domai
 	return nil
 }
 
+// validateTransactionGas checks that a transaction in a non-native subnetwork belongs to a registered
+// subnetwork, and does not use more gas than the subnetwork allows in a single block
+func (mp *mempool) validateTransactionGas(transaction *externalapi.DomainTransaction) error {
+	if subnetworks.IsBuiltInOrNative(transaction.SubnetworkID) {
+		return nil
+	}
+
+	gasLimit, exists, err := mp.consensusReference.Consensus().GetSubnetworkGasLimit(&transaction.SubnetworkID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return transactionRuleError(RejectInvalid, fmt.Sprintf("transaction %s belongs to subnetwork %s, "+
+			"which is not registered", consensushashing.TransactionID(transaction), transaction.SubnetworkID))
+	}
+	if transaction.Gas > gasLimit {
+		return transactionRuleError(RejectInvalid, fmt.Sprintf("transaction %s uses %d gas, which exceeds "+
+			"the gas limit of subnetwork %s (%d)", consensushashing.TransactionID(transaction), transaction.Gas,
+			transaction.SubnetworkID, gasLimit))
+	}
+	return nil
+}
+
 func (mp *mempool) validateTransactionInContext(transaction *externalapi.DomainTransaction) error {
 	if !mp.config.AcceptNonStandard {
 		err := mp.checkTransactionStandardInContext(transaction)
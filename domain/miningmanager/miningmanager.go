@@ -12,6 +12,8 @@ type MiningManager interface {
 	GetTransaction(transactionID *externalapi.DomainTransactionID) (*externalapi.DomainTransaction, bool)
 	AllTransactions() []*externalapi.DomainTransaction
 	TransactionCount() int
+	TotalTransactionMass() uint64
+	MinimumFeeRate() uint64
 	HandleNewBlockTransactions(txs []*externalapi.DomainTransaction) ([]*externalapi.DomainTransaction, error)
 	ValidateAndInsertTransaction(transaction *externalapi.DomainTransaction, isHighPriority bool, allowOrphan bool) (
 		acceptedTransactions []*externalapi.DomainTransaction, err error)
@@ -56,6 +58,14 @@ func (mm *miningManager) TransactionCount() int {
 	return mm.mempool.TransactionCount()
 }
 
+func (mm *miningManager) TotalTransactionMass() uint64 {
+	return mm.mempool.TotalTransactionMass()
+}
+
+func (mm *miningManager) MinimumFeeRate() uint64 {
+	return mm.mempool.MinimumFeeRate()
+}
+
 func (mm *miningManager) RevalidateHighPriorityTransactions() (
 	validTransactions []*externalapi.DomainTransaction, err error) {
 
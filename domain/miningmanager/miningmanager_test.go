@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/kaspanet/kaspad/domain/miningmanager/mempool"
 
@@ -19,6 +20,7 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
 	"github.com/kaspanet/kaspad/domain/miningmanager"
+	"github.com/kaspanet/kaspad/util/mstime"
 	"github.com/pkg/errors"
 )
 
@@ -168,7 +170,641 @@ func TestDoubleSpendInMempool(t *testing.T) {
 	})
 }
 
+// TestReplaceByFee verifies that when replace-by-fee is allowed, a transaction double-spending
+// another transaction in the mempool replaces it and its redeemers only if it pays sufficiently higher fees.
+func TestReplaceByFee(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		consensusConfig.BlockCoinbaseMaturity = 0
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestReplaceByFee")
+		if err != nil {
+			t.Fatalf("Error setting up TestConsensus: %+v", err)
+		}
+		defer teardown(false)
+
+		miningFactory := miningmanager.NewFactory()
+		mempoolConfig := mempool.DefaultConfig(&consensusConfig.Params)
+		mempoolConfig.AllowReplaceByFee = true
+		tcAsConsensus := tc.(externalapi.Consensus)
+		tcAsConsensusPointer := &tcAsConsensus
+		consensusReference := consensusreference.NewConsensusReference(&tcAsConsensusPointer)
+		miningManager := miningFactory.NewMiningManager(consensusReference, &consensusConfig.Params, mempoolConfig)
+
+		transaction, err := createChildAndParentTxsAndAddParentToConsensus(tc)
+		if err != nil {
+			t.Fatalf("Error creating transaction: %+v", err)
+		}
+		_, err = miningManager.ValidateAndInsertTransaction(transaction, false, true)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertTransaction: %v", err)
+		}
+		redeemerTransaction, err := testutils.CreateTransaction(transaction, 1_000_000)
+		if err != nil {
+			t.Fatalf("Error creating redeemerTransaction: %+v", err)
+		}
+		_, err = miningManager.ValidateAndInsertTransaction(redeemerTransaction, false, true)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertTransaction: %v", err)
+		}
+
+		createReplacement := func(additionalFee uint64, additionalOutputs int) *externalapi.DomainTransaction {
+			replacement := transaction.Clone()
+			replacement.ID = nil
+			replacement.Mass = 0
+			replacement.Outputs[0].Value -= additionalFee
+			for i := 0; i < additionalOutputs; i++ {
+				additionalOutput := replacement.Outputs[0].Clone()
+				additionalOutput.Value = 10_000_000
+				replacement.Outputs[0].Value -= additionalOutput.Value
+				replacement.Outputs = append(replacement.Outputs, additionalOutput)
+			}
+			return replacement
+		}
+
+		// A replacement paying only 1 additional sompi should be rejected
+		_, err = miningManager.ValidateAndInsertTransaction(createReplacement(1, 0), false, true)
+		txRuleError := &mempool.TxRuleError{}
+		if !errors.As(err, txRuleError) || txRuleError.RejectCode != mempool.RejectInsufficientFee {
+			t.Fatalf("Unexpected error for a replacement with insufficient fee: %+v", err)
+		}
+
+		// A replacement paying more than the total fee of the transactions it evicts, but with a fee rate
+		// lower than that of the redeemer it evicts, should be rejected and leave the mempool untouched
+		_, err = miningManager.ValidateAndInsertTransaction(createReplacement(1_100_000, 20), false, true)
+		if !errors.As(err, txRuleError) || txRuleError.RejectCode != mempool.RejectInsufficientFee ||
+			!strings.Contains(err.Error(), consensushashing.TransactionID(redeemerTransaction).String()) {
+			t.Fatalf("Unexpected error for a replacement with a lower fee rate than an evicted redeemer: %+v", err)
+		}
+		mempoolTransactions := miningManager.AllTransactions()
+		if len(mempoolTransactions) != 2 || !contains(transaction, mempoolTransactions) ||
+			!contains(redeemerTransaction, mempoolTransactions) {
+			t.Fatalf("Expected the rejected replacement to leave the mempool untouched, "+
+				"but got %v", consensushashing.TransactionIDs(mempoolTransactions))
+		}
+
+		// A replacement that would evict more transactions than allowed should be rejected
+		mempoolConfig.MaximumReplacedTransactionCount = 1
+		_, err = miningManager.ValidateAndInsertTransaction(createReplacement(2_000_000, 0), false, true)
+		if err == nil || !strings.Contains(err.Error(), "would evict at least 2 transactions") {
+			t.Fatalf("Unexpected error for a replacement evicting too many transactions: %+v", err)
+		}
+		mempoolConfig.MaximumReplacedTransactionCount = 2
+
+		// A replacement paying a high enough fee should replace both transaction and its redeemer
+		replacement := createReplacement(2_000_000, 0)
+		_, err = miningManager.ValidateAndInsertTransaction(replacement, false, true)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertTransaction: %v", err)
+		}
+		mempoolTransactions = miningManager.AllTransactions()
+		if len(mempoolTransactions) != 1 || !contains(replacement, mempoolTransactions) {
+			t.Fatalf("Expected the replacement transaction to be the only transaction in the mempool, "+
+				"but got %v", consensushashing.TransactionIDs(mempoolTransactions))
+		}
+	})
+}
+
+// TestReplaceByFeeOfHighPriorityTransactions verifies that a high-priority transaction and its
+// redeemers may be replaced only by a transaction that is high-priority itself.
+func TestReplaceByFeeOfHighPriorityTransactions(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		consensusConfig.BlockCoinbaseMaturity = 0
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestReplaceByFeeOfHighPriorityTransactions")
+		if err != nil {
+			t.Fatalf("Error setting up TestConsensus: %+v", err)
+		}
+		defer teardown(false)
+
+		miningFactory := miningmanager.NewFactory()
+		mempoolConfig := mempool.DefaultConfig(&consensusConfig.Params)
+		mempoolConfig.AllowReplaceByFee = true
+		tcAsConsensus := tc.(externalapi.Consensus)
+		tcAsConsensusPointer := &tcAsConsensus
+		consensusReference := consensusreference.NewConsensusReference(&tcAsConsensusPointer)
+		miningManager := miningFactory.NewMiningManager(consensusReference, &consensusConfig.Params, mempoolConfig)
+
+		transaction, err := createChildAndParentTxsAndAddParentToConsensus(tc)
+		if err != nil {
+			t.Fatalf("Error creating transaction: %+v", err)
+		}
+		_, err = miningManager.ValidateAndInsertTransaction(transaction, false, true)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertTransaction: %v", err)
+		}
+		highPriorityRedeemerTransaction, err := testutils.CreateTransaction(transaction, 1_000_000)
+		if err != nil {
+			t.Fatalf("Error creating highPriorityRedeemerTransaction: %+v", err)
+		}
+		_, err = miningManager.ValidateAndInsertTransaction(highPriorityRedeemerTransaction, true, true)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertTransaction: %v", err)
+		}
+
+		replacement := transaction.Clone()
+		replacement.ID = nil
+		replacement.Mass = 0
+		replacement.Outputs[0].Value -= 2_000_000
+
+		// A low-priority replacement evicting a high-priority redeemer should be rejected, however
+		// high its fee is, and leave the mempool untouched
+		_, err = miningManager.ValidateAndInsertTransaction(replacement, false, true)
+		txRuleError := &mempool.TxRuleError{}
+		if !errors.As(err, txRuleError) || txRuleError.RejectCode != mempool.RejectDuplicate ||
+			!strings.Contains(err.Error(), consensushashing.TransactionID(highPriorityRedeemerTransaction).String()) {
+			t.Fatalf("Unexpected error for a low-priority replacement of a high-priority transaction: %+v", err)
+		}
+		mempoolTransactions := miningManager.AllTransactions()
+		if len(mempoolTransactions) != 2 || !contains(transaction, mempoolTransactions) ||
+			!contains(highPriorityRedeemerTransaction, mempoolTransactions) {
+			t.Fatalf("Expected the rejected replacement to leave the mempool untouched, "+
+				"but got %v", consensushashing.TransactionIDs(mempoolTransactions))
+		}
+
+		// The same replacement should be accepted when it is high-priority itself
+		_, err = miningManager.ValidateAndInsertTransaction(replacement, true, true)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertTransaction: %v", err)
+		}
+		mempoolTransactions = miningManager.AllTransactions()
+		if len(mempoolTransactions) != 1 || !contains(replacement, mempoolTransactions) {
+			t.Fatalf("Expected the replacement transaction to be the only transaction in the mempool, "+
+				"but got %v", consensushashing.TransactionIDs(mempoolTransactions))
+		}
+	})
+}
+
+// TestMempoolMassLimit verifies that once the total mass of the mempool exceeds its limit, the transactions
+// with the lowest fee rates are evicted, and the minimum fee rate required to enter the mempool rises.
+func TestMempoolMassLimit(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		consensusConfig.BlockCoinbaseMaturity = 0
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestMempoolMassLimit")
+		if err != nil {
+			t.Fatalf("Error setting up TestConsensus: %+v", err)
+		}
+		defer teardown(false)
+
+		miningFactory := miningmanager.NewFactory()
+		mempoolConfig := mempool.DefaultConfig(&consensusConfig.Params)
+		tcAsConsensus := tc.(externalapi.Consensus)
+		tcAsConsensusPointer := &tcAsConsensus
+		consensusReference := consensusreference.NewConsensusReference(&tcAsConsensusPointer)
+		miningManager := miningFactory.NewMiningManager(consensusReference, &consensusConfig.Params, mempoolConfig)
+
+		createTransactionWithFee := func(i int, fee uint64) *externalapi.DomainTransaction {
+			transaction := createTransactionWithUTXOEntry(t, i, 0)
+			transaction.Outputs[0].Value = transaction.Inputs[0].UTXOEntry.Amount() - fee
+			return transaction
+		}
+
+		lowFeeTransaction := createTransactionWithFee(0, 100_000)
+		_, err = miningManager.ValidateAndInsertTransaction(lowFeeTransaction, false, true)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertTransaction: %v", err)
+		}
+		mass := miningManager.TotalTransactionMass()
+		if mass != lowFeeTransaction.Mass {
+			t.Fatalf("Expected the mempool mass to be %d, but got %d", lowFeeTransaction.Mass, mass)
+		}
+		minimumFeeRateBeforeEviction := miningManager.MinimumFeeRate()
+
+		// Allow only two transactions in the mempool, so that inserting a third one evicts the lowest paying one
+		mempoolConfig.MaximumTotalTransactionMass = 2 * mass
+		highFeeTransactions := []*externalapi.DomainTransaction{
+			createTransactionWithFee(1, 300_000),
+			createTransactionWithFee(2, 200_000),
+		}
+		for _, transaction := range highFeeTransactions {
+			_, err = miningManager.ValidateAndInsertTransaction(transaction, false, true)
+			if err != nil {
+				t.Fatalf("ValidateAndInsertTransaction: %v", err)
+			}
+		}
+		mempoolTransactions := miningManager.AllTransactions()
+		if len(mempoolTransactions) != len(highFeeTransactions) || contains(lowFeeTransaction, mempoolTransactions) {
+			t.Fatalf("Expected the low fee transaction to be evicted, but the mempool contains %v",
+				consensushashing.TransactionIDs(mempoolTransactions))
+		}
+		if miningManager.TotalTransactionMass() != 2*mass {
+			t.Fatalf("Expected the mempool mass to be %d, but got %d", 2*mass, miningManager.TotalTransactionMass())
+		}
+
+		minimumFeeRate := miningManager.MinimumFeeRate()
+		evictedFeeRate := lowFeeTransaction.Fee * 1000 / lowFeeTransaction.Mass
+		if minimumFeeRate <= evictedFeeRate || minimumFeeRate <= minimumFeeRateBeforeEviction {
+			t.Fatalf("Expected the minimum fee rate to rise above %d, but got %d", evictedFeeRate, minimumFeeRate)
+		}
+
+		// A transaction paying no more than the evicted one should be rejected
+		_, err = miningManager.ValidateAndInsertTransaction(createTransactionWithFee(3, 100_000), false, true)
+		txRuleError := &mempool.TxRuleError{}
+		if !errors.As(err, txRuleError) || txRuleError.RejectCode != mempool.RejectInsufficientFee {
+			t.Fatalf("Unexpected error for a transaction below the minimum fee rate: %+v", err)
+		}
+
+		// A transaction paying above the minimum fee rate, but less than the transactions in the
+		// full mempool, should be rejected, since it would be the one evicted
+		fee := minimumFeeRate*mass/1000 + 1
+		_, err = miningManager.ValidateAndInsertTransaction(createTransactionWithFee(4, fee), false, true)
+		if !errors.As(err, txRuleError) || txRuleError.RejectCode != mempool.RejectInsufficientFee {
+			t.Fatalf("Unexpected error for a transaction evicted from a full mempool: %+v", err)
+		}
+		mempoolTransactions = miningManager.AllTransactions()
+		if len(mempoolTransactions) != len(highFeeTransactions) {
+			t.Fatalf("Expected the mempool to contain only the high fee transactions, but got %v",
+				consensushashing.TransactionIDs(mempoolTransactions))
+		}
+
+		// A replacement of highFeeTransactions[1] that pays enough to replace it, but doubles its mass, doesn't
+		// fit in the mempool unless highFeeTransactions[0] is evicted as well. It should be rejected without
+		// evicting anything, since it pays a lower fee rate than highFeeTransactions[0]
+		mempoolConfig.AllowReplaceByFee = true
+		createReplacement := func(fee uint64) *externalapi.DomainTransaction {
+			replacement := createTransactionWithFee(2, fee)
+			replacement.Mass = 2 * mass
+			return replacement
+		}
+		_, err = miningManager.ValidateAndInsertTransaction(createReplacement(500_000), false, true)
+		if !errors.As(err, txRuleError) || txRuleError.RejectCode != mempool.RejectInsufficientFee {
+			t.Fatalf("Unexpected error for a replacement that doesn't fit in the mempool: %+v", err)
+		}
+		mempoolTransactions = miningManager.AllTransactions()
+		if len(mempoolTransactions) != len(highFeeTransactions) || !contains(highFeeTransactions[0], mempoolTransactions) ||
+			!contains(highFeeTransactions[1], mempoolTransactions) {
+			t.Fatalf("Expected the rejected replacement to leave the mempool untouched, but got %v",
+				consensushashing.TransactionIDs(mempoolTransactions))
+		}
+
+		// A replacement paying a higher fee rate than highFeeTransactions[0] should evict it to make room
+		replacement := createReplacement(700_000)
+		_, err = miningManager.ValidateAndInsertTransaction(replacement, false, true)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertTransaction: %v", err)
+		}
+		mempoolTransactions = miningManager.AllTransactions()
+		if len(mempoolTransactions) != 1 || !contains(replacement, mempoolTransactions) {
+			t.Fatalf("Expected the replacement to be the only transaction in the mempool, but got %v",
+				consensushashing.TransactionIDs(mempoolTransactions))
+		}
+	})
+}
+
+// TestChildPaysForParent verifies that a ready transaction is offered as a block candidate along with
+// the ancestor package of its best-paying descendant, and that the descendant becomes a candidate
+// once its parent is included in a block.
+func TestChildPaysForParent(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		consensusConfig.BlockCoinbaseMaturity = 0
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestChildPaysForParent")
+		if err != nil {
+			t.Fatalf("Error setting up TestConsensus: %+v", err)
+		}
+		defer teardown(false)
+
+		tcAsConsensus := tc.(externalapi.Consensus)
+		tcAsConsensusPointer := &tcAsConsensus
+		consensusReference := consensusreference.NewConsensusReference(&tcAsConsensusPointer)
+		mempoolInstance := mempool.New(mempool.DefaultConfig(&consensusConfig.Params), consensusReference)
+
+		parentTransaction, _, err := createParentAndChildrenTransactions(tc)
+		if err != nil {
+			t.Fatalf("Error creating parentTransaction: %+v", err)
+		}
+		childTransaction, err := testutils.CreateTransaction(parentTransaction, 1_000_000)
+		if err != nil {
+			t.Fatalf("Error creating childTransaction: %+v", err)
+		}
+		for _, transaction := range []*externalapi.DomainTransaction{parentTransaction, childTransaction} {
+			_, err = mempoolInstance.ValidateAndInsertTransaction(transaction, false, false)
+			if err != nil {
+				t.Fatalf("ValidateAndInsertTransaction: %+v", err)
+			}
+		}
+
+		candidates := mempoolInstance.BlockCandidateTransactions()
+		if len(candidates) != 1 || !candidates[0].Transaction.Equal(parentTransaction) {
+			t.Fatalf("Expected parentTransaction to be the only block candidate, but got %d candidates", len(candidates))
+		}
+		expectedPackageFee := parentTransaction.Fee + childTransaction.Fee
+		expectedPackageMass := parentTransaction.Mass + childTransaction.Mass
+		if candidates[0].PackageFee != expectedPackageFee || candidates[0].PackageMass != expectedPackageMass {
+			t.Fatalf("Expected parentTransaction's package to have fee %d and mass %d, but got fee %d and mass %d",
+				expectedPackageFee, expectedPackageMass, candidates[0].PackageFee, candidates[0].PackageMass)
+		}
+
+		_, err = mempoolInstance.HandleNewBlockTransactions([]*externalapi.DomainTransaction{nil, parentTransaction})
+		if err != nil {
+			t.Fatalf("HandleNewBlockTransactions: %+v", err)
+		}
+		candidates = mempoolInstance.BlockCandidateTransactions()
+		if len(candidates) != 1 || !candidates[0].Transaction.Equal(childTransaction) {
+			t.Fatalf("Expected childTransaction to be the only block candidate, but got %d candidates", len(candidates))
+		}
+		if candidates[0].PackageFee != childTransaction.Fee || candidates[0].PackageMass != childTransaction.Mass {
+			t.Fatalf("Expected childTransaction's package to have fee %d and mass %d, but got fee %d and mass %d",
+				childTransaction.Fee, childTransaction.Mass, candidates[0].PackageFee, candidates[0].PackageMass)
+		}
+	})
+}
+
 // TestHandleNewBlockTransactions verifies that all the transactions in the block were successfully removed from the mempool.
+// buildBlockCountingConsensus wraps a consensus, counting the calls to BuildBlock
+type buildBlockCountingConsensus struct {
+	externalapi.Consensus
+	buildBlockCalls int
+}
+
+func (c *buildBlockCountingConsensus) BuildBlock(coinbaseData *externalapi.DomainCoinbaseData,
+	transactions []*externalapi.DomainTransaction) (*externalapi.DomainBlock, error) {
+
+	c.buildBlockCalls++
+	return c.Consensus.BuildBlock(coinbaseData, transactions)
+}
+
+// TestBlockTemplateCache verifies that a block template is built only when the virtual parents,
+// the mempool or the coinbase data change, and is otherwise returned from the cache.
+func TestBlockTemplateCache(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		consensusConfig.BlockCoinbaseMaturity = 0
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestBlockTemplateCache")
+		if err != nil {
+			t.Fatalf("Error setting up TestConsensus: %+v", err)
+		}
+		defer teardown(false)
+
+		countingConsensus := &buildBlockCountingConsensus{Consensus: tc}
+		var countingConsensusAsConsensus externalapi.Consensus = countingConsensus
+		countingConsensusPointer := &countingConsensusAsConsensus
+		consensusReference := consensusreference.NewConsensusReference(&countingConsensusPointer)
+		miningFactory := miningmanager.NewFactory()
+		mempoolConfig := mempool.DefaultConfig(&consensusConfig.Params)
+		clock := mstime.NewMockClock()
+		mempoolConfig.Clock = clock
+		miningManager := miningFactory.NewMiningManager(consensusReference, &consensusConfig.Params, mempoolConfig)
+
+		firstTransaction, err := createChildAndParentTxsAndAddParentToConsensus(tc)
+		if err != nil {
+			t.Fatalf("Error creating transaction: %+v", err)
+		}
+		tips, err := tc.Tips()
+		if err != nil {
+			t.Fatalf("Tips: %+v", err)
+		}
+		blockHash, _, err := tc.AddBlock(tips, nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+		block, err := tc.GetBlock(blockHash)
+		if err != nil {
+			t.Fatalf("GetBlock: %+v", err)
+		}
+		secondTransaction, err := testutils.CreateTransaction(
+			block.Transactions[transactionhelper.CoinbaseTransactionIndex], 1000)
+		if err != nil {
+			t.Fatalf("Error creating transaction: %+v", err)
+		}
+		transactions := []*externalapi.DomainTransaction{firstTransaction, secondTransaction}
+
+		coinbaseData := &externalapi.DomainCoinbaseData{
+			ScriptPublicKey: &externalapi.ScriptPublicKey{Script: nil, Version: 0},
+			ExtraData:       nil,
+		}
+		getBlockTemplate := func(coinbaseData *externalapi.DomainCoinbaseData,
+			expectedBuildBlockCalls int) *externalapi.DomainBlock {
+
+			block, err := miningManager.GetBlockTemplate(coinbaseData)
+			if err != nil {
+				t.Fatalf("GetBlockTemplate: %+v", err)
+			}
+			if countingConsensus.buildBlockCalls != expectedBuildBlockCalls {
+				t.Fatalf("Expected BuildBlock to be called %d times, but it was called %d times",
+					expectedBuildBlockCalls, countingConsensus.buildBlockCalls)
+			}
+			return block
+		}
+		expectTransactions := func(block *externalapi.DomainBlock, transactions ...*externalapi.DomainTransaction) {
+			blockTransactions := block.Transactions[transactionhelper.CoinbaseTransactionIndex+1:]
+			if len(blockTransactions) != len(transactions) {
+				t.Fatalf("Expected %d transactions in the block template, but got %d",
+					len(transactions), len(blockTransactions))
+			}
+			for _, transaction := range transactions {
+				if !contains(transaction, blockTransactions) {
+					t.Fatalf("Missing transaction %s in the block template", consensushashing.TransactionID(transaction))
+				}
+			}
+		}
+
+		// The first call builds the template, and the second one hits the cache
+		firstTemplate := getBlockTemplate(coinbaseData, 1)
+		expectTransactions(firstTemplate)
+		cachedTemplate := getBlockTemplate(coinbaseData, 1)
+		if !consensushashing.BlockHash(cachedTemplate).Equal(consensushashing.BlockHash(firstTemplate)) {
+			t.Fatalf("Expected the cached block template to equal the first one")
+		}
+
+		// A mempool change patches the template
+		for i, transaction := range transactions {
+			_, err = miningManager.ValidateAndInsertTransaction(transaction, false, true)
+			if err != nil {
+				t.Fatalf("ValidateAndInsertTransaction: %+v", err)
+			}
+			template := getBlockTemplate(coinbaseData, 2+i)
+			expectTransactions(template, transactions[:i+1]...)
+		}
+		getBlockTemplate(coinbaseData, 3)
+
+		// A different coinbase reuses the selected transactions, but requires building a block
+		otherCoinbaseData := coinbaseData.Clone()
+		otherCoinbaseData.ExtraData = []byte{1}
+		otherCoinbaseTemplate := getBlockTemplate(otherCoinbaseData, 4)
+		expectTransactions(otherCoinbaseTemplate, transactions...)
+		getBlockTemplate(otherCoinbaseData, 4)
+
+		// A virtual change rebuilds the template
+		newTipHash, _, err := tc.AddBlock(otherCoinbaseTemplate.Header.DirectParents(), nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+		rebuiltTemplate := getBlockTemplate(otherCoinbaseData, 5)
+		if !externalapi.HashesEqual(rebuiltTemplate.Header.DirectParents(), []*externalapi.DomainHash{newTipHash}) {
+			t.Fatalf("Expected the rebuilt block template to point at the new tip %s, but got %v",
+				newTipHash, rebuiltTemplate.Header.DirectParents())
+		}
+		expectTransactions(rebuiltTemplate, transactions...)
+
+		// The removal of a selected transaction from the mempool rebuilds the template without it
+		_, err = miningManager.HandleNewBlockTransactions([]*externalapi.DomainTransaction{
+			rebuiltTemplate.Transactions[transactionhelper.CoinbaseTransactionIndex], firstTransaction})
+		if err != nil {
+			t.Fatalf("HandleNewBlockTransactions: %+v", err)
+		}
+		templateAfterRemoval := getBlockTemplate(otherCoinbaseData, 6)
+		expectTransactions(templateAfterRemoval, secondTransaction)
+		getBlockTemplate(otherCoinbaseData, 6)
+
+		// A template whose transactions were selected too long ago is rebuilt even though nothing changed
+		clock.SetMockTime(mstime.Now().Add(time.Minute))
+		expiredTemplate := getBlockTemplate(otherCoinbaseData, 7)
+		expectTransactions(expiredTemplate, secondTransaction)
+		getBlockTemplate(otherCoinbaseData, 7)
+	})
+}
+
+// TestNonNativeSubnetworkTransactions verifies that transactions of registered subnetworks are accepted to the
+// mempool and mined alongside native transactions, while respecting the gas limits of their subnetworks.
+func TestNonNativeSubnetworkTransactions(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		consensusConfig.BlockCoinbaseMaturity = 0
+		consensusConfig.EnableNonNativeSubnetworks = true
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestNonNativeSubnetworkTransactions")
+		if err != nil {
+			t.Fatalf("Error setting up TestConsensus: %+v", err)
+		}
+		defer teardown(false)
+
+		miningFactory := miningmanager.NewFactory()
+		tcAsConsensus := tc.(externalapi.Consensus)
+		tcAsConsensusPointer := &tcAsConsensus
+		consensusReference := consensusreference.NewConsensusReference(&tcAsConsensusPointer)
+		miningManager := miningFactory.NewMiningManager(consensusReference, &consensusConfig.Params,
+			mempool.DefaultConfig(&consensusConfig.Params))
+
+		// Build a chain whose coinbase transactions fund the test transactions
+		const fundingTransactionCount = 4
+		fundingTransactions := make([]*externalapi.DomainTransaction, 0, fundingTransactionCount)
+		tipHash := consensusConfig.GenesisHash
+		for len(fundingTransactions) < fundingTransactionCount {
+			tipHash, _, err = tc.AddBlock([]*externalapi.DomainHash{tipHash}, nil, nil)
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+			block, err := tc.GetBlock(tipHash)
+			if err != nil {
+				t.Fatalf("GetBlock: %+v", err)
+			}
+			coinbaseTransaction := block.Transactions[transactionhelper.CoinbaseTransactionIndex]
+			if len(coinbaseTransaction.Outputs) > 0 {
+				fundingTransactions = append(fundingTransactions, coinbaseTransaction)
+			}
+		}
+		createTransaction := func(fundingTransaction *externalapi.DomainTransaction,
+			subnetworkID externalapi.DomainSubnetworkID, gas uint64, payload []byte) *externalapi.DomainTransaction {
+
+			transaction, err := testutils.CreateTransaction(fundingTransaction, 1000)
+			if err != nil {
+				t.Fatalf("CreateTransaction: %+v", err)
+			}
+			transaction.SubnetworkID = subnetworkID
+			transaction.Gas = gas
+			transaction.Payload = payload
+			return transaction
+		}
+
+		// Register a subnetwork. Its registry transaction is accepted once the block
+		// containing it has a chain child
+		const gasLimit = 1000
+		registryTransaction := createTransaction(fundingTransactions[0], subnetworks.SubnetworkIDRegistry,
+			0, subnetworks.RegistryPayload(gasLimit))
+		subnetworkID := subnetworks.FromRegistryTransactionID(consensushashing.TransactionID(registryTransaction))
+		tipHash, _, err = tc.AddBlock([]*externalapi.DomainHash{tipHash}, nil,
+			[]*externalapi.DomainTransaction{registryTransaction})
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+		_, _, err = tc.AddBlock([]*externalapi.DomainHash{tipHash}, nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+
+		// Transactions of unregistered subnetworks, or that exceed the gas limit of their subnetwork, are rejected
+		unregisteredSubnetworkID := externalapi.DomainSubnetworkID{0x42}
+		invalidTransactions := []*externalapi.DomainTransaction{
+			createTransaction(fundingTransactions[1], unregisteredSubnetworkID, 1, []byte{1}),
+			createTransaction(fundingTransactions[1], subnetworkID, gasLimit+1, []byte{1}),
+		}
+		for _, transaction := range invalidTransactions {
+			_, err = miningManager.ValidateAndInsertTransaction(transaction, false, true)
+			txRuleError := &mempool.TxRuleError{}
+			if !errors.As(err, txRuleError) || txRuleError.RejectCode != mempool.RejectInvalid {
+				t.Fatalf("Unexpected error for a transaction with invalid gas: %+v", err)
+			}
+		}
+
+		// The two subnetwork transactions together exceed the gas limit, so only one of them can be mined
+		nativeTransaction := createTransaction(fundingTransactions[1], subnetworks.SubnetworkIDNative, 0, nil)
+		subnetworkTransactions := []*externalapi.DomainTransaction{
+			createTransaction(fundingTransactions[2], subnetworkID, gasLimit/2+1, []byte{1}),
+			createTransaction(fundingTransactions[3], subnetworkID, gasLimit/2+1, []byte{2}),
+		}
+		for _, transaction := range append([]*externalapi.DomainTransaction{nativeTransaction}, subnetworkTransactions...) {
+			_, err = miningManager.ValidateAndInsertTransaction(transaction, false, true)
+			if err != nil {
+				t.Fatalf("ValidateAndInsertTransaction: %+v", err)
+			}
+		}
+
+		block, err := miningManager.GetBlockTemplate(&externalapi.DomainCoinbaseData{
+			ScriptPublicKey: &externalapi.ScriptPublicKey{Script: nil, Version: 0},
+			ExtraData:       nil})
+		if err != nil {
+			t.Fatalf("GetBlockTemplate: %+v", err)
+		}
+		blockTransactions := block.Transactions[transactionhelper.CoinbaseTransactionIndex+1:]
+		if len(blockTransactions) != 2 {
+			t.Fatalf("Expected 2 transactions in the block template, but got %d", len(blockTransactions))
+		}
+		if !contains(nativeTransaction, blockTransactions) {
+			t.Fatalf("Missing the native transaction in the block template")
+		}
+		if !contains(subnetworkTransactions[0], blockTransactions) && !contains(subnetworkTransactions[1], blockTransactions) {
+			t.Fatalf("Missing a subnetwork transaction in the block template")
+		}
+
+		_, err = tc.ValidateAndInsertBlock(clearUTXOEntries(block), true)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertBlock: %+v", err)
+		}
+		_, err = miningManager.HandleNewBlockTransactions(block.Transactions)
+		if err != nil {
+			t.Fatalf("HandleNewBlockTransactions: %+v", err)
+		}
+
+		// The remaining subnetwork transaction is mined in the next block
+		block, err = miningManager.GetBlockTemplate(&externalapi.DomainCoinbaseData{
+			ScriptPublicKey: &externalapi.ScriptPublicKey{Script: nil, Version: 0},
+			ExtraData:       nil})
+		if err != nil {
+			t.Fatalf("GetBlockTemplate: %+v", err)
+		}
+		if len(block.Transactions) != 2 {
+			t.Fatalf("Expected the remaining subnetwork transaction in the block template, but got %d transactions",
+				len(block.Transactions)-1)
+		}
+		_, err = tc.ValidateAndInsertBlock(clearUTXOEntries(block), true)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertBlock: %+v", err)
+		}
+	})
+}
+
+// clearUTXOEntries returns a clone of the given block template without the UTXO entries populated by
+// the mempool, the same way it would arrive to consensus after being submitted by a miner
+func clearUTXOEntries(block *externalapi.DomainBlock) *externalapi.DomainBlock {
+	block = block.Clone()
+	for _, transaction := range block.Transactions {
+		for _, input := range transaction.Inputs {
+			input.UTXOEntry = nil
+		}
+	}
+	return block
+}
+
 func TestHandleNewBlockTransactions(t *testing.T) {
 	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
 		consensusConfig.BlockCoinbaseMaturity = 0
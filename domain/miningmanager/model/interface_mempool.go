@@ -8,13 +8,25 @@ import (
 // are intended to be mined into new blocks
 type Mempool interface {
 	HandleNewBlockTransactions(txs []*externalapi.DomainTransaction) ([]*externalapi.DomainTransaction, error)
-	BlockCandidateTransactions() []*externalapi.DomainTransaction
+	BlockCandidateTransactions() []*BlockCandidateTransaction
 	ValidateAndInsertTransaction(transaction *externalapi.DomainTransaction, isHighPriority bool, allowOrphan bool) (
 		acceptedTransactions []*externalapi.DomainTransaction, err error)
 	RemoveTransactions(txs []*externalapi.DomainTransaction, removeRedeemers bool) error
 	GetTransaction(transactionID *externalapi.DomainTransactionID) (*externalapi.DomainTransaction, bool)
 	AllTransactions() []*externalapi.DomainTransaction
 	TransactionCount() int
+	TransactionsVersion() uint64
+	TotalTransactionMass() uint64
+	MinimumFeeRate() uint64
 	RevalidateHighPriorityTransactions() (validTransactions []*externalapi.DomainTransaction, err error)
 	IsTransactionOutputDust(output *externalapi.DomainTransactionOutput) bool
 }
+
+// BlockCandidateTransaction is a transaction that is ready to be included in a block template,
+// along with the fee and mass of the best-paying transaction package that including it helps to mine.
+// That is - the ancestor package of either the transaction itself or of one of its descendants.
+type BlockCandidateTransaction struct {
+	Transaction *externalapi.DomainTransaction
+	PackageFee  uint64
+	PackageMass uint64
+}
@@ -0,0 +1,123 @@
+package utxoindex
+
+import (
+	"fmt"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+)
+
+// CheckIntegrity makes sure that the UTXO index stored in the given database matches the
+// virtual UTXO set of the given consensus, and returns the issues it finds. If repair is
+// true and any issues are found, the UTXO index is rebuilt from the virtual UTXO set.
+//
+// It returns no issues if the database doesn't have a UTXO index.
+//
+// NOTE: While this is called no new blocks can be added to the consensus.
+func CheckIntegrity(consensus externalapi.Consensus, database database.Database, repair bool) (
+	[]*externalapi.IntegrityIssue, error) {
+
+	store := newUTXOIndexStore(database)
+	issues, err := checkIntegrity(consensus, store)
+	if err != nil {
+		return nil, err
+	}
+
+	if repair && len(issues) > 0 {
+		log.Infof("Rebuilding the UTXO index")
+		utxoIndex := &UTXOIndex{
+			consensus: consensus,
+			store:     store,
+		}
+		err := utxoIndex.Reset()
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			issue.Repaired = true
+		}
+	}
+
+	return issues, nil
+}
+
+func checkIntegrity(consensus externalapi.Consensus, store *utxoIndexStore) ([]*externalapi.IntegrityIssue, error) {
+	var issues []*externalapi.IntegrityIssue
+	addIssue := func(description string, args ...interface{}) {
+		issues = append(issues, &externalapi.IntegrityIssue{
+			Check:       externalapi.IntegrityCheckUTXOIndex,
+			Description: fmt.Sprintf(description, args...),
+		})
+	}
+
+	indexEntryCount, err := store.count()
+	if err != nil {
+		return nil, err
+	}
+
+	virtualInfo, err := consensus.GetVirtualInfo()
+	if err != nil {
+		return nil, err
+	}
+	utxoIndexVirtualParents, err := store.getVirtualParents()
+	if err != nil {
+		if !database.IsNotFoundError(err) {
+			return nil, err
+		}
+		if indexEntryCount == 0 {
+			// The node doesn't use a UTXO index
+			return nil, nil
+		}
+		addIssue("the UTXO index has entries but no virtual parents, so it was interrupted while being reset")
+	} else if !externalapi.HashesEqual(utxoIndexVirtualParents, virtualInfo.ParentHashes) {
+		addIssue("the UTXO index was last updated with virtual parents %s, but the virtual parents are %s",
+			utxoIndexVirtualParents, virtualInfo.ParentHashes)
+	}
+
+	virtualUTXOCount := uint64(0)
+	missingCount := uint64(0)
+	mismatchCount := uint64(0)
+	var fromOutpoint *externalapi.DomainOutpoint
+	for {
+		const step = 1000
+		virtualUTXOs, err := consensus.GetVirtualUTXOs(virtualInfo.ParentHashes, fromOutpoint, step)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, virtualUTXO := range virtualUTXOs {
+			virtualUTXOCount++
+			indexEntry, err := store.get(virtualUTXO.UTXOEntry.ScriptPublicKey(), virtualUTXO.Outpoint)
+			if err != nil {
+				if database.IsNotFoundError(err) {
+					missingCount++
+					continue
+				}
+				return nil, err
+			}
+			if !indexEntry.Equal(virtualUTXO.UTXOEntry) {
+				mismatchCount++
+			}
+		}
+
+		if len(virtualUTXOs) < step {
+			break
+		}
+		fromOutpoint = virtualUTXOs[len(virtualUTXOs)-1].Outpoint
+	}
+
+	if missingCount > 0 {
+		addIssue("%d of the %d UTXOs in the virtual UTXO set are missing from the UTXO index",
+			missingCount, virtualUTXOCount)
+	}
+	if mismatchCount > 0 {
+		addIssue("%d UTXOs in the UTXO index are different from their entries in the virtual UTXO set",
+			mismatchCount)
+	}
+	foundEntryCount := virtualUTXOCount - missingCount
+	if indexEntryCount > foundEntryCount {
+		addIssue("the UTXO index has %d UTXOs that are not in the virtual UTXO set",
+			indexEntryCount-foundEntryCount)
+	}
+
+	return issues, nil
+}
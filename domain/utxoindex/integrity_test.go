@@ -0,0 +1,123 @@
+package utxoindex
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/testutils"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/memdb"
+)
+
+func TestCheckIntegrity(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestCheckIntegrity")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		tipHash := consensusConfig.GenesisHash
+		for i := 0; i < 5; i++ {
+			tipHash, _, err = tc.AddBlock([]*externalapi.DomainHash{tipHash}, nil, nil)
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+		}
+
+		db := memdb.NewMemDB()
+		defer db.Close()
+
+		// A database without a UTXO index has nothing to check
+		issues, err := CheckIntegrity(tc, db, false)
+		if err != nil {
+			t.Fatalf("CheckIntegrity: %+v", err)
+		}
+		if len(issues) != 0 {
+			t.Fatalf("Expected no issues without a UTXO index, but got %d", len(issues))
+		}
+
+		utxoIndex, err := New(tc, db)
+		if err != nil {
+			t.Fatalf("New: %+v", err)
+		}
+		issues, err = CheckIntegrity(tc, db, false)
+		if err != nil {
+			t.Fatalf("CheckIntegrity: %+v", err)
+		}
+		if len(issues) != 0 {
+			t.Fatalf("Expected no issues in a synced UTXO index, but got %d. The first: %s",
+				len(issues), issues[0].Description)
+		}
+
+		virtualInfo, err := tc.GetVirtualInfo()
+		if err != nil {
+			t.Fatalf("GetVirtualInfo: %+v", err)
+		}
+		virtualUTXOs, err := tc.GetVirtualUTXOs(virtualInfo.ParentHashes, nil, 1)
+		if err != nil {
+			t.Fatalf("GetVirtualUTXOs: %+v", err)
+		}
+		if len(virtualUTXOs) == 0 {
+			t.Fatalf("Expected the virtual UTXO set to have UTXOs")
+		}
+
+		// Remove a UTXO from the index and add one that isn't in the virtual UTXO set
+		store := utxoIndex.store
+		missingUTXO := virtualUTXOs[0]
+		key, err := store.convertOutpointToKey(
+			store.bucketForScriptPublicKey(missingUTXO.UTXOEntry.ScriptPublicKey()), missingUTXO.Outpoint)
+		if err != nil {
+			t.Fatalf("convertOutpointToKey: %+v", err)
+		}
+		err = db.Delete(key)
+		if err != nil {
+			t.Fatalf("Delete: %+v", err)
+		}
+		extraOutpoint := &externalapi.DomainOutpoint{TransactionID: *externalapi.NewDomainTransactionIDFromByteArray(tipHash.ByteArray()), Index: 0}
+		extraEntry := utxo.NewUTXOEntry(1, missingUTXO.UTXOEntry.ScriptPublicKey(), false, 0)
+		err = store.addAndCommitOutpointsWithoutTransaction([]*externalapi.OutpointAndUTXOEntryPair{
+			{Outpoint: extraOutpoint, UTXOEntry: extraEntry},
+		})
+		if err != nil {
+			t.Fatalf("addAndCommitOutpointsWithoutTransaction: %+v", err)
+		}
+
+		issues, err = CheckIntegrity(tc, db, false)
+		if err != nil {
+			t.Fatalf("CheckIntegrity: %+v", err)
+		}
+		if len(issues) != 2 {
+			t.Fatalf("Expected the missing and the extra UTXOs to be reported, but got %d issues", len(issues))
+		}
+		for _, issue := range issues {
+			if issue.Repaired {
+				t.Fatalf("Expected issues to not be repaired without repair")
+			}
+		}
+
+		issues, err = CheckIntegrity(tc, db, true)
+		if err != nil {
+			t.Fatalf("CheckIntegrity: %+v", err)
+		}
+		if len(issues) != 2 {
+			t.Fatalf("Expected 2 issues to be reported when repairing, but got %d", len(issues))
+		}
+		for _, issue := range issues {
+			if !issue.Repaired {
+				t.Fatalf("Expected all issues to be repaired")
+			}
+		}
+
+		issues, err = CheckIntegrity(tc, db, false)
+		if err != nil {
+			t.Fatalf("CheckIntegrity: %+v", err)
+		}
+		if len(issues) != 0 {
+			t.Fatalf("Expected no issues after repairing, but got %d. The first: %s",
+				len(issues), issues[0].Description)
+		}
+	})
+}
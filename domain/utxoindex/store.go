@@ -273,6 +273,38 @@ func (uis *utxoIndexStore) getUTXOOutpointEntryPairs(scriptPublicKey *externalap
 	return utxoOutpointEntryPairs, nil
 }
 
+func (uis *utxoIndexStore) get(scriptPublicKey *externalapi.ScriptPublicKey,
+	outpoint *externalapi.DomainOutpoint) (externalapi.UTXOEntry, error) {
+
+	if uis.isAnythingStaged() {
+		return nil, errors.Errorf("cannot get a utxo entry while staging isn't empty")
+	}
+
+	key, err := uis.convertOutpointToKey(uis.bucketForScriptPublicKey(scriptPublicKey), outpoint)
+	if err != nil {
+		return nil, err
+	}
+	serializedUTXOEntry, err := uis.database.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return deserializeUTXOEntry(serializedUTXOEntry)
+}
+
+func (uis *utxoIndexStore) count() (uint64, error) {
+	cursor, err := uis.database.Cursor(utxoIndexBucket)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close()
+
+	count := uint64(0)
+	for cursor.Next() {
+		count++
+	}
+	return count, nil
+}
+
 func (uis *utxoIndexStore) getVirtualParents() ([]*externalapi.DomainHash, error) {
 	if uis.isAnythingStaged() {
 		return nil, errors.Errorf("cannot get the virtual parents while staging isn't empty")
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/pkg/errors"
+)
+
+// addCheckpoints adds the checkpoints given by --addcheckpoint to a copy of the active
+// network params, so that the built-in params remain unchanged
+func (cfg *Config) addCheckpoints() error {
+	params := *cfg.ActiveNetParams
+	checkpoints := make([]externalapi.Checkpoint, 0, len(params.Checkpoints)+len(cfg.AddCheckpoints))
+	checkpoints = append(checkpoints, params.Checkpoints...)
+	for _, checkpointString := range cfg.AddCheckpoints {
+		checkpoint, err := parseCheckpoint(checkpointString)
+		if err != nil {
+			return err
+		}
+		for _, existing := range checkpoints {
+			if existing.BlueScore == checkpoint.BlueScore && !existing.Hash.Equal(checkpoint.Hash) {
+				return errors.Errorf("checkpoint %s conflicts with checkpoint %s at blue score %d",
+					checkpoint.Hash, existing.Hash, checkpoint.BlueScore)
+			}
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+	sort.Slice(checkpoints, func(i, j int) bool {
+		return checkpoints[i].BlueScore < checkpoints[j].BlueScore
+	})
+
+	params.Checkpoints = checkpoints
+	cfg.ActiveNetParams = &params
+	return nil
+}
+
+// parseCheckpoint parses a checkpoint in the format '<blue score>:<hash>'
+func parseCheckpoint(checkpointString string) (externalapi.Checkpoint, error) {
+	parts := strings.Split(checkpointString, ":")
+	if len(parts) != 2 {
+		return externalapi.Checkpoint{}, errors.Errorf("invalid checkpoint %s: the format is '<blue score>:<hash>'",
+			checkpointString)
+	}
+	blueScore, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return externalapi.Checkpoint{}, errors.Errorf("invalid checkpoint %s: malformed blue score", checkpointString)
+	}
+	hash, err := externalapi.NewDomainHashFromString(parts[1])
+	if err != nil {
+		return externalapi.Checkpoint{}, errors.Errorf("invalid checkpoint %s: malformed hash", checkpointString)
+	}
+	return externalapi.Checkpoint{BlueScore: blueScore, Hash: hash}, nil
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+)
+
+func TestAddCheckpoints(t *testing.T) {
+	const hash1 = "0000000000000000000000000000000000000000000000000000000000000001"
+	const hash2 = "0000000000000000000000000000000000000000000000000000000000000002"
+
+	cfg := DefaultConfig()
+	cfg.ActiveNetParams = &dagconfig.DevnetParams
+	cfg.AddCheckpoints = []string{"200:" + hash2, "100:" + hash1}
+	err := cfg.addCheckpoints()
+	if err != nil {
+		t.Fatalf("addCheckpoints: %+v", err)
+	}
+	checkpoints := cfg.ActiveNetParams.Checkpoints
+	if len(checkpoints) != 2 || checkpoints[0].BlueScore != 100 || checkpoints[1].BlueScore != 200 {
+		t.Fatalf("Unexpected checkpoints %v", checkpoints)
+	}
+	if checkpoints[0].Hash.String() != hash1 {
+		t.Fatalf("Unexpected checkpoint hash %s", checkpoints[0].Hash)
+	}
+	if len(dagconfig.DevnetParams.Checkpoints) != 0 {
+		t.Fatalf("Adding checkpoints changed the built-in devnet params")
+	}
+
+	invalidCheckpoints := [][]string{
+		{"100"},
+		{"score:" + hash1},
+		{"100:hash"},
+		{"100:" + hash1, "100:" + hash2},
+	}
+	for _, addCheckpoints := range invalidCheckpoints {
+		cfg := DefaultConfig()
+		cfg.ActiveNetParams = &dagconfig.DevnetParams
+		cfg.AddCheckpoints = addCheckpoints
+		err := cfg.addCheckpoints()
+		if err == nil {
+			t.Errorf("Expected an error for checkpoints %v", addCheckpoints)
+		}
+	}
+}
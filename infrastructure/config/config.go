@@ -46,7 +46,11 @@ const (
 	blockMaxMassMin              = 1000
 	blockMaxMassMax              = 10000000
 	defaultMinRelayTxFee         = 1e-5 // 1 sompi per byte
+	defaultRBFMinFeeIncrement    = 1e-5 // 1 sompi per byte
+	defaultMaxRBFEvictions       = 100
+	defaultDbType                = "ldb"
 	defaultMaxOrphanTransactions = 100
+	defaultMaxMempoolMass        = 500_000_000
 	//DefaultMaxOrphanTxSize is the default maximum size for an orphan transaction
 	DefaultMaxOrphanTxSize  = 100000
 	defaultSigCacheMaxSize  = 100000
@@ -103,12 +107,13 @@ type Flags struct {
 	Proxy                           string        `long:"proxy" description:"Connect via SOCKS5 proxy (eg. 127.0.0.1:9050)"`
 	ProxyUser                       string        `long:"proxyuser" description:"Username for proxy server"`
 	ProxyPass                       string        `long:"proxypass" default-mask:"-" description:"Password for proxy server"`
-	DbType                          string        `long:"dbtype" description:"Database backend to use for the Block DAG"`
+	DbType                          string        `long:"dbtype" description:"Database backend to use for the Block DAG {ldb, bolt, memdb} -- memdb keeps all data in memory, so it is lost on shutdown"`
 	Profile                         string        `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
 	LogLevel                        string        `short:"d" long:"loglevel" description:"Logging level for all subsystems {trace, debug, info, warn, error, critical} -- You may also specify <subsystem>=<level>,<subsystem2>=<level>,... to set the log level for individual subsystems -- Use show to list available subsystems"`
 	Upnp                            bool          `long:"upnp" description:"Use UPnP to map our listening port outside of NAT"`
 	MinRelayTxFee                   float64       `long:"minrelaytxfee" description:"The minimum transaction fee in KAS/kB to be considered a non-zero fee."`
 	MaxOrphanTxs                    uint64        `long:"maxorphantx" description:"Max number of orphan transactions to keep in memory"`
+	MaxMempoolMass                  uint64        `long:"maxmempoolmass" description:"Max total mass of the transactions in the mempool. Once exceeded, the transactions with the lowest fee rates are evicted"`
 	BlockMaxMass                    uint64        `long:"blockmaxmass" description:"Maximum transaction mass to be used when creating a block"`
 	UserAgentComments               []string      `long:"uacomment" description:"Comment to add to the user agent -- See BIP 14 for more information."`
 	NoPeerBloomFilters              bool          `long:"nopeerbloomfilters" description:"Disable bloom filtering support"`
@@ -116,10 +121,24 @@ type Flags struct {
 	BlocksOnly                      bool          `long:"blocksonly" description:"Do not accept transactions from remote peers."`
 	RelayNonStd                     bool          `long:"relaynonstd" description:"Relay non-standard transactions regardless of the default settings for the active network."`
 	RejectNonStd                    bool          `long:"rejectnonstd" description:"Reject non-standard transactions regardless of the default settings for the active network."`
+	AllowRBF                        bool          `long:"allow-rbf" description:"Allow transactions in the mempool to be replaced by conflicting transactions that pay a higher fee (replace-by-fee)"`
+	RBFMinFeeIncrement              float64       `long:"rbfminfeeincrement" description:"The minimum amount in KAS/kB by which a replacement transaction's fee rate must exceed the fee rates of the transactions it replaces"`
+	MaxRBFEvictions                 uint64        `long:"maxrbfevictions" description:"Max number of transactions a single replacement transaction may evict from the mempool"`
 	ResetDatabase                   bool          `long:"reset-db" description:"Reset database before starting node. It's needed when switching between subnetworks."`
+	BackupTo                        string        `long:"backup-to" description:"Write a verified backup of the database into the given directory, which must be empty or not exist, and exit"`
+	EnableBackupRPC                 bool          `long:"enablebackuprpc" description:"Allow RPC clients to back up the database with BackupDatabase. Requires --backupdir"`
+	BackupDir                       string        `long:"backupdir" description:"Directory that backups requested with the BackupDatabase RPC are written into. Backups can't be written anywhere else"`
+	VerifyDatabase                  bool          `long:"verify-db" description:"Check the integrity of the database without writing into it, and exit"`
+	RepairDatabase                  bool          `long:"repair-db" description:"Check the integrity of the database, repair the issues that can be repaired, and exit. Only the UTXO index can be repaired"`
+	VerifyDatabaseReport            string        `long:"verify-db-report" description:"Write the report of --verify-db or --repair-db as JSON into the given file"`
+	ExportSnapshot                  string        `long:"export-snapshot" description:"Write a snapshot of the pruning point state, including its UTXO set, into the given file and exit"`
+	ImportSnapshot                  string        `long:"import-snapshot" description:"Bootstrap the node from a snapshot that was written with --export-snapshot. The snapshot is fully validated against the UTXO commitment of its pruning point"`
+	MigrateDatabaseDryRun           bool          `long:"migrate-db-dry-run" description:"Print the migrations the database needs to reach the current version, try the first of them without committing anything, and exit"`
 	MaxUTXOCacheSize                uint64        `long:"maxutxocachesize" description:"Max size of loaded UTXO into ram from the disk in bytes"`
 	UTXOIndex                       bool          `long:"utxoindex" description:"Enable the UTXO index"`
 	IsArchivalNode                  bool          `long:"archival" description:"Run as an archival node: don't delete old block data when moving the pruning point (Warning: heavy disk usage)'"`
+	HeadersOnly                     bool          `long:"headers-only" description:"Run as a light node that syncs and keeps only block headers, without block bodies or the UTXO set. Methods that need transactions or the UTXO set are unavailable over RPC"`
+	AddCheckpoints                  []string      `long:"addcheckpoint" description:"Add a custom checkpoint. Format: '<blue score>:<hash>'"`
 	AllowSubmitBlockWhenNotSynced   bool          `long:"allow-submit-block-when-not-synced" hidden:"true" description:"Allow the node to accept blocks from RPC while not synced (this flag is mainly used for testing)"`
 	EnableSanityCheckPruningUTXOSet bool          `long:"enable-sanity-check-pruning-utxo" hidden:"true" description:"When moving the pruning point - check that the utxo set matches the utxo commitment"`
 	NetworkFlags
@@ -131,12 +150,13 @@ type Flags struct {
 // See loadConfig for details on the configuration load process.
 type Config struct {
 	*Flags
-	Lookup        func(string) ([]net.IP, error)
-	Dial          func(string, string, time.Duration) (net.Conn, error)
-	MiningAddrs   []util.Address
-	MinRelayTxFee util.Amount
-	Whitelists    []*net.IPNet
-	SubnetworkID  *externalapi.DomainSubnetworkID // nil in full nodes
+	Lookup             func(string) ([]net.IP, error)
+	Dial               func(string, string, time.Duration) (net.Conn, error)
+	MiningAddrs        []util.Address
+	MinRelayTxFee      util.Amount
+	RBFMinFeeIncrement util.Amount
+	Whitelists         []*net.IPNet
+	SubnetworkID       *externalapi.DomainSubnetworkID // nil in full nodes
 }
 
 // ServiceOptions defines the configuration options for the daemon as a service on
@@ -184,8 +204,12 @@ func defaultFlags() *Flags {
 		RPCCert:              defaultRPCCertFile,
 		BlockMaxMass:         defaultBlockMaxMass,
 		MaxOrphanTxs:         defaultMaxOrphanTransactions,
+		MaxMempoolMass:       defaultMaxMempoolMass,
 		SigCacheMaxSize:      defaultSigCacheMaxSize,
 		MinRelayTxFee:        defaultMinRelayTxFee,
+		RBFMinFeeIncrement:   defaultRBFMinFeeIncrement,
+		MaxRBFEvictions:      defaultMaxRBFEvictions,
+		DbType:               defaultDbType,
 		MaxUTXOCacheSize:     defaultMaxUTXOCacheSize,
 		ServiceOptions:       &ServiceOptions{},
 	}
@@ -315,6 +339,17 @@ func LoadConfig() (*Config, error) {
 	}
 	cfg.RelayNonStd = relayNonStd
 
+	// Add the checkpoints given by the user to the checkpoints of the active network
+	if len(cfg.AddCheckpoints) > 0 {
+		err := cfg.addCheckpoints()
+		if err != nil {
+			err := errors.Errorf("%s: %s", funcName, err.Error())
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, err
+		}
+	}
+
 	cfg.AppDir = cleanAndExpandPath(cfg.AppDir)
 	// Append the network type to the app directory so it is "namespaced"
 	// per network.
@@ -329,6 +364,61 @@ func LoadConfig() (*Config, error) {
 	}
 	cfg.LogDir = cleanAndExpandPath(cfg.LogDir)
 
+	if cfg.BackupTo != "" {
+		cfg.BackupTo = cleanAndExpandPath(cfg.BackupTo)
+	}
+
+	// --enablebackuprpc lets RPC clients write into the file system of the node,
+	// so it's only allowed together with a directory to confine the backups to
+	if cfg.EnableBackupRPC && cfg.BackupDir == "" {
+		str := "%s: the --enablebackuprpc option requires --backupdir"
+		err := errors.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+	if cfg.BackupDir != "" {
+		cfg.BackupDir = cleanAndExpandPath(cfg.BackupDir)
+	}
+
+	// --repair-db and --verify-db-report imply --verify-db
+	if cfg.RepairDatabase || cfg.VerifyDatabaseReport != "" {
+		cfg.VerifyDatabase = true
+	}
+	if cfg.VerifyDatabaseReport != "" {
+		cfg.VerifyDatabaseReport = cleanAndExpandPath(cfg.VerifyDatabaseReport)
+	}
+	if cfg.ExportSnapshot != "" {
+		cfg.ExportSnapshot = cleanAndExpandPath(cfg.ExportSnapshot)
+	}
+	if cfg.ImportSnapshot != "" {
+		cfg.ImportSnapshot = cleanAndExpandPath(cfg.ImportSnapshot)
+	}
+	exitingModeCount := 0
+	for _, isSet := range []bool{cfg.BackupTo != "", cfg.VerifyDatabase, cfg.ExportSnapshot != "",
+		cfg.MigrateDatabaseDryRun} {
+		if isSet {
+			exitingModeCount++
+		}
+	}
+	if exitingModeCount > 1 {
+		str := "%s: backup-to, verify-db, export-snapshot and migrate-db-dry-run cannot be used together -- " +
+			"choose only one"
+		err := errors.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	// --headers-only nodes don't have the UTXO set to index
+	if cfg.HeadersOnly && cfg.UTXOIndex {
+		str := "%s: the --headers-only and --utxoindex options can not be used together"
+		err := errors.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
 	// Special show command to list supported subsystems and exit.
 	if cfg.LogLevel == "show" {
 		fmt.Println("Supported subsystems", logger.SupportedSubsystems())
@@ -453,7 +543,7 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
-	// Validate the the minrelaytxfee.
+	// Validate the minrelaytxfee.
 	cfg.MinRelayTxFee, err = util.NewAmount(cfg.Flags.MinRelayTxFee)
 	if err != nil {
 		str := "%s: invalid minrelaytxfee: %s"
@@ -472,6 +562,25 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
+	// Validate the rbfminfeeincrement.
+	cfg.RBFMinFeeIncrement, err = util.NewAmount(cfg.Flags.RBFMinFeeIncrement)
+	if err != nil {
+		str := "%s: invalid rbfminfeeincrement: %s"
+		err := errors.Errorf(str, funcName, err)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	// Disallow negative replace-by-fee fee increments.
+	if cfg.RBFMinFeeIncrement < 0 {
+		str := "%s: The rbfminfeeincrement option may not be less than 0 -- parsed [%d]"
+		err := errors.Errorf(str, funcName, cfg.RBFMinFeeIncrement)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
 	// Limit the max block mass to a sane value.
 	if cfg.BlockMaxMass < blockMaxMassMin || cfg.BlockMaxMass >
 		blockMaxMassMax {
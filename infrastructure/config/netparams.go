@@ -0,0 +1,207 @@
+package config
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/kaspanet/kaspad/util"
+	"github.com/kaspanet/kaspad/util/difficulty"
+	"github.com/pkg/errors"
+)
+
+// builtInNetParams are the networks a custom network may be based on
+var builtInNetParams = map[string]*dagconfig.Params{
+	"mainnet": &dagconfig.MainnetParams,
+	"testnet": &dagconfig.TestnetParams,
+	"simnet":  &dagconfig.SimnetParams,
+	"devnet":  &dagconfig.DevnetParams,
+}
+
+// customNetParamsConfig is the definition of a custom network, as given by --netparams.
+// The network starts from the params of the network named in Base (devnet by default), and
+// overrides them with all the fields that are set in the definition. Its genesis block is
+// derived from the Genesis field.
+type customNetParamsConfig struct {
+	Base        string   `json:"base" yaml:"base"`
+	Name        string   `json:"name" yaml:"name"`
+	Net         uint32   `json:"net" yaml:"net"`
+	RPCPort     string   `json:"rpcPort" yaml:"rpcPort"`
+	DefaultPort string   `json:"defaultPort" yaml:"defaultPort"`
+	DNSSeeds    []string `json:"dnsSeeds" yaml:"dnsSeeds"`
+	GRPCSeeds   []string `json:"grpcSeeds" yaml:"grpcSeeds"`
+	Prefix      *string  `json:"prefix" yaml:"prefix"`
+
+	Genesis *customGenesisConfig `json:"genesis" yaml:"genesis"`
+
+	overrideDAGParamsConfig `yaml:",inline"`
+}
+
+type customGenesisConfig struct {
+	// TimeInMilliseconds is the timestamp of the genesis block
+	TimeInMilliseconds int64 `json:"timeInMilliseconds" yaml:"timeInMilliseconds"`
+	// Bits is the difficulty of the genesis block. It defaults to the easiest difficulty the network allows
+	Bits *uint32 `json:"bits" yaml:"bits"`
+	// Nonce is the nonce of the genesis block. The proof of work of the genesis block is never validated
+	Nonce uint64 `json:"nonce" yaml:"nonce"`
+	// Message is added to the coinbase payload of the genesis block. It defaults to the name of the network
+	Message *string `json:"message" yaml:"message"`
+}
+
+// loadCustomNetParams loads the network definition in the given file, and builds its params.
+// The format of the file is detected by its extension: .json for JSON, and .yaml or .yml for YAML.
+func loadCustomNetParams(path string) (*dagconfig.Params, error) {
+	config := &customNetParamsConfig{}
+	var err error
+	switch extension := strings.ToLower(filepath.Ext(path)); extension {
+	case ".json":
+		err = decodeJSONFile(path, config)
+	case ".yaml", ".yml":
+		err = decodeYAMLFile(path, config)
+	default:
+		return nil, errors.Errorf("unsupported format for the network definition in %s: "+
+			"expected a JSON file with a .json extension or a YAML file with a .yaml or .yml extension", path)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading the network definition in %s", path)
+	}
+
+	params, err := config.build()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid network definition in %s", path)
+	}
+	return params, nil
+}
+
+func (config *customNetParamsConfig) build() (*dagconfig.Params, error) {
+	baseName := config.Base
+	if baseName == "" {
+		baseName = "devnet"
+	}
+	base, ok := builtInNetParams[baseName]
+	if !ok {
+		return nil, errors.Errorf("unknown base network %s", baseName)
+	}
+	// Copy the base params, so that the built-in ones are not changed
+	params := *base
+
+	if config.Name == "" {
+		return nil, errors.New("the network must have a name")
+	}
+	if config.Net == 0 {
+		return nil, errors.New("the network must have a non-zero net identifier")
+	}
+	for _, builtIn := range builtInNetParams {
+		if config.Name == builtIn.Name {
+			return nil, errors.Errorf("the name %s is already used by a built-in network", config.Name)
+		}
+		if appmessage.KaspaNet(config.Net) == builtIn.Net {
+			return nil, errors.Errorf("the net identifier %d is already used by %s", config.Net, builtIn.Name)
+		}
+	}
+	params.Name = config.Name
+	params.Net = appmessage.KaspaNet(config.Net)
+
+	if config.RPCPort != "" {
+		params.RPCPort = config.RPCPort
+	}
+	if config.DefaultPort != "" {
+		params.DefaultPort = config.DefaultPort
+	}
+	// A custom network never uses the seeders of its base network, since their nodes belong
+	// to another network
+	params.DNSSeeds = config.DNSSeeds
+	params.GRPCSeeds = config.GRPCSeeds
+
+	if config.Prefix != nil {
+		prefix, err := util.ParsePrefix(*config.Prefix)
+		if err != nil {
+			return nil, err
+		}
+		params.Prefix = prefix
+	}
+
+	err := config.overrideDAGParamsConfig.apply(&params)
+	if err != nil {
+		return nil, err
+	}
+
+	err = config.buildGenesis(&params)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateCustomNetParams(&params)
+	if err != nil {
+		return nil, err
+	}
+	return &params, nil
+}
+
+// buildGenesis derives the genesis block of the network from its genesis definition
+func (config *customNetParamsConfig) buildGenesis(params *dagconfig.Params) error {
+	if config.Genesis == nil {
+		return errors.New("the network must define its genesis")
+	}
+	if config.Genesis.TimeInMilliseconds <= 0 {
+		return errors.New("the genesis must have a positive timestamp")
+	}
+
+	// The default difficulty is derived from powMax, so only explicitly given bits need to be validated against it
+	bits := difficulty.BigToCompact(params.PowMax)
+	validateTarget := false
+	if config.Genesis.Bits != nil {
+		bits = *config.Genesis.Bits
+		validateTarget = true
+	}
+	message := params.Name
+	if config.Genesis.Message != nil {
+		message = *config.Genesis.Message
+	}
+
+	params.GenesisBlock = dagconfig.NewGenesisBlock([]byte(message), config.Genesis.TimeInMilliseconds, bits,
+		config.Genesis.Nonce)
+	params.GenesisHash = consensushashing.BlockHash(params.GenesisBlock)
+
+	if validateTarget {
+		return validateGenesisTarget(params)
+	}
+	return nil
+}
+
+func validateCustomNetParams(params *dagconfig.Params) error {
+	for _, port := range []string{params.RPCPort, params.DefaultPort} {
+		_, err := strconv.ParseUint(port, 10, 16)
+		if err != nil {
+			return errors.Errorf("invalid port %s", port)
+		}
+	}
+	if params.K == 0 {
+		return errors.New("k must be positive")
+	}
+	if params.MaxBlockParents == 0 {
+		return errors.New("maxBlockParents must be positive")
+	}
+	if params.MergeSetSizeLimit == 0 {
+		return errors.New("mergeSetSizeLimit must be positive")
+	}
+	if params.TargetTimePerBlock <= 0 {
+		return errors.New("targetTimePerBlockInMilliSeconds must be positive")
+	}
+	if params.FinalityDuration < params.TargetTimePerBlock {
+		return errors.New("finalityDuration must be at least targetTimePerBlockInMilliSeconds")
+	}
+	if params.TimestampDeviationTolerance <= 0 {
+		return errors.New("timestampDeviationTolerance must be positive")
+	}
+	if params.DifficultyAdjustmentWindowSize <= 0 {
+		return errors.New("difficultyAdjustmentWindowSize must be positive")
+	}
+	if params.PowMax.Sign() <= 0 {
+		return errors.New("powMax must be positive")
+	}
+	return nil
+}
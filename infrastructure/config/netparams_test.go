@@ -0,0 +1,186 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/kaspanet/kaspad/util"
+)
+
+func TestCustomNetParams(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "TestCustomNetParams")
+	if err != nil {
+		t.Fatalf("Failed creating a temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	writeNetParams := func(name string, content string) string {
+		path := filepath.Join(tmpDir, name)
+		err := ioutil.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			t.Fatalf("Failed writing %s: %v", path, err)
+		}
+		return path
+	}
+
+	path := writeNetParams("valid.json", `{
+		"name": "kaspa-customnet",
+		"net": 1234,
+		"rpcPort": "17110",
+		"defaultPort": "17111",
+		"grpcSeeds": ["127.0.0.1:17111"],
+		"prefix": "kaspasim",
+		"k": 10,
+		"targetTimePerBlockInMilliSeconds": 500,
+		"genesis": {"timeInMilliseconds": 1640000000000}
+	}`)
+	networkFlags := &NetworkFlags{NetParamsFile: path}
+	err = networkFlags.ResolveNetwork(flags.NewParser(networkFlags, flags.None))
+	if err != nil {
+		t.Fatalf("ResolveNetwork: %+v", err)
+	}
+	params := networkFlags.NetParams()
+	if params.Name != "kaspa-customnet" || params.Net != 1234 {
+		t.Fatalf("Unexpected network %s (%d)", params.Name, params.Net)
+	}
+	if params.RPCPort != "17110" || params.DefaultPort != "17111" {
+		t.Fatalf("Unexpected ports %s and %s", params.RPCPort, params.DefaultPort)
+	}
+	if len(params.DNSSeeds) != 0 || len(params.GRPCSeeds) != 1 {
+		t.Fatalf("Unexpected seeds %v and %v", params.DNSSeeds, params.GRPCSeeds)
+	}
+	if params.Prefix != util.Bech32PrefixKaspaSim {
+		t.Fatalf("Unexpected prefix %s", params.Prefix)
+	}
+	if params.K != 10 || params.TargetTimePerBlock.Milliseconds() != 500 {
+		t.Fatalf("The overrides in the network definition were not applied")
+	}
+	if params.GenesisBlock.Header.TimeInMilliseconds() != 1640000000000 {
+		t.Fatalf("Unexpected genesis timestamp %d", params.GenesisBlock.Header.TimeInMilliseconds())
+	}
+	if !params.GenesisHash.Equal(consensushashing.BlockHash(params.GenesisBlock)) {
+		t.Fatalf("The genesis hash doesn't match the genesis block")
+	}
+	if params.GenesisHash.Equal(dagconfig.DevnetParams.GenesisHash) {
+		t.Fatalf("Expected the genesis of the custom network to differ from the devnet genesis")
+	}
+	if dagconfig.DevnetParams.Name != "kaspa-devnet" || dagconfig.DevnetParams.K == 10 {
+		t.Fatalf("Loading a custom network changed the params of its base network")
+	}
+
+	tests := []struct {
+		name          string
+		content       string
+		expectedError string
+	}{
+		{
+			name:          "missing name",
+			content:       `{"net": 1234, "genesis": {"timeInMilliseconds": 1}}`,
+			expectedError: "must have a name",
+		},
+		{
+			name:          "built-in net",
+			content:       `{"name": "custom", "net": 1932363745, "genesis": {"timeInMilliseconds": 1}}`,
+			expectedError: "already used by kaspa-devnet",
+		},
+		{
+			name:          "unknown base",
+			content:       `{"base": "othernet", "name": "custom", "net": 1234, "genesis": {"timeInMilliseconds": 1}}`,
+			expectedError: "unknown base network",
+		},
+		{
+			name:          "missing genesis",
+			content:       `{"name": "custom", "net": 1234}`,
+			expectedError: "must define its genesis",
+		},
+		{
+			name:          "zero k",
+			content:       `{"name": "custom", "net": 1234, "k": 0, "genesis": {"timeInMilliseconds": 1}}`,
+			expectedError: "k must be positive",
+		},
+		{
+			name:          "invalid port",
+			content:       `{"name": "custom", "net": 1234, "rpcPort": "port", "genesis": {"timeInMilliseconds": 1}}`,
+			expectedError: "invalid port",
+		},
+		{
+			name:          "malformed",
+			content:       `{"name": "custom",`,
+			expectedError: "error reading the network definition",
+		},
+	}
+	for _, test := range tests {
+		path := writeNetParams(strings.ReplaceAll(test.name, " ", "-")+".json", test.content)
+		_, err := loadCustomNetParams(path)
+		if err == nil {
+			t.Errorf("%s: expected an error", test.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.expectedError) {
+			t.Errorf("%s: expected an error containing %q, but got: %s", test.name, test.expectedError, err)
+		}
+	}
+
+	validContent := `{"name": "custom", "net": 1234, "genesis": {"timeInMilliseconds": 1}}`
+	_, err = loadCustomNetParams(writeNetParams("upper-case.JSON", validContent))
+	if err != nil {
+		t.Errorf("Unexpected error for an upper-case extension: %+v", err)
+	}
+	for _, name := range []string{"netparams.toml", "netparams"} {
+		_, err := loadCustomNetParams(writeNetParams(name, validContent))
+		if err == nil || !strings.Contains(err.Error(), "unsupported format") {
+			t.Errorf("%s: expected an unsupported format error, but got: %v", name, err)
+		}
+	}
+
+	// The same network in YAML. Unquoted ports are read as strings like in JSON
+	validYAMLContent := `
+name: kaspa-customnet
+net: 1234
+rpcPort: 17110
+defaultPort: "17111"
+grpcSeeds:
+  - 127.0.0.1:17111
+prefix: kaspasim
+k: 10
+targetTimePerBlockInMilliSeconds: 500
+genesis:
+  timeInMilliseconds: 1640000000000
+`
+	for _, name := range []string{"valid.yaml", "valid.yml", "upper-case.YAML"} {
+		yamlParams, err := loadCustomNetParams(writeNetParams(name, validYAMLContent))
+		if err != nil {
+			t.Fatalf("%s: loadCustomNetParams: %+v", name, err)
+		}
+		if yamlParams.Name != params.Name || yamlParams.Net != params.Net || yamlParams.RPCPort != params.RPCPort ||
+			yamlParams.DefaultPort != params.DefaultPort || len(yamlParams.GRPCSeeds) != 1 ||
+			yamlParams.Prefix != params.Prefix || yamlParams.K != params.K ||
+			yamlParams.TargetTimePerBlock != params.TargetTimePerBlock {
+
+			t.Fatalf("%s: expected the YAML network definition to match the JSON one", name)
+		}
+		if !yamlParams.GenesisHash.Equal(params.GenesisHash) {
+			t.Fatalf("%s: expected the genesis of the YAML network definition to match the JSON one", name)
+		}
+	}
+
+	_, err = loadCustomNetParams(writeNetParams("malformed.yaml", "name: [custom"))
+	if err == nil || !strings.Contains(err.Error(), "error reading the network definition") {
+		t.Errorf("Expected an error reading a malformed YAML network definition, but got: %v", err)
+	}
+}
+
+func TestNetParamsWithOtherNetwork(t *testing.T) {
+	networkFlags := &NetworkFlags{NetParamsFile: "netparams.json", Devnet: true}
+	parser := flags.NewParser(networkFlags, flags.None)
+	err := networkFlags.ResolveNetwork(parser)
+	if err == nil {
+		t.Fatalf("Expected --netparams together with --devnet to fail")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"io/ioutil"
 	"math/big"
 	"os"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"github.com/kaspanet/kaspad/domain/dagconfig"
 	"github.com/kaspanet/kaspad/util/difficulty"
 	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
 )
 
 // NetworkFlags holds the network configuration, that is which network is selected.
@@ -20,35 +22,36 @@ type NetworkFlags struct {
 	Simnet                bool   `long:"simnet" description:"Use the simulation test network"`
 	Devnet                bool   `long:"devnet" description:"Use the development test network"`
 	OverrideDAGParamsFile string `long:"override-dag-params-file" description:"Overrides DAG params (allowed only on devnet)"`
+	NetParamsFile         string `long:"netparams" description:"Use the custom network defined in the given JSON (.json) or YAML (.yaml or .yml) file"`
 
 	ActiveNetParams *dagconfig.Params
 }
 
 type overrideDAGParamsConfig struct {
-	K                                       *externalapi.KType `json:"k"`
-	MaxBlockParents                         *externalapi.KType `json:"maxBlockParents"`
-	MergeSetSizeLimit                       *uint64            `json:"mergeSetSizeLimit"`
-	MaxBlockMass                            *uint64            `json:"maxBlockMass"`
-	MaxCoinbasePayloadLength                *uint64            `json:"maxCoinbasePayloadLength"`
-	MassPerTxByte                           *uint64            `json:"massPerTxByte"`
-	MassPerScriptPubKeyByte                 *uint64            `json:"massPerScriptPubKeyByte"`
-	MassPerSigOp                            *uint64            `json:"massPerSigOp"`
-	CoinbasePayloadScriptPublicKeyMaxLength *uint8             `json:"coinbasePayloadScriptPublicKeyMaxLength"`
-	PowMax                                  *string            `json:"powMax"`
-	BlockCoinbaseMaturity                   *uint64            `json:"blockCoinbaseMaturity"`
-	SubsidyGenesisReward                    *uint64            `json:"subsidyGenesisReward"`
-	SubsidyPastRewardMultiplier             *float64           `json:"subsidyPastRewardMultiplier"`
-	SubsidyMergeSetRewardMultiplier         *float64           `json:"subsidyMergeSetRewardMultiplier"`
-	TargetTimePerBlockInMilliSeconds        *int64             `json:"targetTimePerBlockInMilliSeconds"`
-	FinalityDuration                        *int64             `json:"finalityDuration"`
-	TimestampDeviationTolerance             *int               `json:"timestampDeviationTolerance"`
-	DifficultyAdjustmentWindowSize          *int               `json:"difficultyAdjustmentWindowSize"`
-	RelayNonStdTxs                          *bool              `json:"relayNonStdTxs"`
-	AcceptUnroutable                        *bool              `json:"acceptUnroutable"`
-	EnableNonNativeSubnetworks              *bool              `json:"enableNonNativeSubnetworks"`
-	DisableDifficultyAdjustment             *bool              `json:"disableDifficultyAdjustment"`
-	SkipProofOfWork                         *bool              `json:"skipProofOfWork"`
-	HardForkOmitGenesisFromParentsDAAScore  *uint64            `json:"hardForkOmitGenesisFromParentsDaaScore"`
+	K                                       *externalapi.KType `json:"k" yaml:"k"`
+	MaxBlockParents                         *externalapi.KType `json:"maxBlockParents" yaml:"maxBlockParents"`
+	MergeSetSizeLimit                       *uint64            `json:"mergeSetSizeLimit" yaml:"mergeSetSizeLimit"`
+	MaxBlockMass                            *uint64            `json:"maxBlockMass" yaml:"maxBlockMass"`
+	MaxCoinbasePayloadLength                *uint64            `json:"maxCoinbasePayloadLength" yaml:"maxCoinbasePayloadLength"`
+	MassPerTxByte                           *uint64            `json:"massPerTxByte" yaml:"massPerTxByte"`
+	MassPerScriptPubKeyByte                 *uint64            `json:"massPerScriptPubKeyByte" yaml:"massPerScriptPubKeyByte"`
+	MassPerSigOp                            *uint64            `json:"massPerSigOp" yaml:"massPerSigOp"`
+	CoinbasePayloadScriptPublicKeyMaxLength *uint8             `json:"coinbasePayloadScriptPublicKeyMaxLength" yaml:"coinbasePayloadScriptPublicKeyMaxLength"`
+	PowMax                                  *string            `json:"powMax" yaml:"powMax"`
+	BlockCoinbaseMaturity                   *uint64            `json:"blockCoinbaseMaturity" yaml:"blockCoinbaseMaturity"`
+	SubsidyGenesisReward                    *uint64            `json:"subsidyGenesisReward" yaml:"subsidyGenesisReward"`
+	SubsidyPastRewardMultiplier             *float64           `json:"subsidyPastRewardMultiplier" yaml:"subsidyPastRewardMultiplier"`
+	SubsidyMergeSetRewardMultiplier         *float64           `json:"subsidyMergeSetRewardMultiplier" yaml:"subsidyMergeSetRewardMultiplier"`
+	TargetTimePerBlockInMilliSeconds        *int64             `json:"targetTimePerBlockInMilliSeconds" yaml:"targetTimePerBlockInMilliSeconds"`
+	FinalityDuration                        *int64             `json:"finalityDuration" yaml:"finalityDuration"`
+	TimestampDeviationTolerance             *int               `json:"timestampDeviationTolerance" yaml:"timestampDeviationTolerance"`
+	DifficultyAdjustmentWindowSize          *int               `json:"difficultyAdjustmentWindowSize" yaml:"difficultyAdjustmentWindowSize"`
+	RelayNonStdTxs                          *bool              `json:"relayNonStdTxs" yaml:"relayNonStdTxs"`
+	AcceptUnroutable                        *bool              `json:"acceptUnroutable" yaml:"acceptUnroutable"`
+	EnableNonNativeSubnetworks              *bool              `json:"enableNonNativeSubnetworks" yaml:"enableNonNativeSubnetworks"`
+	DisableDifficultyAdjustment             *bool              `json:"disableDifficultyAdjustment" yaml:"disableDifficultyAdjustment"`
+	SkipProofOfWork                         *bool              `json:"skipProofOfWork" yaml:"skipProofOfWork"`
+	HardForkOmitGenesisFromParentsDAAScore  *uint64            `json:"hardForkOmitGenesisFromParentsDaaScore" yaml:"hardForkOmitGenesisFromParentsDaaScore"`
 }
 
 // ResolveNetwork parses the network command line argument and sets NetParams accordingly.
@@ -73,8 +76,11 @@ func (networkFlags *NetworkFlags) ResolveNetwork(parser *flags.Parser) error {
 		numNets++
 		networkFlags.ActiveNetParams = &dagconfig.DevnetParams
 	}
+	if networkFlags.NetParamsFile != "" {
+		numNets++
+	}
 	if numNets > 1 {
-		message := "Multiple networks parameters (testnet, simnet, devnet, etc.) cannot be used" +
+		message := "Multiple networks parameters (testnet, simnet, devnet, netparams, etc.) cannot be used" +
 			"together. Please choose only one network"
 		err := errors.Errorf(message)
 		fmt.Fprintln(os.Stderr, err)
@@ -82,6 +88,14 @@ func (networkFlags *NetworkFlags) ResolveNetwork(parser *flags.Parser) error {
 		return err
 	}
 
+	if networkFlags.NetParamsFile != "" {
+		customNetParams, err := loadCustomNetParams(networkFlags.NetParamsFile)
+		if err != nil {
+			return err
+		}
+		networkFlags.ActiveNetParams = customNetParams
+	}
+
 	err := networkFlags.overrideDAGParams()
 	if err != nil {
 		return err
@@ -105,53 +119,74 @@ func (networkFlags *NetworkFlags) overrideDAGParams() error {
 		return errors.Errorf("override-dag-params-file is allowed only when using devnet")
 	}
 
-	overrideDAGParamsFile, err := os.Open(networkFlags.OverrideDAGParamsFile)
+	config := &overrideDAGParamsConfig{}
+	err := decodeJSONFile(networkFlags.OverrideDAGParamsFile, config)
 	if err != nil {
 		return err
 	}
-	defer overrideDAGParamsFile.Close()
 
-	decoder := json.NewDecoder(overrideDAGParamsFile)
-	config := &overrideDAGParamsConfig{}
-	err = decoder.Decode(config)
+	err = config.apply(networkFlags.ActiveNetParams)
 	if err != nil {
 		return err
 	}
+	return validateGenesisTarget(networkFlags.ActiveNetParams)
+}
+
+func decodeJSONFile(path string, value interface{}) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
 
+	decoder := json.NewDecoder(file)
+	return decoder.Decode(value)
+}
+
+func decodeYAMLFile(path string, value interface{}) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(content, value)
+}
+
+// apply overrides the given params with all the fields that are set in the config
+func (config *overrideDAGParamsConfig) apply(params *dagconfig.Params) error {
 	if config.K != nil {
-		networkFlags.ActiveNetParams.K = *config.K
+		params.K = *config.K
 	}
 
 	if config.MaxBlockParents != nil {
-		networkFlags.ActiveNetParams.MaxBlockParents = *config.MaxBlockParents
+		params.MaxBlockParents = *config.MaxBlockParents
 	}
 
 	if config.MergeSetSizeLimit != nil {
-		networkFlags.ActiveNetParams.MergeSetSizeLimit = *config.MergeSetSizeLimit
+		params.MergeSetSizeLimit = *config.MergeSetSizeLimit
 	}
 
 	if config.MaxBlockMass != nil {
-		networkFlags.ActiveNetParams.MaxBlockMass = *config.MaxBlockMass
+		params.MaxBlockMass = *config.MaxBlockMass
 	}
 
 	if config.MaxCoinbasePayloadLength != nil {
-		networkFlags.ActiveNetParams.MaxCoinbasePayloadLength = *config.MaxCoinbasePayloadLength
+		params.MaxCoinbasePayloadLength = *config.MaxCoinbasePayloadLength
 	}
 
 	if config.MassPerTxByte != nil {
-		networkFlags.ActiveNetParams.MassPerTxByte = *config.MassPerTxByte
+		params.MassPerTxByte = *config.MassPerTxByte
 	}
 
 	if config.MassPerScriptPubKeyByte != nil {
-		networkFlags.ActiveNetParams.MassPerScriptPubKeyByte = *config.MassPerScriptPubKeyByte
+		params.MassPerScriptPubKeyByte = *config.MassPerScriptPubKeyByte
 	}
 
 	if config.MassPerSigOp != nil {
-		networkFlags.ActiveNetParams.MassPerSigOp = *config.MassPerSigOp
+		params.MassPerSigOp = *config.MassPerSigOp
 	}
 
 	if config.CoinbasePayloadScriptPublicKeyMaxLength != nil {
-		networkFlags.ActiveNetParams.CoinbasePayloadScriptPublicKeyMaxLength = *config.CoinbasePayloadScriptPublicKeyMaxLength
+		params.CoinbasePayloadScriptPublicKeyMaxLength = *config.CoinbasePayloadScriptPublicKeyMaxLength
 	}
 
 	if config.PowMax != nil {
@@ -159,59 +194,62 @@ func (networkFlags *NetworkFlags) overrideDAGParams() error {
 		if !ok {
 			return errors.Errorf("couldn't convert %s to big int", *config.PowMax)
 		}
-
-		genesisTarget := difficulty.CompactToBig(networkFlags.ActiveNetParams.GenesisBlock.Header.Bits())
-		if powMax.Cmp(genesisTarget) > 0 {
-			return errors.Errorf("powMax (%s) is smaller than genesis's target (%s)", powMax.Text(16),
-				genesisTarget.Text(16))
-		}
-		networkFlags.ActiveNetParams.PowMax = powMax
+		params.PowMax = powMax
 	}
 
 	if config.BlockCoinbaseMaturity != nil {
-		networkFlags.ActiveNetParams.BlockCoinbaseMaturity = *config.BlockCoinbaseMaturity
+		params.BlockCoinbaseMaturity = *config.BlockCoinbaseMaturity
 	}
 
 	if config.SubsidyGenesisReward != nil {
-		networkFlags.ActiveNetParams.SubsidyGenesisReward = *config.SubsidyGenesisReward
+		params.SubsidyGenesisReward = *config.SubsidyGenesisReward
 	}
 
 	if config.TargetTimePerBlockInMilliSeconds != nil {
-		networkFlags.ActiveNetParams.TargetTimePerBlock = time.Duration(*config.TargetTimePerBlockInMilliSeconds) *
+		params.TargetTimePerBlock = time.Duration(*config.TargetTimePerBlockInMilliSeconds) *
 			time.Millisecond
 	}
 
 	if config.FinalityDuration != nil {
-		networkFlags.ActiveNetParams.FinalityDuration = time.Duration(*config.FinalityDuration) * time.Millisecond
+		params.FinalityDuration = time.Duration(*config.FinalityDuration) * time.Millisecond
 	}
 
 	if config.TimestampDeviationTolerance != nil {
-		networkFlags.ActiveNetParams.TimestampDeviationTolerance = *config.TimestampDeviationTolerance
+		params.TimestampDeviationTolerance = *config.TimestampDeviationTolerance
 	}
 
 	if config.DifficultyAdjustmentWindowSize != nil {
-		networkFlags.ActiveNetParams.DifficultyAdjustmentWindowSize = *config.DifficultyAdjustmentWindowSize
+		params.DifficultyAdjustmentWindowSize = *config.DifficultyAdjustmentWindowSize
 	}
 
 	if config.TimestampDeviationTolerance != nil {
-		networkFlags.ActiveNetParams.TimestampDeviationTolerance = *config.TimestampDeviationTolerance
+		params.TimestampDeviationTolerance = *config.TimestampDeviationTolerance
 	}
 
 	if config.RelayNonStdTxs != nil {
-		networkFlags.ActiveNetParams.RelayNonStdTxs = *config.RelayNonStdTxs
+		params.RelayNonStdTxs = *config.RelayNonStdTxs
 	}
 
 	if config.AcceptUnroutable != nil {
-		networkFlags.ActiveNetParams.AcceptUnroutable = *config.AcceptUnroutable
+		params.AcceptUnroutable = *config.AcceptUnroutable
 	}
 
 	if config.EnableNonNativeSubnetworks != nil {
-		networkFlags.ActiveNetParams.EnableNonNativeSubnetworks = *config.EnableNonNativeSubnetworks
+		params.EnableNonNativeSubnetworks = *config.EnableNonNativeSubnetworks
 	}
 
 	if config.SkipProofOfWork != nil {
-		networkFlags.ActiveNetParams.SkipProofOfWork = *config.SkipProofOfWork
+		params.SkipProofOfWork = *config.SkipProofOfWork
 	}
 
 	return nil
 }
+
+func validateGenesisTarget(params *dagconfig.Params) error {
+	genesisTarget := difficulty.CompactToBig(params.GenesisBlock.Header.Bits())
+	if params.PowMax.Cmp(genesisTarget) > 0 {
+		return errors.Errorf("powMax (%s) is smaller than genesis's target (%s)", params.PowMax.Text(16),
+			genesisTarget.Text(16))
+	}
+	return nil
+}
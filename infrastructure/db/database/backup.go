@@ -0,0 +1,44 @@
+package database
+
+import (
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// Backup writes a verified backup of source, which must be a database of the
+// given type, into the given directory. It returns the amount of entries in the backup.
+// Each backend writes its backups in its own format, and some backends can't be backed up.
+func Backup(dbType string, source Database, path string) (entryCount uint64, err error) {
+	driver, err := LookupDriver(dbType)
+	if err != nil {
+		return 0, err
+	}
+	if driver.Backup == nil {
+		return 0, errors.Errorf("the %s database backend does not support backups", dbType)
+	}
+	return driver.Backup(source, path)
+}
+
+// CreateEmptyBackupDirectory creates the directory at the given path, or makes sure
+// it's empty if it already exists, so that a backup never overwrites anything
+func CreateEmptyBackupDirectory(path string) error {
+	directory, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return errors.WithStack(os.MkdirAll(path, 0700))
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer directory.Close()
+
+	_, err = directory.Readdirnames(1)
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.Errorf("cannot write a backup into %s: the directory is not empty", path)
+}
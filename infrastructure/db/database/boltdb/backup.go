@@ -0,0 +1,85 @@
+package boltdb
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Backup writes a copy of all the data in source, as it is at the moment Backup
+// is called, into a new bolt database in the given directory. The source database
+// may keep being written to while the backup is in progress.
+//
+// The data file is written as is by bolt from a single read transaction, then
+// reopened for reading only and compared against that transaction. If anything
+// fails, the backup directory is removed. Backup returns the amount of entries
+// in the backup.
+func Backup(source database.Database, path string) (entryCount uint64, err error) {
+	db, ok := source.(*BoltDB)
+	if !ok {
+		return 0, errors.Errorf("the bolt backend cannot back up a database of type %T", source)
+	}
+
+	err = database.CreateEmptyBackupDirectory(path)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			removeErr := os.RemoveAll(path)
+			if removeErr != nil {
+				log.Errorf("Failed removing the failed backup in %s: %s", path, removeErr)
+			}
+		}
+	}()
+
+	tx, err := db.beginReadTx()
+	if err != nil {
+		return 0, err
+	}
+	snapshot := &BoltDBSnapshot{db: db, tx: tx}
+	defer snapshot.Release()
+
+	log.Infof("Writing a database backup to %s", path)
+	err = writeDataFile(tx, filepath.Join(path, dataFileName))
+	if err != nil {
+		return 0, err
+	}
+
+	log.Infof("Verifying the database backup in %s", path)
+	readOnlyBackupDB, err := NewBoltDBReadOnly(path)
+	if err != nil {
+		return 0, err
+	}
+	defer readOnlyBackupDB.Close()
+	entryCount, err = database.VerifySnapshotCopy(snapshot, readOnlyBackupDB)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed verifying the backup in %s", path)
+	}
+
+	log.Infof("Finished writing a database backup of %d entries to %s", entryCount, path)
+	return entryCount, nil
+}
+
+// writeDataFile writes the whole database, as seen by the given
+// transaction, into a new file at the given path
+func writeDataFile(tx *bolt.Tx, filePath string) error {
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = tx.WriteTo(file)
+	if err != nil {
+		file.Close()
+		return errors.WithStack(err)
+	}
+	err = file.Sync()
+	if err != nil {
+		file.Close()
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(file.Close())
+}
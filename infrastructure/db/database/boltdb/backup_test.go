@@ -0,0 +1,67 @@
+package boltdb
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+)
+
+func TestBackup(t *testing.T) {
+	path, err := ioutil.TempDir("", "TestBackup")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(path)
+
+	db, err := NewBoltDB(filepath.Join(path, "db"))
+	if err != nil {
+		t.Fatalf("NewBoltDB: %s", err)
+	}
+	defer db.Close()
+
+	const entryCount = 1000
+	bucket := database.MakeBucket([]byte("bucket"))
+	for i := 0; i < entryCount; i++ {
+		err := db.Put(bucket.Key([]byte(fmt.Sprintf("key%d", i))), []byte(fmt.Sprintf("value%d", i)))
+		if err != nil {
+			t.Fatalf("Put: %s", err)
+		}
+	}
+
+	backupPath := filepath.Join(path, "backup")
+	backedUpEntryCount, err := database.Backup(DbType, db, backupPath)
+	if err != nil {
+		t.Fatalf("Backup: %+v", err)
+	}
+	if backedUpEntryCount != entryCount {
+		t.Fatalf("Expected %d entries in the backup, but got %d", entryCount, backedUpEntryCount)
+	}
+
+	backup, err := NewBoltDBReadOnly(backupPath)
+	if err != nil {
+		t.Fatalf("NewBoltDBReadOnly: %s", err)
+	}
+	defer backup.Close()
+	value, err := backup.Get(bucket.Key([]byte("key17")))
+	if err != nil {
+		t.Fatalf("Get: %s", err)
+	}
+	if !reflect.DeepEqual(value, []byte("value17")) {
+		t.Fatalf("Unexpected value %s in the backup", value)
+	}
+
+	// A backup must never be written into a directory that has anything in it
+	_, err = Backup(db, backupPath)
+	if err == nil {
+		t.Fatalf("Expected a backup into a non-empty directory to fail")
+	}
+	_, err = os.Stat(filepath.Join(backupPath, dataFileName))
+	if err != nil {
+		t.Fatalf("The failed backup changed the existing directory: %s", err)
+	}
+}
@@ -0,0 +1,228 @@
+package boltdb
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// dataFileName is the name of the file in the database directory that holds all the data
+const dataFileName = "kaspad.bolt"
+
+// dataBucketName is the bolt bucket in which all the keys are kept. Keys of
+// kaspad's database already include the path of their bucket, so there's no
+// need to map kaspad buckets into nested bolt buckets.
+var dataBucketName = []byte("data")
+
+// initialMmapSize is the size of the memory map that the database file is
+// initially opened with.
+//
+// A bolt transaction that needs to grow the memory map waits until all read
+// transactions are closed. Cursors and snapshots hold a read transaction for
+// as long as they are open, so a large initial map makes sure that writes
+// never block on open cursors unless the database outgrows it. Mapping more
+// than the size of the file only reserves address space.
+var initialMmapSize = func() int {
+	if strconv.IntSize == 64 {
+		return 64 * 1024 * 1024 * 1024
+	}
+	return 0
+}()
+
+// BoltDB defines a thin wrapper around bolt.
+//
+// Unlike leveldb, bolt relies on the page cache of the operating system
+// instead of keeping its own cache, so the cache size it's given is ignored.
+type BoltDB struct {
+	bolt *bolt.DB
+
+	// openReadTxs are the read transactions held by open cursors and snapshots
+	openReadTxs     map[*bolt.Tx]struct{}
+	openReadTxsLock sync.Mutex
+}
+
+// NewBoltDB opens a bolt database in the directory defined by the given path.
+// If it doesn't exist, it's created.
+func NewBoltDB(path string) (*BoltDB, error) {
+	err := os.MkdirAll(path, 0700)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	options := &bolt.Options{
+		// Fail instead of waiting forever if another process has the database open
+		Timeout:         time.Second,
+		InitialMmapSize: initialMmapSize,
+		FreelistType:    bolt.FreelistMapType,
+		NoFreelistSync:  true,
+	}
+	boltDB, err := bolt.Open(filepath.Join(path, dataFileName), 0600, options)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed opening the bolt database in %s", path)
+	}
+
+	err = boltDB.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(dataBucketName)
+		return err
+	})
+	if err != nil {
+		boltDB.Close()
+		return nil, errors.WithStack(err)
+	}
+
+	return newBoltDB(boltDB), nil
+}
+
+// NewBoltDBReadOnly opens an existing bolt database in the directory defined
+// by the given path for reading only. Any attempt to write into it fails.
+func NewBoltDBReadOnly(path string) (*BoltDB, error) {
+	options := &bolt.Options{
+		Timeout:  time.Second,
+		ReadOnly: true,
+	}
+	boltDB, err := bolt.Open(filepath.Join(path, dataFileName), 0600, options)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed opening the bolt database in %s", path)
+	}
+
+	err = boltDB.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(dataBucketName) == nil {
+			return errors.Errorf("the bolt database in %s has no data bucket", path)
+		}
+		return nil
+	})
+	if err != nil {
+		boltDB.Close()
+		return nil, err
+	}
+
+	return newBoltDB(boltDB), nil
+}
+
+func newBoltDB(boltDB *bolt.DB) *BoltDB {
+	return &BoltDB{
+		bolt:        boltDB,
+		openReadTxs: make(map[*bolt.Tx]struct{}),
+	}
+}
+
+// Close closes the bolt database. Cursors and snapshots that are still
+// open are released, and must not be used afterwards.
+func (db *BoltDB) Close() error {
+	db.openReadTxsLock.Lock()
+	// bolt waits for all read transactions to finish before closing
+	for tx := range db.openReadTxs {
+		_ = tx.Rollback()
+	}
+	db.openReadTxs = make(map[*bolt.Tx]struct{})
+	db.openReadTxsLock.Unlock()
+
+	err := db.bolt.Close()
+	return errors.WithStack(err)
+}
+
+// beginReadTx begins a read transaction that's held until it's
+// released with releaseReadTx, or until the database is closed
+func (db *BoltDB) beginReadTx() (*bolt.Tx, error) {
+	// Beginning a transaction may wait for the memory map to grow, which in turn waits
+	// for other read transactions to be released, so it must not be done under the lock
+	tx, err := db.bolt.Begin(false)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	db.openReadTxsLock.Lock()
+	defer db.openReadTxsLock.Unlock()
+
+	db.openReadTxs[tx] = struct{}{}
+	return tx, nil
+}
+
+func (db *BoltDB) releaseReadTx(tx *bolt.Tx) error {
+	db.openReadTxsLock.Lock()
+	defer db.openReadTxsLock.Unlock()
+
+	if _, ok := db.openReadTxs[tx]; !ok {
+		// The transaction was already released when the database was closed
+		return nil
+	}
+	delete(db.openReadTxs, tx)
+	return errors.WithStack(tx.Rollback())
+}
+
+// Put sets the value for the given key. It overwrites
+// any previous value for that key.
+func (db *BoltDB) Put(key *database.Key, value []byte) error {
+	err := db.bolt.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(dataBucketName).Put(key.Bytes(), value)
+	})
+	return errors.WithStack(err)
+}
+
+// Get gets the value for the given key. It returns
+// ErrNotFound if the given key does not exist.
+func (db *BoltDB) Get(key *database.Key) ([]byte, error) {
+	var value []byte
+	var found bool
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		value, found = get(tx, key)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if !found {
+		return nil, errors.Wrapf(database.ErrNotFound, "key %s not found", key)
+	}
+	return value, nil
+}
+
+// Has returns true if the database does contains the
+// given key.
+func (db *BoltDB) Has(key *database.Key) (bool, error) {
+	var found bool
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		_, found = get(tx, key)
+		return nil
+	})
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return found, nil
+}
+
+// Delete deletes the value for the given key. Will not
+// return an error if the key doesn't exist.
+func (db *BoltDB) Delete(key *database.Key) error {
+	err := db.bolt.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(dataBucketName).Delete(key.Bytes())
+	})
+	return errors.WithStack(err)
+}
+
+// get returns a copy of the value of the given key within the given bolt
+// transaction, and whether the key was found.
+//
+// The key is looked up with a cursor rather than with Bucket.Get, since the
+// latter doesn't tell an empty value apart from a missing key.
+func get(tx *bolt.Tx, key *database.Key) ([]byte, bool) {
+	foundKey, value := tx.Bucket(dataBucketName).Cursor().Seek(key.Bytes())
+	if foundKey == nil || !bytes.Equal(foundKey, key.Bytes()) {
+		return nil, false
+	}
+	// Values returned by bolt are only valid while the transaction is open
+	return copyBytes(value), true
+}
+
+func copyBytes(bytes []byte) []byte {
+	copied := make([]byte, len(bytes))
+	copy(copied, bytes)
+	return copied
+}
@@ -0,0 +1,117 @@
+package boltdb
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+)
+
+func TestBoltDBPersistence(t *testing.T) {
+	path, err := ioutil.TempDir("", "TestBoltDBPersistence")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(path)
+
+	db, err := NewBoltDB(path)
+	if err != nil {
+		t.Fatalf("NewBoltDB: %s", err)
+	}
+	bucket := database.MakeBucket([]byte("bucket"))
+	key := bucket.Key([]byte("key"))
+	emptyKey := bucket.Key([]byte("empty"))
+	err = db.Put(key, []byte("value"))
+	if err != nil {
+		t.Fatalf("Put: %s", err)
+	}
+	err = db.Put(emptyKey, nil)
+	if err != nil {
+		t.Fatalf("Put: %s", err)
+	}
+
+	// An open cursor must not prevent the database from closing
+	_, err = db.Cursor(bucket)
+	if err != nil {
+		t.Fatalf("Cursor: %s", err)
+	}
+	err = db.Close()
+	if err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+
+	db, err = NewBoltDB(path)
+	if err != nil {
+		t.Fatalf("NewBoltDB: %s", err)
+	}
+	defer db.Close()
+
+	value, err := db.Get(key)
+	if err != nil {
+		t.Fatalf("Get: %s", err)
+	}
+	if !bytes.Equal(value, []byte("value")) {
+		t.Fatalf("Expected the value to survive reopening the database, but got %s", value)
+	}
+	exists, err := db.Has(emptyKey)
+	if err != nil {
+		t.Fatalf("Has: %s", err)
+	}
+	if !exists {
+		t.Fatalf("Expected a key with an empty value to exist")
+	}
+	exists, err = db.Has(bucket.Key([]byte("missing")))
+	if err != nil {
+		t.Fatalf("Has: %s", err)
+	}
+	if exists {
+		t.Fatalf("Expected a key that was never put to not exist")
+	}
+}
+
+func TestBoltDBReadOnly(t *testing.T) {
+	path, err := ioutil.TempDir("", "TestBoltDBReadOnly")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(path)
+
+	_, err = NewBoltDBReadOnly(path)
+	if err == nil {
+		t.Fatalf("NewBoltDBReadOnly unexpectedly opened a database that doesn't exist")
+	}
+
+	db, err := NewBoltDB(path)
+	if err != nil {
+		t.Fatalf("NewBoltDB: %s", err)
+	}
+	key := database.MakeBucket([]byte("bucket")).Key([]byte("key"))
+	err = db.Put(key, []byte("value"))
+	if err != nil {
+		t.Fatalf("Put: %s", err)
+	}
+	err = db.Close()
+	if err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+
+	db, err = NewBoltDBReadOnly(path)
+	if err != nil {
+		t.Fatalf("NewBoltDBReadOnly: %s", err)
+	}
+	defer db.Close()
+
+	value, err := db.Get(key)
+	if err != nil {
+		t.Fatalf("Get: %s", err)
+	}
+	if !bytes.Equal(value, []byte("value")) {
+		t.Fatalf("Expected the value to be readable, but got %s", value)
+	}
+	err = db.Put(key, []byte("other value"))
+	if err == nil {
+		t.Fatalf("Put unexpectedly succeeded on a read-only database")
+	}
+}
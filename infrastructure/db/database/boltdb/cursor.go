@@ -0,0 +1,144 @@
+package boltdb
+
+import (
+	"bytes"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// BoltDBCursor is a thin wrapper around native bolt cursors.
+//
+// Every cursor holds its own read transaction, so it sees the data as it was
+// when the cursor was opened, the same way leveldb cursors do.
+type BoltDBCursor struct {
+	db          *BoltDB
+	tx          *bolt.Tx
+	boltCursor  *bolt.Cursor
+	bucket      *database.Bucket
+	ownsTx      bool
+	isStarted   bool
+	currentKey  []byte
+	currentData []byte
+
+	isClosed bool
+}
+
+// Cursor begins a new cursor over the given prefix.
+func (db *BoltDB) Cursor(bucket *database.Bucket) (database.Cursor, error) {
+	tx, err := db.beginReadTx()
+	if err != nil {
+		return nil, err
+	}
+	return newBoltDBCursor(db, tx, bucket, true), nil
+}
+
+func newBoltDBCursor(db *BoltDB, tx *bolt.Tx, bucket *database.Bucket, ownsTx bool) *BoltDBCursor {
+	return &BoltDBCursor{
+		db:         db,
+		tx:         tx,
+		boltCursor: tx.Bucket(dataBucketName).Cursor(),
+		bucket:     bucket,
+		ownsTx:     ownsTx,
+		isClosed:   false,
+	}
+}
+
+// Next moves the iterator to the next key/value pair. It returns whether the
+// iterator is exhausted. Panics if the cursor is closed.
+func (c *BoltDBCursor) Next() bool {
+	if c.isClosed {
+		panic("cannot call next on a closed cursor")
+	}
+	if !c.isStarted {
+		return c.First()
+	}
+	if c.currentKey == nil {
+		return false
+	}
+	return c.setCurrent(c.boltCursor.Next())
+}
+
+// First moves the iterator to the first key/value pair. It returns false if
+// such a pair does not exist. Panics if the cursor is closed.
+func (c *BoltDBCursor) First() bool {
+	if c.isClosed {
+		panic("cannot call first on a closed cursor")
+	}
+	c.isStarted = true
+	return c.setCurrent(c.boltCursor.Seek(c.bucket.Path()))
+}
+
+// Seek moves the iterator to the first key/value pair whose key is greater
+// than or equal to the given key. It returns ErrNotFound if such pair does not
+// exist.
+func (c *BoltDBCursor) Seek(key *database.Key) error {
+	if c.isClosed {
+		return errors.New("cannot seek a closed cursor")
+	}
+
+	c.isStarted = true
+	found := c.setCurrent(c.boltCursor.Seek(key.Bytes()))
+	if !found || !bytes.Equal(c.currentKey, key.Bytes()) {
+		return errors.Wrapf(database.ErrNotFound, "key %s not found", key)
+	}
+	return nil
+}
+
+// setCurrent sets the current key/value pair of the cursor to the given one,
+// unless it's outside the bucket of the cursor. It returns whether the cursor
+// points to a key/value pair.
+func (c *BoltDBCursor) setCurrent(key []byte, value []byte) bool {
+	if key == nil || !bytes.HasPrefix(key, c.bucket.Path()) {
+		c.currentKey = nil
+		c.currentData = nil
+		return false
+	}
+	c.currentKey = key
+	c.currentData = value
+	return true
+}
+
+// Key returns the key of the current key/value pair, or ErrNotFound if done.
+// Note that the key is trimmed to not include the prefix the cursor was opened
+// with.
+func (c *BoltDBCursor) Key() (*database.Key, error) {
+	if c.isClosed {
+		return nil, errors.New("cannot get the key of a closed cursor")
+	}
+	if c.currentKey == nil {
+		return nil, errors.Wrapf(database.ErrNotFound, "cannot get the "+
+			"key of an exhausted cursor")
+	}
+	suffix := bytes.TrimPrefix(c.currentKey, c.bucket.Path())
+	return c.bucket.Key(copyBytes(suffix)), nil
+}
+
+// Value returns the value of the current key/value pair, or ErrNotFound if done.
+func (c *BoltDBCursor) Value() ([]byte, error) {
+	if c.isClosed {
+		return nil, errors.New("cannot get the value of a closed cursor")
+	}
+	if c.currentKey == nil {
+		return nil, errors.Wrapf(database.ErrNotFound, "cannot get the "+
+			"value of an exhausted cursor")
+	}
+	return copyBytes(c.currentData), nil
+}
+
+// Close releases associated resources.
+func (c *BoltDBCursor) Close() error {
+	if c.isClosed {
+		return errors.New("cannot close an already closed cursor")
+	}
+	c.isClosed = true
+	c.boltCursor = nil
+	c.currentKey = nil
+	c.currentData = nil
+	c.bucket = nil
+	if !c.ownsTx {
+		return nil
+	}
+	return c.db.releaseReadTx(c.tx)
+}
@@ -0,0 +1,25 @@
+package boltdb
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+)
+
+// DbType is the type of the bolt backend, as given by --dbtype
+const DbType = "bolt"
+
+func init() {
+	err := database.RegisterDriver(database.Driver{
+		DbType: DbType,
+		Open: func(path string, _ int) (database.Database, error) {
+			return NewBoltDB(path)
+		},
+		OpenReadOnly: func(path string, _ int) (database.Database, error) {
+			return NewBoltDBReadOnly(path)
+		},
+		Backup:       Backup,
+		IsPersistent: true,
+	})
+	if err != nil {
+		panic(err)
+	}
+}
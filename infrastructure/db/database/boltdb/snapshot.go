@@ -0,0 +1,53 @@
+package boltdb
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// BoltDBSnapshot is a thin wrapper around a bolt read transaction.
+//
+// Bolt transactions aren't safe for concurrent use, so a snapshot and the
+// cursors opened over it must be used by one goroutine at a time.
+type BoltDBSnapshot struct {
+	db *BoltDB
+	tx *bolt.Tx
+}
+
+// Snapshot takes a snapshot of the database's current data.
+func (db *BoltDB) Snapshot() (database.Snapshot, error) {
+	tx, err := db.beginReadTx()
+	if err != nil {
+		return nil, err
+	}
+	return &BoltDBSnapshot{db: db, tx: tx}, nil
+}
+
+// Get gets the value for the given key. It returns
+// ErrNotFound if the given key does not exist.
+func (s *BoltDBSnapshot) Get(key *database.Key) ([]byte, error) {
+	value, found := get(s.tx, key)
+	if !found {
+		return nil, errors.Wrapf(database.ErrNotFound, "key %s not found", key)
+	}
+	return value, nil
+}
+
+// Has returns true if the snapshot does contains the
+// given key.
+func (s *BoltDBSnapshot) Has(key *database.Key) (bool, error) {
+	_, found := get(s.tx, key)
+	return found, nil
+}
+
+// Cursor begins a new cursor over the given prefix.
+func (s *BoltDBSnapshot) Cursor(bucket *database.Bucket) (database.Cursor, error) {
+	return newBoltDBCursor(s.db, s.tx, bucket, false), nil
+}
+
+// Release releases the resources held by the snapshot.
+func (s *BoltDBSnapshot) Release() {
+	// Releasing a read transaction can't fail in a way the caller could act upon
+	_ = s.db.releaseReadTx(s.tx)
+}
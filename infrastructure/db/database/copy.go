@@ -0,0 +1,126 @@
+package database
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+)
+
+// copyBatchSize is the amount of data that's written to the target
+// database in each transaction while copying a snapshot
+const copyBatchSize = 16 * 1024 * 1024
+
+// CopySnapshot copies all the data in the given snapshot into target.
+// It returns the amount of entries that were copied.
+func CopySnapshot(snapshot Snapshot, target Database) (entryCount uint64, err error) {
+	cursor, err := snapshot.Cursor(MakeBucket(nil))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close()
+
+	dbTx, err := target.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		// RollbackUnlessClosed is a no-op if the last batch was already committed
+		rollbackErr := dbTx.RollbackUnlessClosed()
+		if err == nil {
+			err = rollbackErr
+		}
+	}()
+
+	batchSize := 0
+	for ok := cursor.First(); ok; ok = cursor.Next() {
+		key, err := cursor.Key()
+		if err != nil {
+			return 0, err
+		}
+		value, err := cursor.Value()
+		if err != nil {
+			return 0, err
+		}
+		err = dbTx.Put(key, value)
+		if err != nil {
+			return 0, err
+		}
+		entryCount++
+
+		batchSize += len(key.Bytes()) + len(value)
+		if batchSize < copyBatchSize {
+			continue
+		}
+		err = dbTx.Commit()
+		if err != nil {
+			return 0, err
+		}
+		dbTx, err = target.Begin()
+		if err != nil {
+			return 0, err
+		}
+		batchSize = 0
+	}
+
+	err = dbTx.Commit()
+	if err != nil {
+		return 0, err
+	}
+	return entryCount, nil
+}
+
+// VerifySnapshotCopy makes sure that copy contains exactly the same
+// data as the given snapshot. It returns the amount of verified entries.
+func VerifySnapshotCopy(snapshot Snapshot, copy DataAccessor) (uint64, error) {
+	snapshotCursor, err := snapshot.Cursor(MakeBucket(nil))
+	if err != nil {
+		return 0, err
+	}
+	defer snapshotCursor.Close()
+
+	copyCursor, err := copy.Cursor(MakeBucket(nil))
+	if err != nil {
+		return 0, err
+	}
+	defer copyCursor.Close()
+
+	entryCount := uint64(0)
+	snapshotOK := snapshotCursor.First()
+	copyOK := copyCursor.First()
+	for ; snapshotOK && copyOK; snapshotOK, copyOK = snapshotCursor.Next(), copyCursor.Next() {
+		snapshotKey, err := snapshotCursor.Key()
+		if err != nil {
+			return 0, err
+		}
+		copyKey, err := copyCursor.Key()
+		if err != nil {
+			return 0, err
+		}
+		if !bytes.Equal(snapshotKey.Bytes(), copyKey.Bytes()) {
+			return 0, errors.Errorf("entry #%d of the copy has key %s instead of %s",
+				entryCount, copyKey, snapshotKey)
+		}
+
+		snapshotValue, err := snapshotCursor.Value()
+		if err != nil {
+			return 0, err
+		}
+		copyValue, err := copyCursor.Value()
+		if err != nil {
+			return 0, err
+		}
+		if !bytes.Equal(snapshotValue, copyValue) {
+			return 0, errors.Errorf("the value of key %s in the copy is different from its "+
+				"value in the snapshot", snapshotKey)
+		}
+		entryCount++
+	}
+
+	if snapshotOK {
+		return 0, errors.Errorf("the copy is missing entries: it only has %d entries", entryCount)
+	}
+	if copyOK {
+		return 0, errors.Errorf("the copy has more entries than the snapshot, which has %d entries", entryCount)
+	}
+	return entryCount, nil
+}
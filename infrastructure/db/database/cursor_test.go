@@ -343,3 +343,60 @@ func testCursorCloseFirstAndNext(t *testing.T, db database.Database, testName st
 		cursor.Next()
 	}()
 }
+
+func TestCursorIsSnapshot(t *testing.T) {
+	testForAllDatabaseTypes(t, "TestCursorIsSnapshot", testCursorIsSnapshot)
+}
+
+func testCursorIsSnapshot(t *testing.T, db database.Database, testName string) {
+	entries := populateDatabaseForTest(t, db, testName)
+	cursor := prepareCursorForTest(t, db, testName)
+	defer cursor.Close()
+
+	// Change the database after the cursor was opened, both directly and through a transaction
+	err := db.Put(entries[0].key, []byte("changed"))
+	if err != nil {
+		t.Fatalf("%s: Put unexpectedly failed: %s", testName, err)
+	}
+	dbTx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("%s: Begin unexpectedly failed: %s", testName, err)
+	}
+	err = dbTx.Delete(entries[1].key)
+	if err != nil {
+		t.Fatalf("%s: Delete unexpectedly failed: %s", testName, err)
+	}
+	err = dbTx.Put(database.MakeBucket(nil).Key([]byte("key10")), []byte("added"))
+	if err != nil {
+		t.Fatalf("%s: Put unexpectedly failed: %s", testName, err)
+	}
+	err = dbTx.Commit()
+	if err != nil {
+		t.Fatalf("%s: Commit unexpectedly failed: %s", testName, err)
+	}
+
+	// Make sure the cursor still sees exactly the entries from the time it was opened
+	for i, entry := range entries {
+		if !cursor.Next() {
+			t.Fatalf("%s: cursor unexpectedly done after %d entries", testName, i)
+		}
+		cursorKey, err := cursor.Key()
+		if err != nil {
+			t.Fatalf("%s: Key unexpectedly failed: %s", testName, err)
+		}
+		if !reflect.DeepEqual(cursorKey, entry.key) {
+			t.Fatalf("%s: Cursor returned wrong key. Want: %s, got: %s", testName, entry.key, cursorKey)
+		}
+		cursorValue, err := cursor.Value()
+		if err != nil {
+			t.Fatalf("%s: Value unexpectedly failed: %s", testName, err)
+		}
+		if !bytes.Equal(cursorValue, entry.value) {
+			t.Fatalf("%s: Cursor returned wrong value for key %s. Want: %s, got: %s",
+				testName, entry.key, entry.value, cursorValue)
+		}
+	}
+	if cursor.Next() {
+		t.Fatalf("%s: cursor unexpectedly sees an entry that was added after it was opened", testName)
+	}
+}
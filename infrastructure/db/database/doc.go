@@ -6,8 +6,9 @@ Overview
 This package provides a database layer to store and retrieve data in a simple
 and efficient manner.
 
-The current backend is ffldb, which makes use of leveldb, flat files, and strict
-checksums in key areas to ensure data integrity.
+Backends register themselves as drivers with RegisterDriver, and are opened by their
+type with Open. The available backends are ldb, which makes use of leveldb, bolt, which
+makes use of bbolt, and memdb, which keeps all data in memory and is mostly useful for tests.
 
 Implementors of additional backends are required to implement the following interfaces:
 
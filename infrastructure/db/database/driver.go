@@ -0,0 +1,89 @@
+package database
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Driver defines a database backend that kaspad can be configured to use.
+// Backends register themselves with RegisterDriver, usually in their init function.
+type Driver struct {
+	// DbType is the identifier used to select the backend, e.g. with --dbtype
+	DbType string
+
+	// Open opens the database in the given directory, creating it if it doesn't exist.
+	// Backends that don't keep their data on disk may ignore the path.
+	Open func(path string, cacheSizeMiB int) (Database, error)
+
+	// OpenReadOnly opens the existing database in the given directory for reading only.
+	// It's nil for backends that don't keep their data on disk.
+	OpenReadOnly func(path string, cacheSizeMiB int) (Database, error)
+
+	// Backup writes a verified backup of source, which must be a database of this backend,
+	// into the given directory, and returns the amount of entries in the backup.
+	// It's nil for backends that can't be backed up.
+	Backup func(source Database, path string) (entryCount uint64, err error)
+
+	// IsPersistent is false for backends that lose their data once the database is closed
+	IsPersistent bool
+}
+
+var (
+	drivers     = make(map[string]Driver)
+	driversLock sync.RWMutex
+)
+
+// RegisterDriver adds a backend to the available database drivers.
+// It returns an error if a driver of the same type is already registered.
+func RegisterDriver(driver Driver) error {
+	driversLock.Lock()
+	defer driversLock.Unlock()
+
+	if _, exists := drivers[driver.DbType]; exists {
+		return errors.Errorf("database driver %s is already registered", driver.DbType)
+	}
+	drivers[driver.DbType] = driver
+	return nil
+}
+
+// SupportedDrivers returns the types of all the registered drivers
+func SupportedDrivers() []string {
+	driversLock.RLock()
+	defer driversLock.RUnlock()
+
+	return supportedDrivers()
+}
+
+func supportedDrivers() []string {
+	dbTypes := make([]string, 0, len(drivers))
+	for dbType := range drivers {
+		dbTypes = append(dbTypes, dbType)
+	}
+	sort.Strings(dbTypes)
+	return dbTypes
+}
+
+// LookupDriver returns the driver of the given type
+func LookupDriver(dbType string) (Driver, error) {
+	driversLock.RLock()
+	defer driversLock.RUnlock()
+
+	driver, ok := drivers[dbType]
+	if !ok {
+		return Driver{}, errors.Errorf("unknown database type %s. Supported types are: %s",
+			dbType, strings.Join(supportedDrivers(), ", "))
+	}
+	return driver, nil
+}
+
+// Open opens the database of the given type in the given directory
+func Open(dbType string, path string, cacheSizeMiB int) (Database, error) {
+	driver, err := LookupDriver(dbType)
+	if err != nil {
+		return nil, err
+	}
+	return driver.Open(path, cacheSizeMiB)
+}
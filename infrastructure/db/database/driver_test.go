@@ -0,0 +1,67 @@
+package database_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/boltdb"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/memdb"
+)
+
+func TestDrivers(t *testing.T) {
+	expectedDrivers := []string{boltdb.DbType, ldb.DbType, memdb.DbType}
+	if !reflect.DeepEqual(database.SupportedDrivers(), expectedDrivers) {
+		t.Fatalf("Expected the supported drivers to be %v, but got %v",
+			expectedDrivers, database.SupportedDrivers())
+	}
+
+	err := database.RegisterDriver(database.Driver{DbType: ldb.DbType})
+	if err == nil {
+		t.Fatalf("Expected registering a driver twice to fail")
+	}
+
+	_, err = database.Open("unknown", "", 8)
+	if err == nil {
+		t.Fatalf("Expected opening an unknown database type to fail")
+	}
+
+	path, err := ioutil.TempDir("", "TestDrivers")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(path)
+
+	for _, dbType := range expectedDrivers {
+		db, err := database.Open(dbType, path, 8)
+		if err != nil {
+			t.Fatalf("Open %s: %s", dbType, err)
+		}
+		key := database.MakeBucket(nil).Key([]byte("key"))
+		err = db.Put(key, []byte("value"))
+		if err != nil {
+			t.Fatalf("Put into %s: %s", dbType, err)
+		}
+		err = db.Close()
+		if err != nil {
+			t.Fatalf("Close %s: %s", dbType, err)
+		}
+	}
+	memDB, err := database.Open(memdb.DbType, "", 8)
+	if err != nil {
+		t.Fatalf("Open %s: %s", memdb.DbType, err)
+	}
+	defer memDB.Close()
+	_, err = database.Backup(memdb.DbType, memDB, filepath.Join(path, "memdb-backup"))
+	if err == nil {
+		t.Fatalf("Expected backing up a %s database to fail", memdb.DbType)
+	}
+	_, err = database.Backup(boltdb.DbType, memDB, filepath.Join(path, "bolt-backup"))
+	if err == nil {
+		t.Fatalf("Expected backing up a database with the backup of another backend to fail")
+	}
+}
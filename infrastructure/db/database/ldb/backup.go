@@ -0,0 +1,77 @@
+package ldb
+
+import (
+	"os"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/pkg/errors"
+)
+
+// backupCacheSizeMiB is the cache size of the leveldb instance a backup is written into
+const backupCacheSizeMiB = 16
+
+// Backup writes a copy of all the data in source, as it is at the moment Backup
+// is called, into a new leveldb instance in the given directory. The source database
+// may keep being written to while the backup is in progress.
+//
+// Once written, the backup is reopened for reading only and compared against the
+// data it was copied from. If anything fails, the backup directory is removed.
+// Backup returns the amount of entries in the backup.
+func Backup(source database.Database, path string) (entryCount uint64, err error) {
+	snapshotter, ok := source.(database.Snapshotter)
+	if !ok {
+		return 0, errors.Errorf("the database does not support snapshots")
+	}
+
+	err = database.CreateEmptyBackupDirectory(path)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			removeErr := os.RemoveAll(path)
+			if removeErr != nil {
+				log.Errorf("Failed removing the failed backup in %s: %s", path, removeErr)
+			}
+		}
+	}()
+
+	snapshot, err := snapshotter.Snapshot()
+	if err != nil {
+		return 0, err
+	}
+	defer snapshot.Release()
+
+	log.Infof("Writing a database backup to %s", path)
+	backupDB, err := NewLevelDB(path, backupCacheSizeMiB)
+	if err != nil {
+		return 0, err
+	}
+	entryCount, err = database.CopySnapshot(snapshot, backupDB)
+	if err != nil {
+		backupDB.Close()
+		return 0, err
+	}
+	err = backupDB.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	log.Infof("Verifying the database backup in %s", path)
+	readOnlyBackupDB, err := NewLevelDBReadOnly(path, backupCacheSizeMiB)
+	if err != nil {
+		return 0, err
+	}
+	defer readOnlyBackupDB.Close()
+	verifiedEntryCount, err := database.VerifySnapshotCopy(snapshot, readOnlyBackupDB)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed verifying the backup in %s", path)
+	}
+	if verifiedEntryCount != entryCount {
+		return 0, errors.Errorf("the backup in %s has %d entries, but %d were written",
+			path, verifiedEntryCount, entryCount)
+	}
+
+	log.Infof("Finished writing a database backup of %d entries to %s", entryCount, path)
+	return entryCount, nil
+}
@@ -0,0 +1,68 @@
+package ldb
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+)
+
+func TestBackup(t *testing.T) {
+	ldb, teardownFunc := prepareDatabaseForTest(t, "TestBackup")
+	defer teardownFunc()
+
+	const entryCount = 1000
+	bucket := database.MakeBucket([]byte("bucket"))
+	for i := 0; i < entryCount; i++ {
+		err := ldb.Put(bucket.Key([]byte(fmt.Sprintf("key%d", i))), []byte(fmt.Sprintf("value%d", i)))
+		if err != nil {
+			t.Fatalf("Put: %s", err)
+		}
+	}
+
+	backupParentDir, err := ioutil.TempDir("", "TestBackup")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(backupParentDir)
+
+	backupPath := filepath.Join(backupParentDir, "backup")
+	backedUpEntryCount, err := Backup(ldb, backupPath)
+	if err != nil {
+		t.Fatalf("Backup: %+v", err)
+	}
+	if backedUpEntryCount != entryCount {
+		t.Fatalf("Expected %d entries in the backup, but got %d", entryCount, backedUpEntryCount)
+	}
+
+	backup, err := NewLevelDBReadOnly(backupPath, 8)
+	if err != nil {
+		t.Fatalf("NewLevelDBReadOnly: %s", err)
+	}
+	defer backup.Close()
+	value, err := backup.Get(bucket.Key([]byte("key17")))
+	if err != nil {
+		t.Fatalf("Get: %s", err)
+	}
+	if !reflect.DeepEqual(value, []byte("value17")) {
+		t.Fatalf("Unexpected value %s in the backup", value)
+	}
+	err = backup.Put(bucket.Key([]byte("key17")), []byte("changed"))
+	if err == nil {
+		t.Fatalf("Expected writing into a read-only database to fail")
+	}
+
+	// A backup must never be written into a directory that has anything in it
+	_, err = Backup(ldb, backupPath)
+	if err == nil {
+		t.Fatalf("Expected a backup into a non-empty directory to fail")
+	}
+	_, err = os.Stat(filepath.Join(backupPath, "CURRENT"))
+	if err != nil {
+		t.Fatalf("The failed backup changed the existing directory: %s", err)
+	}
+}
@@ -0,0 +1,25 @@
+package ldb
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+)
+
+// DbType is the type of the leveldb backend, as given by --dbtype
+const DbType = "ldb"
+
+func init() {
+	err := database.RegisterDriver(database.Driver{
+		DbType: DbType,
+		Open: func(path string, cacheSizeMiB int) (database.Database, error) {
+			return NewLevelDB(path, cacheSizeMiB)
+		},
+		OpenReadOnly: func(path string, cacheSizeMiB int) (database.Database, error) {
+			return NewLevelDBReadOnly(path, cacheSizeMiB)
+		},
+		Backup:       Backup,
+		IsPersistent: true,
+	})
+	if err != nil {
+		panic(err)
+	}
+}
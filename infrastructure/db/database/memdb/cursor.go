@@ -0,0 +1,130 @@
+package memdb
+
+import (
+	"bytes"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb/comparer"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/memdb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// MemDBCursor is a thin wrapper around skip list iterators.
+//
+// Like leveldb cursors, a MemDBCursor sees the data as it was when the cursor was
+// opened. The skip list doesn't support snapshots, so opening a cursor copies all
+// the entries of its bucket.
+type MemDBCursor struct {
+	iterator iterator.Iterator
+	bucket   *database.Bucket
+
+	isClosed bool
+}
+
+// Cursor begins a new cursor over the given prefix.
+func (db *MemDB) Cursor(bucket *database.Bucket) (database.Cursor, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.isClosed {
+		return nil, errors.New("cannot open a cursor over a closed database")
+	}
+
+	bucketRange := util.BytesPrefix(bucket.Path())
+	bucketCopy := memdb.New(comparer.DefaultComparer, 0)
+	iterator := db.db.NewIterator(bucketRange)
+	defer iterator.Release()
+	for iterator.Next() {
+		// memdb.Put never returns an error
+		_ = bucketCopy.Put(iterator.Key(), iterator.Value())
+	}
+
+	return &MemDBCursor{
+		iterator: bucketCopy.NewIterator(bucketRange),
+		bucket:   bucket,
+		isClosed: false,
+	}, nil
+}
+
+// Next moves the iterator to the next key/value pair. It returns whether the
+// iterator is exhausted. Panics if the cursor is closed.
+func (c *MemDBCursor) Next() bool {
+	if c.isClosed {
+		panic("cannot call next on a closed cursor")
+	}
+	return c.iterator.Next()
+}
+
+// First moves the iterator to the first key/value pair. It returns false if
+// such a pair does not exist. Panics if the cursor is closed.
+func (c *MemDBCursor) First() bool {
+	if c.isClosed {
+		panic("cannot call first on a closed cursor")
+	}
+	return c.iterator.First()
+}
+
+// Seek moves the iterator to the first key/value pair whose key is greater
+// than or equal to the given key. It returns ErrNotFound if such pair does not
+// exist.
+func (c *MemDBCursor) Seek(key *database.Key) error {
+	if c.isClosed {
+		return errors.New("cannot seek a closed cursor")
+	}
+
+	found := c.iterator.Seek(key.Bytes())
+	if !found {
+		return errors.Wrapf(database.ErrNotFound, "key %s not found", key)
+	}
+
+	// Use c.iterator.Key because c.Key removes the prefix from the key
+	currentKey := c.iterator.Key()
+	if currentKey == nil || !bytes.Equal(currentKey, key.Bytes()) {
+		return errors.Wrapf(database.ErrNotFound, "key %s not found", key)
+	}
+
+	return nil
+}
+
+// Key returns the key of the current key/value pair, or ErrNotFound if done.
+// Note that the key is trimmed to not include the prefix the cursor was opened
+// with.
+func (c *MemDBCursor) Key() (*database.Key, error) {
+	if c.isClosed {
+		return nil, errors.New("cannot get the key of a closed cursor")
+	}
+	fullKeyPath := c.iterator.Key()
+	if fullKeyPath == nil {
+		return nil, errors.Wrapf(database.ErrNotFound, "cannot get the "+
+			"key of an exhausted cursor")
+	}
+	suffix := bytes.TrimPrefix(fullKeyPath, c.bucket.Path())
+	return c.bucket.Key(copyBytes(suffix)), nil
+}
+
+// Value returns the value of the current key/value pair, or ErrNotFound if done.
+func (c *MemDBCursor) Value() ([]byte, error) {
+	if c.isClosed {
+		return nil, errors.New("cannot get the value of a closed cursor")
+	}
+	value := c.iterator.Value()
+	if value == nil {
+		return nil, errors.Wrapf(database.ErrNotFound, "cannot get the "+
+			"value of an exhausted cursor")
+	}
+	return copyBytes(value), nil
+}
+
+// Close releases associated resources.
+func (c *MemDBCursor) Close() error {
+	if c.isClosed {
+		return errors.New("cannot close an already closed cursor")
+	}
+	c.isClosed = true
+	c.iterator.Release()
+	c.iterator = nil
+	c.bucket = nil
+	return nil
+}
@@ -0,0 +1,21 @@
+package memdb
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+)
+
+// DbType is the type of the in-memory backend, as given by --dbtype
+const DbType = "memdb"
+
+func init() {
+	err := database.RegisterDriver(database.Driver{
+		DbType: DbType,
+		Open: func(_ string, _ int) (database.Database, error) {
+			return NewMemDB(), nil
+		},
+		IsPersistent: false,
+	})
+	if err != nil {
+		panic(err)
+	}
+}
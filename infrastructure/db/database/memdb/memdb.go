@@ -0,0 +1,148 @@
+package memdb
+
+import (
+	"sync"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb/comparer"
+	"github.com/syndtr/goleveldb/leveldb/memdb"
+)
+
+// minCompactionSize is the amount of memory that is allowed to be taken by overwritten and
+// deleted entries before the database is compacted
+const minCompactionSize = 16 * 1024 * 1024
+
+// MemDB is a database that keeps all of its data in memory. All the data is lost once it's closed.
+//
+// The data is kept in an ordered skip list. The skip list never frees the memory of overwritten
+// or deleted entries, so MemDB rebuilds it whenever these take more memory than the live entries.
+type MemDB struct {
+	// lock makes sure that transactions are committed atomically, and that no data is
+	// written while the database is being compacted
+	lock     sync.RWMutex
+	db       *memdb.DB
+	isClosed bool
+}
+
+// NewMemDB returns a new, empty, in-memory database
+func NewMemDB() *MemDB {
+	return &MemDB{
+		db: memdb.New(comparer.DefaultComparer, 0),
+	}
+}
+
+// Close closes the database and frees all of its data
+func (db *MemDB) Close() error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.isClosed {
+		return errors.New("cannot close an already closed database")
+	}
+	db.isClosed = true
+	db.db = nil
+	return nil
+}
+
+// Put sets the value for the given key. It overwrites
+// any previous value for that key.
+func (db *MemDB) Put(key *database.Key, value []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.isClosed {
+		return errors.New("cannot put into a closed database")
+	}
+	return db.put(key.Bytes(), value)
+}
+
+// Get gets the value for the given key. It returns
+// ErrNotFound if the given key does not exist.
+func (db *MemDB) Get(key *database.Key) ([]byte, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.isClosed {
+		return nil, errors.New("cannot get from a closed database")
+	}
+	value, err := db.db.Get(key.Bytes())
+	if err != nil {
+		if errors.Is(err, memdb.ErrNotFound) {
+			return nil, errors.Wrapf(database.ErrNotFound, "key %s not found", key)
+		}
+		return nil, errors.WithStack(err)
+	}
+	// The returned value points into the memory of the skip list, so it's copied
+	// to make sure the caller can't change the stored data
+	return copyBytes(value), nil
+}
+
+// Has returns true if the database does contains the
+// given key.
+func (db *MemDB) Has(key *database.Key) (bool, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.isClosed {
+		return false, errors.New("cannot has from a closed database")
+	}
+	return db.db.Contains(key.Bytes()), nil
+}
+
+// Delete deletes the value for the given key. Will not
+// return an error if the key doesn't exist.
+func (db *MemDB) Delete(key *database.Key) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.isClosed {
+		return errors.New("cannot delete from a closed database")
+	}
+	return db.delete(key.Bytes())
+}
+
+func (db *MemDB) put(key []byte, value []byte) error {
+	err := db.db.Put(key, value)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	db.compactIfRequired()
+	return nil
+}
+
+func (db *MemDB) delete(key []byte) error {
+	err := db.db.Delete(key)
+	if err != nil && !errors.Is(err, memdb.ErrNotFound) {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// compactIfRequired rebuilds the skip list if the memory taken by overwritten and deleted
+// entries exceeds the memory taken by the live ones. The caller must hold the write lock.
+func (db *MemDB) compactIfRequired() {
+	usedSize := db.db.Capacity() - db.db.Free()
+	liveSize := db.db.Size()
+	if usedSize-liveSize < minCompactionSize || usedSize-liveSize < liveSize {
+		return
+	}
+
+	compacted := memdb.New(comparer.DefaultComparer, liveSize)
+	iterator := db.db.NewIterator(nil)
+	defer iterator.Release()
+	for iterator.Next() {
+		// memdb.Put never returns an error
+		_ = compacted.Put(iterator.Key(), iterator.Value())
+	}
+	db.db = compacted
+}
+
+func copyBytes(bytes []byte) []byte {
+	if bytes == nil {
+		return nil
+	}
+	copied := make([]byte, len(bytes))
+	copy(copied, bytes)
+	return copied
+}
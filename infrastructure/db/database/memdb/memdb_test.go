@@ -0,0 +1,72 @@
+package memdb
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+)
+
+func TestMemDBCompaction(t *testing.T) {
+	db := NewMemDB()
+	defer db.Close()
+
+	// Overwrite a handful of keys until the overwritten values take several times
+	// the compaction threshold
+	const keyCount = 10
+	value := make([]byte, 64*1024)
+	bucket := database.MakeBucket([]byte("bucket"))
+	for i := 0; i < 4*minCompactionSize/len(value); i++ {
+		value[0] = byte(i)
+		err := db.Put(bucket.Key([]byte(fmt.Sprintf("key%d", i%keyCount))), value)
+		if err != nil {
+			t.Fatalf("Put: %s", err)
+		}
+	}
+
+	usedSize := db.db.Capacity() - db.db.Free()
+	if usedSize > 2*minCompactionSize+keyCount*len(value) {
+		t.Fatalf("The database takes %d bytes even though it holds only %d live bytes",
+			usedSize, db.db.Size())
+	}
+
+	cursor, err := db.Cursor(bucket)
+	if err != nil {
+		t.Fatalf("Cursor: %s", err)
+	}
+	defer cursor.Close()
+	count := 0
+	for cursor.Next() {
+		count++
+	}
+	if count != keyCount {
+		t.Fatalf("Expected %d keys after compaction, but got %d", keyCount, count)
+	}
+}
+
+func TestMemDBReturnsCopies(t *testing.T) {
+	db := NewMemDB()
+	defer db.Close()
+
+	key := database.MakeBucket(nil).Key([]byte("key"))
+	putData := []byte("Hello world!")
+	err := db.Put(key, putData)
+	if err != nil {
+		t.Fatalf("Put: %s", err)
+	}
+
+	getData, err := db.Get(key)
+	if err != nil {
+		t.Fatalf("Get: %s", err)
+	}
+	getData[0] = 'J'
+
+	getData, err = db.Get(key)
+	if err != nil {
+		t.Fatalf("Get: %s", err)
+	}
+	if !bytes.Equal(getData, putData) {
+		t.Fatalf("Modifying a value returned by Get changed the stored value to %s", getData)
+	}
+}
@@ -0,0 +1,69 @@
+package memdb
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb/comparer"
+	"github.com/syndtr/goleveldb/leveldb/memdb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// MemDBSnapshot is a read-only copy of a MemDB's data.
+//
+// The skip list doesn't support snapshots, so taking a snapshot copies
+// all the data, and temporarily doubles the memory taken by the database.
+type MemDBSnapshot struct {
+	db *memdb.DB
+}
+
+// Snapshot takes a snapshot of the database's current data.
+func (db *MemDB) Snapshot() (database.Snapshot, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.isClosed {
+		return nil, errors.New("cannot take a snapshot of a closed database")
+	}
+
+	snapshotDB := memdb.New(comparer.DefaultComparer, db.db.Size())
+	iterator := db.db.NewIterator(nil)
+	defer iterator.Release()
+	for iterator.Next() {
+		// memdb.Put never returns an error
+		_ = snapshotDB.Put(iterator.Key(), iterator.Value())
+	}
+	return &MemDBSnapshot{db: snapshotDB}, nil
+}
+
+// Get gets the value for the given key. It returns
+// ErrNotFound if the given key does not exist.
+func (s *MemDBSnapshot) Get(key *database.Key) ([]byte, error) {
+	value, err := s.db.Get(key.Bytes())
+	if err != nil {
+		if errors.Is(err, memdb.ErrNotFound) {
+			return nil, errors.Wrapf(database.ErrNotFound, "key %s not found", key)
+		}
+		return nil, errors.WithStack(err)
+	}
+	return copyBytes(value), nil
+}
+
+// Has returns true if the snapshot does contains the
+// given key.
+func (s *MemDBSnapshot) Has(key *database.Key) (bool, error) {
+	return s.db.Contains(key.Bytes()), nil
+}
+
+// Cursor begins a new cursor over the given prefix.
+func (s *MemDBSnapshot) Cursor(bucket *database.Bucket) (database.Cursor, error) {
+	return &MemDBCursor{
+		iterator: s.db.NewIterator(util.BytesPrefix(bucket.Path())),
+		bucket:   bucket,
+		isClosed: false,
+	}, nil
+}
+
+// Release releases the resources held by the snapshot.
+func (s *MemDBSnapshot) Release() {
+	s.db = nil
+}
@@ -0,0 +1,147 @@
+package database
+
+import "github.com/pkg/errors"
+
+// ErrReadOnly denotes that a write was attempted through a read-only
+// view of the database.
+var ErrReadOnly = errors.New("the database is read-only")
+
+// IsReadOnlyError checks whether an error is an ErrReadOnly.
+func IsReadOnlyError(err error) bool {
+	return errors.Is(err, ErrReadOnly)
+}
+
+type readOnlyDatabase struct {
+	database Database
+}
+
+// NewReadOnlyDatabase returns a view of the given database that fails every
+// write with ErrReadOnly. Transactions can be begun and written into, but are
+// only ever rolled back: committing one fails with ErrReadOnly.
+//
+// Closing the view doesn't close the underlying database.
+func NewReadOnlyDatabase(database Database) Database {
+	return &readOnlyDatabase{database: database}
+}
+
+// Put always returns ErrReadOnly.
+// This method is part of the DataAccessor interface.
+func (db *readOnlyDatabase) Put(key *Key, _ []byte) error {
+	return errors.Wrapf(ErrReadOnly, "cannot put %s", key)
+}
+
+// Get gets the value for the given key from the underlying database.
+// This method is part of the DataAccessor interface.
+func (db *readOnlyDatabase) Get(key *Key) ([]byte, error) {
+	return db.database.Get(key)
+}
+
+// Has returns true if the underlying database contains the given key.
+// This method is part of the DataAccessor interface.
+func (db *readOnlyDatabase) Has(key *Key) (bool, error) {
+	return db.database.Has(key)
+}
+
+// Delete always returns ErrReadOnly.
+// This method is part of the DataAccessor interface.
+func (db *readOnlyDatabase) Delete(key *Key) error {
+	return errors.Wrapf(ErrReadOnly, "cannot delete %s", key)
+}
+
+// Cursor begins a new cursor over the given bucket of the underlying database.
+// This method is part of the DataAccessor interface.
+func (db *readOnlyDatabase) Cursor(bucket *Bucket) (Cursor, error) {
+	return db.database.Cursor(bucket)
+}
+
+// Begin begins a transaction that can't be committed.
+// This method is part of the Database interface.
+func (db *readOnlyDatabase) Begin() (Transaction, error) {
+	return &readOnlyTransaction{database: db.database}, nil
+}
+
+// Close does nothing. The underlying database is closed by its owner.
+// This method is part of the Database interface.
+func (db *readOnlyDatabase) Close() error {
+	return nil
+}
+
+// readOnlyTransaction discards whatever is written into it. This adheres to the
+// Transaction interface, which doesn't guarantee that the data that's put into a
+// transaction is available to get within the same transaction.
+type readOnlyTransaction struct {
+	database Database
+	isClosed bool
+}
+
+// Put discards the given value.
+// This method is part of the DataAccessor interface.
+func (tx *readOnlyTransaction) Put(_ *Key, _ []byte) error {
+	if tx.isClosed {
+		return errors.New("cannot put into a closed transaction")
+	}
+	return nil
+}
+
+// Get gets the value for the given key from the underlying database.
+// This method is part of the DataAccessor interface.
+func (tx *readOnlyTransaction) Get(key *Key) ([]byte, error) {
+	if tx.isClosed {
+		return nil, errors.New("cannot get from a closed transaction")
+	}
+	return tx.database.Get(key)
+}
+
+// Has returns true if the underlying database contains the given key.
+// This method is part of the DataAccessor interface.
+func (tx *readOnlyTransaction) Has(key *Key) (bool, error) {
+	if tx.isClosed {
+		return false, errors.New("cannot has from a closed transaction")
+	}
+	return tx.database.Has(key)
+}
+
+// Delete discards the deletion of the given key.
+// This method is part of the DataAccessor interface.
+func (tx *readOnlyTransaction) Delete(_ *Key) error {
+	if tx.isClosed {
+		return errors.New("cannot delete from a closed transaction")
+	}
+	return nil
+}
+
+// Cursor begins a new cursor over the given bucket of the underlying database.
+// This method is part of the DataAccessor interface.
+func (tx *readOnlyTransaction) Cursor(bucket *Bucket) (Cursor, error) {
+	if tx.isClosed {
+		return nil, errors.New("cannot open a cursor from a closed transaction")
+	}
+	return tx.database.Cursor(bucket)
+}
+
+// Rollback closes the transaction.
+// This method is part of the Transaction interface.
+func (tx *readOnlyTransaction) Rollback() error {
+	if tx.isClosed {
+		return errors.New("cannot rollback a closed transaction")
+	}
+	tx.isClosed = true
+	return nil
+}
+
+// Commit closes the transaction and always returns ErrReadOnly.
+// This method is part of the Transaction interface.
+func (tx *readOnlyTransaction) Commit() error {
+	if tx.isClosed {
+		return errors.New("cannot commit a closed transaction")
+	}
+	tx.isClosed = true
+	return errors.Wrap(ErrReadOnly, "cannot commit a transaction")
+}
+
+// RollbackUnlessClosed closes the transaction unless it's already closed.
+// This method is part of the Transaction interface.
+func (tx *readOnlyTransaction) RollbackUnlessClosed() error {
+	tx.isClosed = true
+	return nil
+}
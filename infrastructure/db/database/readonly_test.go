@@ -0,0 +1,99 @@
+package database_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+)
+
+func TestReadOnlyDatabase(t *testing.T) {
+	testForAllDatabaseTypes(t, "TestReadOnlyDatabase", testReadOnlyDatabase)
+}
+
+func testReadOnlyDatabase(t *testing.T, db database.Database, testName string) {
+	entries := populateDatabaseForTest(t, db, testName)
+	readOnlyDB := database.NewReadOnlyDatabase(db)
+
+	// Reads go through to the underlying database
+	value, err := readOnlyDB.Get(entries[0].key)
+	if err != nil {
+		t.Fatalf("%s: Get unexpectedly failed: %s", testName, err)
+	}
+	if !bytes.Equal(value, entries[0].value) {
+		t.Fatalf("%s: Get returned wrong value. Want: %s, got: %s",
+			testName, entries[0].value, value)
+	}
+	cursor, err := readOnlyDB.Cursor(database.MakeBucket(nil))
+	if err != nil {
+		t.Fatalf("%s: Cursor unexpectedly failed: %s", testName, err)
+	}
+	entryCount := 0
+	for cursor.Next() {
+		entryCount++
+	}
+	cursor.Close()
+	if entryCount != len(entries) {
+		t.Fatalf("%s: the cursor returned %d entries instead of %d", testName, entryCount, len(entries))
+	}
+
+	// Writes fail and leave the underlying database untouched
+	err = readOnlyDB.Put(entries[0].key, []byte("other value"))
+	if !database.IsReadOnlyError(err) {
+		t.Fatalf("%s: Put unexpectedly returned %v instead of ErrReadOnly", testName, err)
+	}
+	err = readOnlyDB.Delete(entries[1].key)
+	if !database.IsReadOnlyError(err) {
+		t.Fatalf("%s: Delete unexpectedly returned %v instead of ErrReadOnly", testName, err)
+	}
+
+	// Transactions can be written into, but not committed
+	dbTx, err := readOnlyDB.Begin()
+	if err != nil {
+		t.Fatalf("%s: Begin unexpectedly failed: %s", testName, err)
+	}
+	err = dbTx.Put(entries[2].key, []byte("other value"))
+	if err != nil {
+		t.Fatalf("%s: Put into the transaction unexpectedly failed: %s", testName, err)
+	}
+	err = dbTx.Delete(entries[3].key)
+	if err != nil {
+		t.Fatalf("%s: Delete from the transaction unexpectedly failed: %s", testName, err)
+	}
+	value, err = dbTx.Get(entries[4].key)
+	if err != nil {
+		t.Fatalf("%s: Get from the transaction unexpectedly failed: %s", testName, err)
+	}
+	if !bytes.Equal(value, entries[4].value) {
+		t.Fatalf("%s: Get from the transaction returned wrong value. Want: %s, got: %s",
+			testName, entries[4].value, value)
+	}
+	err = dbTx.Commit()
+	if !database.IsReadOnlyError(err) {
+		t.Fatalf("%s: Commit unexpectedly returned %v instead of ErrReadOnly", testName, err)
+	}
+	err = dbTx.RollbackUnlessClosed()
+	if err != nil {
+		t.Fatalf("%s: RollbackUnlessClosed unexpectedly failed: %s", testName, err)
+	}
+
+	for _, entry := range entries {
+		value, err := db.Get(entry.key)
+		if err != nil {
+			t.Fatalf("%s: Get unexpectedly failed: %s", testName, err)
+		}
+		if !bytes.Equal(value, entry.value) {
+			t.Fatalf("%s: the value of %s changed through the read-only view", testName, entry.key)
+		}
+	}
+
+	// Closing the view doesn't close the underlying database
+	err = readOnlyDB.Close()
+	if err != nil {
+		t.Fatalf("%s: Close unexpectedly failed: %s", testName, err)
+	}
+	_, err = db.Get(entries[0].key)
+	if err != nil {
+		t.Fatalf("%s: Get after closing the view unexpectedly failed: %s", testName, err)
+	}
+}
@@ -0,0 +1,28 @@
+package database
+
+// Snapshot is a read-only view of a database's data as it was
+// at the moment the snapshot was taken. Changes made to the
+// database after that moment are not visible through it.
+type Snapshot interface {
+	// Get gets the value for the given key. It returns
+	// ErrNotFound if the given key does not exist.
+	Get(key *Key) ([]byte, error)
+
+	// Has returns true if the snapshot does contains the
+	// given key.
+	Has(key *Key) (bool, error)
+
+	// Cursor begins a new cursor over the given bucket.
+	Cursor(bucket *Bucket) (Cursor, error)
+
+	// Release releases the resources held by the snapshot.
+	// The snapshot must not be used after it's released.
+	Release()
+}
+
+// Snapshotter is implemented by databases that are able to
+// take snapshots of their data.
+type Snapshotter interface {
+	// Snapshot takes a snapshot of the database's current data.
+	Snapshot() (Snapshot, error)
+}
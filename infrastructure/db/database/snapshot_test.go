@@ -0,0 +1,83 @@
+package database_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/memdb"
+)
+
+func TestSnapshot(t *testing.T) {
+	testForAllDatabaseTypes(t, "TestSnapshot", testSnapshot)
+}
+
+func testSnapshot(t *testing.T, db database.Database, testName string) {
+	entries := populateDatabaseForTest(t, db, testName)
+
+	snapshot, err := db.(database.Snapshotter).Snapshot()
+	if err != nil {
+		t.Fatalf("%s: Snapshot unexpectedly failed: %s", testName, err)
+	}
+	defer snapshot.Release()
+
+	// Change the database after the snapshot was taken
+	err = db.Put(entries[0].key, []byte("changed"))
+	if err != nil {
+		t.Fatalf("%s: Put unexpectedly failed: %s", testName, err)
+	}
+	err = db.Delete(entries[1].key)
+	if err != nil {
+		t.Fatalf("%s: Delete unexpectedly failed: %s", testName, err)
+	}
+	addedKey := database.MakeBucket(nil).Key([]byte("added"))
+	err = db.Put(addedKey, []byte("added"))
+	if err != nil {
+		t.Fatalf("%s: Put unexpectedly failed: %s", testName, err)
+	}
+
+	// Make sure none of the changes are visible through the snapshot
+	value, err := snapshot.Get(entries[0].key)
+	if err != nil {
+		t.Fatalf("%s: Get unexpectedly failed: %s", testName, err)
+	}
+	if !bytes.Equal(value, entries[0].value) {
+		t.Fatalf("%s: the snapshot sees a value that was put after it was taken", testName)
+	}
+	exists, err := snapshot.Has(entries[1].key)
+	if err != nil {
+		t.Fatalf("%s: Has unexpectedly failed: %s", testName, err)
+	}
+	if !exists {
+		t.Fatalf("%s: the snapshot doesn't see a key that was deleted after it was taken", testName)
+	}
+	_, err = snapshot.Get(addedKey)
+	if !database.IsNotFoundError(err) {
+		t.Fatalf("%s: expected Get of a key added after the snapshot to return "+
+			"ErrNotFound, but got: %v", testName, err)
+	}
+
+	// Copy the snapshot into a new database and make sure the copy passes verification
+	target := memdb.NewMemDB()
+	defer target.Close()
+	copiedEntryCount, err := database.CopySnapshot(snapshot, target)
+	if err != nil {
+		t.Fatalf("%s: CopySnapshot unexpectedly failed: %s", testName, err)
+	}
+	if copiedEntryCount != uint64(len(entries)) {
+		t.Fatalf("%s: expected %d entries to be copied, but got %d", testName, len(entries), copiedEntryCount)
+	}
+	verifiedEntryCount, err := database.VerifySnapshotCopy(snapshot, target)
+	if err != nil {
+		t.Fatalf("%s: VerifySnapshotCopy unexpectedly failed: %s", testName, err)
+	}
+	if verifiedEntryCount != copiedEntryCount {
+		t.Fatalf("%s: expected %d entries to be verified, but got %d", testName, copiedEntryCount, verifiedEntryCount)
+	}
+
+	// The database itself no longer matches the snapshot, so it must fail verification
+	_, err = database.VerifySnapshotCopy(snapshot, db)
+	if err == nil {
+		t.Fatalf("%s: expected VerifySnapshotCopy of a different database to fail", testName)
+	}
+}
@@ -0,0 +1,14 @@
+/*
+Package migration upgrades the on-disk format of the database from one version to the next.
+
+A Migration upgrades the database from a single version to the one after it. It's run in
+steps, each in its own transaction. Together with the changes of each step, the position
+the next step should start from is committed as a progress marker. If kaspad stops in the
+middle of a migration, the next run resumes from the last committed step, so a migration
+never sees a half-written step and never migrates the same data twice.
+
+The progress marker also records the version the database was migrated to, so the version
+that's recorded elsewhere (kaspad keeps it in a file next to the database) can be updated
+after Migrate returns, followed by a call to ClearProgress.
+*/
+package migration
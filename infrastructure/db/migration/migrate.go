@@ -0,0 +1,203 @@
+package migration
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/pkg/errors"
+)
+
+var progressKey = database.MakeBucket(nil).Key([]byte("migration-progress"))
+
+// progress is the progress marker that's committed together with each step. A nil
+// position means that the migration from version hasn't started yet.
+type progress struct {
+	version  int
+	position []byte
+}
+
+func serializeProgress(p *progress) []byte {
+	serializedProgress := make([]byte, 4+len(p.position))
+	binary.LittleEndian.PutUint32(serializedProgress[:4], uint32(p.version))
+	copy(serializedProgress[4:], p.position)
+	return serializedProgress
+}
+
+func deserializeProgress(serializedProgress []byte) (*progress, error) {
+	if len(serializedProgress) < 4 {
+		return nil, errors.Errorf("malformed migration progress of length %d", len(serializedProgress))
+	}
+	p := &progress{version: int(binary.LittleEndian.Uint32(serializedProgress[:4]))}
+	if len(serializedProgress) > 4 {
+		p.position = serializedProgress[4:]
+	}
+	return p, nil
+}
+
+func readProgress(dataAccessor database.DataAccessor) (p *progress, found bool, err error) {
+	serializedProgress, err := dataAccessor.Get(progressKey)
+	if err != nil {
+		if database.IsNotFoundError(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	p, err = deserializeProgress(serializedProgress)
+	if err != nil {
+		return nil, false, err
+	}
+	return p, true, nil
+}
+
+// resumePoint returns the version the database is actually at, and the position to
+// resume the migration from that version at, according to the progress marker
+func resumePoint(db database.Database, databaseVersion int) (version int, position []byte, err error) {
+	p, found, err := readProgress(db)
+	if err != nil {
+		return 0, nil, err
+	}
+	if !found {
+		return databaseVersion, nil, nil
+	}
+	if p.version < databaseVersion {
+		return 0, nil, errors.Errorf("the migration progress of the database is at version %d, "+
+			"but the database version is %d", p.version, databaseVersion)
+	}
+	return p.version, p.position, nil
+}
+
+// Migrate runs the migrations that bring db from databaseVersion to targetVersion,
+// resuming an interrupted migration if there is one.
+//
+// Once Migrate returns successfully, the caller should record targetVersion as the
+// version of the database, and then call ClearProgress.
+func (m *Migrator) Migrate(db database.Database, databaseVersion int, targetVersion int) error {
+	version, position, err := resumePoint(db, databaseVersion)
+	if err != nil {
+		return err
+	}
+	pendingMigrations, err := m.PendingMigrations(version, targetVersion)
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range pendingMigrations {
+		if len(position) > 0 {
+			log.Infof("Resuming the database migration from version %d: %s",
+				migration.FromVersion, migration.Description)
+		} else {
+			log.Infof("Migrating the database from version %d: %s", migration.FromVersion, migration.Description)
+		}
+
+		stepCount, err := runMigration(db, migration, position)
+		if err != nil {
+			return err
+		}
+		log.Infof("Migrated the database to version %d in %d steps", migration.FromVersion+1, stepCount)
+
+		position = nil
+	}
+
+	return nil
+}
+
+func runMigration(db database.Database, migration *Migration, position []byte) (stepCount int, err error) {
+	for {
+		nextPosition, err := runStep(db, migration, position, true)
+		if err != nil {
+			return 0, err
+		}
+		stepCount++
+
+		if len(nextPosition) == 0 {
+			return stepCount, nil
+		}
+		position = nextPosition
+	}
+}
+
+// runStep runs a single step of the migration. The step is committed along with the
+// progress marker only if shouldCommit is true.
+func runStep(db database.Database, migration *Migration, position []byte, shouldCommit bool) (
+	nextPosition []byte, err error) {
+
+	dbTx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		rollbackErr := dbTx.RollbackUnlessClosed()
+		if err == nil {
+			err = rollbackErr
+		}
+	}()
+
+	nextPosition, err = migration.Step(dbTx, position)
+	if err != nil {
+		return nil, errors.Wrapf(err, "the migration from database version %d failed", migration.FromVersion)
+	}
+	if len(nextPosition) > 0 && bytes.Equal(nextPosition, position) {
+		return nil, errors.Errorf("a step of the migration from database version %d didn't advance "+
+			"from its position", migration.FromVersion)
+	}
+
+	if !shouldCommit {
+		return nextPosition, nil
+	}
+
+	p := &progress{version: migration.FromVersion, position: nextPosition}
+	if len(nextPosition) == 0 {
+		p = &progress{version: migration.FromVersion + 1}
+	}
+	err = dbTx.Put(progressKey, serializeProgress(p))
+	if err != nil {
+		return nil, err
+	}
+	err = dbTx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return nextPosition, nil
+}
+
+// DryRun returns the migrations that Migrate would run, and runs all the steps of the
+// first of them without committing anything, to make sure that it succeeds. The
+// following migrations can't be tried this way, since they expect the database
+// to be in the format the first migration writes.
+//
+// It returns the amount of steps the first migration took, or 0 if there's nothing to migrate.
+func (m *Migrator) DryRun(db database.Database, databaseVersion int, targetVersion int) (
+	pendingMigrations []*Migration, stepCount int, err error) {
+
+	version, position, err := resumePoint(db, databaseVersion)
+	if err != nil {
+		return nil, 0, err
+	}
+	pendingMigrations, err = m.PendingMigrations(version, targetVersion)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(pendingMigrations) == 0 {
+		return pendingMigrations, 0, nil
+	}
+
+	for {
+		nextPosition, err := runStep(db, pendingMigrations[0], position, false)
+		if err != nil {
+			return nil, 0, err
+		}
+		stepCount++
+
+		if len(nextPosition) == 0 {
+			return pendingMigrations, stepCount, nil
+		}
+		position = nextPosition
+	}
+}
+
+// ClearProgress removes the progress marker of the migrations. It should be called after
+// the version the database was migrated to is recorded.
+func ClearProgress(db database.Database) error {
+	return db.Delete(progressKey)
+}
@@ -0,0 +1,59 @@
+package migration
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/pkg/errors"
+)
+
+// StepFunc migrates the next part of the database. position is where the previous step
+// stopped, or nil for the first step. All the changes of the step must be written into
+// dbTx, which is committed together with the returned position. A step returns an empty
+// position once there's nothing left to migrate.
+//
+// Note that reading from dbTx returns the data as it was before the step started.
+type StepFunc func(dbTx database.Transaction, position []byte) (nextPosition []byte, err error)
+
+// Migration upgrades the database from version FromVersion to version FromVersion+1
+type Migration struct {
+	FromVersion int
+	Description string
+	Step        StepFunc
+}
+
+// Migrator runs the migrations that are needed to bring a database to the current version
+type Migrator struct {
+	migrations map[int]*Migration
+}
+
+// NewMigrator returns a Migrator for the given migrations. There can be at most one
+// migration from each version.
+func NewMigrator(migrations ...*Migration) (*Migrator, error) {
+	migrationsByVersion := make(map[int]*Migration, len(migrations))
+	for _, migration := range migrations {
+		if _, ok := migrationsByVersion[migration.FromVersion]; ok {
+			return nil, errors.Errorf("more than one migration from version %d", migration.FromVersion)
+		}
+		migrationsByVersion[migration.FromVersion] = migration
+	}
+	return &Migrator{migrations: migrationsByVersion}, nil
+}
+
+// PendingMigrations returns, in order, the migrations that bring a database at
+// databaseVersion to targetVersion. It returns an error if any of them is missing.
+func (m *Migrator) PendingMigrations(databaseVersion int, targetVersion int) ([]*Migration, error) {
+	if databaseVersion > targetVersion {
+		return nil, errors.Errorf("the database version %d is newer than the version %d this kaspad "+
+			"supports", databaseVersion, targetVersion)
+	}
+
+	pendingMigrations := make([]*Migration, 0, targetVersion-databaseVersion)
+	for version := databaseVersion; version < targetVersion; version++ {
+		migration, ok := m.migrations[version]
+		if !ok {
+			return nil, errors.Errorf("there's no migration from database version %d to version %d. "+
+				"Restart kaspad with --reset-db to resync the database", version, version+1)
+		}
+		pendingMigrations = append(pendingMigrations, migration)
+	}
+	return pendingMigrations, nil
+}
@@ -0,0 +1,393 @@
+package migration
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
+	"github.com/pkg/errors"
+)
+
+// The fixture database is at version 1, where balances are stored as decimal strings
+// in the accounts bucket. Version 2 stores them as uint64s, and version 3 moves them
+// into the balances bucket.
+var accountsBucket = database.MakeBucket([]byte("accounts"))
+var balancesBucket = database.MakeBucket([]byte("balances"))
+
+const fixtureAccountCount = 10
+
+func fixtureAccountKey(i int) []byte {
+	return []byte(fmt.Sprintf("account-%02d", i))
+}
+
+func fixtureBalance(i int) uint64 {
+	return uint64(i * 1000)
+}
+
+func createFixtureDB(t *testing.T) (path string) {
+	path, err := ioutil.TempDir("", "migration-fixture")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	db, err := ldb.NewLevelDB(path, 8)
+	if err != nil {
+		t.Fatalf("NewLevelDB: %+v", err)
+	}
+	defer db.Close()
+
+	for i := 0; i < fixtureAccountCount; i++ {
+		err := db.Put(accountsBucket.Key(fixtureAccountKey(i)), []byte(strconv.FormatUint(fixtureBalance(i), 10)))
+		if err != nil {
+			t.Fatalf("Put: %+v", err)
+		}
+	}
+	return path
+}
+
+func openFixtureDB(t *testing.T, path string) database.Database {
+	db, err := ldb.NewLevelDB(path, 8)
+	if err != nil {
+		t.Fatalf("NewLevelDB: %+v", err)
+	}
+	return db
+}
+
+const accountsPerStep = 3
+
+// encodeBalances converts the balances to uint64s in place, and continues from
+// the last account that the previous step converted
+func encodeBalances(dbTx database.Transaction, position []byte) ([]byte, error) {
+	cursor, err := dbTx.Cursor(accountsBucket)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close()
+
+	ok := cursor.First()
+	if len(position) > 0 {
+		err := cursor.Seek(accountsBucket.Key(position))
+		if err != nil {
+			return nil, err
+		}
+		ok = cursor.Next()
+	}
+
+	var lastAccount []byte
+	for converted := 0; ok && converted < accountsPerStep; ok = cursor.Next() {
+		key, err := cursor.Key()
+		if err != nil {
+			return nil, err
+		}
+		value, err := cursor.Value()
+		if err != nil {
+			return nil, err
+		}
+		balance, err := strconv.ParseUint(string(value), 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "the balance of %s was already converted", key.Suffix())
+		}
+		encodedBalance := make([]byte, 8)
+		binary.LittleEndian.PutUint64(encodedBalance, balance)
+		err = dbTx.Put(accountsBucket.Key(key.Suffix()), encodedBalance)
+		if err != nil {
+			return nil, err
+		}
+
+		lastAccount = append([]byte{}, key.Suffix()...)
+		converted++
+	}
+	if !ok {
+		return nil, nil
+	}
+	return lastAccount, nil
+}
+
+// moveBalances moves the balances into the balances bucket. Since the moved balances are
+// deleted, each step starts from the first account, and the position only counts the steps.
+func moveBalances(dbTx database.Transaction, position []byte) ([]byte, error) {
+	cursor, err := dbTx.Cursor(accountsBucket)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close()
+
+	ok := cursor.First()
+	for moved := 0; ok && moved < accountsPerStep; ok = cursor.Next() {
+		key, err := cursor.Key()
+		if err != nil {
+			return nil, err
+		}
+		value, err := cursor.Value()
+		if err != nil {
+			return nil, err
+		}
+		err = dbTx.Put(balancesBucket.Key(key.Suffix()), value)
+		if err != nil {
+			return nil, err
+		}
+		err = dbTx.Delete(accountsBucket.Key(key.Suffix()))
+		if err != nil {
+			return nil, err
+		}
+		moved++
+	}
+	if !ok {
+		return nil, nil
+	}
+	stepCount := byte(0)
+	if len(position) > 0 {
+		stepCount = position[0]
+	}
+	return []byte{stepCount + 1}, nil
+}
+
+var errCrash = errors.New("crash")
+
+// crashAfter returns a step that fails after the given amount of successful steps,
+// as if kaspad was stopped in the middle of the migration
+func crashAfter(step StepFunc, successfulSteps int) StepFunc {
+	return func(dbTx database.Transaction, position []byte) ([]byte, error) {
+		if successfulSteps == 0 {
+			return nil, errCrash
+		}
+		successfulSteps--
+		return step(dbTx, position)
+	}
+}
+
+func newTestMigrator(t *testing.T, encode StepFunc, move StepFunc) *Migrator {
+	migrator, err := NewMigrator(
+		&Migration{FromVersion: 1, Description: "Encode balances as uint64s", Step: encode},
+		&Migration{FromVersion: 2, Description: "Move balances into their own bucket", Step: move},
+	)
+	if err != nil {
+		t.Fatalf("NewMigrator: %+v", err)
+	}
+	return migrator
+}
+
+func checkMigratedBalances(t *testing.T, db database.Database) {
+	for i := 0; i < fixtureAccountCount; i++ {
+		value, err := db.Get(balancesBucket.Key(fixtureAccountKey(i)))
+		if err != nil {
+			t.Fatalf("Get balance %d: %+v", i, err)
+		}
+		if len(value) != 8 || binary.LittleEndian.Uint64(value) != fixtureBalance(i) {
+			t.Fatalf("Unexpected balance %x for account %d", value, i)
+		}
+	}
+	cursor, err := db.Cursor(accountsBucket)
+	if err != nil {
+		t.Fatalf("Cursor: %+v", err)
+	}
+	defer cursor.Close()
+	if cursor.First() {
+		t.Fatalf("Expected the accounts bucket to be empty after the migration")
+	}
+}
+
+func checkProgressVersion(t *testing.T, db database.Database, expectedVersion int) {
+	p, found, err := readProgress(db)
+	if err != nil {
+		t.Fatalf("readProgress: %+v", err)
+	}
+	if !found {
+		t.Fatalf("Expected a migration progress marker")
+	}
+	if p.version != expectedVersion {
+		t.Fatalf("Expected the migration progress to be at version %d, but it's at %d", expectedVersion, p.version)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	path := createFixtureDB(t)
+	defer os.RemoveAll(path)
+	db := openFixtureDB(t, path)
+	defer db.Close()
+
+	migrator := newTestMigrator(t, encodeBalances, moveBalances)
+	err := migrator.Migrate(db, 1, 3)
+	if err != nil {
+		t.Fatalf("Migrate: %+v", err)
+	}
+	checkMigratedBalances(t, db)
+	checkProgressVersion(t, db, 3)
+
+	err = ClearProgress(db)
+	if err != nil {
+		t.Fatalf("ClearProgress: %+v", err)
+	}
+	_, found, err := readProgress(db)
+	if err != nil {
+		t.Fatalf("readProgress: %+v", err)
+	}
+	if found {
+		t.Fatalf("Expected ClearProgress to remove the progress marker")
+	}
+
+	// A database at the target version has nothing to migrate
+	err = migrator.Migrate(db, 3, 3)
+	if err != nil {
+		t.Fatalf("Migrate: %+v", err)
+	}
+	checkMigratedBalances(t, db)
+}
+
+func TestMigrateResumesAfterCrash(t *testing.T) {
+	path := createFixtureDB(t)
+	defer os.RemoveAll(path)
+
+	// Crash in the middle of the first migration. Converting a balance twice fails,
+	// so resuming from anywhere but the last committed step would fail.
+	db := openFixtureDB(t, path)
+	err := newTestMigrator(t, crashAfter(encodeBalances, 2), moveBalances).Migrate(db, 1, 3)
+	if !errors.Is(err, errCrash) {
+		t.Fatalf("Expected the migration to crash, got: %+v", err)
+	}
+	checkProgressVersion(t, db, 1)
+	db.Close()
+
+	// Crash in the middle of the second migration. The version of the database is
+	// still recorded as 1, but the progress marker knows it was migrated to 2.
+	db = openFixtureDB(t, path)
+	err = newTestMigrator(t, encodeBalances, crashAfter(moveBalances, 1)).Migrate(db, 1, 3)
+	if !errors.Is(err, errCrash) {
+		t.Fatalf("Expected the migration to crash, got: %+v", err)
+	}
+	checkProgressVersion(t, db, 2)
+	db.Close()
+
+	db = openFixtureDB(t, path)
+	defer db.Close()
+	err = newTestMigrator(t, encodeBalances, moveBalances).Migrate(db, 1, 3)
+	if err != nil {
+		t.Fatalf("Migrate: %+v", err)
+	}
+	checkMigratedBalances(t, db)
+	checkProgressVersion(t, db, 3)
+}
+
+func TestDryRun(t *testing.T) {
+	path := createFixtureDB(t)
+	defer os.RemoveAll(path)
+	db := openFixtureDB(t, path)
+	defer db.Close()
+
+	migrator := newTestMigrator(t, encodeBalances, moveBalances)
+	pendingMigrations, stepCount, err := migrator.DryRun(db, 1, 3)
+	if err != nil {
+		t.Fatalf("DryRun: %+v", err)
+	}
+	if len(pendingMigrations) != 2 {
+		t.Fatalf("Expected 2 pending migrations, but got %d", len(pendingMigrations))
+	}
+	expectedStepCount := fixtureAccountCount/accountsPerStep + 1
+	if stepCount != expectedStepCount {
+		t.Fatalf("Expected the dry run to take %d steps, but it took %d", expectedStepCount, stepCount)
+	}
+
+	// Nothing was committed, so the real migration starts from the fixture data
+	_, found, err := readProgress(db)
+	if err != nil {
+		t.Fatalf("readProgress: %+v", err)
+	}
+	if found {
+		t.Fatalf("Expected the dry run to not write a progress marker")
+	}
+	err = migrator.Migrate(db, 1, 3)
+	if err != nil {
+		t.Fatalf("Migrate: %+v", err)
+	}
+	checkMigratedBalances(t, db)
+
+	// A failing migration fails the dry run too
+	path = createFixtureDB(t)
+	defer os.RemoveAll(path)
+	failingDB := openFixtureDB(t, path)
+	defer failingDB.Close()
+	_, _, err = newTestMigrator(t, crashAfter(encodeBalances, 2), moveBalances).DryRun(failingDB, 1, 3)
+	if !errors.Is(err, errCrash) {
+		t.Fatalf("Expected the dry run to fail, got: %+v", err)
+	}
+}
+
+func TestDryRunOnReadOnlyDatabase(t *testing.T) {
+	path := createFixtureDB(t)
+	defer os.RemoveAll(path)
+	filesBefore := readDirectoryFiles(t, path)
+
+	db, err := ldb.NewLevelDBReadOnly(path, 8)
+	if err != nil {
+		t.Fatalf("NewLevelDBReadOnly: %+v", err)
+	}
+	migrator := newTestMigrator(t, encodeBalances, moveBalances)
+	_, stepCount, err := migrator.DryRun(database.NewReadOnlyDatabase(db), 1, 3)
+	if err != nil {
+		t.Fatalf("DryRun: %+v", err)
+	}
+	expectedStepCount := fixtureAccountCount/accountsPerStep + 1
+	if stepCount != expectedStepCount {
+		t.Fatalf("Expected the dry run to take %d steps, but it took %d", expectedStepCount, stepCount)
+	}
+	err = db.Close()
+	if err != nil {
+		t.Fatalf("Close: %+v", err)
+	}
+
+	filesAfter := readDirectoryFiles(t, path)
+	if !reflect.DeepEqual(filesBefore, filesAfter) {
+		t.Fatalf("The dry run changed the files of the database")
+	}
+}
+
+// readDirectoryFiles returns the contents of all the files in the given directory by their names
+func readDirectoryFiles(t *testing.T, path string) map[string]string {
+	fileInfos, err := ioutil.ReadDir(path)
+	if err != nil {
+		t.Fatalf("ReadDir: %s", err)
+	}
+	files := make(map[string]string, len(fileInfos))
+	for _, fileInfo := range fileInfos {
+		content, err := ioutil.ReadFile(filepath.Join(path, fileInfo.Name()))
+		if err != nil {
+			t.Fatalf("ReadFile: %s", err)
+		}
+		files[fileInfo.Name()] = string(content)
+	}
+	return files
+}
+
+func TestPendingMigrations(t *testing.T) {
+	_, err := NewMigrator(
+		&Migration{FromVersion: 1, Step: encodeBalances},
+		&Migration{FromVersion: 1, Step: moveBalances},
+	)
+	if err == nil {
+		t.Fatalf("Expected NewMigrator to fail for two migrations from the same version")
+	}
+
+	migrator := newTestMigrator(t, encodeBalances, moveBalances)
+	pendingMigrations, err := migrator.PendingMigrations(2, 3)
+	if err != nil {
+		t.Fatalf("PendingMigrations: %+v", err)
+	}
+	if len(pendingMigrations) != 1 || pendingMigrations[0].FromVersion != 2 {
+		t.Fatalf("Expected only the migration from version 2 to be pending")
+	}
+
+	_, err = migrator.PendingMigrations(0, 3)
+	if err == nil {
+		t.Fatalf("Expected PendingMigrations to fail when a migration is missing")
+	}
+	_, err = migrator.PendingMigrations(4, 3)
+	if err == nil {
+		t.Fatalf("Expected PendingMigrations to fail for a database that is newer than the target")
+	}
+}
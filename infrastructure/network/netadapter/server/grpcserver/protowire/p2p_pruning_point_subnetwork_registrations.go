@@ -0,0 +1,51 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_PruningPointSubnetworkRegistrations) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_PruningPointSubnetworkRegistrations is nil")
+	}
+	return x.PruningPointSubnetworkRegistrations.toAppMessage()
+}
+
+func (x *PruningPointSubnetworkRegistrationsMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "PruningPointSubnetworkRegistrationsMessage is nil")
+	}
+	registrations := make([]*externalapi.SubnetworkRegistration, len(x.Registrations))
+	for i, registration := range x.Registrations {
+		if registration == nil {
+			return nil, errors.Wrapf(errorNil, "SubnetworkRegistration is nil")
+		}
+		subnetworkID, err := registration.SubnetworkId.toDomain()
+		if err != nil {
+			return nil, err
+		}
+		registrations[i] = &externalapi.SubnetworkRegistration{
+			SubnetworkID: *subnetworkID,
+			GasLimit:     registration.GasLimit,
+		}
+	}
+	return &appmessage.MsgPruningPointSubnetworkRegistrations{Registrations: registrations}, nil
+}
+
+func (x *KaspadMessage_PruningPointSubnetworkRegistrations) fromAppMessage(
+	msgPruningPointSubnetworkRegistrations *appmessage.MsgPruningPointSubnetworkRegistrations) error {
+
+	registrations := make([]*SubnetworkRegistration, len(msgPruningPointSubnetworkRegistrations.Registrations))
+	for i, registration := range msgPruningPointSubnetworkRegistrations.Registrations {
+		registrations[i] = &SubnetworkRegistration{
+			SubnetworkId: domainSubnetworkIDToProto(&registration.SubnetworkID),
+			GasLimit:     registration.GasLimit,
+		}
+	}
+	x.PruningPointSubnetworkRegistrations = &PruningPointSubnetworkRegistrationsMessage{
+		Registrations: registrations,
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_BackupDatabaseRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_BackupDatabaseRequest is nil")
+	}
+	return x.BackupDatabaseRequest.toAppMessage()
+}
+
+func (x *KaspadMessage_BackupDatabaseRequest) fromAppMessage(message *appmessage.BackupDatabaseRequestMessage) error {
+	x.BackupDatabaseRequest = &BackupDatabaseRequestMessage{
+		Path: message.Path,
+	}
+	return nil
+}
+
+func (x *BackupDatabaseRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "BackupDatabaseRequestMessage is nil")
+	}
+	return &appmessage.BackupDatabaseRequestMessage{
+		Path: x.Path,
+	}, nil
+}
+
+func (x *KaspadMessage_BackupDatabaseResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_BackupDatabaseResponse is nil")
+	}
+	return x.BackupDatabaseResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_BackupDatabaseResponse) fromAppMessage(message *appmessage.BackupDatabaseResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	x.BackupDatabaseResponse = &BackupDatabaseResponseMessage{
+		Path:  message.Path,
+		Error: err,
+	}
+	return nil
+}
+
+func (x *BackupDatabaseResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "BackupDatabaseResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	return &appmessage.BackupDatabaseResponseMessage{
+		Path:  x.Path,
+		Error: rpcErr,
+	}, nil
+}
+
+func (x *KaspadMessage_GetDatabaseBackupStatusRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetDatabaseBackupStatusRequest is nil")
+	}
+	return &appmessage.GetDatabaseBackupStatusRequestMessage{}, nil
+}
+
+func (x *KaspadMessage_GetDatabaseBackupStatusRequest) fromAppMessage(_ *appmessage.GetDatabaseBackupStatusRequestMessage) error {
+	x.GetDatabaseBackupStatusRequest = &GetDatabaseBackupStatusRequestMessage{}
+	return nil
+}
+
+func (x *KaspadMessage_GetDatabaseBackupStatusResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetDatabaseBackupStatusResponse is nil")
+	}
+	return x.GetDatabaseBackupStatusResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_GetDatabaseBackupStatusResponse) fromAppMessage(message *appmessage.GetDatabaseBackupStatusResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	x.GetDatabaseBackupStatusResponse = &GetDatabaseBackupStatusResponseMessage{
+		Path:       message.Path,
+		IsRunning:  message.IsRunning,
+		EntryCount: message.EntryCount,
+		Failure:    message.Failure,
+		Error:      err,
+	}
+	return nil
+}
+
+func (x *GetDatabaseBackupStatusResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetDatabaseBackupStatusResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	return &appmessage.GetDatabaseBackupStatusResponseMessage{
+		Path:       x.Path,
+		IsRunning:  x.IsRunning,
+		EntryCount: x.EntryCount,
+		Failure:    x.Failure,
+		Error:      rpcErr,
+	}, nil
+}
@@ -36,17 +36,39 @@ func (x *KaspadMessage_GetBlockTemplateResponse) toAppMessage() (appmessage.Mess
 }
 
 func (x *KaspadMessage_GetBlockTemplateResponse) fromAppMessage(message *appmessage.GetBlockTemplateResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	var block *RpcBlock
+	if message.Block != nil {
+		protoBlock := &RpcBlock{}
+		err := protoBlock.fromAppMessage(message.Block)
+		if err != nil {
+			return err
+		}
+		block = protoBlock
+	}
 	x.GetBlockTemplateResponse = &GetBlockTemplateResponseMessage{
-		Block:    &RpcBlock{},
+		Block:    block,
 		IsSynced: message.IsSynced,
+		Error:    err,
 	}
-	return x.GetBlockTemplateResponse.Block.fromAppMessage(message.Block)
+	return nil
 }
 
 func (x *GetBlockTemplateResponseMessage) toAppMessage() (appmessage.Message, error) {
 	if x == nil {
 		return nil, errors.Wrapf(errorNil, "GetBlockTemplateResponseMessage is nil")
 	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	if rpcErr != nil {
+		return &appmessage.GetBlockTemplateResponseMessage{Error: rpcErr}, nil
+	}
 	msgBlock, err := x.Block.toAppMessage()
 	if err != nil {
 		return nil, err
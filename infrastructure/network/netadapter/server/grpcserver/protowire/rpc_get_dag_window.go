@@ -0,0 +1,118 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_GetDAGWindowRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetDAGWindowRequest is nil")
+	}
+	return x.GetDAGWindowRequest.toAppMessage()
+}
+
+func (x *KaspadMessage_GetDAGWindowRequest) fromAppMessage(message *appmessage.GetDAGWindowRequestMessage) error {
+	x.GetDAGWindowRequest = &GetDAGWindowRequestMessage{
+		LowBlueScore:   message.LowBlueScore,
+		HighBlueScore:  message.HighBlueScore,
+		AnticoneOfHash: message.AnticoneOfHash,
+	}
+	return nil
+}
+
+func (x *GetDAGWindowRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetDAGWindowRequestMessage is nil")
+	}
+	return &appmessage.GetDAGWindowRequestMessage{
+		LowBlueScore:   x.LowBlueScore,
+		HighBlueScore:  x.HighBlueScore,
+		AnticoneOfHash: x.AnticoneOfHash,
+	}, nil
+}
+
+func (x *KaspadMessage_GetDAGWindowResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetDAGWindowResponse is nil")
+	}
+	return x.GetDAGWindowResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_GetDAGWindowResponse) fromAppMessage(message *appmessage.GetDAGWindowResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	blocks := make([]*RpcDagWindowBlock, len(message.Blocks))
+	for i, block := range message.Blocks {
+		blocks[i] = &RpcDagWindowBlock{}
+		blocks[i].fromAppMessage(block)
+	}
+	x.GetDAGWindowResponse = &GetDAGWindowResponseMessage{
+		Blocks: blocks,
+		Error:  err,
+	}
+	return nil
+}
+
+func (x *GetDAGWindowResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetDAGWindowResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+
+	if rpcErr != nil && len(x.Blocks) != 0 {
+		return nil, errors.New("GetDAGWindowResponseMessage contains both an error and a response")
+	}
+
+	blocks := make([]*appmessage.RPCDAGWindowBlock, len(x.Blocks))
+	for i, block := range x.Blocks {
+		appBlock, err := block.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+		blocks[i] = appBlock
+	}
+	return &appmessage.GetDAGWindowResponseMessage{
+		Blocks: blocks,
+		Error:  rpcErr,
+	}, nil
+}
+
+func (x *RpcDagWindowBlock) toAppMessage() (*appmessage.RPCDAGWindowBlock, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "RpcDagWindowBlock is nil")
+	}
+	return &appmessage.RPCDAGWindowBlock{
+		Hash:                x.Hash,
+		ParentHashes:        x.ParentHashes,
+		SelectedParentHash:  x.SelectedParentHash,
+		MergeSetBluesHashes: x.MergeSetBluesHashes,
+		MergeSetRedsHashes:  x.MergeSetRedsHashes,
+		IsChainBlock:        x.IsChainBlock,
+		Color:               x.Color,
+		Status:              x.Status,
+		BlueScore:           x.BlueScore,
+		DAAScore:            x.DaaScore,
+	}, nil
+}
+
+func (x *RpcDagWindowBlock) fromAppMessage(message *appmessage.RPCDAGWindowBlock) {
+	*x = RpcDagWindowBlock{
+		Hash:                message.Hash,
+		ParentHashes:        message.ParentHashes,
+		SelectedParentHash:  message.SelectedParentHash,
+		MergeSetBluesHashes: message.MergeSetBluesHashes,
+		MergeSetRedsHashes:  message.MergeSetRedsHashes,
+		IsChainBlock:        message.IsChainBlock,
+		Color:               message.Color,
+		Status:              message.Status,
+		BlueScore:           message.BlueScore,
+		DaaScore:            message.DAAScore,
+	}
+}
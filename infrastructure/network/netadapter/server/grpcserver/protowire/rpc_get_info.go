@@ -27,10 +27,12 @@ func (x *KaspadMessage_GetInfoResponse) fromAppMessage(message *appmessage.GetIn
 		err = &RPCError{Message: message.Error.Message}
 	}
 	x.GetInfoResponse = &GetInfoResponseMessage{
-		P2PId:         message.P2PID,
-		ServerVersion: message.ServerVersion,
-		MempoolSize:   message.MempoolSize,
-		Error:         err,
+		P2PId:                 message.P2PID,
+		ServerVersion:         message.ServerVersion,
+		MempoolSize:           message.MempoolSize,
+		MempoolMass:           message.MempoolMass,
+		MempoolMinimumFeeRate: message.MempoolMinimumFeeRate,
+		Error:                 err,
 	}
 	return nil
 }
@@ -50,9 +52,11 @@ func (x *GetInfoResponseMessage) toAppMessage() (appmessage.Message, error) {
 	}
 
 	return &appmessage.GetInfoResponseMessage{
-		P2PID:         x.P2PId,
-		MempoolSize:   x.MempoolSize,
-		ServerVersion: x.ServerVersion,
-		Error:         rpcErr,
+		P2PID:                 x.P2PId,
+		MempoolSize:           x.MempoolSize,
+		ServerVersion:         x.ServerVersion,
+		MempoolMass:           x.MempoolMass,
+		MempoolMinimumFeeRate: x.MempoolMinimumFeeRate,
+		Error:                 rpcErr,
 	}, nil
 }
@@ -0,0 +1,81 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_NotifyVirtualChainReorgRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_NotifyVirtualChainReorgRequest is nil")
+	}
+	return &appmessage.NotifyVirtualChainReorgRequestMessage{}, nil
+}
+
+func (x *KaspadMessage_NotifyVirtualChainReorgRequest) fromAppMessage(_ *appmessage.NotifyVirtualChainReorgRequestMessage) error {
+	x.NotifyVirtualChainReorgRequest = &NotifyVirtualChainReorgRequestMessage{}
+	return nil
+}
+
+func (x *KaspadMessage_NotifyVirtualChainReorgResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_NotifyVirtualChainReorgResponse is nil")
+	}
+	return x.NotifyVirtualChainReorgResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_NotifyVirtualChainReorgResponse) fromAppMessage(message *appmessage.NotifyVirtualChainReorgResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	x.NotifyVirtualChainReorgResponse = &NotifyVirtualChainReorgResponseMessage{
+		Error: err,
+	}
+	return nil
+}
+
+func (x *NotifyVirtualChainReorgResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "NotifyVirtualChainReorgResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	return &appmessage.NotifyVirtualChainReorgResponseMessage{
+		Error: rpcErr,
+	}, nil
+}
+
+func (x *KaspadMessage_VirtualChainReorgNotification) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_VirtualChainReorgNotification is nil")
+	}
+	return x.VirtualChainReorgNotification.toAppMessage()
+}
+
+func (x *KaspadMessage_VirtualChainReorgNotification) fromAppMessage(message *appmessage.VirtualChainReorgNotificationMessage) error {
+	x.VirtualChainReorgNotification = &VirtualChainReorgNotificationMessage{
+		Depth:                       message.Depth,
+		RemovedChainBlockHashes:     message.RemovedChainBlockHashes,
+		AddedChainBlockHashes:       message.AddedChainBlockHashes,
+		RevertedTransactionIds:      message.RevertedTransactionIDs,
+		NewlyAcceptedTransactionIds: message.NewlyAcceptedTransactionIDs,
+	}
+	return nil
+}
+
+func (x *VirtualChainReorgNotificationMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "VirtualChainReorgNotificationMessage is nil")
+	}
+	return &appmessage.VirtualChainReorgNotificationMessage{
+		Depth:                       x.Depth,
+		RemovedChainBlockHashes:     x.RemovedChainBlockHashes,
+		AddedChainBlockHashes:       x.AddedChainBlockHashes,
+		RevertedTransactionIDs:      x.RevertedTransactionIds,
+		NewlyAcceptedTransactionIDs: x.NewlyAcceptedTransactionIds,
+	}, nil
+}
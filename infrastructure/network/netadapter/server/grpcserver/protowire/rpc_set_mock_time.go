@@ -0,0 +1,61 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_SetMockTimeRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_SetMockTimeRequest is nil")
+	}
+	return x.SetMockTimeRequest.toAppMessage()
+}
+
+func (x *KaspadMessage_SetMockTimeRequest) fromAppMessage(message *appmessage.SetMockTimeRequestMessage) error {
+	x.SetMockTimeRequest = &SetMockTimeRequestMessage{
+		MockTime: message.MockTime,
+	}
+	return nil
+}
+
+func (x *SetMockTimeRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SetMockTimeRequestMessage is nil")
+	}
+	return &appmessage.SetMockTimeRequestMessage{
+		MockTime: x.MockTime,
+	}, nil
+}
+
+func (x *KaspadMessage_SetMockTimeResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_SetMockTimeResponse is nil")
+	}
+	return x.SetMockTimeResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_SetMockTimeResponse) fromAppMessage(message *appmessage.SetMockTimeResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	x.SetMockTimeResponse = &SetMockTimeResponseMessage{
+		Error: err,
+	}
+	return nil
+}
+
+func (x *SetMockTimeResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SetMockTimeResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	return &appmessage.SetMockTimeResponseMessage{
+		Error: rpcErr,
+	}, nil
+}
@@ -310,6 +310,13 @@ func toP2PPayload(message appmessage.Message) (isKaspadMessage_Payload, error) {
 			return nil, err
 		}
 		return payload, nil
+	case *appmessage.MsgPruningPointSubnetworkRegistrations:
+		payload := new(KaspadMessage_PruningPointSubnetworkRegistrations)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
 	default:
 		return nil, nil
 	}
@@ -842,6 +849,97 @@ func toRPCPayload(message appmessage.Message) (isKaspadMessage_Payload, error) {
 			return nil, err
 		}
 		return payload, nil
+	case *appmessage.GenerateBlocksRequestMessage:
+		payload := new(KaspadMessage_GenerateBlocksRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GenerateBlocksResponseMessage:
+		payload := new(KaspadMessage_GenerateBlocksResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.SetMockTimeRequestMessage:
+		payload := new(KaspadMessage_SetMockTimeRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.SetMockTimeResponseMessage:
+		payload := new(KaspadMessage_SetMockTimeResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.BackupDatabaseRequestMessage:
+		payload := new(KaspadMessage_BackupDatabaseRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.BackupDatabaseResponseMessage:
+		payload := new(KaspadMessage_BackupDatabaseResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetDAGWindowRequestMessage:
+		payload := new(KaspadMessage_GetDAGWindowRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetDAGWindowResponseMessage:
+		payload := new(KaspadMessage_GetDAGWindowResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.NotifyVirtualChainReorgRequestMessage:
+		payload := new(KaspadMessage_NotifyVirtualChainReorgRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.NotifyVirtualChainReorgResponseMessage:
+		payload := new(KaspadMessage_NotifyVirtualChainReorgResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.VirtualChainReorgNotificationMessage:
+		payload := new(KaspadMessage_VirtualChainReorgNotification)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetDatabaseBackupStatusRequestMessage:
+		payload := new(KaspadMessage_GetDatabaseBackupStatusRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetDatabaseBackupStatusResponseMessage:
+		payload := new(KaspadMessage_GetDatabaseBackupStatusResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
 	default:
 		return nil, nil
 	}
@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// BackupDatabase sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) BackupDatabase(path string) (*appmessage.BackupDatabaseResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewBackupDatabaseRequestMessage(path))
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdBackupDatabaseResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	backupDatabaseResponse := response.(*appmessage.BackupDatabaseResponseMessage)
+	if backupDatabaseResponse.Error != nil {
+		return nil, c.convertRPCError(backupDatabaseResponse.Error)
+	}
+	return backupDatabaseResponse, nil
+}
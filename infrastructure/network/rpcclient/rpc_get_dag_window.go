@@ -0,0 +1,31 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// GetDAGWindowByBlueScore sends a GetDAGWindow RPC request for the blocks with blue scores
+// between lowBlueScore and highBlueScore, and returns the RPC server's response
+func (c *RPCClient) GetDAGWindowByBlueScore(lowBlueScore, highBlueScore uint64) (*appmessage.GetDAGWindowResponseMessage, error) {
+	return c.getDAGWindow(appmessage.NewGetDAGWindowByBlueScoreRequestMessage(lowBlueScore, highBlueScore))
+}
+
+// GetDAGWindowByAnticone sends a GetDAGWindow RPC request for the given block and its anticone,
+// and returns the RPC server's response
+func (c *RPCClient) GetDAGWindowByAnticone(anticoneOfHash string) (*appmessage.GetDAGWindowResponseMessage, error) {
+	return c.getDAGWindow(appmessage.NewGetDAGWindowByAnticoneRequestMessage(anticoneOfHash))
+}
+
+func (c *RPCClient) getDAGWindow(request *appmessage.GetDAGWindowRequestMessage) (*appmessage.GetDAGWindowResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(request)
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdGetDAGWindowResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	getDAGWindowResponse := response.(*appmessage.GetDAGWindowResponseMessage)
+	if getDAGWindowResponse.Error != nil {
+		return nil, c.convertRPCError(getDAGWindowResponse.Error)
+	}
+	return getDAGWindowResponse, nil
+}
@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// GetDatabaseBackupStatus sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) GetDatabaseBackupStatus() (*appmessage.GetDatabaseBackupStatusResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewGetDatabaseBackupStatusRequestMessage())
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdGetDatabaseBackupStatusResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	getDatabaseBackupStatusResponse := response.(*appmessage.GetDatabaseBackupStatusResponseMessage)
+	if getDatabaseBackupStatusResponse.Error != nil {
+		return nil, c.convertRPCError(getDatabaseBackupStatusResponse.Error)
+	}
+	return getDatabaseBackupStatusResponse, nil
+}
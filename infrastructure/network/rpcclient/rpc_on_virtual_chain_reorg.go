@@ -0,0 +1,41 @@
+package rpcclient
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	routerpkg "github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/pkg/errors"
+)
+
+// RegisterForVirtualChainReorgNotifications sends an RPC request respective to the function's
+// name and returns the RPC server's response. Additionally, it starts listening for the appropriate notification
+// using the given handler function
+func (c *RPCClient) RegisterForVirtualChainReorgNotifications(
+	onVirtualChainReorg func(notification *appmessage.VirtualChainReorgNotificationMessage)) error {
+
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewNotifyVirtualChainReorgRequestMessage())
+	if err != nil {
+		return err
+	}
+	response, err := c.route(appmessage.CmdNotifyVirtualChainReorgResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return err
+	}
+	notifyVirtualChainReorgResponse := response.(*appmessage.NotifyVirtualChainReorgResponseMessage)
+	if notifyVirtualChainReorgResponse.Error != nil {
+		return c.convertRPCError(notifyVirtualChainReorgResponse.Error)
+	}
+	spawn("RegisterForVirtualChainReorgNotifications", func() {
+		for {
+			notification, err := c.route(appmessage.CmdVirtualChainReorgNotificationMessage).Dequeue()
+			if err != nil {
+				if errors.Is(err, routerpkg.ErrRouteClosed) {
+					break
+				}
+				panic(err)
+			}
+			virtualChainReorgNotification := notification.(*appmessage.VirtualChainReorgNotificationMessage)
+			onVirtualChainReorg(virtualChainReorgNotification)
+		}
+	})
+	return nil
+}
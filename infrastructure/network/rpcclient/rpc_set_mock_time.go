@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// SetMockTime sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) SetMockTime(mockTime int64) (*appmessage.SetMockTimeResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewSetMockTimeRequestMessage(mockTime))
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdSetMockTimeResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	setMockTimeResponse := response.(*appmessage.SetMockTimeResponseMessage)
+	if setMockTimeResponse.Error != nil {
+		return nil, c.convertRPCError(setMockTimeResponse.Error)
+	}
+	return setMockTimeResponse, nil
+}
@@ -113,6 +113,10 @@ func (c *RPCClient) Reconnect() error {
 
 	// Attempt to connect until we succeed
 	for {
+		// The client might have been closed while we were waiting to retry
+		if atomic.LoadUint32(&c.isClosed) == 1 {
+			return nil
+		}
 		const retryDelay = 10 * time.Second
 		if time.Since(c.lastDisconnectedTime) > retryDelay {
 			err := c.connect()
@@ -0,0 +1,132 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
+)
+
+func TestBackupDatabase(t *testing.T) {
+	kaspad := &appHarness{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+		utxoIndex:               true,
+	}
+	setConfig(t, kaspad)
+	backupDir := randomDirectory(t)
+	defer os.RemoveAll(backupDir)
+	kaspad.config.EnableBackupRPC = true
+	kaspad.config.BackupDir = backupDir
+	setDatabaseContext(t, kaspad)
+	setApp(t, kaspad)
+	kaspad.app.Start()
+	setRPCClient(t, kaspad)
+	defer teardownHarness(t, kaspad)
+
+	const blockAmountToMine = 20
+	for i := 0; i < blockAmountToMine; i++ {
+		mineNextBlock(t, kaspad)
+	}
+
+	outsidePath := randomDirectory(t)
+	defer os.RemoveAll(outsidePath)
+	for _, path := range []string{outsidePath, filepath.Join(backupDir, "..", "escape"), "../escape", ".", backupDir} {
+		_, err := kaspad.rpcClient.BackupDatabase(path)
+		if err == nil {
+			t.Fatalf("Expected BackupDatabase into %s, which isn't under the backup directory, to fail", path)
+		}
+	}
+
+	response, err := kaspad.rpcClient.BackupDatabase("backup")
+	if err != nil {
+		t.Fatalf("BackupDatabase: %s", err)
+	}
+	backupPath := filepath.Join(backupDir, "backup")
+	if response.Path != backupPath {
+		t.Fatalf("Expected the backup to be written into %s, but it's written into %s", backupPath, response.Path)
+	}
+	status := waitForDatabaseBackup(t, kaspad)
+	if status.Path != backupPath || status.Failure != "" {
+		t.Fatalf("Unexpected status of the backup into %s: %+v", backupPath, status)
+	}
+	if status.EntryCount == 0 {
+		t.Fatalf("Expected the backup to have entries")
+	}
+
+	// The backup fails in the background, since the directory is no longer empty
+	_, err = kaspad.rpcClient.BackupDatabase(backupPath)
+	if err != nil {
+		t.Fatalf("BackupDatabase: %s", err)
+	}
+	status = waitForDatabaseBackup(t, kaspad)
+	if status.Failure == "" {
+		t.Fatalf("Expected BackupDatabase into a non-empty directory to fail")
+	}
+
+	// The node keeps running after the backup
+	mineNextBlock(t, kaspad)
+
+	// Start a node from the backup, and make sure it has the state the
+	// original node had when the backup was taken
+	restored := &appHarness{
+		p2pAddress:              p2pAddress2,
+		rpcAddress:              rpcAddress2,
+		miningAddress:           miningAddress2,
+		miningAddressPrivateKey: miningAddress2PrivateKey,
+		utxoIndex:               true,
+	}
+	setConfig(t, restored)
+	restored.database, err = ldb.NewLevelDB(backupPath, 8)
+	if err != nil {
+		t.Fatalf("Error opening the backup: %+v", err)
+	}
+	setApp(t, restored)
+	restored.app.Start()
+	setRPCClient(t, restored)
+	defer teardownHarness(t, restored)
+
+	dagInfo, err := restored.rpcClient.GetBlockDAGInfo()
+	if err != nil {
+		t.Fatalf("GetBlockDAGInfo: %s", err)
+	}
+	// The backup has the genesis and all the blocks that were mined before it was taken
+	if dagInfo.BlockCount != blockAmountToMine+1 {
+		t.Fatalf("Expected the restored node to have %d blocks, but it has %d",
+			blockAmountToMine+1, dagInfo.BlockCount)
+	}
+
+	utxos, err := restored.rpcClient.GetUTXOsByAddresses([]string{miningAddress1})
+	if err != nil {
+		t.Fatalf("GetUTXOsByAddresses: %s", err)
+	}
+	if len(utxos.Entries) == 0 {
+		t.Fatalf("Expected the restored UTXO index to have the UTXOs of the mined blocks")
+	}
+
+	// The restored node wasn't started with --enablebackuprpc
+	_, err = restored.rpcClient.BackupDatabase("backup")
+	if err == nil {
+		t.Fatalf("Expected BackupDatabase to fail when it's not enabled")
+	}
+}
+
+func waitForDatabaseBackup(t *testing.T, harness *appHarness) *appmessage.GetDatabaseBackupStatusResponseMessage {
+	const timeout = 30 * time.Second
+	for start := time.Now(); time.Since(start) < timeout; time.Sleep(10 * time.Millisecond) {
+		status, err := harness.rpcClient.GetDatabaseBackupStatus()
+		if err != nil {
+			t.Fatalf("GetDatabaseBackupStatus: %s", err)
+		}
+		if !status.IsRunning {
+			return status
+		}
+	}
+	t.Fatalf("The database backup didn't finish after %s", timeout)
+	return nil
+}
@@ -0,0 +1,58 @@
+package integration
+
+import (
+	"testing"
+)
+
+func TestGenerateBlocks(t *testing.T) {
+	harness, teardown := setupHarness(t, &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+	})
+	defer teardown()
+
+	const numberOfBlocks = 10
+	response, err := harness.rpcClient.GenerateBlocks(numberOfBlocks, harness.miningAddress)
+	if err != nil {
+		t.Fatalf("GenerateBlocks: %s", err)
+	}
+	if len(response.BlockHashes) != numberOfBlocks {
+		t.Fatalf("Expected %d generated blocks, but got %d", numberOfBlocks, len(response.BlockHashes))
+	}
+
+	// Every generated block is built on top of the previous one
+	for i, blockHash := range response.BlockHashes {
+		getBlockResponse, err := harness.rpcClient.GetBlock(blockHash, false)
+		if err != nil {
+			t.Fatalf("GetBlock: %s", err)
+		}
+		if i == 0 {
+			continue
+		}
+		parentHashes := getBlockResponse.Block.Header.Parents[0].ParentHashes
+		if len(parentHashes) != 1 || parentHashes[0] != response.BlockHashes[i-1] {
+			t.Fatalf("Expected generated block %s to have the single parent %s, but got %s",
+				blockHash, response.BlockHashes[i-1], parentHashes)
+		}
+	}
+
+	selectedTipHashResponse, err := harness.rpcClient.GetSelectedTipHash()
+	if err != nil {
+		t.Fatalf("GetSelectedTipHash: %s", err)
+	}
+	lastBlockHash := response.BlockHashes[numberOfBlocks-1]
+	if selectedTipHashResponse.SelectedTipHash != lastBlockHash {
+		t.Fatalf("Expected the selected tip to be %s, but got %s", lastBlockHash, selectedTipHashResponse.SelectedTipHash)
+	}
+
+	_, err = harness.rpcClient.GenerateBlocks(0, harness.miningAddress)
+	if err == nil {
+		t.Fatalf("Expected GenerateBlocks to fail for zero blocks")
+	}
+	_, err = harness.rpcClient.GenerateBlocks(1, "not an address")
+	if err == nil {
+		t.Fatalf("Expected GenerateBlocks to fail for an invalid address")
+	}
+}
@@ -0,0 +1,128 @@
+package integration
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/mining"
+)
+
+func TestGetDAGWindow(t *testing.T) {
+	harness, teardown := setupHarness(t, &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+	})
+	defer teardown()
+
+	const chainLength = 5
+	for i := 0; i < chainLength; i++ {
+		mineNextBlock(t, harness)
+	}
+
+	// Mine two sibling blocks out of the same template, so that each is in the anticone of the other
+	blockTemplate, err := harness.rpcClient.GetBlockTemplate(harness.miningAddress)
+	if err != nil {
+		t.Fatalf("Error getting block template: %+v", err)
+	}
+	rd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	siblingHashes := make([]string, 2)
+	for i := range siblingHashes {
+		block, err := appmessage.RPCBlockToDomainBlock(blockTemplate.Block)
+		if err != nil {
+			t.Fatalf("Error converting block: %s", err)
+		}
+		mutableHeader := block.Header.ToMutable()
+		mutableHeader.SetTimeInMilliseconds(block.Header.TimeInMilliseconds() + int64(i))
+		block.Header = mutableHeader.ToImmutable()
+		mining.SolveBlock(block, rd)
+		_, err = harness.rpcClient.SubmitBlock(block)
+		if err != nil {
+			t.Fatalf("Error submitting block: %s", err)
+		}
+		siblingHashes[i] = consensushashing.BlockHash(block).String()
+	}
+
+	// Merge both siblings
+	mergingBlock := mineNextBlock(t, harness)
+	mergingBlockHash := consensushashing.BlockHash(mergingBlock).String()
+
+	// The genesis, the chain, the siblings and the merging block
+	const expectedBlockCount = chainLength + 4
+	response, err := harness.rpcClient.GetDAGWindowByBlueScore(0, 100)
+	if err != nil {
+		t.Fatalf("GetDAGWindowByBlueScore: %s", err)
+	}
+	if len(response.Blocks) != expectedBlockCount {
+		t.Fatalf("Expected %d blocks, got %d", expectedBlockCount, len(response.Blocks))
+	}
+	for i, block := range response.Blocks {
+		if i > 0 && block.BlueScore < response.Blocks[i-1].BlueScore {
+			t.Fatalf("Expected the blocks to be sorted by blue score")
+		}
+		if block.Color != appmessage.DAGWindowBlockColorBlue {
+			t.Fatalf("Expected block %s to be blue, but its color is %q", block.Hash, block.Color)
+		}
+		if block.Status != "Valid" && block.Status != "UTXOPendingVerification" {
+			t.Fatalf("Unexpected status %s of block %s", block.Status, block.Hash)
+		}
+	}
+
+	lastBlock := response.Blocks[len(response.Blocks)-1]
+	if lastBlock.Hash != mergingBlockHash || !lastBlock.IsChainBlock {
+		t.Fatalf("Expected the merging block %s to be the last chain block, got %s", mergingBlockHash, lastBlock.Hash)
+	}
+	if len(lastBlock.ParentHashes) != 2 || len(lastBlock.MergeSetBluesHashes) != 2 {
+		t.Fatalf("Expected the merging block to have 2 parents and to merge 2 blues, got %d and %d",
+			len(lastBlock.ParentHashes), len(lastBlock.MergeSetBluesHashes))
+	}
+	chainBlockCount := 0
+	for _, block := range response.Blocks {
+		if block.IsChainBlock {
+			chainBlockCount++
+		}
+	}
+	// Only one of the siblings is in the selected parent chain
+	if chainBlockCount != expectedBlockCount-1 {
+		t.Fatalf("Expected %d chain blocks, got %d", expectedBlockCount-1, chainBlockCount)
+	}
+
+	response, err = harness.rpcClient.GetDAGWindowByBlueScore(1, 3)
+	if err != nil {
+		t.Fatalf("GetDAGWindowByBlueScore: %s", err)
+	}
+	if len(response.Blocks) != 3 {
+		t.Fatalf("Expected 3 blocks with blue scores between 1 and 3, got %d", len(response.Blocks))
+	}
+	for _, block := range response.Blocks {
+		if block.BlueScore < 1 || block.BlueScore > 3 {
+			t.Fatalf("Block %s with blue score %d is outside the window", block.Hash, block.BlueScore)
+		}
+	}
+
+	response, err = harness.rpcClient.GetDAGWindowByAnticone(siblingHashes[0])
+	if err != nil {
+		t.Fatalf("GetDAGWindowByAnticone: %s", err)
+	}
+	if len(response.Blocks) != 2 {
+		t.Fatalf("Expected the window to contain the sibling and its anticone, got %d blocks", len(response.Blocks))
+	}
+	for _, block := range response.Blocks {
+		if block.Hash != siblingHashes[0] && block.Hash != siblingHashes[1] {
+			t.Fatalf("Unexpected block %s in the anticone of %s", block.Hash, siblingHashes[0])
+		}
+	}
+
+	_, err = harness.rpcClient.GetDAGWindowByBlueScore(3, 1)
+	if err == nil {
+		t.Fatalf("Expected GetDAGWindowByBlueScore with lowBlueScore > highBlueScore to fail")
+	}
+	_, err = harness.rpcClient.GetDAGWindowByAnticone("not a hash")
+	if err == nil {
+		t.Fatalf("Expected GetDAGWindowByAnticone with an invalid hash to fail")
+	}
+}
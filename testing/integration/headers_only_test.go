@@ -0,0 +1,207 @@
+package integration
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+)
+
+func TestHeadersOnly(t *testing.T) {
+	harnesses, teardown := setupHarnesses(t, []*harnessParams{
+		{
+			p2pAddress:              p2pAddress1,
+			rpcAddress:              rpcAddress1,
+			miningAddress:           miningAddress1,
+			miningAddressPrivateKey: miningAddress1PrivateKey,
+		},
+		{
+			p2pAddress:              p2pAddress2,
+			rpcAddress:              rpcAddress2,
+			miningAddress:           miningAddress2,
+			miningAddressPrivateKey: miningAddress2PrivateKey,
+			headersOnly:             true,
+		},
+	})
+	defer teardown()
+
+	fullNode, headersOnlyNode := harnesses[0], harnesses[1]
+
+	const numBlocks = 10
+	for i := 0; i < numBlocks; i++ {
+		mineNextBlock(t, fullNode)
+	}
+
+	// We expect this to trigger IBD of the headers
+	connect(t, fullNode, headersOnlyNode)
+
+	waitForHeadersOnlySync(t, fullNode, headersOnlyNode)
+
+	// New blocks are relayed to the headers-only node as headers
+	onBlockAddedChan := make(chan *appmessage.BlockAddedNotificationMessage, 1)
+	setOnBlockAddedHandler(t, headersOnlyNode, func(notification *appmessage.BlockAddedNotificationMessage) {
+		onBlockAddedChan <- notification
+	})
+	minedBlockHash := consensushashing.BlockHash(mineNextBlock(t, fullNode)).String()
+	select {
+	case notification := <-onBlockAddedChan:
+		if notification.Block.VerboseData.Hash != minedBlockHash {
+			t.Fatalf("Expected a notification for block %s, but got one for %s",
+				minedBlockHash, notification.Block.VerboseData.Hash)
+		}
+	case <-time.After(defaultTimeout):
+		t.Fatalf("Timeout waiting for the headers-only node to receive block %s", minedBlockHash)
+	}
+
+	getBlockResponse, err := headersOnlyNode.rpcClient.GetBlock(minedBlockHash, false)
+	if err != nil {
+		t.Fatalf("GetBlock: %s", err)
+	}
+	if !getBlockResponse.Block.VerboseData.IsHeaderOnly {
+		t.Fatalf("Expected the headers-only node to not have the body of block %s", minedBlockHash)
+	}
+
+	// Everything that needs transactions or the UTXO set is unavailable
+	_, err = headersOnlyNode.rpcClient.GetBlock(minedBlockHash, true)
+	if err == nil {
+		t.Fatalf("Expected GetBlock with transactions to fail on the headers-only node")
+	}
+	_, err = headersOnlyNode.rpcClient.GetBlockTemplate(headersOnlyNode.miningAddress)
+	if err == nil {
+		t.Fatalf("Expected GetBlockTemplate to fail on the headers-only node")
+	}
+	_, err = headersOnlyNode.rpcClient.GetMempoolEntries()
+	if err == nil {
+		t.Fatalf("Expected GetMempoolEntries to fail on the headers-only node")
+	}
+	fullBlock, err := fullNode.rpcClient.GetBlock(minedBlockHash, true)
+	if err != nil {
+		t.Fatalf("GetBlock: %s", err)
+	}
+	_, err = headersOnlyNode.rpcClient.SubmitTransaction(fullBlock.Block.Transactions[0], false)
+	if err == nil {
+		t.Fatalf("Expected SubmitTransaction to fail on the headers-only node")
+	}
+}
+
+func TestHeadersOnlySyncPastPruningPoint(t *testing.T) {
+	overrideDAGParams := dagconfig.SimnetParams
+
+	// Increase the target time per block so that we could mine
+	// blocks with timestamps that are spaced far enough apart
+	// to avoid failing the timestamp threshold validation of
+	// ibd-with-headers-proof
+	overrideDAGParams.TargetTimePerBlock = time.Minute
+
+	// This is done to make a pruning depth of 6 blocks
+	overrideDAGParams.FinalityDuration = 2 * overrideDAGParams.TargetTimePerBlock
+	overrideDAGParams.K = 0
+	overrideDAGParams.PruningProofM = 20
+
+	harnesses, teardown := setupHarnesses(t, []*harnessParams{
+		{
+			p2pAddress:              p2pAddress1,
+			rpcAddress:              rpcAddress1,
+			miningAddress:           miningAddress1,
+			miningAddressPrivateKey: miningAddress1PrivateKey,
+			overrideDAGParams:       &overrideDAGParams,
+		},
+		{
+			p2pAddress:              p2pAddress2,
+			rpcAddress:              rpcAddress2,
+			miningAddress:           miningAddress2,
+			miningAddressPrivateKey: miningAddress2PrivateKey,
+			overrideDAGParams:       &overrideDAGParams,
+			headersOnly:             true,
+		},
+		{
+			p2pAddress:              p2pAddress3,
+			rpcAddress:              rpcAddress3,
+			miningAddress:           miningAddress3,
+			miningAddressPrivateKey: miningAddress3PrivateKey,
+			overrideDAGParams:       &overrideDAGParams,
+			headersOnly:             true,
+		},
+	})
+	defer teardown()
+
+	fullNode, relayedHeadersNode, headersProofNode := harnesses[0], harnesses[1], harnesses[2]
+
+	// The first headers-only node is connected from the start, so it gets
+	// all the headers past the genesis relayed to it one by one
+	connect(t, fullNode, relayedHeadersNode)
+
+	const numBlocks = 30
+	rd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for i := 0; i < numBlocks; i++ {
+		mineNextBlockWithMockTimestamps(t, fullNode, rd)
+	}
+	waitForHeadersOnlySync(t, fullNode, relayedHeadersNode)
+	checkHeadersOnlyPruningPoint(t, fullNode, relayedHeadersNode, overrideDAGParams.GenesisHash)
+
+	// The second one joins once the full node is several pruning points
+	// past the genesis, so it syncs with a headers proof
+	connect(t, fullNode, headersProofNode)
+	waitForHeadersOnlySync(t, fullNode, headersProofNode)
+	checkHeadersOnlyPruningPoint(t, fullNode, headersProofNode, overrideDAGParams.GenesisHash)
+
+	// Both keep moving their pruning point as new headers arrive
+	for i := 0; i < numBlocks; i++ {
+		mineNextBlockWithMockTimestamps(t, fullNode, rd)
+	}
+	for _, headersOnlyNode := range []*appHarness{relayedHeadersNode, headersProofNode} {
+		waitForHeadersOnlySync(t, fullNode, headersOnlyNode)
+		checkHeadersOnlyPruningPoint(t, fullNode, headersOnlyNode, overrideDAGParams.GenesisHash)
+	}
+}
+
+// waitForHeadersOnlySync waits until the headers selected tip of headersOnlyNode is the selected tip of fullNode
+func waitForHeadersOnlySync(t *testing.T, fullNode, headersOnlyNode *appHarness) {
+	fullNodeTip, err := fullNode.rpcClient.GetSelectedTipHash()
+	if err != nil {
+		t.Fatalf("Error getting tip for the full node: %s", err)
+	}
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	start := time.Now()
+	for range ticker.C {
+		if time.Since(start) > defaultTimeout {
+			t.Fatalf("Timeout waiting for the headers-only node to sync")
+		}
+
+		headersOnlyNodeTip, err := headersOnlyNode.rpcClient.GetSelectedTipHash()
+		if err != nil {
+			t.Fatalf("Error getting tip for the headers-only node: %s", err)
+		}
+		if headersOnlyNodeTip.SelectedTipHash == fullNodeTip.SelectedTipHash {
+			return
+		}
+	}
+}
+
+// checkHeadersOnlyPruningPoint makes sure that headersOnlyNode, which is synced with fullNode,
+// moved its pruning point past the genesis to the pruning point of fullNode
+func checkHeadersOnlyPruningPoint(t *testing.T, fullNode, headersOnlyNode *appHarness,
+	genesisHash *externalapi.DomainHash) {
+
+	fullNodeDAGInfo, err := fullNode.rpcClient.GetBlockDAGInfo()
+	if err != nil {
+		t.Fatalf("GetBlockDAGInfo: %s", err)
+	}
+	headersOnlyNodeDAGInfo, err := headersOnlyNode.rpcClient.GetBlockDAGInfo()
+	if err != nil {
+		t.Fatalf("GetBlockDAGInfo: %s", err)
+	}
+
+	if fullNodeDAGInfo.PruningPointHash == genesisHash.String() {
+		t.Fatalf("The pruning point of the full node didn't move past the genesis")
+	}
+	if headersOnlyNodeDAGInfo.PruningPointHash != fullNodeDAGInfo.PruningPointHash {
+		t.Fatalf("Expected the pruning point of the headers-only node to be %s, but got %s",
+			fullNodeDAGInfo.PruningPointHash, headersOnlyNodeDAGInfo.PruningPointHash)
+	}
+}
@@ -0,0 +1,126 @@
+package integration
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/mining"
+	"github.com/kaspanet/kaspad/util/mstime"
+)
+
+func TestSetMockTime(t *testing.T) {
+	// A node is only synced when it has peers, so the harness gets one
+	harnesses, teardown := setupHarnesses(t, []*harnessParams{
+		{
+			p2pAddress:              p2pAddress1,
+			rpcAddress:              rpcAddress1,
+			miningAddress:           miningAddress1,
+			miningAddressPrivateKey: miningAddress1PrivateKey,
+		},
+		{
+			p2pAddress:              p2pAddress2,
+			rpcAddress:              rpcAddress2,
+			miningAddress:           miningAddress2,
+			miningAddressPrivateKey: miningAddress2PrivateKey,
+		},
+	})
+	defer teardown()
+	harness, peer := harnesses[0], harnesses[1]
+	connect(t, harness, peer)
+
+	_, err := harness.rpcClient.GenerateBlocks(1, harness.miningAddress)
+	if err != nil {
+		t.Fatalf("GenerateBlocks: %s", err)
+	}
+	template, err := harness.rpcClient.GetBlockTemplate(harness.miningAddress)
+	if err != nil {
+		t.Fatalf("GetBlockTemplate: %s", err)
+	}
+	if !template.IsSynced {
+		t.Fatalf("Expected the node to be synced after generating a block")
+	}
+
+	// Move the clocks of both nodes two hours forward, so that the peer accepts the blocks
+	// generated at the mock time. The selected tip is now too old for the node to be synced
+	mockTime := mstime.Now().Add(2 * time.Hour).UnixMilliseconds()
+	for _, harness := range harnesses {
+		_, err = harness.rpcClient.SetMockTime(mockTime)
+		if err != nil {
+			t.Fatalf("SetMockTime: %s", err)
+		}
+	}
+	template, err = harness.rpcClient.GetBlockTemplate(harness.miningAddress)
+	if err != nil {
+		t.Fatalf("GetBlockTemplate: %s", err)
+	}
+	if template.IsSynced {
+		t.Fatalf("Expected the node not to be synced after its clock moved two hours forward")
+	}
+
+	// A block two hours in the future is valid according to the mock time, and brings the node back in sync
+	response, err := harness.rpcClient.GenerateBlocks(1, harness.miningAddress)
+	if err != nil {
+		t.Fatalf("GenerateBlocks: %s", err)
+	}
+	block, err := harness.rpcClient.GetBlock(response.BlockHashes[0], false)
+	if err != nil {
+		t.Fatalf("GetBlock: %s", err)
+	}
+	if block.Block.Header.Timestamp != mockTime {
+		t.Fatalf("Expected the generated block to have the mock time %d, but got %d",
+			mockTime, block.Block.Header.Timestamp)
+	}
+	template, err = harness.rpcClient.GetBlockTemplate(harness.miningAddress)
+	if err != nil {
+		t.Fatalf("GetBlockTemplate: %s", err)
+	}
+	if !template.IsSynced {
+		t.Fatalf("Expected the node to be synced after generating a block at the mock time")
+	}
+
+	_, err = harness.rpcClient.SetMockTime(-1)
+	if err == nil {
+		t.Fatalf("Expected SetMockTime to fail for a negative time")
+	}
+
+	// Simnet allows block timestamps up to 132 milliseconds ahead of the current time, so a
+	// block 100 milliseconds after the mock time is valid
+	_, err = harness.rpcClient.SubmitBlock(blockWithTimestamp(t, harness, mockTime+100))
+	if err != nil {
+		t.Fatalf("Expected a block 100 milliseconds after the mock time to be accepted: %s", err)
+	}
+
+	// Clearing the mock time brings the nodes back to the system time, by which such a block
+	// is two hours in the future
+	for _, harness := range harnesses {
+		_, err = harness.rpcClient.SetMockTime(0)
+		if err != nil {
+			t.Fatalf("SetMockTime: %s", err)
+		}
+	}
+	_, err = harness.rpcClient.SubmitBlock(blockWithTimestamp(t, harness, mockTime+200))
+	if err == nil {
+		t.Fatalf("Expected a block two hours in the future to be rejected once the mock time is cleared")
+	}
+}
+
+// blockWithTimestamp returns a solved block built on the node's block template, with the given timestamp
+func blockWithTimestamp(t *testing.T, harness *appHarness, timestamp int64) *externalapi.DomainBlock {
+	template, err := harness.rpcClient.GetBlockTemplate(harness.miningAddress)
+	if err != nil {
+		t.Fatalf("GetBlockTemplate: %s", err)
+	}
+	block, err := appmessage.RPCBlockToDomainBlock(template.Block)
+	if err != nil {
+		t.Fatalf("RPCBlockToDomainBlock: %s", err)
+	}
+	header := block.Header.ToMutable()
+	header.SetTimeInMilliseconds(timestamp)
+	block.Header = header.ToImmutable()
+
+	mining.SolveBlock(block, rand.New(rand.NewSource(time.Now().UnixNano())))
+	return block
+}
@@ -0,0 +1,103 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionhelper"
+)
+
+func TestVirtualChainReorg(t *testing.T) {
+	// Setup a couple of kaspad instances. kaspad1 and kaspad3 mine to different
+	// addresses, so that their coinbase transactions differ
+	kaspad1, _, kaspad3, teardown := standardSetup(t)
+	defer teardown()
+
+	onVirtualChainReorgChan := make(chan *appmessage.VirtualChainReorgNotificationMessage, 1)
+	err := kaspad1.rpcClient.RegisterForVirtualChainReorgNotifications(
+		func(notification *appmessage.VirtualChainReorgNotificationMessage) {
+			onVirtualChainReorgChan <- notification
+		})
+	if err != nil {
+		t.Fatalf("Failed to register for virtual chain reorg notifications: %s", err)
+	}
+
+	// In kaspad1, mine a chain over the genesis. Extending the chain
+	// is not a reorg, so no notifications are expected
+	const blockAmountToMine = 10
+	chain1BlockHashes := make([]string, blockAmountToMine)
+	chain1CoinbaseIDs := make([]string, blockAmountToMine)
+	for i := 0; i < blockAmountToMine; i++ {
+		block := mineNextBlock(t, kaspad1)
+		chain1BlockHashes[i] = consensushashing.BlockHash(block).String()
+		chain1CoinbaseIDs[i] = consensushashing.TransactionID(
+			block.Transactions[transactionhelper.CoinbaseTransactionIndex]).String()
+	}
+	select {
+	case <-onVirtualChainReorgChan:
+		t.Fatalf("Got a virtual chain reorg notification while extending the chain")
+	default:
+	}
+
+	// In kaspad3, mine a different chain of `blockAmountToMine` + 1
+	// blocks over the genesis
+	chain2CoinbaseIDs := make(map[string]struct{})
+	for i := 0; i < blockAmountToMine+1; i++ {
+		block := mineNextBlock(t, kaspad3)
+		chain2CoinbaseIDs[consensushashing.TransactionID(
+			block.Transactions[transactionhelper.CoinbaseTransactionIndex]).String()] = struct{}{}
+	}
+
+	// Connect the two kaspads. The sync makes kaspad1 reorg into the chain of kaspad3
+	connect(t, kaspad1, kaspad3)
+
+	var notification *appmessage.VirtualChainReorgNotificationMessage
+	select {
+	case notification = <-onVirtualChainReorgChan:
+	case <-time.After(defaultTimeout):
+		t.Fatalf("Timed out waiting for a virtual chain reorg notification")
+	}
+
+	// All of chain1 is removed, from its tip down
+	if notification.Depth != blockAmountToMine || len(notification.RemovedChainBlockHashes) != blockAmountToMine {
+		t.Fatalf("Unexpected reorg depth. Want: %d, got: %d with %d removed chain blocks",
+			blockAmountToMine, notification.Depth, len(notification.RemovedChainBlockHashes))
+	}
+	for i, removedChainBlockHash := range notification.RemovedChainBlockHashes {
+		expectedHash := chain1BlockHashes[blockAmountToMine-1-i]
+		if removedChainBlockHash != expectedHash {
+			t.Fatalf("Unexpected removed chain block at index %d. Want: %s, got: %s", i, expectedHash, removedChainBlockHash)
+		}
+	}
+
+	// Every chain1 block accepted the coinbase of its selected parent, so all coinbases
+	// except the one of the tip of chain1 lose their acceptance, in the order of the removed
+	// chain blocks. The coinbase of the genesis is accepted by both chains, so its acceptance
+	// is not reverted
+	expectedRevertedAmount := blockAmountToMine - 1
+	if len(notification.RevertedTransactionIDs) != expectedRevertedAmount {
+		t.Fatalf("Unexpected amount of reverted transactions. Want: %d, got: %d",
+			expectedRevertedAmount, len(notification.RevertedTransactionIDs))
+	}
+	for i, revertedTransactionID := range notification.RevertedTransactionIDs {
+		expectedTransactionID := chain1CoinbaseIDs[expectedRevertedAmount-1-i]
+		if revertedTransactionID != expectedTransactionID {
+			t.Fatalf("Unexpected reverted transaction at index %d. Want: %s, got: %s",
+				i, expectedTransactionID, revertedTransactionID)
+		}
+	}
+
+	// Likewise, every added chain block accepts the coinbase of its selected parent
+	expectedNewlyAcceptedAmount := len(notification.AddedChainBlockHashes) - 1
+	if len(notification.NewlyAcceptedTransactionIDs) != expectedNewlyAcceptedAmount {
+		t.Fatalf("Unexpected amount of newly accepted transactions. Want: %d, got: %d",
+			expectedNewlyAcceptedAmount, len(notification.NewlyAcceptedTransactionIDs))
+	}
+	for _, newlyAcceptedTransactionID := range notification.NewlyAcceptedTransactionIDs {
+		if _, ok := chain2CoinbaseIDs[newlyAcceptedTransactionID]; !ok {
+			t.Fatalf("Newly accepted transaction %s is not a coinbase of chain2", newlyAcceptedTransactionID)
+		}
+	}
+}
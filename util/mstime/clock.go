@@ -0,0 +1,56 @@
+package mstime
+
+import (
+	"sync/atomic"
+)
+
+// Clock is a source of the current time. Components that make time-dependent decisions
+// take a Clock rather than calling Now directly, so that tests can control the time they see.
+type Clock interface {
+	Now() Time
+}
+
+type systemClock struct{}
+
+// NewSystemClock returns a Clock that returns the current local time
+func NewSystemClock() Clock {
+	return systemClock{}
+}
+
+func (systemClock) Now() Time {
+	return Now()
+}
+
+// MockClock is a Clock that returns the current local time until a mock time is set,
+// and from then on returns the mock time, which stands still until it is changed again.
+// It is safe for concurrent use.
+type MockClock struct {
+	// mockTimeMilliseconds is the mock time as a Unix time in milliseconds, or 0 if no
+	// mock time is set. It must be accessed atomically
+	mockTimeMilliseconds int64
+}
+
+// NewMockClock returns a MockClock with no mock time set
+func NewMockClock() *MockClock {
+	return &MockClock{}
+}
+
+// Now returns the mock time if one is set, and the current local time otherwise
+func (c *MockClock) Now() Time {
+	mockTimeMilliseconds := atomic.LoadInt64(&c.mockTimeMilliseconds)
+	if mockTimeMilliseconds == 0 {
+		return Now()
+	}
+	return UnixMilliseconds(mockTimeMilliseconds)
+}
+
+// SetMockTime makes the clock return the given time until it is set again.
+// Setting the zero Unix time makes the clock return the current local time again
+func (c *MockClock) SetMockTime(mockTime Time) {
+	atomic.StoreInt64(&c.mockTimeMilliseconds, mockTime.UnixMilliseconds())
+}
+
+// ClearMockTime makes the clock return the current local time again
+func (c *MockClock) ClearMockTime() {
+	atomic.StoreInt64(&c.mockTimeMilliseconds, 0)
+}
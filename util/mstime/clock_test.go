@@ -0,0 +1,32 @@
+package mstime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMockClock(t *testing.T) {
+	clock := NewMockClock()
+	before := Now()
+	now := clock.Now()
+	if now.Before(before) || now.After(Now()) {
+		t.Fatalf("Expected a clock without a mock time to return the current time, but got %s", now)
+	}
+
+	mockTime := UnixMilliseconds(1_600_000_000_123)
+	clock.SetMockTime(mockTime)
+	if got := clock.Now(); got.UnixMilliseconds() != mockTime.UnixMilliseconds() {
+		t.Fatalf("Expected the mock time %d, but got %d", mockTime.UnixMilliseconds(), got.UnixMilliseconds())
+	}
+
+	advancedTime := mockTime.Add(time.Hour)
+	clock.SetMockTime(advancedTime)
+	if got := clock.Now(); got.UnixMilliseconds() != advancedTime.UnixMilliseconds() {
+		t.Fatalf("Expected the mock time %d, but got %d", advancedTime.UnixMilliseconds(), got.UnixMilliseconds())
+	}
+
+	clock.ClearMockTime()
+	if got := clock.Now(); got.Before(before) {
+		t.Fatalf("Expected a cleared clock to return the current time, but got %s", got)
+	}
+}